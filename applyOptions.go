@@ -0,0 +1,115 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// applyOptions applies request options to a request, returning an error if
+// any option conflicts with an option applied previously, rather than
+// silently overriding it.  Options conflict if:
+//
+//   - more than one option sets the body of the request;
+//
+//   - an option replaces the query established by a previous option (e.g.
+//     request.RawQuery after request.Query); options that add to the query
+//     do not conflict;
+//
+//   - options set different Content-Type headers, unless the later is a
+//     more specific structured syntax type, e.g. application/vnd.api+json
+//     following application/json (as set by request.JSONBody).
+//
+// Any body, query or Content-Type header of the request established before
+// the options are applied may be replaced by an option without conflict.
+func applyOptions(rq *http.Request, opts []RequestOption) error {
+	const none = -1
+	bodyBy, queryBy, contentTypeBy := none, none, none
+
+	for ix, opt := range opts {
+		body := rq.Body
+		query := rq.URL.RawQuery
+		contentType := rq.Header.Get("Content-Type")
+
+		if err := opt(rq); err != nil {
+			return err
+		}
+
+		conflict := func(s string, by int) error {
+			return fmt.Errorf("%w: option #%d %s set by option #%d", ErrConflictingOptions, ix+1, s, by+1)
+		}
+
+		if !sameBody(body, rq.Body) {
+			if bodyBy != none {
+				return conflict("replaces the body", bodyBy)
+			}
+			bodyBy = ix
+		}
+
+		if q := rq.URL.RawQuery; q != query {
+			if queryBy != none && !strings.HasPrefix(q, query) {
+				return conflict("replaces the query", queryBy)
+			}
+			queryBy = ix
+		}
+
+		if ct := rq.Header.Get("Content-Type"); ct != contentType {
+			if contentTypeBy != none && !refinesContentType(contentType, ct) {
+				return conflict(fmt.Sprintf("sets Content-Type %q, conflicting with %q", ct, contentType), contentTypeBy)
+			}
+			contentTypeBy = ix
+		}
+	}
+	return nil
+}
+
+// sameBody returns true if two request bodies are the same.  Bodies of a
+// type that cannot be compared are the same only if they are the same value,
+// i.e. one is a copy of the other (as when an option does not change the body
+// of a request); otherwise they are assumed to be different.
+func sameBody(a, b io.ReadCloser) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	if !va.Comparable() {
+		return bodyData(a) == bodyData(b)
+	}
+	return va.Equal(vb)
+}
+
+// bodyData returns the data pointer of a request body interface value.  A
+// copy of an interface value holds the same data pointer, whereas separately
+// assigned values of a type that cannot be compared (which always contain a
+// slice, map or func) do not.
+func bodyData(body io.ReadCloser) unsafe.Pointer {
+	return (*[2]unsafe.Pointer)(unsafe.Pointer(&body))[1]
+}
+
+// refinesContentType returns true if a content type is a more specific
+// structured syntax type of another, e.g. application/vnd.api+json is a more
+// specific type of application/json
+func refinesContentType(general, specific string) bool {
+	g, _, err := mime.ParseMediaType(general)
+	if err != nil {
+		return false
+	}
+	s, _, err := mime.ParseMediaType(specific)
+	if err != nil {
+		return false
+	}
+	if g == s {
+		return true
+	}
+
+	gtype, gsub, _ := strings.Cut(g, "/")
+	stype, ssub, _ := strings.Cut(s, "/")
+	return gtype == stype && strings.HasSuffix(ssub, "+"+gsub)
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+// uncomparableBody is a request body of a type that cannot be compared
+type uncomparableBody struct {
+	io.Reader
+	b []byte
+}
+
+func (uncomparableBody) Close() error { return nil }
+
+func TestApplyOptions(t *testing.T) {
+	// ARRANGE
+	newRequest := func() *http.Request {
+		rq, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://example.com", nil)
+		return rq
+	}
+	opterr := errors.New("option error")
+	body := func(rq *http.Request) error {
+		rq.Body = uncomparableBody{Reader: strings.NewReader("body")}
+		return nil
+	}
+
+	testcases := []struct {
+		scenario string
+		opts     []RequestOption
+		setup    func(*http.Request)
+		err      error
+		msg      string
+	}{
+		{scenario: "no options"},
+		{scenario: "compatible options",
+			opts: []RequestOption{
+				request.JSONBody(map[string]any{"id": 1}),
+				request.ContentType("application/json; charset=utf-8"),
+				request.Query(map[string]any{"a": 1}),
+				request.QueryP("b", 2),
+				request.Header("X-Key", "value"),
+			},
+		},
+		{scenario: "refined content type",
+			opts: []RequestOption{
+				request.JSONBody(map[string]any{"id": 1}),
+				request.ContentType("application/vnd.api+json"),
+			},
+		},
+		{scenario: "uncomparable body set once",
+			opts: []RequestOption{body, request.Header("X-Key", "value")},
+		},
+		{scenario: "pre-existing body, query and content type replaced",
+			setup: func(rq *http.Request) {
+				rq.Body = io.NopCloser(strings.NewReader("existing"))
+				rq.URL.RawQuery = "existing"
+				rq.Header.Set("Content-Type", "text/plain")
+			},
+			opts: []RequestOption{
+				request.JSONBody(map[string]any{"id": 1}),
+				request.RawQuery("a=1"),
+			},
+		},
+		{scenario: "uncomparable body set twice",
+			opts: []RequestOption{body, request.Header("X-Key", "value"), body},
+			err:  ErrConflictingOptions,
+			msg:  "option #3 replaces the body set by option #1",
+		},
+		{scenario: "body set twice",
+			opts: []RequestOption{
+				request.JSONBody(map[string]any{"id": 1}),
+				request.Header("X-Key", "value"),
+				request.Body([]byte("body")),
+			},
+			err: ErrConflictingOptions,
+			msg: "option #3 replaces the body set by option #1",
+		},
+		{scenario: "query replaced",
+			opts: []RequestOption{
+				request.QueryP("a", 1),
+				request.RawQuery("b=2"),
+			},
+			err: ErrConflictingOptions,
+			msg: "option #2 replaces the query set by option #1",
+		},
+		{scenario: "conflicting content types",
+			opts: []RequestOption{
+				request.ContentType("application/json"),
+				request.ContentType("text/plain"),
+			},
+			err: ErrConflictingOptions,
+			msg: `option #2 sets Content-Type "text/plain", conflicting with "application/json" set by option #1`,
+		},
+		{scenario: "option error",
+			opts: []RequestOption{func(*http.Request) error { return opterr }},
+			err:  opterr,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			rq := newRequest()
+			if tc.setup != nil {
+				tc.setup(rq)
+			}
+
+			// ACT
+			err := applyOptions(rq, tc.opts)
+
+			// ASSERT
+			test.Error(t, err).Is(tc.err)
+			if tc.msg != "" {
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), tc.msg), "error: "+tc.msg)
+			}
+		})
+	}
+}
+
+func TestRefinesContentType(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		general  string
+		specific string
+		result   bool
+	}{
+		{general: "application/json", specific: "application/json; charset=utf-8", result: true},
+		{general: "application/json", specific: "application/hal+json", result: true},
+		{general: "application/json", specific: "text/plain"},
+		{general: "application/json", specific: "text/vnd.foo+json"},
+		{general: "application/hal+json", specific: "application/json"},
+		{general: "invalid;", specific: "application/json"},
+		{general: "application/json", specific: "invalid;"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.general+" > "+tc.specific, func(t *testing.T) {
+			// ACT
+			result := refinesContentType(tc.general, tc.specific)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// TestingT describes the methods of a *testing.T (or *testing.B etc.) used to
+// report failed assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// ResponseAssertion provides methods for asserting the properties of a response,
+// returned by a real or mock client.  Failed assertions are reported to a
+// TestingT in the same style as the failed expectations of a mock client.
+//
+// Each method returns the ResponseAssertion, so that assertions may be chained:
+//
+//	http.AssertResponse(t, r).
+//		Status(http.StatusOK).
+//		HeaderEquals("Content-Type", "application/json").
+//		JSONEquals(want)
+type ResponseAssertion struct {
+	t    TestingT
+	r    *http.Response
+	body []byte
+	read bool
+}
+
+// AssertResponse returns a ResponseAssertion for a specified response.
+func AssertResponse(t TestingT, r *http.Response) *ResponseAssertion {
+	t.Helper()
+	if r == nil {
+		t.Errorf("response: <nil>")
+	}
+	return &ResponseAssertion{t: t, r: r}
+}
+
+// fail reports a failed assertion
+func (a *ResponseAssertion) fail(rpt ...string) {
+	a.t.Helper()
+	a.t.Errorf("response:\n   %s", strings.Join(rpt, "\n   "))
+}
+
+// readBody reads the body of the response (once), replacing it so that the
+// body may still be read by the caller
+func (a *ResponseAssertion) readBody() ([]byte, error) {
+	if a.read || a.r.Body == nil {
+		return a.body, nil
+	}
+
+	b, err := io.ReadAll(a.r.Body)
+	_ = a.r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	a.read = true
+	a.body = b
+	a.r.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// Status asserts that the response has a specified status code.
+func (a *ResponseAssertion) Status(want int) *ResponseAssertion {
+	a.t.Helper()
+	if a.r == nil {
+		return a
+	}
+	if a.r.StatusCode != want {
+		a.fail(
+			fmt.Sprintf("expected status: %d %s", want, http.StatusText(want)),
+			fmt.Sprintf("   got         : %d %s", a.r.StatusCode, http.StatusText(a.r.StatusCode)),
+		)
+	}
+	return a
+}
+
+// HasHeader asserts that the response has a specified header, with any value.
+func (a *ResponseAssertion) HasHeader(key string) *ResponseAssertion {
+	a.t.Helper()
+	if a.r == nil {
+		return a
+	}
+	if _, ok := a.r.Header[http.CanonicalHeaderKey(key)]; !ok {
+		a.fail(fmt.Sprintf("expected header: %s", key), "   got         : <not set>")
+	}
+	return a
+}
+
+// HeaderEquals asserts that the response has a specified header with a
+// specified value.  If the header has multiple values, the first is tested.
+func (a *ResponseAssertion) HeaderEquals(key string, want string) *ResponseAssertion {
+	a.t.Helper()
+	if a.r == nil {
+		return a
+	}
+	got := "<not set>"
+	if v, ok := a.r.Header[http.CanonicalHeaderKey(key)]; ok && len(v) > 0 {
+		if v[0] == want {
+			return a
+		}
+		got = v[0]
+	}
+	a.fail(fmt.Sprintf("expected header: %s: %s", key, want), fmt.Sprintf("   got         : %s: %s", key, got))
+	return a
+}
+
+// BodyEquals asserts that the body of the response is equal to specified bytes.
+func (a *ResponseAssertion) BodyEquals(want []byte) *ResponseAssertion {
+	a.t.Helper()
+	if a.r == nil {
+		return a
+	}
+	got, err := a.readBody()
+	switch {
+	case err != nil:
+		a.fail(fmt.Sprintf("error reading body: %v", err))
+	case !bytes.Equal(got, want):
+		a.fail(fmt.Sprintf("expected body: %s", want), fmt.Sprintf("   got       : %s", got))
+	}
+	return a
+}
+
+// JSONEquals asserts that the body of the response is JSON equivalent to a
+// specified value when marshalled as JSON.  The comparison is semantic; the
+// order of object keys and any whitespace is not significant.
+func (a *ResponseAssertion) JSONEquals(want any) *ResponseAssertion {
+	a.t.Helper()
+	if a.r == nil {
+		return a
+	}
+
+	wb, err := json.Marshal(want)
+	if err != nil {
+		a.fail(fmt.Sprintf("error marshalling expected json: %v", err))
+		return a
+	}
+	var wv any
+	_ = json.Unmarshal(wb, &wv)
+
+	got, err := a.readBody()
+	if err != nil {
+		a.fail(fmt.Sprintf("error reading body: %v", err))
+		return a
+	}
+	var gv any
+	if err := json.Unmarshal(got, &gv); err != nil {
+		a.fail(fmt.Sprintf("expected json: %s", wb), fmt.Sprintf("   got      : %s (%v)", got, err))
+		return a
+	}
+
+	if !reflect.DeepEqual(gv, wv) {
+		gb, _ := json.Marshal(gv)
+		a.fail(fmt.Sprintf("expected json: %s", wb), fmt.Sprintf("   got      : %s", gb))
+	}
+	return a
+}
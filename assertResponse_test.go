@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// fakeT is a TestingT recording any reported failures
+type fakeT struct {
+	failures []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// errorReader is a reader that returns an error
+type errorReader struct{ error }
+
+func (r errorReader) Read([]byte) (int, error) { return 0, r.error }
+
+func TestAssertResponse(t *testing.T) {
+	// ARRANGE
+	response := func(sc int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: sc,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "nil response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, nil).Status(200).HasHeader("a").HeaderEquals("a", "b").BodyEquals(nil).JSONEquals(nil)
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{"response: <nil>"})
+			},
+		},
+		{scenario: "all assertions pass",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+				r := response(http.StatusOK, `{"id": 1, "name": "x"}`)
+
+				// ACT
+				AssertResponse(ft, r).
+					Status(http.StatusOK).
+					HasHeader("content-type").
+					HeaderEquals("Content-Type", "application/json").
+					JSONEquals(map[string]any{"name": "x", "id": 1}).
+					BodyEquals([]byte(`{"id": 1, "name": "x"}`))
+
+				// ASSERT
+				test.Strings(t, ft.failures).IsEmpty()
+				b, _ := io.ReadAll(r.Body)
+				test.That(t, string(b)).Equals(`{"id": 1, "name": "x"}`, "body remains readable")
+			},
+		},
+		{scenario: "status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusNotFound, "")).Status(http.StatusOK)
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"response:\n   expected status: 200 OK\n      got         : 404 Not Found",
+				})
+			},
+		},
+		{scenario: "headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusOK, "")).
+					HasHeader("X-Missing").
+					HeaderEquals("X-Missing", "value").
+					HeaderEquals("Content-Type", "text/plain")
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"response:\n   expected header: X-Missing\n      got         : <not set>",
+					"response:\n   expected header: X-Missing: value\n      got         : X-Missing: <not set>",
+					"response:\n   expected header: Content-Type: text/plain\n      got         : Content-Type: application/json",
+				})
+			},
+		},
+		{scenario: "body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusOK, "got")).BodyEquals([]byte("wanted"))
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"response:\n   expected body: wanted\n      got       : got",
+				})
+			},
+		},
+		{scenario: "json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusOK, `{"id": 2}`)).JSONEquals(map[string]int{"id": 1})
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"response:\n   expected json: {\"id\":1}\n      got      : {\"id\":2}",
+				})
+			},
+		},
+		{scenario: "json/invalid body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusOK, `not json`)).JSONEquals(1)
+
+				// ASSERT
+				test.That(t, len(ft.failures)).Equals(1)
+				test.IsTrue(t, strings.HasPrefix(ft.failures[0], "response:\n   expected json: 1\n      got      : not json ("))
+			},
+		},
+		{scenario: "json/unmarshallable value",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+
+				// ACT
+				AssertResponse(ft, response(http.StatusOK, `{}`)).JSONEquals(func() {})
+
+				// ASSERT
+				test.That(t, len(ft.failures)).Equals(1)
+				test.IsTrue(t, strings.HasPrefix(ft.failures[0], "response:\n   error marshalling expected json: "))
+			},
+		},
+		{scenario: "error reading body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ft := &fakeT{}
+				r := response(http.StatusOK, "")
+				r.Body = io.NopCloser(errorReader{errors.New("read error")})
+
+				// ACT
+				AssertResponse(ft, r).BodyEquals(nil).JSONEquals(nil)
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"response:\n   error reading body: read error",
+					"response:\n   error reading body: read error",
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
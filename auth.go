@@ -0,0 +1,26 @@
+package http
+
+// Auth configures credentials to be applied to every request initialised by
+// the client (using NewRequest, or any of the convenience methods such as Get
+// and Invoke), so that they need not be repeated for each request.  Typically
+// the option is one of the request authentication options, for example:
+//
+//	c, err := http.NewClient("billing",
+//		http.URL("https://billing.example.com"),
+//		http.Auth(request.BasicAuth(user, password)),
+//	)
+//
+// The option is applied before any options configured for a tenant (see:
+// Tenants) and those supplied for the request itself, so that options which
+// set (rather than add) a header, such as request.BasicAuth and
+// request.APIKey, may replace the credentials for individual requests.  As for tenant configuration, the
+// option is not applied to requests initialised separately and performed
+// using Do.
+//
+// A nil option removes any credentials configured previously.
+func Auth(opt RequestOption) ClientOption {
+	return func(c *client) error {
+		c.auth = opt
+		return nil
+	}
+}
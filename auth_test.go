@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestAuth(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := Auth(request.APIKey("X-Api-Key", "key"))(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.auth).IsNotNil()
+			},
+		},
+		{scenario: "applied to requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"), Auth(request.BasicAuth("user", "pass")))
+
+				// ACT
+				rq, err := c.NewRequest(ctx, "GET", "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				user, _, ok := rq.BasicAuth()
+				test.IsTrue(t, ok, "basic auth")
+				test.That(t, user).Equals("user")
+			},
+		},
+		{scenario: "replaced by request option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"), Auth(request.BasicAuth("user", "pass")))
+
+				// ACT
+				rq, err := c.NewRequest(ctx, "GET", "path", request.BasicAuth("other", "pass"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				user, _, _ := rq.BasicAuth()
+				test.That(t, user).Equals("other")
+			},
+		},
+		{scenario: "replaced by tenant option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				provider := TenantProviderFunc(func(context.Context, string) (TenantConfig, error) {
+					return TenantConfig{Options: []RequestOption{request.APIKey("X-Api-Key", "tenant-key")}}, nil
+				})
+				c, _ := NewClient("name",
+					URL("https://example.com"),
+					Auth(request.APIKey("X-Api-Key", "client-key")),
+					Tenants(provider),
+				)
+
+				// ACT
+				rq, err := c.NewRequest(Tenant(ctx, "acme"), "GET", "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, rq.Header.Values("X-Api-Key")).Equals([]string{"tenant-key"})
+			},
+		},
+		{scenario: "option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"), Auth(request.APIKey("", "")))
+
+				// ACT
+				_, err := c.NewRequest(ctx, "GET", "path")
+
+				// ASSERT
+				test.Error(t, err).Is(request.ErrMissingCredentials)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/blugnu/http/request"
+)
+
+// maxPinnedKeys is the maximum number of affinity keys pinned to a backend;
+// once exceeded, the least recently used key is forgotten
+const maxPinnedKeys = 10000
+
+// backends holds the backend hosts across which the requests of a client are
+// distributed (see: Backends)
+type backends struct {
+	mu        sync.Mutex
+	hosts     []string
+	cooldown  time.Duration
+	next      int
+	unhealthy map[int]time.Time
+	pinned    map[string]*list.Element
+	pins      *list.List
+}
+
+// backendPin is an item in the lru list of the affinity keys pinned to a
+// backend
+type backendPin struct {
+	key string
+	ix  int
+}
+
+// affinityKey is the key under which an affinity key is held in a context
+type affinityKey struct{}
+
+// Backends configures a client to distribute requests across a number of
+// backend hosts (each a host or host:port), e.g. the replicas of a service
+// that does not sit behind a load balancer.
+//
+// Requests addressed to the host of the client url are routed to each of the
+// backends in turn, skipping any backend that is unhealthy.  A backend is
+// unhealthy for a specified cooldown period after a request to it fails
+// without a response (e.g. the connection is refused); if all backends are
+// unhealthy, requests are routed to each in turn regardless.
+//
+// A request with an affinity key (see: WithAffinity and request.Affinity) is
+// routed to the same backend as any previous request with the same key, for
+// as long as that backend remains healthy, as required by upstreams with
+// sticky sessions.  If the backend becomes unhealthy, the key is pinned to
+// another backend.  Up to 10000 affinity keys are pinned; once exceeded, the
+// least recently used key is forgotten.
+//
+// Each attempt to perform a request is routed separately, so a request that is
+// retried after failing without a response is retried using another backend.
+func Backends(cooldown time.Duration, hosts ...string) ClientOption {
+	return func(c *client) error {
+		if len(hosts) == 0 {
+			return errors.New("http: Backends option: no hosts specified")
+		}
+		for _, h := range hosts {
+			if h == "" {
+				return errors.New("http: Backends option: host must not be empty")
+			}
+		}
+		if cooldown < 0 {
+			return fmt.Errorf("http: Backends option: cooldown must not be negative: %v", cooldown)
+		}
+		c.backends = &backends{
+			hosts:     append([]string{}, hosts...),
+			cooldown:  cooldown,
+			unhealthy: map[int]time.Time{},
+			pinned:    map[string]*list.Element{},
+			pins:      list.New(),
+		}
+		return nil
+	}
+}
+
+// WithAffinity returns a context carrying an affinity key, pinning requests
+// made with the context to the same backend (see: Backends).
+func WithAffinity(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, affinityKey{}, key)
+}
+
+// AffinityFromContext returns any affinity key carried by a context (see:
+// WithAffinity).  If the context carries no affinity key, an empty string is
+// returned.
+func AffinityFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(affinityKey{}).(string)
+	return key
+}
+
+// route returns a copy of a request addressed to the host of a specified base
+// url, routed to a backend.  Requests addressed to any other host are returned
+// unchanged.
+func (b *backends) route(rq *http.Request, base string) *http.Request {
+	u, err := url.Parse(base)
+	if err != nil || rq.URL.Host != u.Host {
+		return rq
+	}
+
+	key := AffinityFromContext(rq.Context())
+	if cfg, ok := request.ConfigFromContext(rq.Context()); ok && cfg.Affinity != "" {
+		key = cfg.Affinity
+	}
+
+	ix := b.pick(key)
+	routed := rq.WithContext(rq.Context())
+	ru := *rq.URL
+	ru.Host = b.hosts[ix]
+	routed.URL = &ru
+	if routed.Host == rq.URL.Host {
+		routed.Host = ""
+	}
+	return routed
+}
+
+// pick returns the index of the backend to which a request with a specified
+// affinity key (if any) is routed
+func (b *backends) pick(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := timeNow()
+	healthy := func(ix int) bool {
+		until, ok := b.unhealthy[ix]
+		if ok && !now.Before(until) {
+			delete(b.unhealthy, ix)
+			return true
+		}
+		return !ok
+	}
+
+	if el, ok := b.pinned[key]; ok && key != "" && healthy(el.Value.(*backendPin).ix) {
+		b.pins.MoveToFront(el)
+		return el.Value.(*backendPin).ix
+	}
+
+	ix := b.next
+	for range b.hosts {
+		if healthy(b.next) {
+			ix = b.next
+			break
+		}
+		b.next = (b.next + 1) % len(b.hosts)
+	}
+	b.next = (ix + 1) % len(b.hosts)
+
+	if key != "" {
+		b.pin(key, ix)
+	}
+	return ix
+}
+
+// pin pins an affinity key to the backend with a specified index, forgetting
+// the least recently used key if the maximum number of keys is exceeded
+func (b *backends) pin(key string, ix int) {
+	if el, ok := b.pinned[key]; ok {
+		el.Value.(*backendPin).ix = ix
+		b.pins.MoveToFront(el)
+		return
+	}
+
+	b.pinned[key] = b.pins.PushFront(&backendPin{key: key, ix: ix})
+	if b.pins.Len() > maxPinnedKeys {
+		el := b.pins.Back()
+		b.pins.Remove(el)
+		delete(b.pinned, el.Value.(*backendPin).key)
+	}
+}
+
+// failed marks any backend with a specified host as unhealthy for the
+// cooldown period
+func (b *backends) failed(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ix, h := range b.hosts {
+		if h == host {
+			b.unhealthy[ix] = timeNow().Add(b.cooldown)
+		}
+	}
+}
+
+// backendDoer wraps a Doer, routing each request addressed to the host of the
+// client url to a backend and marking the backend as unhealthy if the request
+// fails without a response (other than as a result of the request context
+// being done)
+type backendDoer struct {
+	Doer
+	backends *backends
+	base     string
+}
+
+// Do implements Doer
+func (bd backendDoer) Do(rq *http.Request) (*http.Response, error) {
+	rq = bd.backends.route(rq, bd.base)
+	r, err := bd.Doer.Do(rq)
+	if err != nil && r == nil && rq.Context().Err() == nil {
+		bd.backends.failed(rq.URL.Host)
+	}
+	return r, err
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestBackends(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	og := timeNow
+	defer func() { timeNow = og }()
+	timeNow = func() time.Time { return now }
+
+	// server returns a client with specified backends, recording the host
+	// of each request and failing requests to any host identified as down
+	refused := errors.New("connection refused")
+	server := func(sent *[]string, down map[string]bool, hosts ...string) HttpClient {
+		c, _ := NewClient("name",
+			URL("http://service"),
+			Backends(time.Minute, hosts...),
+			Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+				*sent = append(*sent, rq.URL.Host)
+				if down[rq.URL.Host] {
+					return nil, refused
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})),
+		)
+		return c
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/no hosts",
+			exec: func(t *testing.T) {
+				// ACT
+				err := Backends(time.Minute)(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/empty host",
+			exec: func(t *testing.T) {
+				// ACT
+				err := Backends(time.Minute, "a", "")(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/negative cooldown",
+			exec: func(t *testing.T) {
+				// ACT
+				err := Backends(-time.Minute, "a")(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "round robin",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, nil, "a:8080", "b:8080")
+
+				// ACT
+				for i := 0; i < 3; i++ {
+					_, _ = c.Get(ctx, "path")
+				}
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"a:8080", "b:8080", "a:8080"})
+			},
+		},
+		{scenario: "other hosts are not routed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, nil, "a", "b")
+				rq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://other/path", nil)
+
+				// ACT
+				_, _ = c.Do(rq)
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"other"})
+			},
+		},
+		{scenario: "unhealthy backend is skipped until cooldown expires",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer func(t time.Time) { now = t }(now)
+				sent := []string{}
+				down := map[string]bool{"a": true}
+				c := server(&sent, down, "a", "b")
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+				_, _ = c.Get(ctx, "path")
+				_, _ = c.Get(ctx, "path")
+				down["a"] = false
+				now = now.Add(time.Minute)
+				_, _ = c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(refused)
+				test.Strings(t, sent).Equals([]string{"a", "b", "b", "a"})
+			},
+		},
+		{scenario: "retry is routed to a healthy backend",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, map[string]bool{"a": true}, "a", "b")
+
+				// ACT
+				_, err := c.Get(WithAffinity(ctx, "session-1"), "path", request.MaxRetries(1), request.Backoff(NoBackoff))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, sent).Equals([]string{"a", "b"})
+			},
+		},
+		{scenario: "affinity/least recently used key is forgotten",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &backends{
+					hosts:     []string{"a", "b"},
+					unhealthy: map[int]time.Time{},
+					pinned:    map[string]*list.Element{},
+					pins:      list.New(),
+				}
+				first := b.pick("first")
+				_ = b.pick("second")
+
+				// ACT
+				for i := 0; i < maxPinnedKeys; i++ {
+					_ = b.pick(fmt.Sprintf("key-%d", i))
+					if i == 0 {
+						_ = b.pick("first")
+					}
+				}
+
+				// ASSERT
+				test.That(t, b.pins.Len()).Equals(maxPinnedKeys)
+				test.That(t, len(b.pinned)).Equals(maxPinnedKeys)
+				_, pinned := b.pinned["second"]
+				test.IsFalse(t, pinned, "second is pinned")
+				test.That(t, b.pinned["first"].Value.(*backendPin).ix).Equals(first)
+			},
+		},
+		{scenario: "all backends unhealthy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, map[string]bool{"a": true, "b": true}, "a", "b")
+
+				// ACT
+				for i := 0; i < 3; i++ {
+					_, _ = c.Get(ctx, "path")
+				}
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"a", "b", "a"})
+			},
+		},
+		{scenario: "affinity/pinned to backend",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, nil, "a", "b", "c")
+				session := WithAffinity(ctx, "session-1")
+
+				// ACT
+				_, _ = c.Get(ctx, "path")
+				_, _ = c.Get(session, "path")
+				_, _ = c.Get(ctx, "path")
+				_, _ = c.Get(session, "path")
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"a", "b", "c", "b"})
+			},
+		},
+		{scenario: "affinity/request option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(&sent, nil, "a", "b")
+
+				// ACT
+				_, _ = c.Get(WithAffinity(ctx, "session-1"), "path")
+				_, _ = c.Get(ctx, "path", request.Affinity("session-2"))
+				_, _ = c.Get(ctx, "path", request.Affinity("session-1"))
+				_, _ = c.Get(WithAffinity(ctx, "session-1"), "path", request.Affinity("session-2"))
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"a", "b", "a", "b"})
+			},
+		},
+		{scenario: "affinity/repinned when backend is unhealthy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				down := map[string]bool{}
+				c := server(&sent, down, "a", "b")
+				session := WithAffinity(ctx, "session-1")
+				_, _ = c.Get(session, "path")
+
+				// ACT
+				down["a"] = true
+				_, _ = c.Get(session, "path")
+				down["a"] = false
+				_, _ = c.Get(session, "path")
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"a", "a", "b"})
+			},
+		},
+		{scenario: "AffinityFromContext/no key",
+			exec: func(t *testing.T) {
+				// ACT
+				result := AffinityFromContext(ctx)
+
+				// ASSERT
+				test.That(t, result).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
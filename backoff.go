@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// randInt63n is used to apply jitter to backoff delays; it may be replaced in
+// tests for deterministic delays
+var randInt63n = rand.Int63n
+
+// Default parameters of the exponential backoff policy used by a client if no
+// other policy is configured
+const (
+	DefaultBackoffBase = 100 * time.Millisecond
+	DefaultBackoffMax  = 10 * time.Second
+)
+
+// BackoffPolicy determines the delay before each retry of a request.  It is
+// called with the number of the retry (1 for the first retry) and returns the
+// delay before that retry is attempted.
+//
+// A policy is configured on a client using the Backoff client option and may be
+// overridden for an individual request using the request.Backoff option.
+type BackoffPolicy func(retry uint) time.Duration
+
+// Backoff configures the backoff policy of the client, determining the delay
+// before each retry of a failed request.  If no policy is configured the client
+// uses ExponentialBackoff(DefaultBackoffBase, DefaultBackoffMax).
+//
+// Waits are context-aware; if the context of a request is cancelled while
+// waiting to retry, the wait is interrupted and the request fails.
+func Backoff(policy BackoffPolicy) ClientOption {
+	return func(c *client) error {
+		c.backoff = policy
+		return nil
+	}
+}
+
+// ConstantBackoff returns a BackoffPolicy with the same delay before every retry.
+func ConstantBackoff(d time.Duration) BackoffPolicy {
+	return func(uint) time.Duration { return d }
+}
+
+// ExponentialBackoff returns a BackoffPolicy with a delay that doubles with each
+// retry, starting from a base delay and limited to a maximum, with jitter.
+//
+// The jitter is "equal jitter": the delay before each retry is a random duration
+// between half and all of the exponential delay.  This spreads retries from
+// many clients experiencing the same failure, while ensuring that each client
+// waits for at least half of the exponential delay.
+func ExponentialBackoff(base time.Duration, max time.Duration) BackoffPolicy {
+	return func(retry uint) time.Duration {
+		d := base
+		for i := uint(1); i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if d <= 1 {
+			return d
+		}
+		half := d / 2
+		return half + time.Duration(randInt63n(int64(d-half)+1))
+	}
+}
+
+// NoBackoff is a BackoffPolicy with no delay before any retry.
+func NoBackoff(uint) time.Duration {
+	return 0
+}
+
+// defaultBackoff is the backoff policy used by a client if no other policy
+// is configured
+var defaultBackoff = ExponentialBackoff(DefaultBackoffBase, DefaultBackoffMax)
+
+// canRetry returns true if a retry, following a specified delay and taking at
+// least a specified duration, could complete before a specified time (if not
+// zero) and the deadline (if any) of a context.
+func canRetry(ctx context.Context, until time.Time, delay time.Duration, duration time.Duration) bool {
+	complete := timeNow().Add(delay + duration)
+	if !until.IsZero() && !complete.Before(until) {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && !complete.Before(deadline) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,460 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestBackoffPolicies(t *testing.T) {
+	// ARRANGE
+	og := randInt63n
+	defer func() { randInt63n = og }()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ConstantBackoff",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := ConstantBackoff(time.Second)
+
+				// ACT & ASSERT
+				test.That(t, sut(1)).Equals(time.Second)
+				test.That(t, sut(5)).Equals(time.Second)
+			},
+		},
+		{scenario: "NoBackoff",
+			exec: func(t *testing.T) {
+				// ACT & ASSERT
+				test.That(t, NoBackoff(1)).Equals(0)
+			},
+		},
+		{scenario: "ExponentialBackoff/minimum jitter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				randInt63n = func(int64) int64 { return 0 }
+				sut := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+				// ACT
+				result := []time.Duration{sut(1), sut(2), sut(3), sut(4), sut(5), sut(100)}
+
+				// ASSERT
+				test.Slice(t, result).Equals([]time.Duration{
+					50 * time.Millisecond,
+					100 * time.Millisecond,
+					200 * time.Millisecond,
+					400 * time.Millisecond,
+					500 * time.Millisecond,
+					500 * time.Millisecond,
+				})
+			},
+		},
+		{scenario: "ExponentialBackoff/maximum jitter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				randInt63n = func(n int64) int64 { return n - 1 }
+				sut := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+				// ACT
+				result := []time.Duration{sut(1), sut(4), sut(5)}
+
+				// ASSERT
+				test.Slice(t, result).Equals([]time.Duration{
+					100 * time.Millisecond,
+					800 * time.Millisecond,
+					time.Second,
+				})
+			},
+		},
+		{scenario: "ExponentialBackoff/zero base",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := ExponentialBackoff(0, time.Second)
+
+				// ACT & ASSERT
+				test.That(t, sut(3)).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	// ARRANGE
+	og := timeAfter
+	defer func() { timeAfter = og }()
+	timeAfter = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	failing := DoerFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("failed")
+	})
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := Backoff(ConstantBackoff(time.Second))(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.backoff(1)).Equals(time.Second)
+			},
+		},
+		{scenario: "client policy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				waits := []time.Duration{}
+				c, _ := NewClient("name",
+					Using(failing),
+					MaxRetries(3),
+					Backoff(func(retry uint) time.Duration { return time.Duration(retry) * time.Second }),
+					OnWait(func(reason string, d time.Duration) {
+						test.That(t, reason).Equals(WaitBackoff)
+						waits = append(waits, d)
+					}),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMaxRetriesExceeded)
+				test.Slice(t, waits).Equals([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second})
+			},
+		},
+		{scenario: "request policy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				waits := []time.Duration{}
+				c, _ := NewClient("name",
+					Using(failing),
+					MaxRetries(2),
+					Backoff(ConstantBackoff(time.Second)),
+					OnWait(func(_ string, d time.Duration) { waits = append(waits, d) }),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.Backoff(ConstantBackoff(time.Minute)))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMaxRetriesExceeded)
+				test.Slice(t, waits).Equals([]time.Duration{time.Minute, time.Minute})
+			},
+		},
+		{scenario: "no backoff",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				waits := 0
+				c, _ := NewClient("name",
+					Using(failing),
+					MaxRetries(2),
+					Backoff(NoBackoff),
+					OnWait(func(string, time.Duration) { waits++ }),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMaxRetriesExceeded)
+				test.That(t, waits).Equals(0)
+			},
+		},
+		{scenario: "context cancelled while waiting",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				attempts := 0
+				c, _ := NewClient("name",
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						attempts++
+						return nil, errors.New("failed")
+					})),
+					MaxRetries(2),
+					OnWait(func(string, time.Duration) { cancel() }),
+				)
+				timeAfter = func(time.Duration) <-chan time.Time { return nil }
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.That(t, attempts).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestCanRetry(t *testing.T) {
+	// ARRANGE
+	og := timeNow
+	defer func() { timeNow = og }()
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(10*time.Second))
+	defer cancel()
+
+	testcases := []struct {
+		scenario string
+		ctx      context.Context
+		until    time.Time
+		delay    time.Duration
+		duration time.Duration
+		result   bool
+	}{
+		{scenario: "no deadline", ctx: context.Background(), delay: time.Hour, duration: time.Hour, result: true},
+		{scenario: "before deadline", ctx: ctx, delay: 4 * time.Second, duration: 5 * time.Second, result: true},
+		{scenario: "at deadline", ctx: ctx, delay: 5 * time.Second, duration: 5 * time.Second, result: false},
+		{scenario: "after deadline", ctx: ctx, delay: time.Second, duration: 10 * time.Second, result: false},
+		{scenario: "before until", ctx: context.Background(), until: now.Add(time.Minute), delay: time.Second, duration: time.Second, result: true},
+		{scenario: "after until", ctx: context.Background(), until: now.Add(time.Minute), delay: time.Minute, duration: time.Second, result: false},
+		{scenario: "before until, after deadline", ctx: ctx, until: now.Add(time.Minute), delay: 10 * time.Second, duration: time.Second, result: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			result := canRetry(tc.ctx, tc.until, tc.delay, tc.duration)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
+
+func TestClientRetryDeadline(t *testing.T) {
+	// ARRANGE
+	ogNow := timeNow
+	ogSince := timeSince
+	defer func() {
+		timeNow = ogNow
+		timeSince = ogSince
+	}()
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	timeSince = func(time.Time) time.Duration { return 2 * time.Second }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "error/insufficient time to retry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithDeadline(context.Background(), now.Add(3*time.Second))
+				defer cancel()
+
+				attempts := 0
+				cause := errors.New("failed")
+				c, _ := NewClient("name",
+					MaxRetries(3),
+					Backoff(ConstantBackoff(time.Second)),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						attempts++
+						return nil, cause
+					})),
+				)
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRetryDeadline)
+				test.Error(t, err).Is(cause)
+				test.That(t, attempts).Equals(1)
+			},
+		},
+		{scenario: "status/insufficient time to retry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithDeadline(context.Background(), now.Add(3*time.Second))
+				defer cancel()
+
+				attempts := 0
+				c, _ := NewClient("name",
+					MaxRetries(3),
+					Backoff(ConstantBackoff(time.Second)),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						attempts++
+						return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+					})),
+				)
+
+				// ACT
+				r, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRetryDeadline)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusServiceUnavailable)
+				test.That(t, attempts).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestClientRetryModes(t *testing.T) {
+	// ARRANGE
+	ogNow := timeNow
+	ogSince := timeSince
+	ogAfter := timeAfter
+	defer func() {
+		timeNow = ogNow
+		timeSince = ogSince
+		timeAfter = ogAfter
+	}()
+
+	// the clock advances by one second for each attempt and by the duration
+	// of each wait
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	timeSince = func(time.Time) time.Duration {
+		now = now.Add(time.Second)
+		return time.Second
+	}
+	timeAfter = func(d time.Duration) <-chan time.Time {
+		now = now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+
+	// failing returns a Doer that fails (with rqerr) a specified number of
+	// times before succeeding, recording the number of attempts in n
+	rqerr := errors.New("failed")
+	failing := func(n *int, failures int) Doer {
+		return DoerFunc(func(*http.Request) (*http.Response, error) {
+			*n++
+			if *n <= failures {
+				return nil, rqerr
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "request.MaxRetries(0) disables client retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(failing(&n, 1)), MaxRetries(3))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.MaxRetries(0))
+
+				// ASSERT
+				test.Error(t, err).Is(rqerr)
+				test.IsFalse(t, errors.Is(err, ErrMaxRetriesExceeded), "max retries exceeded")
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "request.MaxRetries(0) disables unlimited client retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(failing(&n, 1)), UnlimitedRetriesWithin(0))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.MaxRetries(0))
+
+				// ASSERT
+				test.Error(t, err).Is(rqerr)
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "unlimited retries succeed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(failing(&n, 20)), UnlimitedRetriesWithin(0), Backoff(ConstantBackoff(time.Minute)))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(21)
+			},
+		},
+		{scenario: "unlimited retries of retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name",
+					UnlimitedRetriesWithin(0),
+					Backoff(NoBackoff),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						n++
+						if n <= 5 {
+							return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+					})),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(6)
+			},
+		},
+		{scenario: "unlimited retries within duration",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(failing(&n, 100)), Backoff(ConstantBackoff(9*time.Second)))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.UnlimitedRetriesWithin(time.Minute))
+
+				// ASSERT
+				// each attempt takes 10s (including backoff), so the 6th retry
+				// could not complete within a minute
+				test.Error(t, err).Is(ErrRetryDeadline)
+				test.That(t, n).Equals(6)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
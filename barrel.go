@@ -13,6 +13,7 @@ type (
 
 var (
 	NoBody         = http.NoBody
+	StatusText     = http.StatusText
 	ListenAndServe = http.ListenAndServe
 )
 
@@ -29,11 +30,111 @@ const (
 )
 
 const (
-	StatusBadRequest          = http.StatusBadRequest
-	StatusForbidden           = http.StatusForbidden
-	StatusInternalServerError = http.StatusInternalServerError
-	StatusNotAcceptable       = http.StatusNotAcceptable
-	StatusNotFound            = http.StatusNotFound
-	StatusOK                  = http.StatusOK
-	StatusUnauthorized        = http.StatusUnauthorized
+	// 1xx informational
+	StatusContinue           = http.StatusContinue
+	StatusSwitchingProtocols = http.StatusSwitchingProtocols
+	StatusProcessing         = http.StatusProcessing
+	StatusEarlyHints         = http.StatusEarlyHints
+
+	// 2xx success
+	StatusOK                   = http.StatusOK
+	StatusCreated              = http.StatusCreated
+	StatusAccepted             = http.StatusAccepted
+	StatusNonAuthoritativeInfo = http.StatusNonAuthoritativeInfo
+	StatusNoContent            = http.StatusNoContent
+	StatusResetContent         = http.StatusResetContent
+	StatusPartialContent       = http.StatusPartialContent
+	StatusMultiStatus          = http.StatusMultiStatus
+	StatusAlreadyReported      = http.StatusAlreadyReported
+	StatusIMUsed               = http.StatusIMUsed
+
+	// 3xx redirection
+	StatusMultipleChoices   = http.StatusMultipleChoices
+	StatusMovedPermanently  = http.StatusMovedPermanently
+	StatusFound             = http.StatusFound
+	StatusSeeOther          = http.StatusSeeOther
+	StatusNotModified       = http.StatusNotModified
+	StatusUseProxy          = http.StatusUseProxy
+	StatusTemporaryRedirect = http.StatusTemporaryRedirect
+	StatusPermanentRedirect = http.StatusPermanentRedirect
+
+	// 4xx client errors
+	StatusBadRequest                   = http.StatusBadRequest
+	StatusUnauthorized                 = http.StatusUnauthorized
+	StatusPaymentRequired              = http.StatusPaymentRequired
+	StatusForbidden                    = http.StatusForbidden
+	StatusNotFound                     = http.StatusNotFound
+	StatusMethodNotAllowed             = http.StatusMethodNotAllowed
+	StatusNotAcceptable                = http.StatusNotAcceptable
+	StatusProxyAuthRequired            = http.StatusProxyAuthRequired
+	StatusRequestTimeout               = http.StatusRequestTimeout
+	StatusConflict                     = http.StatusConflict
+	StatusGone                         = http.StatusGone
+	StatusLengthRequired               = http.StatusLengthRequired
+	StatusPreconditionFailed           = http.StatusPreconditionFailed
+	StatusRequestEntityTooLarge        = http.StatusRequestEntityTooLarge
+	StatusRequestURITooLong            = http.StatusRequestURITooLong
+	StatusUnsupportedMediaType         = http.StatusUnsupportedMediaType
+	StatusRequestedRangeNotSatisfiable = http.StatusRequestedRangeNotSatisfiable
+	StatusExpectationFailed            = http.StatusExpectationFailed
+	StatusTeapot                       = http.StatusTeapot
+	StatusMisdirectedRequest           = http.StatusMisdirectedRequest
+	StatusUnprocessableEntity          = http.StatusUnprocessableEntity
+	StatusLocked                       = http.StatusLocked
+	StatusFailedDependency             = http.StatusFailedDependency
+	StatusTooEarly                     = http.StatusTooEarly
+	StatusUpgradeRequired              = http.StatusUpgradeRequired
+	StatusPreconditionRequired         = http.StatusPreconditionRequired
+	StatusTooManyRequests              = http.StatusTooManyRequests
+	StatusRequestHeaderFieldsTooLarge  = http.StatusRequestHeaderFieldsTooLarge
+	StatusUnavailableForLegalReasons   = http.StatusUnavailableForLegalReasons
+
+	// 5xx server errors
+	StatusInternalServerError           = http.StatusInternalServerError
+	StatusNotImplemented                = http.StatusNotImplemented
+	StatusBadGateway                    = http.StatusBadGateway
+	StatusServiceUnavailable            = http.StatusServiceUnavailable
+	StatusGatewayTimeout                = http.StatusGatewayTimeout
+	StatusHTTPVersionNotSupported       = http.StatusHTTPVersionNotSupported
+	StatusVariantAlsoNegotiates         = http.StatusVariantAlsoNegotiates
+	StatusInsufficientStorage           = http.StatusInsufficientStorage
+	StatusLoopDetected                  = http.StatusLoopDetected
+	StatusNotExtended                   = http.StatusNotExtended
+	StatusNetworkAuthenticationRequired = http.StatusNetworkAuthenticationRequired
+)
+
+// canonical header names; these are the keys under which headers are held
+// in an http.Header after canonicalisation (textproto.CanonicalMIMEHeaderKey)
+const (
+	HeaderAccept             = "Accept"
+	HeaderAcceptEncoding     = "Accept-Encoding"
+	HeaderAcceptLanguage     = "Accept-Language"
+	HeaderAuthorization      = "Authorization"
+	HeaderCacheControl       = "Cache-Control"
+	HeaderConnection         = "Connection"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderContentEncoding    = "Content-Encoding"
+	HeaderContentLength      = "Content-Length"
+	HeaderContentType        = "Content-Type"
+	HeaderCookie             = "Cookie"
+	HeaderDate               = "Date"
+	HeaderETag               = "Etag"
+	HeaderExpires            = "Expires"
+	HeaderHost               = "Host"
+	HeaderIfMatch            = "If-Match"
+	HeaderIfModifiedSince    = "If-Modified-Since"
+	HeaderIfNoneMatch        = "If-None-Match"
+	HeaderIfUnmodifiedSince  = "If-Unmodified-Since"
+	HeaderLastModified       = "Last-Modified"
+	HeaderLink               = "Link"
+	HeaderLocation           = "Location"
+	HeaderOrigin             = "Origin"
+	HeaderRange              = "Range"
+	HeaderReferer            = "Referer"
+	HeaderRetryAfter         = "Retry-After"
+	HeaderSetCookie          = "Set-Cookie"
+	HeaderTransferEncoding   = "Transfer-Encoding"
+	HeaderUserAgent          = "User-Agent"
+	HeaderVary               = "Vary"
+	HeaderWWWAuthenticate    = "Www-Authenticate"
 )
@@ -0,0 +1,33 @@
+package http
+
+// BaseClient is an exported struct that may be embedded in an application-defined
+// client type, to add domain-specific methods while inheriting all of the methods
+// of an HttpClient:
+//
+//	type PaymentsClient struct {
+//		http.BaseClient
+//	}
+//
+//	func (c PaymentsClient) Capture(ctx context.Context, id string) (*http.Response, error) {
+//		return c.Post(ctx, "payments/"+id+"/capture")
+//	}
+//
+// A BaseClient is usually initialised using NewBaseClient.  Since BaseClient
+// simply embeds an HttpClient, a BaseClient may also be initialised with any
+// other HttpClient, such as the client returned by NewMockClient:
+//
+//	c, mock := http.NewMockClient("payments")
+//	payments := PaymentsClient{http.BaseClient{HttpClient: c}}
+type BaseClient struct {
+	HttpClient
+}
+
+// NewBaseClient returns a BaseClient embedding a new HttpClient, configured with
+// the name and options specified (see: NewClient).
+func NewBaseClient(name string, opts ...ClientOption) (BaseClient, error) {
+	c, err := NewClient(name, opts...)
+	if err != nil {
+		return BaseClient{}, err
+	}
+	return BaseClient{HttpClient: c}, nil
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// paymentsClient is an application-defined client embedding BaseClient
+type paymentsClient struct {
+	BaseClient
+}
+
+func (c paymentsClient) Capture(ctx context.Context, id string) (*Response, error) {
+	return c.Post(ctx, "payments/"+id+"/capture")
+}
+
+func TestBaseClient(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "NewBaseClient/ok",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NewBaseClient("name", URL("http://hostname"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if c, ok := test.IsType[client](t, result.HttpClient); ok {
+					test.That(t, c.name).Equals("name")
+					test.That(t, c.url).Equals("http://hostname")
+				}
+			},
+		},
+		{scenario: "NewBaseClient/option error",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NewBaseClient("name", URL(42))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+				test.That(t, result.HttpClient).IsNil()
+			},
+		},
+		{scenario: "embedded with mock client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx := context.Background()
+				c, mock := NewMockClient("payments")
+				sut := paymentsClient{BaseClient{HttpClient: c}}
+				mock.ExpectPost("/payments/42/capture")
+
+				// ACT
+				_, err := sut.Capture(ctx, "42")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
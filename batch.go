@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// BatchItem is the result of an individual item in a batch response, as
+// returned by SplitBatch.
+type BatchItem struct {
+	// ID identifies the item; the href of a 207 Multi-Status response
+	// element or the Content-ID of a part of a multipart batch response
+	ID string
+
+	// StatusCode is the status code of the item
+	StatusCode int
+
+	// Status is the status of the item, e.g. "404 Not Found"
+	Status string
+
+	// Header holds the headers of the item; nil for a 207 Multi-Status
+	// response element
+	Header http.Header
+
+	// Body is the body of the item; the (xml) content of a 207 Multi-Status
+	// response element
+	Body []byte
+}
+
+// SplitBatch splits the body of a batch response into the results of each
+// item in the batch, each with an individual status code.  The format of the
+// body is identified by the Content-Type of the response:
+//
+//   - a multipart (e.g. multipart/mixed) body is split into parts, each
+//     containing an http response (Content-Type: application/http), as
+//     returned by many batch apis;
+//
+//   - an xml body is parsed as a 207 Multi-Status (WebDAV) response, with
+//     an item for each href; an element reporting only propstat elements
+//     has the status of the first propstat.
+//
+// A 207 Multi-Status response is not acceptable to a request by default; the
+// request must be configured with request.AcceptStatus(http.StatusMultiStatus).
+//
+// ErrUnsupportedMediaType is returned for any other Content-Type.  An error
+// wrapping ErrDecodingResponseBody is returned if the body cannot be parsed.
+// The response body is always closed.
+func SplitBatch(ctx context.Context, r *http.Response) ([]BatchItem, error) {
+	defer func() { _ = closeBody(ctx, r.Body) }()
+
+	handle := func(sen, err error) ([]BatchItem, error) {
+		return nil, errorcontext.Errorf(ctx, "http.SplitBatch: %w: %w", sen, err)
+	}
+
+	mediaType, params, err := parseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return handle(ErrUnsupportedMediaType, err)
+	}
+
+	var items []BatchItem
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		items, err = splitMultipartBatch(r.Body, params["boundary"])
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		items, err = splitMultiStatus(r.Body)
+	default:
+		return handle(ErrUnsupportedMediaType, errors.New(mediaType))
+	}
+	if err != nil {
+		return handle(ErrDecodingResponseBody, err)
+	}
+	return items, nil
+}
+
+// splitMultipartBatch splits a multipart body into items, parsing each part as
+// an http response
+func splitMultipartBatch(body io.Reader, boundary string) ([]BatchItem, error) {
+	mpr := multipart.NewReader(body, boundary)
+	items := []BatchItem{}
+	for {
+		p, err := nextPart(mpr)
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ix := len(items) + 1
+		r, err := http.ReadResponse(bufio.NewReader(p), nil)
+		if err != nil {
+			return nil, fmt.Errorf("part #%d: %w", ix, err)
+		}
+		b, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("part #%d: %w", ix, err)
+		}
+
+		items = append(items, BatchItem{
+			ID:         p.Header.Get("Content-ID"),
+			StatusCode: r.StatusCode,
+			Status:     r.Status,
+			Header:     r.Header,
+			Body:       b,
+		})
+	}
+}
+
+// multiStatus is the xml structure of a 207 Multi-Status body
+type multiStatus struct {
+	XMLName   xml.Name `xml:"multistatus"`
+	Responses []struct {
+		Hrefs    []string `xml:"href"`
+		Status   string   `xml:"status"`
+		Propstat []struct {
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+		Content []byte `xml:",innerxml"`
+	} `xml:"response"`
+}
+
+// splitMultiStatus parses a 207 Multi-Status body into items
+func splitMultiStatus(body io.Reader) ([]BatchItem, error) {
+	ms := multiStatus{}
+	if err := xml.NewDecoder(body).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	items := []BatchItem{}
+	for ix, r := range ms.Responses {
+		s := r.Status
+		if s == "" && len(r.Propstat) > 0 {
+			s = r.Propstat[0].Status
+		}
+		code, status, err := parseStatusLine(s)
+		if err != nil {
+			return nil, fmt.Errorf("response #%d: %w", ix+1, err)
+		}
+		for _, href := range r.Hrefs {
+			items = append(items, BatchItem{
+				ID:         strings.TrimSpace(href),
+				StatusCode: code,
+				Status:     status,
+				Body:       bytes.TrimSpace(r.Content),
+			})
+		}
+	}
+	return items, nil
+}
+
+// parseStatusLine parses an http status line (e.g. "HTTP/1.1 200 OK"),
+// returning the status code and status (e.g. "200 OK")
+func parseStatusLine(s string) (int, string, error) {
+	_, status, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid status: %q", s)
+	}
+	code, _, _ := strings.Cut(status, " ")
+	n, err := strconv.Atoi(code)
+	if err != nil || n < 100 || n > 999 {
+		return 0, "", fmt.Errorf("invalid status: %q", s)
+	}
+	return n, status, nil
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestSplitBatch(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	response := func(ct string, body string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{ct}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	crlf := func(s string) string { return strings.ReplaceAll(s, "\n", "\r\n") }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "multipart batch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("multipart/mixed; boundary=batch", crlf(`--batch
+Content-Type: application/http
+Content-ID: <item1>
+
+HTTP/1.1 200 OK
+Content-Type: application/json
+Content-Length: 8
+
+{"id":1}
+--batch
+Content-Type: application/http
+Content-ID: <item2>
+
+HTTP/1.1 404 Not Found
+Content-Length: 0
+
+
+--batch--
+`))
+
+				// ACT
+				result, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(2)
+				test.That(t, result[0].ID).Equals("<item1>")
+				test.That(t, result[0].StatusCode).Equals(http.StatusOK)
+				test.That(t, result[0].Header.Get("Content-Type")).Equals("application/json")
+				test.That(t, string(result[0].Body)).Equals(`{"id":1}`)
+				test.That(t, result[1].ID).Equals("<item2>")
+				test.That(t, result[1].StatusCode).Equals(http.StatusNotFound)
+				test.That(t, result[1].Status).Equals("404 Not Found")
+				test.That(t, len(result[1].Body)).Equals(0)
+			},
+		},
+		{scenario: "multipart batch/invalid part",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("multipart/mixed; boundary=batch", crlf(`--batch
+Content-Type: application/http
+
+not a response
+--batch--
+`))
+
+				// ACT
+				_, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDecodingResponseBody)
+			},
+		},
+		{scenario: "multistatus",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("application/xml; charset=utf-8", `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:">
+  <D:response>
+    <D:href>/files/a</D:href>
+    <D:href>/files/b</D:href>
+    <D:status>HTTP/1.1 423 Locked</D:status>
+  </D:response>
+  <D:response>
+    <D:href>/files/c</D:href>
+    <D:propstat>
+      <D:prop><D:displayname>c</D:displayname></D:prop>
+      <D:status>HTTP/1.1 200 OK</D:status>
+    </D:propstat>
+  </D:response>
+</D:multistatus>`)
+
+				// ACT
+				result, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(3)
+				test.That(t, result[0].ID).Equals("/files/a")
+				test.That(t, result[0].StatusCode).Equals(http.StatusLocked)
+				test.That(t, result[0].Status).Equals("423 Locked")
+				test.That(t, result[1].ID).Equals("/files/b")
+				test.That(t, result[1].StatusCode).Equals(http.StatusLocked)
+				test.That(t, result[2].ID).Equals("/files/c")
+				test.That(t, result[2].StatusCode).Equals(http.StatusOK)
+				test.IsTrue(t, strings.Contains(string(result[2].Body), "displayname"), "body contains prop")
+			},
+		},
+		{scenario: "multistatus/invalid status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("text/xml", `<multistatus xmlns="DAV:"><response><href>/a</href><status>bad</status></response></multistatus>`)
+
+				// ACT
+				_, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDecodingResponseBody)
+			},
+		},
+		{scenario: "multistatus/invalid xml",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("text/xml", `<multistatus>`)
+
+				// ACT
+				_, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDecodingResponseBody)
+			},
+		},
+		{scenario: "unsupported media type",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("application/json", `{}`)
+
+				// ACT
+				_, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnsupportedMediaType)
+			},
+		},
+		{scenario: "invalid content type",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("", `{}`)
+
+				// ACT
+				_, err := SplitBatch(ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnsupportedMediaType)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
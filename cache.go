@@ -0,0 +1,521 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blugnu/http/request"
+)
+
+// DefaultCacheCapacity is the maximum number of responses held by the
+// in-memory cache used if no CacheStore is specified for the Cache option
+const DefaultCacheCapacity = 1000
+
+// maxCachedBodySize is the maximum size of a response body that is cached;
+// responses with larger bodies are not stored
+const maxCachedBodySize = 1 << 20
+
+// CachedResponse is a response held in a CacheStore
+type CachedResponse struct {
+	// StatusCode is the status code of the response
+	StatusCode int
+
+	// Header holds the headers of the response, updated by any revalidation
+	Header http.Header
+
+	// Body is the body of the response
+	Body []byte
+
+	// Vary holds the values of any request headers nominated by the Vary
+	// header of the response; the response is used only for requests with
+	// the same values for those headers
+	Vary http.Header
+
+	// Stored is the time at which the response was received or was last
+	// revalidated
+	Stored time.Time
+}
+
+// CacheStore is implemented by types providing storage for the responses
+// cached by a client (see: Cache).  Implementations must be safe for
+// concurrent use and must not modify a CachedResponse once it has been stored.
+//
+// A store that is unable to retrieve a response (e.g. due to an error
+// accessing some external storage) should report a cache miss.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool)
+	Set(ctx context.Context, key string, r *CachedResponse)
+	Delete(ctx context.Context, key string)
+}
+
+// Cache configures the client to cache responses to GET requests, observing
+// HTTP caching semantics (RFC 9111, formerly RFC 7234) as a private cache:
+//
+//   - a 200 OK response is stored if it has an explicit freshness lifetime
+//     (Cache-Control max-age or an Expires header) or a validator (ETag or
+//     Last-Modified), unless it is marked no-store or has a Vary: * header;
+//
+//   - a request for a stored response is served from the cache, without a
+//     request being sent, while the response is fresh; a response without an
+//     explicit lifetime but with a Last-Modified header is fresh for 10% of
+//     the time since it was last modified;
+//
+//   - once a stored response is stale (or is marked no-cache), it is
+//     revalidated using a conditional request (If-None-Match and/or
+//     If-Modified-Since); a 304 Not Modified response updates the stored
+//     response, which is then returned in place of the 304;
+//
+//   - a successful POST, PUT, PATCH or DELETE request invalidates any
+//     stored response for the same url.
+//
+// Requests with a Cache-Control: no-store header, Range requests and requests
+// with conditional headers are not served from (or stored in) the cache.  A
+// request with a Cache-Control: no-cache header always revalidates any stored
+// response.  Responses with bodies larger than 1 MiB are not stored.
+//
+// Responses are stored by url, so a response to a request bearing credentials
+// (an Authorization, Proxy-Authorization or Cookie header, or a header set by
+// request.APIKey) is stored, and a request bearing credentials is served from
+// the cache, only if the response is marked Cache-Control: public.  Responses
+// to requests made for a tenant (see: Tenants) are stored separately for each
+// tenant.  Credentials presented in any other header are not identified; a
+// response to such a request is isolated only if the server nominates the
+// header in a Vary header.
+//
+// Responses are held in a supplied CacheStore.  If the store is nil, an
+// in-memory store holding up to DefaultCacheCapacity responses is used (see:
+// NewMemoryCache).
+//
+// Any Middleware is applied to the cache, i.e. middleware observes responses
+// served from the cache as well as those received from the server.
+func Cache(store CacheStore) ClientOption {
+	return func(c *client) error {
+		if store == nil {
+			store = NewMemoryCache(DefaultCacheCapacity)
+		}
+		c.cache = store
+		return nil
+	}
+}
+
+// cacheDoer wraps a Doer, serving requests from and storing responses in a
+// CacheStore (see: Cache)
+type cacheDoer struct {
+	Doer
+	store CacheStore
+}
+
+// Do implements Doer
+func (cd cacheDoer) Do(rq *http.Request) (*http.Response, error) {
+	ctx := rq.Context()
+	key := cacheKey(rq)
+
+	if rq.Method != http.MethodGet && rq.Method != "" {
+		r, err := cd.Doer.Do(rq)
+		if err == nil && !isSafeMethod(rq.Method) && r.StatusCode < 400 {
+			cd.store.Delete(ctx, key)
+		}
+		return r, err
+	}
+
+	cc := parseCacheControl(rq.Header)
+	if _, noStore := cc["no-store"]; noStore || !isCacheableRequest(rq) {
+		return cd.Doer.Do(rq)
+	}
+
+	authenticated := hasCredentials(rq)
+
+	entry, ok := cd.store.Get(ctx, key)
+	if ok && (!entry.matches(rq) || (authenticated && !isPublic(entry.Header))) {
+		entry = nil
+	}
+	if entry != nil && entry.fresh(timeNow(), cc) {
+		return entry.response(rq, timeNow()), nil
+	}
+
+	crq := rq
+	if entry != nil {
+		crq = entry.conditional(rq)
+	}
+
+	r, err := cd.Doer.Do(crq)
+	if err != nil {
+		return r, err
+	}
+
+	if entry != nil && r.StatusCode == http.StatusNotModified {
+		_ = closeBody(ctx, r.Body)
+		entry = entry.revalidated(r.Header, timeNow())
+		cd.store.Set(ctx, key, entry)
+		return entry.response(rq, timeNow()), nil
+	}
+
+	if isStorableResponse(r) && (!authenticated || isPublic(r.Header)) {
+		r.Body = &cachingBody{
+			ReadCloser: r.Body,
+			store: func(body []byte) {
+				cd.store.Set(ctx, key, &CachedResponse{
+					StatusCode: r.StatusCode,
+					Header:     r.Header.Clone(),
+					Body:       body,
+					Vary:       varyHeaders(rq, r),
+					Stored:     timeNow(),
+				})
+			},
+		}
+	}
+
+	return r, nil
+}
+
+// isSafeMethod returns true if a specified request method is safe, i.e. does
+// not modify the resource identified by the request
+func isSafeMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isCacheableRequest returns true if a request may be served from the cache,
+// i.e. it is neither a Range request nor a conditional request
+func isCacheableRequest(rq *http.Request) bool {
+	for _, h := range []string{"Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"} {
+		if rq.Header.Get(h) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheKey returns the key under which a response to a request is stored: the
+// url of the request, qualified by the id of any tenant for which the request
+// is made so that a response is never served to some other tenant
+func cacheKey(rq *http.Request) string {
+	key := rq.URL.String()
+	if id := TenantID(rq.Context()); id != "" {
+		key = "tenant:" + url.QueryEscape(id) + " " + key
+	}
+	return key
+}
+
+// hasCredentials returns true if a request bears credentials identifying the
+// user making the request, including any header identified as carrying
+// credentials by the request options applied to it (e.g. request.APIKey)
+func hasCredentials(rq *http.Request) bool {
+	headers := []string{"Authorization", "Proxy-Authorization", "Cookie"}
+	if cfg, ok := request.ConfigFromContext(rq.Context()); ok {
+		headers = append(headers, cfg.CredentialHeaders...)
+	}
+	for _, h := range headers {
+		if rq.Header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublic returns true if a set of response headers includes a Cache-Control
+// public directive, i.e. the response may be served to any user
+func isPublic(h http.Header) bool {
+	_, public := parseCacheControl(h)["public"]
+	return public
+}
+
+// isStorableResponse returns true if a response may be stored in the cache
+func isStorableResponse(r *http.Response) bool {
+	if r.StatusCode != http.StatusOK || r.ContentLength > maxCachedBodySize {
+		return false
+	}
+
+	cc := parseCacheControl(r.Header)
+	if _, noStore := cc["no-store"]; noStore {
+		return false
+	}
+	if slices.Contains(headerTokens(r.Header, "Vary"), "*") {
+		return false
+	}
+
+	_, maxAge := cc["max-age"]
+	return maxAge ||
+		r.Header.Get("Expires") != "" ||
+		r.Header.Get("ETag") != "" ||
+		r.Header.Get("Last-Modified") != ""
+}
+
+// varyHeaders returns the values of the headers of a request nominated by
+// the Vary header of a response to the request
+func varyHeaders(rq *http.Request, r *http.Response) http.Header {
+	fields := headerTokens(r.Header, "Vary")
+	if len(fields) == 0 {
+		return nil
+	}
+	vary := http.Header{}
+	for _, field := range fields {
+		vary[http.CanonicalHeaderKey(field)] = rq.Header.Values(field)
+	}
+	return vary
+}
+
+// headerTokens returns the comma separated tokens of all values of a header
+func headerTokens(h http.Header, key string) []string {
+	tokens := []string{}
+	for _, v := range h.Values(key) {
+		for _, token := range strings.Split(v, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+// parseCacheControl returns the directives of the Cache-Control header(s) in
+// a set of headers, mapping the (lower case) name of each directive to its
+// value (if any)
+func parseCacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, directive := range headerTokens(h, "Cache-Control") {
+		name, value, _ := strings.Cut(directive, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
+
+// matches returns true if the cached response may be used for a request,
+// i.e. the request has the same values for any headers nominated by the Vary
+// header of the response
+func (e *CachedResponse) matches(rq *http.Request) bool {
+	for k, v := range e.Vary {
+		if !slices.Equal(rq.Header.Values(k), v) {
+			return false
+		}
+	}
+	return true
+}
+
+// age returns the age of the cached response at a specified time, including
+// any age of the response when it was received
+func (e *CachedResponse) age(now time.Time) time.Duration {
+	age := max(now.Sub(e.Stored), 0)
+	if n, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && n > 0 {
+		age += time.Duration(n) * time.Second
+	}
+	return age
+}
+
+// lifetime returns the freshness lifetime of the cached response
+func (e *CachedResponse) lifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if _, noCache := cc["no-cache"]; noCache {
+		return 0
+	}
+	if v, ok := cc["max-age"]; ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(n) * time.Second
+	}
+
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.Stored
+	}
+	if v := e.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+		return expires.Sub(date)
+	}
+	if modified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil {
+		return date.Sub(modified) / 10
+	}
+	return 0
+}
+
+// fresh returns true if the cached response may be used at a specified time
+// without revalidation, given the Cache-Control directives of a request
+func (e *CachedResponse) fresh(now time.Time, cc map[string]string) bool {
+	if _, noCache := cc["no-cache"]; noCache {
+		return false
+	}
+
+	age := e.age(now)
+	if v, ok := cc["max-age"]; ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || age > time.Duration(n)*time.Second {
+			return false
+		}
+	}
+	return age < e.lifetime()
+}
+
+// conditional returns a copy of a request with conditional headers to
+// revalidate the cached response
+func (e *CachedResponse) conditional(rq *http.Request) *http.Request {
+	cpy := *rq
+	cpy.Header = rq.Header.Clone()
+	if cpy.Header == nil {
+		cpy.Header = http.Header{}
+	}
+	if etag := e.Header.Get("ETag"); etag != "" {
+		cpy.Header.Set("If-None-Match", etag)
+	}
+	if modified := e.Header.Get("Last-Modified"); modified != "" {
+		cpy.Header.Set("If-Modified-Since", modified)
+	}
+	return &cpy
+}
+
+// revalidated returns a copy of the cached response, updated with the headers
+// of a 304 Not Modified response received at a specified time
+func (e *CachedResponse) revalidated(h http.Header, now time.Time) *CachedResponse {
+	cpy := *e
+	cpy.Header = e.Header.Clone()
+	for k, v := range h {
+		switch k {
+		case "Content-Length", "Transfer-Encoding":
+			continue
+		}
+		cpy.Header[k] = slices.Clone(v)
+	}
+	if len(h.Values("Age")) == 0 {
+		cpy.Header.Del("Age")
+	}
+	cpy.Stored = now
+	return &cpy
+}
+
+// response returns a response to a request, provided by the cached response
+// at a specified time
+func (e *CachedResponse) response(rq *http.Request, now time.Time) *http.Response {
+	r := &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       rq,
+	}
+	r.Header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	return r
+}
+
+// cachingBody wraps the body of a response, capturing the content of the
+// body as it is read.  When the body has been completely read, the captured
+// content is stored (unless it exceeds the maximum size of a cached body).
+type cachingBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	abandoned bool
+	store     func([]byte)
+}
+
+// Read implements io.Reader
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.abandoned {
+		return n, err
+	}
+
+	if b.buf.Len()+n > maxCachedBodySize {
+		b.abandoned = true
+		b.buf = bytes.Buffer{}
+		return n, err
+	}
+	b.buf.Write(p[:n])
+
+	if err == io.EOF {
+		b.abandoned = true
+		b.store(b.buf.Bytes())
+	}
+	return n, err
+}
+
+// memoryCache is an in-memory CacheStore, holding up to a maximum number of
+// responses and evicting the least recently used response when full
+type memoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+// memoryCacheItem is an item in the lru list of a memoryCache
+type memoryCacheItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+// NewMemoryCache returns an in-memory CacheStore holding up to a specified
+// number of responses, evicting the least recently used response when full.
+// If the capacity is zero or less, DefaultCacheCapacity is used.
+func NewMemoryCache(capacity int) CacheStore {
+	if capacity <= 0 {
+		capacity = DefaultCacheCapacity
+	}
+	return &memoryCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// Get implements CacheStore
+func (mc *memoryCache) Get(_ context.Context, key string) (*CachedResponse, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	el, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	mc.lru.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true
+}
+
+// Set implements CacheStore
+func (mc *memoryCache) Set(_ context.Context, key string, r *CachedResponse) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if el, ok := mc.entries[key]; ok {
+		el.Value.(*memoryCacheItem).entry = r
+		mc.lru.MoveToFront(el)
+		return
+	}
+
+	mc.entries[key] = mc.lru.PushFront(&memoryCacheItem{key: key, entry: r})
+	if mc.lru.Len() > mc.capacity {
+		el := mc.lru.Back()
+		mc.lru.Remove(el)
+		delete(mc.entries, el.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete implements CacheStore
+func (mc *memoryCache) Delete(_ context.Context, key string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if el, ok := mc.entries[key]; ok {
+		mc.lru.Remove(el)
+		delete(mc.entries, key)
+	}
+}
@@ -0,0 +1,554 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestCache(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+
+	og := timeNow
+	defer func() { timeNow = og }()
+
+	// server returns a client with a cache, performing requests using a
+	// function returning the status, headers and body of the response to each
+	// request; each request received is recorded
+	server := func(requests *[]*http.Request, fn func(*http.Request) (int, http.Header, string)) HttpClient {
+		c, _ := NewClient("name", URL("http://hostname"), Cache(nil),
+			Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+				*requests = append(*requests, rq)
+				status, h, body := fn(rq)
+				if h == nil {
+					h = http.Header{}
+				}
+				return &http.Response{
+					StatusCode:    status,
+					Header:        h,
+					Body:          io.NopCloser(strings.NewReader(body)),
+					ContentLength: int64(len(body)),
+				}, nil
+			})),
+		)
+		return c
+	}
+
+	// get performs a GET request returning the status, Age header and body of
+	// the response
+	get := func(t *testing.T, c HttpClient, opts ...RequestOption) (int, string, string) {
+		t.Helper()
+		opts = append(opts, request.AcceptStatus(http.StatusOK, http.StatusNotModified))
+		r, err := c.Get(ctx, "resource", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(r.Body)
+		return r.StatusCode, r.Header.Get("Age"), string(body)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/default store",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := Cache(nil)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				mc, ok := c.cache.(*memoryCache)
+				test.IsTrue(t, ok, "is memory cache")
+				test.That(t, mc.capacity).Equals(DefaultCacheCapacity)
+			},
+		},
+		{scenario: "option/store",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+				store := NewMemoryCache(10)
+
+				// ACT
+				err := Cache(store)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.cache).Equals(store)
+			},
+		},
+		{scenario: "fresh response is served from cache",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(*http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, "content"
+				})
+				_, _, _ = get(t, c)
+				timeNow = func() time.Time { return now.Add(30 * time.Second) }
+
+				// ACT
+				status, age, body := get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(1)
+				test.That(t, status).Equals(http.StatusOK)
+				test.That(t, age).Equals("30")
+				test.That(t, body).Equals("content")
+			},
+		},
+		{scenario: "stale response is revalidated",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					if rq.Header.Get("If-None-Match") == `"v1"` {
+						return http.StatusNotModified, http.Header{"Cache-Control": {"max-age=120"}}, ""
+					}
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Etag": {`"v1"`}}, "content"
+				})
+				_, _, _ = get(t, c)
+				timeNow = func() time.Time { return now.Add(90 * time.Second) }
+
+				// ACT
+				status, age, body := get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, requests[1].Header.Get("If-None-Match")).Equals(`"v1"`)
+				test.That(t, status).Equals(http.StatusOK)
+				test.That(t, age).Equals("0")
+				test.That(t, body).Equals("content")
+
+				// the revalidated response is fresh for the updated max-age
+				timeNow = func() time.Time { return now.Add(200 * time.Second) }
+				_, _, _ = get(t, c)
+				test.That(t, len(requests)).Equals(2)
+			},
+		},
+		{scenario: "stale response is replaced",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				content := "content"
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Last-Modified": {now.Add(-time.Hour).Format(http.TimeFormat)}}, content
+				})
+				_, _, _ = get(t, c)
+				timeNow = func() time.Time { return now.Add(7 * time.Minute) }
+				content = "updated content"
+
+				// ACT
+				_, _, body := get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, requests[1].Header.Get("If-Modified-Since")).Equals(now.Add(-time.Hour).Format(http.TimeFormat))
+				test.That(t, body).Equals("updated content")
+
+				_, _, body = get(t, c)
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, body).Equals("updated content")
+			},
+		},
+		{scenario: "last modified heuristic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{
+						"Date":          {now.Format(http.TimeFormat)},
+						"Last-Modified": {now.Add(-time.Hour).Format(http.TimeFormat)},
+					}, "content"
+				})
+				_, _, _ = get(t, c)
+
+				// ACT
+				timeNow = func() time.Time { return now.Add(5 * time.Minute) }
+				_, _, _ = get(t, c)
+				fresh := len(requests)
+
+				timeNow = func() time.Time { return now.Add(7 * time.Minute) }
+				_, _, _ = get(t, c)
+				stale := len(requests)
+
+				// ASSERT
+				test.That(t, fresh).Equals(1)
+				test.That(t, stale).Equals(2)
+			},
+		},
+		{scenario: "expires",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{
+						"Date":    {now.Format(http.TimeFormat)},
+						"Expires": {now.Add(time.Minute).Format(http.TimeFormat)},
+					}, "content"
+				})
+				_, _, _ = get(t, c)
+
+				// ACT
+				timeNow = func() time.Time { return now.Add(59 * time.Second) }
+				_, _, _ = get(t, c)
+				fresh := len(requests)
+
+				timeNow = func() time.Time { return now.Add(time.Minute) }
+				_, _, _ = get(t, c)
+				stale := len(requests)
+
+				// ASSERT
+				test.That(t, fresh).Equals(1)
+				test.That(t, stale).Equals(2)
+			},
+		},
+		{scenario: "response is not stored",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				testcases := []struct {
+					status int
+					header http.Header
+				}{
+					{status: http.StatusOK, header: http.Header{}},
+					{status: http.StatusOK, header: http.Header{"Cache-Control": {"no-store, max-age=60"}}},
+					{status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}},
+					{status: http.StatusNotModified, header: http.Header{"Cache-Control": {"max-age=60"}}},
+				}
+				for _, tc := range testcases {
+					requests := []*http.Request{}
+					c := server(&requests, func(*http.Request) (int, http.Header, string) {
+						return tc.status, tc.header, "content"
+					})
+
+					// ACT
+					_, _, _ = get(t, c)
+					_, _, _ = get(t, c)
+
+					// ASSERT
+					test.That(t, len(requests), fmt.Sprintf("%d %v", tc.status, tc.header)).Equals(2)
+				}
+			},
+		},
+		{scenario: "response body too large",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				body := strings.Repeat("x", maxCachedBodySize+1)
+				c, _ := NewClient("name", URL("http://hostname"), Cache(nil),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						requests = append(requests, rq)
+						return &http.Response{
+							StatusCode:    http.StatusOK,
+							Header:        http.Header{"Cache-Control": {"max-age=60"}},
+							Body:          io.NopCloser(strings.NewReader(body)),
+							ContentLength: -1,
+						}, nil
+					})),
+				)
+
+				// ACT
+				_, _, _ = get(t, c)
+				_, _, _ = get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+			},
+		},
+		{scenario: "vary",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{
+						"Cache-Control": {"max-age=60"},
+						"Vary":          {"Accept-Language"},
+					}, rq.Header.Get("Accept-Language")
+				})
+				_, _, _ = get(t, c, request.Header("Accept-Language", "en"))
+
+				// ACT
+				_, _, en := get(t, c, request.Header("Accept-Language", "en"))
+				_, _, fr := get(t, c, request.Header("Accept-Language", "fr"))
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, en).Equals("en")
+				test.That(t, fr).Equals("fr")
+			},
+		},
+		{scenario: "authenticated request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, rq.Header.Get("Authorization")
+				})
+				_, _, _ = get(t, c)
+
+				// ACT
+				_, _, alice := get(t, c, request.Header("Authorization", "alice"))
+				_, _, bob := get(t, c, request.Header("Authorization", "bob"))
+				_, _, anon := get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(3)
+				test.That(t, alice).Equals("alice")
+				test.That(t, bob).Equals("bob")
+				test.That(t, anon).Equals("")
+			},
+		},
+		{scenario: "authenticated request/public response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"public, max-age=60"}}, "content"
+				})
+				_, _, _ = get(t, c, request.Header("Authorization", "alice"))
+
+				// ACT
+				_, _, bob := get(t, c, request.Header("Authorization", "bob"))
+				_, _, anon := get(t, c)
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(1)
+				test.That(t, bob).Equals("content")
+				test.That(t, anon).Equals("content")
+			},
+		},
+		{scenario: "authenticated request/api key",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, "data for " + rq.Header.Get("X-Api-Key")
+				})
+				_, _, _ = get(t, c, request.APIKey("X-Api-Key", "key-acme"))
+
+				// ACT
+				_, _, globex := get(t, c, request.APIKey("X-Api-Key", "key-globex"))
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, globex).Equals("data for key-globex")
+			},
+		},
+		{scenario: "tenants",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c, _ := NewClient("name", URL("http://hostname"), Cache(nil),
+					Tenants(TenantProviderFunc(func(_ context.Context, id string) (TenantConfig, error) {
+						return TenantConfig{Options: []RequestOption{request.Header("X-Tenant", id)}}, nil
+					})),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						requests = append(requests, rq)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Header:     http.Header{"Cache-Control": {"max-age=60"}},
+							Body:       io.NopCloser(strings.NewReader("data for " + rq.Header.Get("X-Tenant"))),
+						}, nil
+					})),
+				)
+				get := func(tenant string) string {
+					r, err := c.Get(Tenant(ctx, tenant), "resource")
+					if err != nil {
+						t.Fatal(err)
+					}
+					body, _ := io.ReadAll(r.Body)
+					return string(body)
+				}
+				_ = get("acme")
+
+				// ACT
+				globex := get("globex")
+				acme := get("acme")
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, globex).Equals("data for globex")
+				test.That(t, acme).Equals("data for acme")
+			},
+		},
+		{scenario: "request cache-control",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Etag": {`"v1"`}}, "content"
+				})
+				_, _, _ = get(t, c)
+				timeNow = func() time.Time { return now.Add(30 * time.Second) }
+
+				// ACT
+				_, _, _ = get(t, c, request.Header("Cache-Control", "max-age=10"))
+				_, _, _ = get(t, c, request.Header("Cache-Control", "no-cache"))
+				_, _, _ = get(t, c, request.Header("Cache-Control", "no-store"))
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(4)
+				test.That(t, requests[1].Header.Get("If-None-Match")).Equals(`"v1"`)
+				test.That(t, requests[2].Header.Get("If-None-Match")).Equals(`"v1"`)
+				test.That(t, requests[3].Header.Get("If-None-Match")).Equals("")
+			},
+		},
+		{scenario: "conditional request is not served from cache",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					if rq.Header.Get("If-None-Match") == `"v1"` {
+						return http.StatusNotModified, nil, ""
+					}
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Etag": {`"v1"`}}, "content"
+				})
+				_, _, _ = get(t, c)
+
+				// ACT
+				status, _, _ := get(t, c, request.Header("If-None-Match", `"v1"`))
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(2)
+				test.That(t, status).Equals(http.StatusNotModified)
+			},
+		},
+		{scenario: "unsafe request invalidates cache",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeNow = func() time.Time { return now }
+				requests := []*http.Request{}
+				c := server(&requests, func(rq *http.Request) (int, http.Header, string) {
+					return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, "content"
+				})
+				_, _, _ = get(t, c)
+
+				// ACT
+				_, err := c.Put(ctx, "resource", request.Body([]byte("update")))
+				_, _, _ = get(t, c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(requests)).Equals(3)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	entry := func(s string) *CachedResponse {
+		return &CachedResponse{StatusCode: http.StatusOK, Body: []byte(s)}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "default capacity",
+			exec: func(t *testing.T) {
+				// ACT
+				mc := NewMemoryCache(0)
+
+				// ASSERT
+				test.That(t, mc.(*memoryCache).capacity).Equals(DefaultCacheCapacity)
+			},
+		},
+		{scenario: "get/set/delete",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				mc := NewMemoryCache(2)
+
+				// ACT
+				mc.Set(ctx, "a", entry("a"))
+				mc.Set(ctx, "a", entry("a2"))
+				a, aok := mc.Get(ctx, "a")
+				mc.Delete(ctx, "a")
+				_, deleted := mc.Get(ctx, "a")
+				mc.Delete(ctx, "b")
+
+				// ASSERT
+				test.IsTrue(t, aok, "a is cached")
+				test.That(t, string(a.Body)).Equals("a2")
+				test.IsFalse(t, deleted, "a is deleted")
+			},
+		},
+		{scenario: "least recently used is evicted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				mc := NewMemoryCache(2)
+				mc.Set(ctx, "a", entry("a"))
+				mc.Set(ctx, "b", entry("b"))
+				_, _ = mc.Get(ctx, "a")
+
+				// ACT
+				mc.Set(ctx, "c", entry("c"))
+
+				// ASSERT
+				_, a := mc.Get(ctx, "a")
+				_, b := mc.Get(ctx, "b")
+				_, c := mc.Get(ctx, "c")
+				test.IsTrue(t, a, "a is cached")
+				test.IsFalse(t, b, "b is evicted")
+				test.IsTrue(t, c, "c is cached")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestCachingBody(t *testing.T) {
+	// ARRANGE
+	stored := [][]byte{}
+	body := &cachingBody{
+		ReadCloser: io.NopCloser(bytes.NewReader([]byte("content"))),
+		store:      func(b []byte) { stored = append(stored, b) },
+	}
+
+	// ACT
+	content, err := io.ReadAll(body)
+	_, _ = body.Read(make([]byte, 1))
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.That(t, string(content)).Equals("content")
+	test.That(t, len(stored)).Equals(1)
+	test.That(t, string(stored[0])).Equals("content")
+}
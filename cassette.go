@@ -0,0 +1,253 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+// cassette holds the interactions recorded by RecordCassette, in the order
+// in which they were performed
+type cassette struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// cassetteInteraction holds a request and the response received
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+// cassetteRequest holds the details of a recorded request
+type cassetteRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   cassetteBody `json:"body,omitempty"`
+}
+
+// cassetteResponse holds the details of a recorded response
+type cassetteResponse struct {
+	Status     string       `json:"status"`
+	StatusCode int          `json:"statusCode"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       cassetteBody `json:"body,omitempty"`
+}
+
+// cassetteBody is the body of a recorded request or response.  A body of
+// valid UTF-8 is recorded as a string, so that the cassette remains readable;
+// any other body is recorded as an object with a base64 encoded "base64"
+// member.
+type cassetteBody []byte
+
+// MarshalJSON implements json.Marshaler
+func (b cassetteBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(struct {
+		Base64 string `json:"base64"`
+	}{base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *cassetteBody) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = cassetteBody(s)
+		return nil
+	}
+
+	enc := struct {
+		Base64 []byte `json:"base64"`
+	}{}
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	*b = enc.Base64
+	return nil
+}
+
+// response returns a response with the recorded status, headers and body
+func (r cassetteResponse) response() *http.Response {
+	return &http.Response{
+		Status:        r.Status,
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		ContentLength: int64(len(r.Body)),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+	}
+}
+
+// cassetteRecorder records interactions to a cassette file
+type cassetteRecorder struct {
+	sync.Mutex
+	path     string
+	redact   []string
+	cassette cassette
+}
+
+// RecordCassette returns middleware recording every request performed by a
+// client, together with the response received, to a cassette at a specified
+// path.  A mock client may then serve the recorded responses in place of the
+// real service (see: MockClient.ReplayCassette), enabling hermetic tests
+// without hand-written expectations:
+//
+//	c, err := http.NewClient("api", http.URL(url),
+//		http.Use(http.RecordCassette("testdata/customers.json", "X-Api-Key")),
+//	)
+//
+// The cassette is a JSON document, rewritten as each interaction is recorded;
+// any existing cassette at the path is replaced.  Headers are sanitized as for
+// RecordResponse: the values of any Authorization, Cookie, Proxy-Authorization
+// and Set-Cookie headers, and of any additional headers specified, are
+// redacted and headers specific to the connection are omitted.
+//
+// Requests that fail without a response are not recorded.  The body of each
+// response is read in order to record it and is replaced by a copy, so the
+// middleware is not suitable for streamed responses.  If the cassette cannot
+// be written, the request fails with the error.
+func RecordCassette(path string, redact ...string) Middleware {
+	rec := &cassetteRecorder{path: path, redact: redact}
+	return func(next Doer) Doer {
+		return DoerFunc(func(rq *http.Request) (*http.Response, error) {
+			return rec.do(next, rq)
+		})
+	}
+}
+
+// do performs a request, recording the request and the response received
+func (rec *cassetteRecorder) do(next Doer, rq *http.Request) (*http.Response, error) {
+	var rqbody []byte
+	if rq.Body != nil && rq.Body != http.NoBody {
+		b, err := io.ReadAll(rq.Body)
+		_ = rq.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("RecordCassette: %w", err)
+		}
+		rqbody = b
+		rq.Body = io.NopCloser(bytes.NewReader(rqbody))
+	}
+
+	r, err := next.Do(rq)
+	if err != nil || r == nil {
+		return r, err
+	}
+
+	var body []byte
+	if r.Body != nil {
+		b, err := ioReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("RecordCassette: %w: %w", ErrReadingResponseBody, err)
+		}
+		body = b
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := rec.record(cassetteInteraction{
+		Request: cassetteRequest{
+			Method: rq.Method,
+			URL:    rq.URL.String(),
+			Header: sanitizeHeader(rq.Header, rec.redact),
+			Body:   rqbody,
+		},
+		Response: cassetteResponse{
+			Status:     r.Status,
+			StatusCode: r.StatusCode,
+			Header:     sanitizeHeader(r.Header, rec.redact),
+			Body:       body,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// record adds an interaction to the cassette and (re)writes the cassette file
+func (rec *cassetteRecorder) record(i cassetteInteraction) error {
+	rec.Lock()
+	defer rec.Unlock()
+
+	rec.cassette.Interactions = append(rec.cassette.Interactions, i)
+	b, err := json.MarshalIndent(rec.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("RecordCassette: %w", err)
+	}
+	if err := os.WriteFile(rec.path, b, 0o644); err != nil {
+		return fmt.Errorf("RecordCassette: %w", err)
+	}
+	return nil
+}
+
+// loadCassette reads a cassette recorded by RecordCassette
+func loadCassette(path string) (*cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &cassette{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	return c, nil
+}
+
+// ReplayCassette configures the mock client to expect the requests recorded
+// in a cassette (see: RecordCassette), in the order recorded, each responded
+// to with the recorded response.
+//
+// A request matches a recorded request by method, url path and query
+// parameters (the scheme and host of the recorded url are disregarded) and
+// body; headers are not matched.  Since the path of a recorded url is expected
+// relative to the url of the mock client, a cassette should be recorded using
+// a client with a url having no path.  As for any expected request, a recorded
+// request that is not made is reported by ExpectationsWereMet.  If requests
+// are made concurrently, MatchAnyOrder may be used to replay the cassette in
+// any order.
+//
+// As for Expect, this method will panic if called after the mock client has
+// already received at least one request.
+func (mock *mockClient) ReplayCassette(path string) error {
+	c, err := loadCassette(path)
+	if err != nil {
+		return fmt.Errorf("ReplayCassette: %w", err)
+	}
+
+	// the urls of all interactions are parsed before any are expected, so
+	// that no expectations are configured from an invalid cassette
+	urls := make([]*url.URL, len(c.Interactions))
+	for ix, i := range c.Interactions {
+		u, err := url.Parse(i.Request.URL)
+		if err != nil {
+			return fmt.Errorf("ReplayCassette: %w", InvalidURLError{URL: i.Request.URL, Err: err})
+		}
+		urls[ix] = u
+	}
+
+	for ix, i := range c.Interactions {
+		u := urls[ix]
+		rq := mock.Expect(i.Request.Method, u.Path).WithBody(i.Request.Body)
+		for k, values := range u.Query() {
+			for _, v := range values {
+				rq.WithQuery(k, v)
+			}
+		}
+
+		response := i.Response
+		rq.WillRespondWith(func(*http.Request) (*http.Response, error) {
+			return response.response(), nil
+		})
+	}
+	return nil
+}
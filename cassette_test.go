@@ -0,0 +1,238 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestCassette(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server returns a test server echoing the body of each request, with
+	// a status code of 201 for a POST
+	server := func(t *testing.T) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Set-Cookie", "session=secret")
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+			_, _ = w.Write([]byte(r.URL.Path + ":" + string(body)))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	// requests performs a GET and a POST request using a client, returning
+	// the bodies of the responses
+	requests := func(t *testing.T, c HttpClient) []string {
+		result := []string{}
+		r, err := c.Get(ctx, "customers", request.QueryP("page", 1), request.QueryP("size", 2))
+		test.Error(t, err).IsNil()
+		body, _ := io.ReadAll(r.Body)
+		result = append(result, string(body))
+
+		r, err = c.Post(ctx, "customers", request.Body([]byte("jane")),
+			request.Header("X-Api-Key", "secret"),
+			request.AcceptStatus(http.StatusCreated),
+		)
+		test.Error(t, err).IsNil()
+		body, _ = io.ReadAll(r.Body)
+		result = append(result, string(body))
+		return result
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "record and replay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				srv := server(t)
+				c, _ := NewClient("name", URL(srv.URL),
+					Use(RecordCassette(path, "X-Api-Key")),
+				)
+				recorded := requests(t, c)
+
+				mc, mock := NewMockClient("name")
+
+				// ACT
+				err := mock.ReplayCassette(path)
+				replayed := requests(t, mc)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, recorded).Equals([]string{"/customers:", "/customers:jane"})
+				test.Strings(t, replayed).Equals(recorded)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "record/sanitized",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				srv := server(t)
+				c, _ := NewClient("name", URL(srv.URL),
+					Use(RecordCassette(path, "X-Api-Key")),
+				)
+
+				// ACT
+				_ = requests(t, c)
+
+				// ASSERT
+				cst, err := loadCassette(path)
+				test.Error(t, err).IsNil()
+				test.That(t, len(cst.Interactions)).Equals(2)
+				test.That(t, cst.Interactions[0].Request.URL).Equals(srv.URL + "/customers?page=1&size=2")
+				test.That(t, cst.Interactions[1].Request.Header.Get("X-Api-Key")).Equals(redacted)
+				test.That(t, string(cst.Interactions[1].Request.Body)).Equals("jane")
+				test.That(t, cst.Interactions[1].Response.StatusCode).Equals(http.StatusCreated)
+				test.That(t, cst.Interactions[1].Response.Header.Get("Set-Cookie")).Equals(redacted)
+				test.That(t, cst.Interactions[1].Response.Header.Get("Date")).Equals("")
+			},
+		},
+		{scenario: "record/request fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				rqerr := errors.New("request error")
+				c, _ := NewClient("name", URL("http://hostname"),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) { return nil, rqerr })),
+					Use(RecordCassette(path)),
+				)
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(rqerr)
+				_, staterr := os.Stat(path)
+				test.Error(t, staterr).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "record/cassette not written",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "missing", "cassette.json")
+				srv := server(t)
+				c, _ := NewClient("name", URL(srv.URL), Use(RecordCassette(path)))
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "replay/request not made",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				_ = os.WriteFile(path, []byte(`{"interactions":[
+					{"request":{"method":"GET","url":"http://api/customers"},"response":{"status":"200 OK","statusCode":200}}
+				]}`), 0o644)
+				_, mock := NewMockClient("name")
+
+				// ACT
+				err := mock.ReplayCassette(path)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				err = mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "expecting: GET mock://hostname/customers"))
+			},
+		},
+		{scenario: "replay/no cassette",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_, mock := NewMockClient("name")
+
+				// ACT
+				err := mock.ReplayCassette(filepath.Join(t.TempDir(), "cassette.json"))
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "replay/invalid cassette",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				_ = os.WriteFile(path, []byte(`not json`), 0o644)
+				_, mock := NewMockClient("name")
+
+				// ACT
+				err := mock.ReplayCassette(path)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "replay/invalid url",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "cassette.json")
+				_ = os.WriteFile(path, []byte(`{"interactions":[
+					{"request":{"method":"GET","url":"http://api/customers"},"response":{"statusCode":200}},
+					{"request":{"method":"GET","url":"http://host name"},"response":{"statusCode":200}}
+				]}`), 0o644)
+				_, mock := NewMockClient("name")
+
+				// ACT
+				err := mock.ReplayCassette(path)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidURL)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "cassetteBody",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					body cassetteBody
+					json string
+				}{
+					{body: cassetteBody("text"), json: `"text"`},
+					{body: cassetteBody{0xff, 0x00}, json: `{"base64":"/wA="}`},
+				}
+				for _, tc := range testcases {
+					// ACT
+					b, err := json.Marshal(tc.body)
+					var result cassetteBody
+					uerr := json.Unmarshal(b, &result)
+
+					// ASSERT
+					test.Error(t, err).IsNil()
+					test.That(t, string(b)).Equals(tc.json)
+					test.Error(t, uerr).IsNil()
+					test.That(t, result).Equals(tc.body)
+				}
+
+				// invalid body
+				var result cassetteBody
+				err := json.Unmarshal([]byte(`1`), &result)
+				test.IsTrue(t, err != nil, "invalid body")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
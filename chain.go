@@ -0,0 +1,168 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/blugnu/http/request"
+)
+
+// doerChain holds the Doers performing the requests of a client.  The chain
+// is built once, when the client is initialised (see: NewClient), so that any
+// state established by middleware (or by other Doers in the chain) when
+// wrapping the underlying client persists across requests.
+type doerChain struct {
+	// doer performs each attempt of a request, applying any backends,
+	// hedging, cache and middleware configured on the client
+	doer Doer
+
+	// requests performs each attempt of a request using the underlying
+	// client, applying the configuration of the request
+	requests requestDoer
+
+	// err, if not nil, is the error applying the timeouts configured on the
+	// client to the underlying client; every request fails with this error
+	err error
+}
+
+// newChain builds the chain of Doers performing the requests of the client
+func (c client) newChain() *doerChain {
+	chain := &doerChain{}
+
+	base := c.wrapped
+	if c.timeouts != nil {
+		d, err := withTimeouts(base, *c.timeouts)
+		if err != nil {
+			chain.err = err
+		} else {
+			base = d
+		}
+	}
+
+	rd := requestDoer{
+		base:         base,
+		tls:          newTLSTransports(),
+		cookies:      c.cookies,
+		maxRedirects: c.maxRedirects,
+	}
+	rd.follow = rd.wrap(base, false)
+	rd.noFollow = rd.wrap(base, true)
+	chain.requests = rd
+
+	var d Doer = rd
+	if c.backends != nil {
+		d = backendDoer{Doer: d, backends: c.backends, base: c.url}
+	}
+	if c.hedgeDelay > 0 {
+		d = hedgeDoer{Doer: d, delay: c.hedgeDelay}
+	}
+	if c.cache != nil {
+		d = cacheDoer{Doer: d, store: c.cache}
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	chain.doer = d
+
+	return chain
+}
+
+// closeIdleConnections closes any idle connections of the underlying client
+// and of any client derived from it by the chain (see: Timeouts and
+// request.TLSServerName)
+func (chain *doerChain) closeIdleConnections() {
+	closeIdle(chain.requests.base)
+	chain.requests.tls.closeIdleConnections()
+}
+
+// closeIdle closes any idle connections of a Doer, if it supports doing so (as
+// *http.Client does)
+func closeIdle(d Doer) {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if ci, ok := d.(closeIdler); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// CloseIdleConnections closes any idle connections of the underlying client
+// (see: Using) and of any transports derived from it by the client, such as
+// those configured with Timeouts or with the TLS options of a request.  Connections in use are not closed.
+//
+// The HttpClient returned by NewClient implements this method, so may be
+// closed in the same way as an *http.Client:
+//
+//	if ci, ok := c.(interface{ CloseIdleConnections() }); ok {
+//		ci.CloseIdleConnections()
+//	}
+func (c client) CloseIdleConnections() {
+	closeIdle(c.wrapped)
+	if c.chain != nil {
+		c.chain.closeIdleConnections()
+	}
+}
+
+// requestDoer performs each attempt of a request using the underlying client
+// of a client, choosing between Doers built when the client is initialised
+// according to the configuration of the request (see: request.NoFollowRedirects).
+// A request with TLS options (see: request.TLSServerName) is performed using a
+// Doer derived from the underlying client, configured with those options.
+type requestDoer struct {
+	// base is the underlying client, with any timeouts applied
+	base Doer
+
+	// tls holds the transports derived from the transport of the base
+	// client, configured with the TLS options of requests
+	tls *tlsTransports
+
+	// follow is the base client, applying the cookie jar and redirect policy
+	// of the client
+	follow Doer
+
+	// noFollow is the base client, applying the cookie jar of the client and
+	// following no redirects
+	noFollow Doer
+
+	// cookies is the cookie jar of the client, if any
+	cookies http.CookieJar
+
+	// maxRedirects is the maximum number of redirects configured on the
+	// client, if any
+	maxRedirects *int
+}
+
+// Do implements Doer
+func (rd requestDoer) Do(rq *http.Request) (*http.Response, error) {
+	cfg, _ := request.ConfigFromContext(rq.Context())
+	if cfg.TLSServerName == "" && len(cfg.PinnedCertificates) == 0 {
+		if cfg.NoFollowRedirects {
+			return rd.noFollow.Do(rq)
+		}
+		return rd.follow.Do(rq)
+	}
+
+	d, err := withTLS(rd.base, requestTLS{serverName: cfg.TLSServerName, pins: cfg.PinnedCertificates}, rd.tls)
+	if err != nil {
+		return nil, err
+	}
+	return rd.wrap(d, cfg.NoFollowRedirects).Do(rq)
+}
+
+// wrap applies the cookie jar and redirect policy of the client to a Doer; if
+// noFollow is true, no redirects are followed
+func (rd requestDoer) wrap(d Doer, noFollow bool) Doer {
+	if rd.cookies != nil {
+		d = withCookies(d, rd.cookies)
+	}
+	if rd.maxRedirects != nil || noFollow {
+		limit := DefaultMaxRedirects
+		if rd.maxRedirects != nil {
+			limit = *rd.maxRedirects
+		}
+		if noFollow {
+			limit = 0
+		}
+		d = withRedirects(d, limit)
+	}
+	return d
+}
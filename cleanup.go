@@ -0,0 +1,55 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/blugnu/http/request"
+)
+
+// requestCleanup holds the cleanup functions registered for a request (using
+// request.Cleanup), ensuring that they are called at most once
+type requestCleanup struct {
+	once sync.Once
+	fns  []func()
+}
+
+// newRequestCleanup returns a requestCleanup for any cleanup functions
+// registered for a request.  If there are no cleanup functions, nil is
+// returned.
+func newRequestCleanup(rq *http.Request) *requestCleanup {
+	fns := request.CleanupFuncs(rq)
+	if len(fns) == 0 {
+		return nil
+	}
+	return &requestCleanup{fns: fns}
+}
+
+// run calls the cleanup functions in the reverse of the order in which they
+// were registered; subsequent calls have no effect.  It is safe to call run
+// on a nil requestCleanup.
+func (rc *requestCleanup) run() {
+	if rc == nil {
+		return
+	}
+	rc.once.Do(func() {
+		for i := len(rc.fns) - 1; i >= 0; i-- {
+			rc.fns[i]()
+		}
+	})
+}
+
+// cleanupBody wraps a streamed response body, running the cleanup functions
+// of the request when the body is closed
+type cleanupBody struct {
+	io.ReadCloser
+	cleanup *requestCleanup
+}
+
+// Close implements io.Closer, closing the wrapped body and then running the
+// cleanup functions of the request
+func (cb cleanupBody) Close() error {
+	defer cb.cleanup.run()
+	return cb.ReadCloser.Close()
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRequestCleanup(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no cleanup functions",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				result := newRequestCleanup(rq)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				result.run() // must not panic
+			},
+		},
+		{scenario: "run in reverse order, once",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := []int{}
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = request.Cleanup(func() { calls = append(calls, 1) })(rq)
+				_ = request.Cleanup(func() { calls = append(calls, 2) })(rq)
+				sut := newRequestCleanup(rq)
+
+				// ACT
+				sut.run()
+				sut.run()
+
+				// ASSERT
+				test.Slice(t, calls).Equals([]int{2, 1})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestDoRunsCleanup(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "buffered response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cleaned := false
+				c := client{wrapped: &fakeClient{body: []byte("body")}}
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = request.Cleanup(func() { cleaned = true })(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, cleaned, "cleaned up")
+			},
+		},
+		{scenario: "request fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				wcerr := errors.New("wrapped client error")
+				cleaned := false
+				c := client{wrapped: &fakeClient{error: wcerr}}
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = request.Cleanup(func() { cleaned = true })(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(wcerr)
+				test.IsTrue(t, cleaned, "cleaned up")
+			},
+		},
+		{scenario: "unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cleaned := false
+				c := client{wrapped: &fakeClient{statusCode: http.StatusNotFound}}
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = request.Cleanup(func() { cleaned = true })(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsTrue(t, cleaned, "cleaned up")
+			},
+		},
+		{scenario: "streamed response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cleaned := false
+				c := client{wrapped: &fakeClient{body: []byte("body")}}
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = request.StreamResponse()(rq)
+				_ = request.Cleanup(func() { cleaned = true })(rq)
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, cleaned, "cleaned up before body is closed")
+
+				// ACT
+				body, _ := io.ReadAll(r.Body)
+				err = r.Body.Close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("body")
+				test.IsTrue(t, cleaned, "cleaned up when body is closed")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -11,13 +11,18 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/blugnu/errorcontext"
 	"github.com/blugnu/http/request"
 )
 
 var (
+	timeNow        = time.Now
+	timeSince      = time.Since
+	ioCopy         = io.Copy
 	ioReadAll      = io.ReadAll
 	parseMediaType = mime.ParseMediaType
 	nextPart       = func(mpr *multipart.Reader) (*multipart.Part, error) { return mpr.NextPart() }
@@ -34,18 +39,36 @@ type RequestOption = func(*http.Request) error
 type HttpClient interface {
 	Delete(context.Context, string, ...RequestOption) (*http.Response, error)
 	Do(*http.Request) (*http.Response, error)
+	DoWith(*http.Request, ...RequestOption) (*http.Response, error)
 	Get(context.Context, string, ...RequestOption) (*http.Response, error)
+	Invoke(context.Context, string, map[string]any, ...RequestOption) (*http.Response, error)
+	Download(context.Context, string, string, ...RequestOption) (int64, error)
+	GetInto(context.Context, string, io.Writer, ...RequestOption) (int64, error)
+	LongPoll(context.Context, string, time.Duration, ...RequestOption) <-chan Result
 	Patch(context.Context, string, ...RequestOption) (*http.Response, error)
 	Post(context.Context, string, ...RequestOption) (*http.Response, error)
 	Put(context.Context, string, ...RequestOption) (*http.Response, error)
 	NewRequest(context.Context, string, string, ...RequestOption) (*http.Request, error)
+	UploadFile(context.Context, string, string, string, io.Reader, ...RequestOption) (*http.Response, error)
 }
 
-// ClientInterface is an interface that describes a wrappable http client
-type ClientInterface interface {
+// Doer describes any type that submits requests, such as an *http.Client.  It is
+// the type of client wrapped by an HttpClient (see: Using) and the type of the
+// clients passed to and returned by NewMockClient wrapper functions.
+//
+// Doer is an alias for an anonymous interface type, so types and function
+// signatures declared in other modules using an equivalent anonymous interface
+// are interchangeable with those declared using Doer, without any coupling
+// to this package.
+type Doer = interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// ClientInterface is an interface that describes a wrappable http client.
+//
+// Deprecated: use Doer.
+type ClientInterface = Doer
+
 // ClientOption is a function that applies an option to a client
 type ClientOption func(*client) error
 
@@ -62,10 +85,119 @@ type client struct {
 	url string
 
 	// wrapped is the underlying http client
-	wrapped ClientInterface
+	wrapped Doer
 
 	// maxRetries is the maximum number of times a request will be retried
 	maxRetries uint
+
+	// retryWithin, if not nil, configures unlimited retries within the
+	// duration specified (see: UnlimitedRetriesWithin)
+	retryWithin *time.Duration
+
+	// endpoints holds any named endpoints registered on the client
+	endpoints map[string]endpoint
+
+	// rateLimit holds the rate limiting state of the client, if configured
+	// to handle 429 Too Many Requests responses
+	rateLimit *rateLimit
+
+	// onWait, if not nil, is called whenever the client deliberately waits
+	onWait func(reason string, d time.Duration)
+
+	// stats records the transfer stats of the client
+	stats *clientStats
+
+	// backoff determines the delay before each retry; if nil the
+	// defaultBackoff policy is used
+	backoff BackoffPolicy
+
+	// faults, if not nil, injects delays and/or errors into requests
+	// (see: InjectFaults)
+	faults *FaultInjection
+
+	// retryStatus identifies the status codes for which a response is
+	// retried; if nil the DefaultRetryStatus codes are used
+	retryStatus []int
+
+	// https, if not nil, upgrades requests to https (see: UpgradeToHTTPS)
+	https *httpsPolicy
+
+	// middleware wraps the underlying client in each request (see: Use)
+	middleware []Middleware
+
+	// tenants, if not nil, resolves the configuration of tenants for
+	// requests made for a tenant (see: Tenants)
+	tenants TenantProvider
+
+	// onDeprecation, if not nil, is called for any response with
+	// deprecation or warning headers (see: OnDeprecation)
+	onDeprecation func(context.Context, DeprecationNotice)
+
+	// logging, if not nil, logs every request (see: Logging)
+	logging *logging
+
+	// metrics, if not nil, records metrics for every request (see: Metrics)
+	metrics MetricsRecorder
+
+	// throttle, if not nil, limits the rate at which requests are sent
+	// (see: RateLimit)
+	throttle *throttle
+
+	// timeouts, if not nil, configures the timeouts applied to each stage
+	// of a request (see: Timeouts)
+	timeouts *TimeoutConfig
+
+	// transformers are applied to every successful response (see: TransformResponse)
+	transformers []ResponseTransformer
+
+	// cookies, if not nil, holds cookies applied to and captured from
+	// requests (see: CookieJar)
+	cookies http.CookieJar
+
+	// auth, if not nil, is applied to every request initialised by the
+	// client (see: Auth)
+	auth RequestOption
+
+	// tokens, if not nil, authorizes every attempt to perform a request
+	// (see: BearerAuth)
+	tokens *TokenSource
+
+	// headers are set on every request initialised by the client (see: Headers)
+	headers http.Header
+
+	// requestID, if not nil, configures a correlation id to be sent with
+	// every request (see: RequestID)
+	requestID *requestID
+
+	// backends, if not nil, holds backend hosts across which requests are
+	// distributed (see: Backends)
+	backends *backends
+
+	// hedgeDelay, if not zero, is the delay after which a second attempt is
+	// sent for an idempotent request that has not completed (see: Hedging)
+	hedgeDelay time.Duration
+
+	// transport holds functions applied to a clone of the Transport of the
+	// wrapped *http.Client once all options have been applied (see:
+	// ConfigureTransport)
+	transport []func(*http.Transport)
+
+	// cache, if not nil, stores responses to GET requests to be served
+	// without (or revalidated by) subsequent requests (see: Cache)
+	cache CacheStore
+
+	// maxResponseBytes, if greater than zero, is the maximum size of a
+	// response body read by the client (see: MaxResponseBytes)
+	maxResponseBytes int64
+
+	// maxRedirects, if not nil, is the maximum number of redirects followed
+	// for a request (see: MaxRedirects)
+	maxRedirects *int
+
+	// chain, if not nil, holds the Doers performing requests, built once all
+	// options have been applied (see: NewClient); if nil, the chain is built
+	// for each request
+	chain *doerChain
 }
 
 // NewClient returns a new HttpClient with the name and url specified, wrapping
@@ -83,7 +215,8 @@ type client struct {
 func NewClient(name string, opts ...ClientOption) (HttpClient, error) {
 	w := client{
 		name:    name,
-		wrapped: http.DefaultClient,
+		wrapped: withStrippedOptionHeaders(http.DefaultClient),
+		stats:   &clientStats{},
 	}
 	errs := make([]error, 0, len(opts))
 	for _, opt := range opts {
@@ -91,9 +224,13 @@ func NewClient(name string, opts ...ClientOption) (HttpClient, error) {
 			errs = append(errs, err)
 		}
 	}
+	if err := w.configureTransport(); err != nil {
+		errs = append(errs, err)
+	}
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("%w: %w", ErrInitialisingClient, errors.Join(errs...))
 	}
+	w.chain = w.newChain()
 	return w, nil
 }
 
@@ -125,26 +262,46 @@ func NewClient(name string, opts ...ClientOption) (HttpClient, error) {
 //	)
 //
 // The above code will result in a request being made to "http://example.com/path?query=string"
+//
+// An error wrapping ErrConflictingOptions is returned if any option conflicts
+// with an option applied before it, e.g. if more than one option sets the
+// body of the request or request.RawQuery replaces a query established using
+// request.Query.
+//
+// The request is configured only by the options specified; any request.Config
+// carried by the context (e.g. the context of some other request) is not
+// inherited by the request.
 func (c client) NewRequest(
 	ctx context.Context,
 	method string,
 	path string,
 	opts ...RequestOption,
 ) (*http.Request, error) {
-	url, err := url.JoinPath(c.url, path)
+	ctx = request.WithoutConfig(ctx)
+
+	base, opts, err := c.resolveTenant(ctx, opts)
+	if err != nil {
+		return nil, errorcontext.Errorf(ctx, "NewRequest: %w", err)
+	}
+	if c.auth != nil {
+		opts = append([]RequestOption{c.auth}, opts...)
+	}
+
+	url, err := url.JoinPath(base, path)
 	if err != nil {
-		return nil, errorcontext.Errorf(ctx, "NewRequest: %w: %w", ErrInvalidURL, err)
+		return nil, errorcontext.Errorf(ctx, "NewRequest: %w", InvalidURLError{URL: base, Err: err})
 	}
 
 	rq, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, errorcontext.Errorf(ctx, "NewRequest: %w: %w", ErrInitialisingRequest, err)
 	}
+	for k, v := range c.headers {
+		rq.Header[k] = slices.Clone(v)
+	}
 
-	for _, opt := range opts {
-		if err := opt(rq); err != nil {
-			return nil, errorcontext.Errorf(ctx, "NewRequest: %w", err)
-		}
+	if err := applyOptions(rq, opts); err != nil {
+		return nil, errorcontext.Errorf(ctx, "NewRequest: %w", err)
 	}
 
 	return rq, nil
@@ -153,56 +310,204 @@ func (c client) NewRequest(
 // do submits a supplied request using the wrapped client.
 //
 // If an error occurs while submitting the request then it will be resubmitted up
-// to the number of retries specified on the request or the client.
+// to the number of retries specified on the request or the client, waiting
+// before each retry for a delay determined by the backoff policy of the request
+// or client.
 //
 // If a response is received with a status code that is not http.StatusOK or any
 // additional acceptable statuses configured on the request using the request.AcceptStatus()
 // option, then the response is returned with an http.ErrUnexpectedResponse error.
+//
+// If the client is configured to handle 429 Too Many Requests responses, the
+// client is paused and the request may be retried (see: HandleTooManyRequests).
+//
+// If the request context has a deadline, a retry is not attempted if the deadline
+// would be reached before the retry could complete, based on the delay before the
+// retry and the shortest duration of any attempt so far; a RetryDeadlineError is
+// returned instead of starting an attempt that is doomed to be cancelled.
+//
+// If retries are unlimited (see: UnlimitedRetriesWithin), a request is retried
+// until it succeeds, the context is done or a retry could not complete within
+// the duration configured for the request.
+//
+// The number of attempts made is returned together with the response and/or error.
 func (c client) do(
 	ctx context.Context,
 	rq *http.Request,
-	retries uint,
-	accept []uint,
-) (*http.Response, error) {
+	opts requestOptions,
+) (*http.Response, uint, error) {
+	retries := opts.maxRetries
 	n := retries
+	retry := uint(0)
+	attempts := uint(0)
+	shortest := time.Duration(0)
+	retryUntil := time.Time{}
+	if opts.unlimited && opts.retryWithin > 0 {
+		retryUntil = timeNow().Add(opts.retryWithin)
+	}
+	rateLimitRetries := uint(0)
+	if c.rateLimit != nil {
+		rateLimitRetries = c.rateLimit.retries
+	}
+	reauthorized := false
 	for {
-		r, err := c.wrapped.Do(rq)
+		if c.rateLimit != nil {
+			if err := c.awaitRateLimit(ctx); err != nil {
+				return nil, attempts, errorcontext.Errorf(ctx, "%w", err)
+			}
+		}
+		if c.throttle != nil && !opts.unthrottled {
+			if err := c.awaitThrottle(ctx); err != nil {
+				return nil, attempts, err
+			}
+		}
+
+		// a request body is consumed by each attempt; if the request has
+		// been attempted the body is replaced (if possible) before retrying
+		if attempts > 0 && rq.GetBody != nil {
+			body, err := rq.GetBody()
+			if err != nil {
+				return nil, attempts, errorcontext.Errorf(ctx, "%w: %w", ErrCannotCloneBody, err)
+			}
+			rq.Body = body
+		}
+
+		var token Token
+		if c.tokens != nil {
+			var err error
+			if token, err = c.authorize(ctx, rq); err != nil {
+				return nil, attempts, err
+			}
+		}
+
+		attempts++
+		if c.stats != nil {
+			c.stats.add(EndpointName(ctx), TransferStats{Requests: 1})
+		}
+		var r *http.Response
+		start := timeNow()
+		err := c.injectFault(ctx)
+		if err == nil {
+			r, err = c.wrapped.Do(rq)
+		}
+		if elapsed := timeSince(start); attempts == 1 || elapsed < shortest {
+			shortest = elapsed
+		}
+		if err == nil {
+			c.countReceived(rq, r, opts.transfer)
+		}
 		if err != nil {
 			switch {
+			// retries are unlimited (bounded by time)
+			case opts.unlimited:
+
 			// no retries were configured
 			case retries == 0:
-				return r, err
+				return r, attempts, err
 
 			// retries were configured but have been exhausted
 			case n == 0:
-				return r, errorcontext.Wrap(ctx, ErrMaxRetriesExceeded, err)
+				return r, attempts, errorcontext.Errorf(ctx, "%w", MaxRetriesExceededError{Attempts: attempts, Err: err})
 
 			// at least one retry attempt remains
 			default:
 				n--
 			}
+			retry++
+
+			d := opts.backoff(retry)
+			if !canRetry(ctx, retryUntil, d, shortest) {
+				return r, attempts, errorcontext.Errorf(ctx, "%w", RetryDeadlineError{Attempts: attempts, Err: err})
+			}
+			if d > 0 {
+				if ctxerr := c.wait(ctx, WaitBackoff, d); ctxerr != nil {
+					return r, attempts, errorcontext.Errorf(ctx, "%w: %w", ctxerr, err)
+				}
+			}
 			continue
 		}
 
+		// a rate limited response pauses the client, even if the status
+		// is acceptable to the request
+		var reset time.Time
+		if r.StatusCode == http.StatusTooManyRequests && c.rateLimit != nil {
+			reset = c.rateLimit.pause(r)
+		}
+
 		// if the response has any of the acceptable status codes then it
 		// is returned without error
-		for _, sc := range accept {
+		for _, sc := range opts.acceptStatus {
 			if uint(r.StatusCode) == sc {
-				return r, nil
+				return r, attempts, nil
+			}
+		}
+		for _, accept := range opts.acceptStatusFuncs {
+			if accept(r.StatusCode) {
+				return r, attempts, nil
 			}
 		}
 
+		// a request rejected as unauthorized is retried once with a new
+		// token (if the body of the request can be replayed)
+		if r.StatusCode == http.StatusUnauthorized && c.tokens != nil && !reauthorized &&
+			(rq.Body == nil || rq.Body == http.NoBody || rq.GetBody != nil) {
+			reauthorized = true
+			c.tokens.invalidate(token)
+			_ = closeBody(ctx, r.Body)
+			continue
+		}
+
 		// if we reach this point then we have received a response with a status
 		// code that is not acceptable
-		return r, errorcontext.Errorf(ctx, "%w: %s", ErrUnexpectedStatusCode, r.Status)
+		statusErr := statusError(r)
+		if r.StatusCode == http.StatusTooManyRequests && c.rateLimit != nil {
+			if rateLimitRetries > 0 && c.rateLimit.canWait(ctx, reset) {
+				rateLimitRetries--
+				_ = closeBody(ctx, r.Body)
+				continue
+			}
+			return r, attempts, errorcontext.Errorf(ctx, "%w", RateLimitedError{Reset: reset, Err: statusErr})
+		}
+
+		// a response with a retryable status is retried if any retries remain
+		if (retries > 0 || opts.unlimited) && slices.Contains(opts.retryStatus, r.StatusCode) {
+			if !opts.unlimited {
+				if n == 0 {
+					return r, attempts, errorcontext.Errorf(ctx, "%w", MaxRetriesExceededError{Attempts: attempts, Err: statusErr})
+				}
+				n--
+			}
+			retry++
+
+			reason := WaitBackoff
+			if r.Header.Get("Retry-After") != "" {
+				reason = WaitRetryAfter
+			}
+			d := retryDelay(r, opts.backoff(retry))
+			if !canRetry(ctx, retryUntil, d, shortest) {
+				return r, attempts, errorcontext.Errorf(ctx, "%w", RetryDeadlineError{Attempts: attempts, Err: statusErr})
+			}
+			if d > 0 {
+				if ctxerr := c.wait(ctx, reason, d); ctxerr != nil {
+					return r, attempts, errorcontext.Errorf(ctx, "%w: %w", ctxerr, statusErr)
+				}
+			}
+			_ = closeBody(ctx, r.Body)
+			continue
+		}
+		return r, attempts, errorcontext.Errorf(ctx, "%w", statusErr)
 	}
 }
 
 // parseRequestHeaders parses the headers of a specified request to identify
 // configuration relevant to the execution of the request and initial handling
-// of any response.
+// of any response.  These headers are no longer set by the request options
+// provided by this module but continue to be supported for backwards
+// compatibility.
 //
-// Any headers found and parsed are removed from the request.
+// Any headers found and parsed are removed from the request.  If any header
+// is invalid, an InvalidOptionsError is returned identifying every invalid
+// header.
 func (c client) parseRequestHeaders(rq *http.Request) (
 	maxRetries uint,
 	acceptableStatusCodes []uint,
@@ -212,15 +517,15 @@ func (c client) parseRequestHeaders(rq *http.Request) (
 ) {
 	ctx := rq.Context()
 
-	parse := func(hdr string, fn func(string) error) error {
+	invalid := []InvalidRequestHeaderError{}
+	parse := func(hdr string, fn func(string) error) {
 		defer delete(rq.Header, hdr)
 
 		if s, ok := rq.Header[hdr]; ok {
 			if err := fn(s[0]); err != nil {
-				return errorcontext.Errorf(ctx, "%w: %s: %w", ErrInvalidRequestHeader, hdr, err)
+				invalid = append(invalid, InvalidRequestHeaderError{Header: hdr, Value: s[0], Err: err})
 			}
 		}
-		return nil
 	}
 
 	// default values if option headers are not present
@@ -228,42 +533,136 @@ func (c client) parseRequestHeaders(rq *http.Request) (
 	acceptableStatusCodes = []uint{http.StatusOK}
 	responseBodyRequired = false
 	streamResponse = false
-	errs := []error{}
 
 	// extract max retries
-	errs = append(errs, parse(request.MaxRetriesHeader, func(s string) error {
+	parse(request.MaxRetriesHeader, func(s string) error {
 		i, err := strconv.Atoi(s)
 		if err != nil {
 			return err
 		}
 		maxRetries = uint(i)
 		return nil
-	}))
+	})
 
 	// extract acceptable statuses
-	errs = append(errs, parse(request.AcceptStatusHeader, func(s string) error {
+	parse(request.AcceptStatusHeader, func(s string) error {
 		if err := json.Unmarshal([]byte(s), &acceptableStatusCodes); err != nil {
 			return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
 		}
 		return nil
-	}))
+	})
 
 	// extract response body required flag
-	errs = append(errs, parse(request.ResponseBodyRequiredHeader, func(s string) error {
+	parse(request.ResponseBodyRequiredHeader, func(s string) error {
 		responseBodyRequired = s == "true"
 		return nil
-	}))
+	})
 
 	// extract stream response flag
-	errs = append(errs, parse(request.StreamResponseHeader, func(s string) error {
+	parse(request.StreamResponseHeader, func(s string) error {
 		streamResponse = s == "true"
 		return nil
-	}))
+	})
 
-	err = errors.Join(errs...)
+	if len(invalid) > 0 {
+		err = errorcontext.Errorf(ctx, "%w", InvalidOptionsError{Options: invalid})
+	}
 	return
 }
 
+// requestOptions holds the configuration of a request, determining how the
+// request is performed and the initial handling of any response
+type requestOptions struct {
+	maxRetries        uint
+	acceptStatus      []uint
+	acceptStatusFuncs []func(int) bool
+	bodyRequired      bool
+	stream            bool
+	progress          func(int64, int64)
+	downloadProgress  func(int64, int64)
+	backoff           BackoffPolicy
+	retryStatus       []int
+	tls               *requestTLS
+	unlimited         bool
+	retryWithin       time.Duration
+	unthrottled       bool
+	maxResponseBytes  int64
+	noFollowRedirects bool
+	transfer          *transferCount
+}
+
+// requestConfig determines the configuration of a specified request, combining
+// any configuration established by (legacy) request option headers with the
+// request.Config (if any) carried in the request context.
+//
+// Where both are present, a MaxRetries value in the request.Config takes
+// precedence over any header, acceptable status codes are combined and the
+// response body required and stream response flags are set if set by either.
+func (c client) requestConfig(rq *http.Request) (requestOptions, error) {
+	maxRetries, acceptStatus, bodyRequired, stream, err := c.parseRequestHeaders(rq)
+	if err != nil {
+		return requestOptions{}, err
+	}
+
+	opts := requestOptions{
+		maxRetries:       maxRetries,
+		acceptStatus:     acceptStatus,
+		bodyRequired:     bodyRequired,
+		stream:           stream,
+		backoff:          c.backoff,
+		retryStatus:      c.retryStatus,
+		maxResponseBytes: c.maxResponseBytes,
+	}
+	if opts.backoff == nil {
+		opts.backoff = defaultBackoff
+	}
+	if opts.retryStatus == nil {
+		opts.retryStatus = DefaultRetryStatus
+	}
+	if c.retryWithin != nil {
+		opts.unlimited = true
+		opts.retryWithin = *c.retryWithin
+	}
+
+	cfg, ok := request.ConfigFromContext(rq.Context())
+	if !ok {
+		return opts, nil
+	}
+
+	if cfg.MaxRetries != nil {
+		opts.maxRetries = *cfg.MaxRetries
+		opts.unlimited = false
+	}
+	if cfg.UnlimitedRetriesWithin != nil {
+		opts.unlimited = true
+		opts.retryWithin = *cfg.UnlimitedRetriesWithin
+	}
+	for _, sc := range cfg.AcceptStatus {
+		opts.acceptStatus = append(opts.acceptStatus, uint(sc))
+	}
+	opts.acceptStatusFuncs = cfg.AcceptStatusFuncs
+	opts.bodyRequired = opts.bodyRequired || cfg.ResponseBodyRequired
+	opts.stream = opts.stream || cfg.StreamResponse
+	opts.progress = cfg.Progress
+	opts.downloadProgress = cfg.DownloadProgress
+	opts.unthrottled = cfg.BypassRateLimit
+	if cfg.Backoff != nil {
+		opts.backoff = cfg.Backoff
+	}
+	if cfg.RetryOnStatus != nil {
+		opts.retryStatus = cfg.RetryOnStatus
+	}
+	if cfg.MaxResponseBytes != nil {
+		opts.maxResponseBytes = *cfg.MaxResponseBytes
+	}
+	opts.noFollowRedirects = cfg.NoFollowRedirects
+	if cfg.TLSServerName != "" || len(cfg.PinnedCertificates) > 0 {
+		opts.tls = &requestTLS{serverName: cfg.TLSServerName, pins: cfg.PinnedCertificates}
+	}
+
+	return opts, nil
+}
+
 // execute is used by the exported convenience methods to execute a specific method
 func (c client) execute(
 	ctx context.Context,
@@ -273,53 +672,186 @@ func (c client) execute(
 ) (*http.Response, error) {
 	rq, err := c.NewRequest(ctx, method, url, opts...)
 	if err != nil {
-		return nil, errorcontext.Errorf(ctx, "%s: %s: %w", c.name, method, err)
+		return nil, errorcontext.Errorf(ctx, "%w", ClientError{Client: c.name, Method: method, Err: err})
 	}
 	return c.Do(rq)
 }
 
 // Do submits a request using the wrapped client, handling the response and
 // returning the response or an error.
-func (c client) Do(rq *http.Request) (*http.Response, error) {
+//
+// Any error returned is a ClientError, identifying the client and request
+// involved, the number of attempts made and the time elapsed.
+//
+// If the client is configured with Logging and/or Metrics, the request is
+// logged and/or recorded when it completes.
+//
+// Any cleanup functions registered for the request (see request.Cleanup) are
+// called when the request fails, once the response body has been read or,
+// for a streamed response, when the response body is closed.
+func (c client) Do(rq *http.Request) (response *http.Response, err error) {
 	ctx := rq.Context()
+	start := timeNow()
+	attempts := uint(0)
+	cleanup := newRequestCleanup(rq)
+	defer func() {
+		if err != nil || response == nil || response.Body == nil {
+			cleanup.run()
+			return
+		}
+		if _, streamed := response.Body.(cleanupBody); !streamed {
+			cleanup.run()
+		}
+	}()
+	var transfer *transferCount
+	if c.metrics != nil {
+		transfer = &transferCount{}
+	}
+	if c.logging != nil || c.metrics != nil {
+		defer func() {
+			c.observe(ctx, rq, response, timeSince(start), attempts, transfer, err)
+		}()
+	}
+	requestID := RequestIDFromContext(ctx)
+	if c.requestID != nil {
+		requestID = c.requestID.stamp(rq)
+		if requestID != RequestIDFromContext(ctx) {
+			ctx = WithRequestID(ctx, requestID)
+			*rq = *rq.WithContext(ctx)
+		}
+	}
 	handle := func(r *http.Response, err error) (*http.Response, error) {
-		return r, errorcontext.Errorf(ctx, "%s: %s %s: %w", c.name, rq.Method, rq.URL, err)
+		return r, errorcontext.Errorf(ctx, "%w", ClientError{
+			Client:    c.name,
+			Method:    rq.Method,
+			URL:       rq.URL.Redacted(),
+			RequestID: requestID,
+			Attempts:  attempts,
+			Elapsed:   timeSince(start),
+			Err:       err,
+		})
+	}
+
+	if c.https != nil {
+		c.https.upgrade(rq)
 	}
 
-	retries, statusCodes, bodyRequired, stream, err := c.parseRequestHeaders(rq)
+	opts, err := c.requestConfig(rq)
 	if err != nil {
 		return handle(nil, err)
 	}
 
-	r, err := c.do(ctx, rq, retries, statusCodes)
+	chain := c.chain
+	if chain == nil {
+		chain = c.newChain()
+	}
+	if chain.err != nil {
+		return handle(nil, chain.err)
+	}
+	if opts.tls != nil {
+		if _, err := withTLS(chain.requests.base, *opts.tls, chain.requests.tls); err != nil {
+			return handle(nil, err)
+		}
+	}
+	c.wrapped = chain.doer
+
+	if opts.progress != nil {
+		reportProgress(rq, opts.progress)
+	}
+	c.countSent(rq, transfer)
+
+	opts.transfer = transfer
+	r, attempts, err := c.do(ctx, rq, opts)
+	if c.https != nil {
+		c.https.pin(r)
+	}
+	if c.onDeprecation != nil {
+		if n, ok := ParseDeprecation(r); ok {
+			c.onDeprecation(request.WithoutConfig(ctx), n)
+		}
+	}
 	if err != nil {
 		return handle(r, err)
 	}
-	if stream {
+	if c.timeouts != nil && c.timeouts.BodyIdle > 0 {
+		r.Body = newIdleTimeoutBody(r.Body, c.timeouts.BodyIdle)
+	}
+	if opts.downloadProgress != nil {
+		reportDownloadProgress(r, opts.downloadProgress)
+	}
+	if opts.stream {
+		// a streamed body is read once metrics are recorded, so the bytes
+		// received are taken from the Content-Length (if known)
+		if transfer != nil && r.ContentLength > 0 {
+			transfer.received.Add(r.ContentLength)
+		}
+		r.Body = newContextBody(ctx, r.Body)
+		if r, err = c.transform(r); err != nil {
+			return handle(r, err)
+		}
+		if cleanup != nil {
+			r.Body = cleanupBody{ReadCloser: r.Body, cleanup: cleanup}
+		}
 		return r, nil
 	}
 
-	body, err := ioReadAll(r.Body)
+	body, err := readResponseBody(r, opts.maxResponseBytes)
 	defer r.Body.Close()
 
 	r.ContentLength = 0
 	r.Body = http.NoBody
 
 	switch {
+	case errors.Is(err, ErrResponseBodyTooLarge):
+		return handle(r, err)
+
 	case err != nil:
 		return handle(r, errorcontext.Errorf(ctx, "response.Body: %w", err))
 
-	case len(body) == 0 && bodyRequired:
+	case len(body) == 0 && opts.bodyRequired && r.StatusCode != http.StatusNotModified:
 		return handle(r, ErrNoResponseBody)
 
-	case len(body) == 0:
-		return r, nil
-
-	default:
+	case len(body) > 0:
 		r.ContentLength = int64(len(body))
 		r.Body = io.NopCloser(bytes.NewReader(body))
-		return r, nil
 	}
+	if r, err = c.transform(r); err != nil {
+		return handle(r, err)
+	}
+	return r, nil
+}
+
+// readResponseBody reads the body of a response, limited to a maximum size if
+// greater than zero.  If the body (or the Content-Length of the response)
+// exceeds the limit, an error wrapping ErrResponseBodyTooLarge is returned.
+func readResponseBody(r *http.Response, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return ioReadAll(r.Body)
+	}
+	if r.ContentLength > limit {
+		return nil, fmt.Errorf("%w: content length %d exceeds limit of %d bytes", ErrResponseBodyTooLarge, r.ContentLength, limit)
+	}
+	return ioReadAll(&limitReader{r: r.Body, remaining: limit})
+}
+
+// DoWith applies any specified request options to a supplied request before
+// submitting it using the client, as for Do.  This enables requests constructed
+// elsewhere (e.g. by an SDK or a proxy handler) to be performed with the benefit
+// of request options, retries and status handling provided by the client.
+//
+// The supplied request is modified by the options applied.  As for NewRequest,
+// an error wrapping ErrConflictingOptions is returned if any options conflict.
+func (c client) DoWith(rq *http.Request, opts ...RequestOption) (*http.Response, error) {
+	ctx := rq.Context()
+	if err := applyOptions(rq, opts); err != nil {
+		return nil, errorcontext.Errorf(ctx, "%w", ClientError{
+			Client: c.name,
+			Method: rq.Method,
+			URL:    rq.URL.Redacted(),
+			Err:    err,
+		})
+	}
+	return c.Do(rq)
 }
 
 // Delete is a convenience method for constructing and performing a Delete request,
@@ -342,6 +874,59 @@ func (c client) Get(
 	return c.execute(ctx, http.MethodGet, path, opts...)
 }
 
+// GetInto performs a Get request, appending the specified path to the client url
+// and applying any RequestOptions, streaming the body of the response directly
+// into a supplied writer (e.g. a file, hash or pipe) with no intermediate buffering
+// of the body.  The number of bytes written is returned.
+//
+// If the response specifies a Content-Length and the number of bytes written
+// differs, ErrContentLengthMismatch is returned together with the number of bytes
+// written.
+//
+// The response body is always closed; the StreamResponse option is implied.
+func (c client) GetInto(
+	ctx context.Context,
+	path string,
+	w io.Writer,
+	opts ...RequestOption,
+) (int64, error) {
+	opts = append(append([]RequestOption{}, opts...), request.StreamResponse())
+
+	rq, err := c.NewRequest(ctx, http.MethodGet, path, opts...)
+	if err != nil {
+		return 0, errorcontext.Errorf(ctx, "%w", ClientError{Client: c.name, Method: http.MethodGet, Err: err})
+	}
+
+	r, err := c.Do(rq)
+	if r != nil {
+		defer func() { _ = closeBody(ctx, r.Body) }()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	handle := func(n int64, err error) (int64, error) {
+		return n, errorcontext.Errorf(ctx, "%w", ClientError{
+			Client: c.name,
+			Method: rq.Method,
+			URL:    rq.URL.Redacted(),
+			Err:    err,
+		})
+	}
+
+	n, err := ioCopy(w, r.Body)
+	switch {
+	case err != nil:
+		return handle(n, fmt.Errorf("%w: %w", ErrReadingResponseBody, err))
+
+	case r.ContentLength >= 0 && n != r.ContentLength:
+		return handle(n, fmt.Errorf("%w: expected %d bytes, got %d", ErrContentLengthMismatch, r.ContentLength, n))
+
+	default:
+		return n, nil
+	}
+}
+
 // Patch is a convenience method for constructing and performing a Patch request,
 // appending the specified path to the client url and applying any RequestOptions
 func (c client) Patch(
@@ -417,15 +1002,19 @@ func MapFromMultipartFormData[K comparable, V any](
 //
 // The function returns an error if the body cannot be read or if the body does not
 // contain valid JSON and the result will be the zero value of the generic type.
-func UnmarshalJSON[T any](ctx context.Context, r *http.Response) (T, error) {
+//
+// Options may be specified to limit the size of the body that will be processed
+// (see: MaxDecodeSize).  To decode the elements of a very large JSON array without
+// holding the entire body in memory, use DecodeEach.
+func UnmarshalJSON[T any](ctx context.Context, r *http.Response, opts ...DecodeOption) (T, error) {
 	result := *new(T)
 
 	handle := func(sen, err error) (T, error) {
 		return result, errorcontext.Errorf(ctx, "http.UnmarshalJSON: %w: %w", sen, err)
 	}
 
-	body, err := ioReadAll(r.Body)
-	defer r.Body.Close()
+	body, err := ioReadAll(decodeReader(r, opts...))
+	defer func() { _ = closeBody(ctx, r.Body) }()
 	if err != nil {
 		return handle(ErrReadingResponseBody, err)
 	}
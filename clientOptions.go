@@ -1,16 +1,58 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // MaxRetries sets the maximum number of retries for requests made using the client.
 // Individual requests may be configured to override this value on a case-by-case basis.
+//
+// A maximum of zero (the default) disables retries.  MaxRetries replaces any
+// UnlimitedRetriesWithin option applied previously.
 func MaxRetries(n uint) ClientOption {
 	return func(c *client) error {
 		c.maxRetries = n
+		c.retryWithin = nil
+		return nil
+	}
+}
+
+// MaxResponseBytes sets the maximum size (in bytes) of a response body that the
+// client will read into memory.  If a response body exceeds this size it is
+// discarded and an error wrapping ErrResponseBodyTooLarge is returned; a response
+// with a Content-Length exceeding the limit is refused without reading the body.
+//
+// The limit does not apply to streamed responses (see: request.StreamResponse),
+// the body of which is read by the caller.  Individual requests may be configured
+// to override the limit using request.MaxResponseBytes.
+//
+// A limit of zero (the default) or less imposes no limit.
+func MaxResponseBytes(n int64) ClientOption {
+	return func(c *client) error {
+		c.maxResponseBytes = n
+		return nil
+	}
+}
+
+// UnlimitedRetriesWithin configures requests made using the client to be retried
+// without limit on the number of retries, but only within a specified duration
+// from the initial attempt; a retry is not attempted if it could not complete
+// within that duration.  This is intended for long-running processes (such as
+// reconciliation jobs) that should persist until a request succeeds.
+//
+// If the duration is zero, retries are bounded only by the request context; a
+// request with a context that is never done is retried indefinitely.
+//
+// UnlimitedRetriesWithin replaces any MaxRetries option applied previously;
+// individual requests may be configured to override it using request.MaxRetries
+// or request.UnlimitedRetriesWithin.
+func UnlimitedRetriesWithin(d time.Duration) ClientOption {
+	return func(c *client) error {
+		c.retryWithin = &d
 		return nil
 	}
 }
@@ -21,35 +63,51 @@ func MaxRetries(n uint) ClientOption {
 // If a string is provided, it will be parsed to ensure it is a valid, absolute URL.
 //
 // If a URL is provided is must be absolute.
+//
+// A url with a unix scheme identifies the path to a unix domain socket (e.g.
+// unix:///var/run/docker.sock); the client connects to the socket and the base
+// url for requests is http://localhost (see: UnixSocket).
 func URL(u any) ClientOption {
 	return func(c *client) error {
 		switch u := u.(type) {
 		case string:
 			url, err := url.Parse(u)
 			if err != nil {
-				return fmt.Errorf("http: URL option: %w: %w", ErrInvalidURL, err)
+				return fmt.Errorf("http: URL option: %w", InvalidURLError{URL: u, Err: err})
 			}
 			return URL(url)(c)
 
 		case *url.URL:
 			if !u.IsAbs() {
-				return fmt.Errorf("http: URL option: %w: URL must be absolute", ErrInvalidURL)
+				return fmt.Errorf("http: URL option: %w", InvalidURLError{URL: u.String(), Err: errors.New("URL must be absolute")})
+			}
+			if u.Scheme == "unix" {
+				if err := unixSocket(c, u); err != nil {
+					return fmt.Errorf("http: URL option: %w", err)
+				}
+				return nil
 			}
 			c.url = u.String()
 
 		default:
-			return fmt.Errorf("http: URL option: %w: must be a string or *url.URL", ErrInvalidURL)
+			return fmt.Errorf("http: URL option: %w", InvalidURLError{URL: fmt.Sprintf("%v", u), Err: errors.New("must be a string or *url.URL")})
 		}
 		return nil
 	}
 }
 
-// Using sets the HTTP client to use for requests made using the client.  Any value
-// that implements the `Do(*http.Request) (*http.Response, error)` method may be used.
-func Using(httpClient interface {
-	Do(*http.Request) (*http.Response, error)
-}) ClientOption {
+// Using sets the HTTP client to use for requests made using the client.  Any Doer
+// (i.e. any value that implements the `Do(*http.Request) (*http.Response, error)`
+// method) may be used.
+//
+// If an *http.Client is specified, a copy is used with a Transport that ensures
+// that request option headers are never sent to a server (see: StripOptionHeaders);
+// the supplied *http.Client is not modified.
+func Using(httpClient Doer) ClientOption {
 	return func(c *client) error {
+		if hc, ok := httpClient.(*http.Client); ok {
+			httpClient = withStrippedOptionHeaders(hc)
+		}
 		c.wrapped = httpClient
 		return nil
 	}
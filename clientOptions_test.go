@@ -1,9 +1,15 @@
 package http
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/blugnu/http/request"
 	"github.com/blugnu/test"
 )
 
@@ -19,6 +25,170 @@ func TestMaxRetries(t *testing.T) {
 	test.That(t, client.maxRetries).Equals(3)
 }
 
+func TestMaxResponseBytes(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server returns a client with a specified limit, for which every request
+	// receives a response with a 16 byte body and a specified content length
+	server := func(limit, contentLength int64) HttpClient {
+		c, _ := NewClient("name",
+			URL("http://hostname"),
+			MaxResponseBytes(limit),
+			Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: contentLength,
+					Body:          io.NopCloser(strings.NewReader("0123456789abcdef")),
+				}, nil
+			})),
+		)
+		return c
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &client{}
+
+				// ACT
+				err := MaxResponseBytes(1024)(client)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, client.maxResponseBytes).Equals(1024)
+			},
+		},
+		{scenario: "within limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(16, 16)
+
+				// ACT
+				r, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("0123456789abcdef")
+			},
+		},
+		{scenario: "body exceeds limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(15, -1)
+
+				// ACT
+				r, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+				test.That(t, r.Body).Equals(http.NoBody)
+			},
+		},
+		{scenario: "content length exceeds limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(15, 16)
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+			},
+		},
+		{scenario: "request override",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(8, 16)
+
+				// ACT
+				_, err := c.Get(ctx, "path", request.MaxResponseBytes(16))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "request removes limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(8, 16)
+
+				// ACT
+				_, err := c.Get(ctx, "path", request.MaxResponseBytes(0))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "streamed response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(8, 16)
+
+				// ACT
+				r, err := c.Get(ctx, "path", request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("0123456789abcdef")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestUnlimitedRetriesWithin(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &client{}
+
+				// ACT
+				err := UnlimitedRetriesWithin(time.Minute)(client)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, *client.retryWithin).Equals(time.Minute)
+			},
+		},
+		{scenario: "replaced by MaxRetries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &client{}
+				_ = UnlimitedRetriesWithin(time.Minute)(client)
+
+				// ACT
+				err := MaxRetries(1)(client)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, client.retryWithin == nil, "unlimited retries removed")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
 func TestClientOptions(t *testing.T) {
 	// ARRANGE
 	testcases := []struct {
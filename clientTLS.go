@@ -0,0 +1,71 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ClientCertificate configures a client certificate, presented by the client
+// to servers requiring mutual TLS (mTLS).  The certificate and private key are
+// loaded from a pair of PEM encoded files when the option is applied; if the
+// files cannot be loaded, NewClient returns an error.
+//
+// The certificate is added to any certificates in the TLS configuration of the
+// client; a TLSConfig option specified after this option replaces it.
+//
+// This is a transport option; see ConfigureTransport for details.
+func ClientCertificate(certFile, keyFile string) ClientOption {
+	return func(c *client) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("http: ClientCertificate option: %w", err)
+		}
+		return ConfigureTransport(func(t *http.Transport) {
+			cfg := tlsClientConfig(t)
+			cfg.Certificates = append(cfg.Certificates, cert)
+		})(c)
+	}
+}
+
+// RootCAs configures the certificate authorities trusted by the client to
+// verify the certificates of servers, replacing the system roots.  The
+// certificates are parsed from PEM encoded bytes (e.g. the content of a CA
+// bundle file); if no certificates are found, NewClient returns an error.
+//
+// A TLSConfig option specified after this option replaces the root CAs.
+//
+// This is a transport option; see ConfigureTransport for details.
+func RootCAs(pem []byte) ClientOption {
+	return func(c *client) error {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("http: RootCAs option: no certificates found")
+		}
+		return RootCAPool(pool)(c)
+	}
+}
+
+// RootCAPool configures the certificate authorities trusted by the client to
+// verify the certificates of servers, replacing the system roots.  A nil pool
+// restores the use of the system roots.
+//
+// A TLSConfig option specified after this option replaces the root CAs.
+//
+// This is a transport option; see ConfigureTransport for details.
+func RootCAPool(pool *x509.CertPool) ClientOption {
+	return ConfigureTransport(func(t *http.Transport) {
+		tlsClientConfig(t).RootCAs = pool
+	})
+}
+
+// tlsClientConfig returns the TLS configuration of a transport, initialising
+// an empty configuration if the transport has none
+func tlsClientConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestClientTLS(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// writeClientCertificate writes a self-signed client certificate and
+	// private key to files in a temporary directory, returning the paths of
+	// the files and the certificate
+	writeClientCertificate := func(t *testing.T) (string, string, *x509.Certificate) {
+		t.Helper()
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "client"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, _ := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		cert, _ := x509.ParseCertificate(der)
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+
+		dir := t.TempDir()
+		certFile := filepath.Join(dir, "client.crt")
+		keyFile := filepath.Join(dir, "client.key")
+		_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+		_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+		return certFile, keyFile, cert
+	}
+
+	// mtlsServer returns a TLS server requiring a client certificate signed
+	// by a specified certificate, responding with the common name of the
+	// client certificate presented
+	mtlsServer := func(client *x509.Certificate) *httptest.Server {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}))
+		srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+		pool := x509.NewCertPool()
+		pool.AddCert(client)
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		srv.StartTLS()
+		return srv
+	}
+
+	// serverCA returns the PEM encoded certificate of a TLS server
+	serverCA := func(srv *httptest.Server) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "mutual tls",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				certFile, keyFile, cert := writeClientCertificate(t)
+				srv := mtlsServer(cert)
+				defer srv.Close()
+
+				c, err := NewClient("name",
+					URL(srv.URL),
+					ClientCertificate(certFile, keyFile),
+					RootCAs(serverCA(srv)),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				r, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("client")
+			},
+		},
+		{scenario: "no client certificate",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_, _, cert := writeClientCertificate(t)
+				srv := mtlsServer(cert)
+				defer srv.Close()
+
+				c, _ := NewClient("name", URL(srv.URL), RootCAs(serverCA(srv)))
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				var opErr *net.OpError
+				test.IsTrue(t, errors.As(err, &opErr) && opErr.Op == "remote error", "is a remote (tls) error")
+			},
+		},
+		{scenario: "untrusted server",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				certFile, keyFile, cert := writeClientCertificate(t)
+				srv := mtlsServer(cert)
+				defer srv.Close()
+
+				c, _ := NewClient("name", URL(srv.URL), ClientCertificate(certFile, keyFile))
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				var unknown x509.UnknownAuthorityError
+				test.IsTrue(t, errors.As(err, &unknown))
+			},
+		},
+		{scenario: "ClientCertificate/files not found",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("name", ClientCertificate("missing.crt", "missing.key"))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "RootCAs/no certificates",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("name", RootCAs([]byte("not a certificate")))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+			},
+		},
+		{scenario: "RootCAPool",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				pool := x509.NewCertPool()
+
+				// ACT
+				c, err := NewClient("name", RootCAPool(pool))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				tr := c.(client).wrapped.(*http.Client).Transport.(stripOptionHeaders).next.(*http.Transport)
+				test.IsTrue(t, tr.TLSClientConfig.RootCAs == pool)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
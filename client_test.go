@@ -28,9 +28,13 @@ func TestNewClient(t *testing.T) {
 
 				// ASSERT
 				test.That(t, err).IsNil()
-				test.That(t, result).Equals(client{
+				got := result.(client)
+				test.IsTrue(t, got.chain != nil, "builds the doer chain")
+				got.chain = nil
+				test.That(t, got).Equals(client{
 					name:    "name",
-					wrapped: http.DefaultClient,
+					wrapped: withStrippedOptionHeaders(http.DefaultClient),
+					stats:   &clientStats{},
 				})
 			},
 		},
@@ -120,6 +124,21 @@ func TestNewRequest(t *testing.T) {
 				test.That(t, rq).Equals(want)
 			},
 		},
+		{scenario: "config of context is not inherited",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{url: "http://hostname:80"}
+				parent, _ := c.NewRequest(ctx, http.MethodGet, "parent", request.AcceptStatus(http.StatusNotFound))
+
+				// ACT
+				rq, err := c.NewRequest(parent.Context(), http.MethodGet, "child", request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := request.ConfigFromContext(rq.Context())
+				test.That(t, cfg).Equals(request.Config{StreamResponse: true})
+			},
+		},
 		{scenario: "QueryP execution order",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -321,6 +340,29 @@ func TestDo(t *testing.T) {
 				test.That(t, len(fake.requests)).Equals(0)
 			},
 		},
+		{scenario: "retries/multiple invalid request headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				rq.Header[request.MaxRetriesHeader] = []string{"invalid"}
+				rq.Header[request.AcceptStatusHeader] = []string{"[404"}
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				var opterr InvalidOptionsError
+				test.IsTrue(t, errors.As(err, &opterr), "is an InvalidOptionsError")
+				test.That(t, len(opterr.Options)).Equals(2)
+				test.That(t, opterr.Options[0].Header).Equals(request.MaxRetriesHeader)
+				test.That(t, opterr.Options[0].Value).Equals("invalid")
+				test.That(t, opterr.Options[1].Header).Equals(request.AcceptStatusHeader)
+				test.That(t, opterr.Options[1].Value).Equals("[404")
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
 		{
 			scenario: "acceptable status",
 			exec: func(t *testing.T) {
@@ -428,6 +470,186 @@ func TestDo(t *testing.T) {
 				test.IsTrue(t, r.Body != http.NoBody)
 			},
 		},
+		{scenario: "stream response/option overrides header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{body: []byte("non-empty")}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				rq.Header[request.StreamResponseHeader] = []string{"false"}
+				_ = request.StreamResponse()(rq)
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.ContentLength).Equals(-1)
+			},
+		},
+		{scenario: "request config/max retries overrides header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				permerr := errors.New("permanent failure")
+				fake := &fakeClient{error: permerr}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				rq.Header[request.MaxRetriesHeader] = []string{"3"}
+				_ = request.MaxRetries(1)(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(permerr)
+				test.That(t, len(fake.requests)).Equals(2)
+			},
+		},
+		{scenario: "request config/acceptable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{statusCode: http.StatusNotFound}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				_ = request.AcceptStatus(http.StatusNotFound)(rq)
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusNotFound)
+			},
+		},
+		{scenario: "request config/response body required",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{body: []byte{}}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				_ = request.ResponseBodyRequired()(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrNoResponseBody)
+			},
+		},
+		{scenario: "request config/accept status range",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{wrapped: &fakeClient{statusCode: http.StatusAccepted}}
+				rq, _ := http.NewRequest("", "", nil)
+				_ = request.AcceptStatusRange(200, 299)(rq)
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusAccepted)
+			},
+		},
+		{scenario: "request config/accept status func",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{wrapped: &fakeClient{statusCode: http.StatusNotFound}}
+				rq, _ := http.NewRequest("", "", nil)
+				_ = request.AcceptStatusFunc(func(sc int) bool { return sc == http.StatusConflict })(rq)
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+			},
+		},
+		{scenario: "request config/stream response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{body: []byte("non-empty")}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest("", "", nil)
+				_ = request.StreamResponse()(rq)
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.ContentLength).Equals(-1)
+				test.That(t, len(fake.requests[0].Header)).Equals(0)
+			},
+		},
+		{scenario: "request config/stream response/cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				fake := &fakeClient{body: []byte("non-empty")}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequestWithContext(ctx, "", "", nil)
+				_ = request.StreamResponse()(rq)
+				r, _ := c.Do(rq)
+
+				// ACT
+				cancel()
+				_, err := io.ReadAll(r.Body)
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestDoWith(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "options applied",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{statusCode: http.StatusNotFound}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+
+				// ACT
+				r, err := c.DoWith(rq,
+					request.Header("X-Header", "value"),
+					request.AcceptStatus(http.StatusNotFound),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusNotFound)
+				test.That(t, fake.requests[0].Header.Get("X-Header")).Equals("value")
+			},
+		},
+		{scenario: "option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				fake := &fakeClient{}
+				c := client{wrapped: fake}
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+
+				// ACT
+				r, err := c.DoWith(rq, func(*http.Request) error { return opterr })
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+				test.That(t, r).IsNil()
+				test.That(t, len(fake.requests)).Equals(0)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
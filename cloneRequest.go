@@ -0,0 +1,45 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// CloneRequest returns a deep copy of a supplied request with any specified
+// request options applied to the copy.  The supplied request is not modified.
+//
+// If the request has a body, the body of the copy is obtained from the GetBody
+// function of the request.  GetBody is set by the body request options provided
+// by this module (and by http.NewRequest for common body types); if a request
+// has a body but no GetBody function, ErrCannotCloneBody is returned.
+//
+// The copy has the configuration established by any request options applied
+// to the supplied request; options applied to the copy do not affect the
+// configuration of the supplied request.  Cleanup functions registered for the
+// supplied request (see: request.Cleanup) are not registered for the copy.
+//
+// As for NewRequest, an error wrapping ErrConflictingOptions is returned if
+// any options conflict.
+//
+// This enables a template request to be safely submitted multiple times, e.g.
+// to different hosts or when retrying a request in the calling code.
+func CloneRequest(rq *http.Request, opts ...RequestOption) (*http.Request, error) {
+	cpy := rq.Clone(rq.Context())
+
+	if rq.Body != nil && rq.Body != http.NoBody {
+		if rq.GetBody == nil {
+			return nil, fmt.Errorf("CloneRequest: %w", ErrCannotCloneBody)
+		}
+		body, err := rq.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("CloneRequest: GetBody: %w", err)
+		}
+		cpy.Body = body
+	}
+
+	if err := applyOptions(cpy, opts); err != nil {
+		return nil, fmt.Errorf("CloneRequest: %w", err)
+	}
+
+	return cpy, nil
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestCloneRequest(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				rq.Header.Set("X-Header", "original")
+
+				// ACT
+				result, err := CloneRequest(rq, request.Header("X-Header", "clone"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.URL.String()).Equals("http://hostname/path")
+				test.That(t, result.Header.Get("X-Header")).Equals("clone")
+				test.That(t, rq.Header.Get("X-Header")).Equals("original")
+			},
+		},
+		{scenario: "body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname/path", nil)
+				_ = request.Body([]byte("body"))(rq)
+
+				// ACT
+				result, err := CloneRequest(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				original, _ := io.ReadAll(rq.Body)
+				clone, _ := io.ReadAll(result.Body)
+				test.Bytes(t, original).Equals([]byte("body"))
+				test.Bytes(t, clone).Equals([]byte("body"))
+			},
+		},
+		{scenario: "body without GetBody",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname/path", nil)
+				rq.Body = io.NopCloser(bytes.NewReader([]byte("body")))
+
+				// ACT
+				result, err := CloneRequest(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrCannotCloneBody)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "GetBody error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				bodyerr := errors.New("body error")
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname/path", nil)
+				rq.Body = io.NopCloser(bytes.NewReader([]byte("body")))
+				rq.GetBody = func() (io.ReadCloser, error) { return nil, bodyerr }
+
+				// ACT
+				result, err := CloneRequest(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(bodyerr)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+
+				// ACT
+				result, err := CloneRequest(rq, func(*http.Request) error { return opterr })
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "conflicting options",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname/path", nil)
+
+				// ACT
+				result, err := CloneRequest(rq, request.Body([]byte("a")), request.Body([]byte("b")))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrConflictingOptions)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "config is copied",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				_ = request.AcceptStatus(http.StatusNotFound)(rq)
+
+				// ACT
+				result, err := CloneRequest(rq, request.AcceptStatus(http.StatusConflict))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := request.ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotFound})
+				cfg, _ = request.ConfigFromContext(result.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotFound, http.StatusConflict})
+			},
+		},
+		{scenario: "cleanup functions are not copied",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cleaned := []string{}
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				_ = request.Cleanup(func() { cleaned = append(cleaned, "original") })(rq)
+				c := client{wrapped: &fakeClient{}}
+
+				// ACT
+				result, err := CloneRequest(rq, request.Cleanup(func() { cleaned = append(cleaned, "clone") }))
+				_, _ = c.Do(result)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, cleaned).Equals([]string{"clone"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Command openapi-gen generates a typed client from a JSON encoded OpenAPI
+// 3.x specification, using the openapi package of the github.com/blugnu/http
+// module.
+//
+// Usage:
+//
+//	openapi-gen -spec <file> -package <name> [-type <name>] [-out <file>]
+//
+// If -out is not specified the generated code is written to stdout.
+//
+// The command is typically invoked from a go:generate directive:
+//
+//	//go:generate go run github.com/blugnu/http/cmd/openapi-gen -spec api.json -package users -out client.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/blugnu/http/openapi"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the command line arguments and generates the client
+func run(args []string) error {
+	fs := flag.NewFlagSet("openapi-gen", flag.ContinueOnError)
+	specFile := fs.String("spec", "", "the OpenAPI specification (JSON) file (required)")
+	pkg := fs.String("package", "", "the package name for the generated code (required)")
+	typeName := fs.String("type", "Client", "the name of the generated client type")
+	out := fs.String("out", "", "the output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *specFile == "" || *pkg == "" {
+		fs.Usage()
+		return fmt.Errorf("-spec and -package are required")
+	}
+
+	b, err := os.ReadFile(*specFile)
+	if err != nil {
+		return err
+	}
+
+	src, err := openapi.Generate(b, openapi.Config{Package: *pkg, ClientType: *typeName})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestRun(t *testing.T) {
+	// ARRANGE
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(spec, []byte(`{"openapi":"3.0.0","paths":{"/ping":{"get":{"operationId":"ping"}}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "missing arguments",
+			exec: func(t *testing.T) {
+				// ACT
+				err := run([]string{"-spec", spec})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "spec not found",
+			exec: func(t *testing.T) {
+				// ACT
+				err := run([]string{"-spec", filepath.Join(dir, "missing.json"), "-package", "api"})
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "output file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				out := filepath.Join(dir, "client.go")
+
+				// ACT
+				err := run([]string{"-spec", spec, "-package", "api", "-type", "Pinger", "-out", out})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				b, _ := os.ReadFile(out)
+				test.IsTrue(t, strings.Contains(string(b), "func (c *Pinger) Ping("))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"io"
+)
+
+// contextBody wraps the body of a streamed response such that reads fail
+// promptly with the error of the request context once the context is done.
+// When the context is done the wrapped body is closed, releasing the underlying
+// connection and unblocking any read in progress.
+type contextBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	stop func() bool
+}
+
+// newContextBody returns a body wrapping a specified body, closing it when a
+// specified context is done.  If the context cannot be cancelled the body is
+// returned unmodified.
+func newContextBody(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	if ctx.Done() == nil {
+		return body
+	}
+	return &contextBody{
+		ReadCloser: body,
+		ctx:        ctx,
+		stop:       context.AfterFunc(ctx, func() { _ = body.Close() }),
+	}
+}
+
+// Read implements io.Reader, returning the error of the context if the context
+// is done before or during the read
+func (cb *contextBody) Read(p []byte) (int, error) {
+	if err := cb.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cb.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxerr := cb.ctx.Err(); ctxerr != nil {
+			return n, ctxerr
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer, closing the wrapped body and releasing the
+// resources associated with monitoring the context
+func (cb *contextBody) Close() error {
+	cb.stop()
+	return cb.ReadCloser.Close()
+}
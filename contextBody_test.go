@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// closeRecorder wraps a body, recording whether it has been closed; the
+// body may be closed asynchronously, so closed is a channel
+type closeRecorder struct {
+	io.Reader
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newCloseRecorder(s string) *closeRecorder {
+	return &closeRecorder{Reader: strings.NewReader(s), closed: make(chan struct{})}
+}
+
+func (cr *closeRecorder) Close() error {
+	cr.once.Do(func() { close(cr.closed) })
+	return nil
+}
+
+// isClosed returns true if the body is closed within a short time
+func (cr *closeRecorder) isClosed() bool {
+	select {
+	case <-cr.closed:
+		return true
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
+
+func TestContextBody(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "context cannot be cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				body := io.NopCloser(strings.NewReader("body"))
+
+				// ACT
+				result := newContextBody(context.Background(), body)
+
+				// ASSERT
+				test.IsTrue(t, result == body)
+			},
+		},
+		{scenario: "read before cancellation",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				body := newCloseRecorder("body")
+				sut := newContextBody(ctx, body)
+
+				// ACT
+				b, err := io.ReadAll(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(b)).Equals("body")
+				test.IsFalse(t, body.isClosed())
+			},
+		},
+		{scenario: "read after cancellation",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				body := newCloseRecorder("body")
+				sut := newContextBody(ctx, body)
+				cancel()
+
+				// ACT
+				n, err := sut.Read(make([]byte, 4))
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.That(t, n).Equals(0)
+				test.IsTrue(t, body.isClosed())
+			},
+		},
+		{scenario: "read blocked when cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				pr, pw := io.Pipe()
+				defer pw.Close()
+				sut := newContextBody(ctx, pr)
+
+				// ACT
+				_, err := sut.Read(make([]byte, 4))
+
+				// ASSERT
+				test.Error(t, err).Is(context.DeadlineExceeded)
+			},
+		},
+		{scenario: "read error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rderr := errors.New("read error")
+				pr, pw := io.Pipe()
+				_ = pw.CloseWithError(rderr)
+				sut := newContextBody(ctx, pr)
+
+				// ACT
+				_, err := sut.Read(make([]byte, 4))
+
+				// ASSERT
+				test.Error(t, err).Is(rderr)
+			},
+		},
+		{scenario: "close",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				body := newCloseRecorder("body")
+				sut := newContextBody(ctx, body)
+
+				// ACT
+				err := sut.Close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, body.isClosed())
+				test.IsFalse(t, sut.(*contextBody).stop(), "context monitoring stopped")
+				cancel()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import "net/http"
+
+// CookieJar configures a cookie jar for the client, so that session-based
+// APIs may be consumed.  Cookies in the jar are applied to requests made
+// using the client and any cookies set by responses are captured in the jar,
+// across all requests made with the same client.
+//
+// If the wrapped client is an *http.Client, the jar is used by (a copy of)
+// that client, so that cookies are also applied to and captured from any
+// redirects followed; any jar configured on the wrapped client is replaced.
+// Any other wrapped client is wrapped such that cookies are applied to and
+// captured from each request made by the client.
+//
+// A nil jar disables cookie handling by the client.  Cookie jars may be
+// created using net/http/cookiejar.
+func CookieJar(jar http.CookieJar) ClientOption {
+	return func(c *client) error {
+		c.cookies = jar
+		return nil
+	}
+}
+
+// withCookies returns a Doer using a specified cookie jar.  If the Doer is an
+// *http.Client, a copy of the client is returned with its Jar set (if not
+// already set to the same jar).  Otherwise the Doer is wrapped by a
+// cookieDoer.
+func withCookies(d Doer, jar http.CookieJar) Doer {
+	if hc, ok := d.(*http.Client); ok {
+		if hc.Jar == jar {
+			return d
+		}
+		cpy := *hc
+		cpy.Jar = jar
+		return &cpy
+	}
+	return cookieDoer{Doer: d, jar: jar}
+}
+
+// cookieDoer wraps a Doer, applying cookies from a jar to each request and
+// capturing any cookies set by the response
+type cookieDoer struct {
+	Doer
+	jar http.CookieJar
+}
+
+// Do implements Doer.  Cookies from the jar are added to a copy of the
+// request, so that cookies are not accumulated by a request that is retried.
+func (cd cookieDoer) Do(rq *http.Request) (*http.Response, error) {
+	if cookies := cd.jar.Cookies(rq.URL); len(cookies) > 0 {
+		cpy := *rq
+		cpy.Header = rq.Header.Clone()
+		if cpy.Header == nil {
+			cpy.Header = http.Header{}
+		}
+		for _, cookie := range cookies {
+			cpy.AddCookie(cookie)
+		}
+		rq = &cpy
+	}
+
+	r, err := cd.Doer.Do(rq)
+	if r != nil {
+		if cookies := r.Cookies(); len(cookies) > 0 {
+			cd.jar.SetCookies(rq.URL, cookies)
+		}
+	}
+	return r, err
+}
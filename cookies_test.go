@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestCookieJar(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	u, _ := url.Parse("http://example.com")
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				jar, _ := cookiejar.New(nil)
+				c := client{}
+
+				// ACT
+				err := CookieJar(jar)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.cookies).Equals(http.CookieJar(jar))
+			},
+		},
+		{scenario: "wrapped *http.Client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				jar, _ := cookiejar.New(nil)
+				hc := &http.Client{}
+
+				// ACT
+				result := withCookies(hc, jar)
+
+				// ASSERT
+				cpy, ok := result.(*http.Client)
+				test.IsTrue(t, ok, "is *http.Client")
+				test.IsTrue(t, cpy != hc, "is copy")
+				test.That(t, cpy.Jar).Equals(http.CookieJar(jar))
+				test.That(t, hc.Jar).IsNil()
+			},
+		},
+		{scenario: "wrapped *http.Client/same jar",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				jar, _ := cookiejar.New(nil)
+				hc := &http.Client{Jar: jar}
+
+				// ACT
+				result := withCookies(hc, jar)
+
+				// ASSERT
+				test.IsTrue(t, result.(*http.Client) == hc, "is same client")
+			},
+		},
+		{scenario: "other wrapped client/applies and captures cookies",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				jar, _ := cookiejar.New(nil)
+				sent := []string{}
+				c := client{
+					url:     u.String(),
+					cookies: jar,
+					wrapped: DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						sent = append(sent, rq.Header.Get("Cookie"))
+						h := http.Header{}
+						h.Add("Set-Cookie", "session=abc")
+						return &http.Response{StatusCode: http.StatusOK, Header: h, Body: http.NoBody}, nil
+					}),
+				}
+
+				// ACT
+				_, err1 := c.Get(ctx, "login")
+				_, err2 := c.Get(ctx, "data", request.Cookie("lang", "en"))
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Strings(t, sent).Equals([]string{"", "lang=en; session=abc"})
+				test.That(t, len(jar.Cookies(u))).Equals(1)
+			},
+		},
+		{scenario: "other wrapped client/retried request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				jar, _ := cookiejar.New(nil)
+				jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "abc"}})
+				sent := []string{}
+				c := client{
+					url:        u.String(),
+					cookies:    jar,
+					maxRetries: 1,
+					wrapped: DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						sent = append(sent, rq.Header.Get("Cookie"))
+						return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+					}),
+				}
+
+				// ACT
+				_, _ = c.Get(ctx, "data", request.Backoff(func(uint) time.Duration { return 0 }))
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"session=abc", "session=abc"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// DecodeOption is a function that applies an option to the decoding of a
+// response body
+type DecodeOption func(*decodeOptions)
+
+// decodeOptions holds the configuration for decoding a response body
+type decodeOptions struct {
+	maxSize int64
+}
+
+// MaxDecodeSize sets the maximum size (in bytes) of a response body that will
+// be decoded.  If the body exceeds this size, decoding is abandoned and an
+// error wrapping ErrResponseBodyTooLarge is returned.
+//
+// A size of zero (the default) or less imposes no limit.
+func MaxDecodeSize(n int64) DecodeOption {
+	return func(cfg *decodeOptions) {
+		cfg.maxSize = n
+	}
+}
+
+// decodeReader returns a reader for a response body, applying any limit on
+// the size of the body configured by the options specified.
+func decodeReader(r *http.Response, opts ...DecodeOption) io.Reader {
+	cfg := &decodeOptions{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.maxSize <= 0 {
+		return r.Body
+	}
+	return &limitReader{r: r.Body, remaining: cfg.maxSize}
+}
+
+// limitReader is an io.Reader that returns ErrResponseBodyTooLarge if more
+// than a specified number of bytes are available from a wrapped reader.
+//
+// Unlike io.LimitReader, which silently truncates, this enables a body that
+// exceeds the limit to be distinguished from a body that does not.
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Read implements io.Reader.  At most one byte more than the remaining limit
+// is read from the wrapped reader; if that byte is read, the limit has been
+// exceeded.
+func (lr *limitReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+	n, err := lr.r.Read(p)
+	if int64(n) > lr.remaining {
+		n = int(lr.remaining)
+		lr.remaining = 0
+		return n, ErrResponseBodyTooLarge
+	}
+	lr.remaining -= int64(n)
+	return n, err
+}
+
+// DecodeEach is a generic function that decodes a response body containing
+// a JSON array, calling a supplied function for each element in the array
+// as it is decoded.
+//
+// The body is decoded as a stream of tokens; only one element is held in
+// memory at any time, making this suitable for very large arrays.  Any
+// DecodeOption may be specified, e.g. to limit the size of the body.
+//
+// If the function returns an error, decoding is abandoned and the error
+// returned.  The response body is always closed; if decoding is abandoned, any
+// (reasonably small) remainder of the body is first drained so that the
+// connection may be reused.
+func DecodeEach[T any](
+	ctx context.Context,
+	r *http.Response,
+	fn func(T) error,
+	opts ...DecodeOption,
+) error {
+	defer func() { _ = closeBody(ctx, r.Body) }()
+
+	if err := decodeArray(json.NewDecoder(decodeReader(r, opts...)), fn); err != nil {
+		return errorcontext.Errorf(ctx, "http.DecodeEach: %w", err)
+	}
+	return nil
+}
+
+// DecodeJSONStream is a generic function that decodes a response body
+// containing either a JSON array or newline-delimited JSON (NDJSON, also known
+// as JSON Lines), calling a supplied function for each element of the array or
+// each value in the stream as it is decoded.  The format is determined by the
+// body: if the first non-whitespace character is '[' the body is decoded as an
+// array; otherwise as a stream of values (an empty body is an empty stream).
+//
+// Only one value is held in memory at any time, so this is suitable for very
+// large or long-lived responses, typically performed using the
+// request.StreamResponse option.  Any DecodeOption may be specified, e.g. to
+// limit the size of the body.
+//
+// If the function returns an error, decoding is abandoned and the error
+// returned.  The response body is always closed, as for DecodeEach.
+func DecodeJSONStream[T any](
+	ctx context.Context,
+	r *http.Response,
+	fn func(T) error,
+	opts ...DecodeOption,
+) error {
+	defer func() { _ = closeBody(ctx, r.Body) }()
+
+	handle := func(err error) error {
+		return errorcontext.Errorf(ctx, "http.DecodeJSONStream: %w", err)
+	}
+
+	br := bufio.NewReader(decodeReader(r, opts...))
+	array, err := isJSONArray(br)
+	if err != nil {
+		return handle(fmt.Errorf("%w: %w", ErrReadingResponseBody, err))
+	}
+
+	dec := json.NewDecoder(br)
+	if array {
+		if err := decodeArray(dec, fn); err != nil {
+			return handle(err)
+		}
+		return nil
+	}
+
+	for {
+		v := *new(T)
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return handle(jsonError(err))
+		}
+		if err := fn(v); err != nil {
+			return handle(err)
+		}
+	}
+}
+
+// isJSONArray returns true if the first non-whitespace byte read from a
+// reader opens a JSON array.  The byte is not consumed.  If the reader is
+// empty (or contains only whitespace) false is returned, with no error.
+func isJSONArray(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b == '[', br.UnreadByte()
+	}
+}
+
+// decodeArray decodes a JSON array using a decoder, calling a supplied
+// function for each element in the array as it is decoded.  Any error
+// returned by the function is returned unwrapped.
+func decodeArray[T any](dec *json.Decoder, fn func(T) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonError(err)
+	}
+	if tok != json.Delim('[') {
+		return jsonError(fmt.Errorf("expected an array, got %v", tok))
+	}
+
+	for dec.More() {
+		v := *new(T)
+		if err := dec.Decode(&v); err != nil {
+			return jsonError(err)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return jsonError(err)
+	}
+
+	return nil
+}
+
+// jsonError wraps an error arising from decoding a JSON body with
+// ErrInvalidJSON or, if the body exceeded a configured limit,
+// ErrReadingResponseBody.
+func jsonError(err error) error {
+	sen := ErrInvalidJSON
+	if errors.Is(err, ErrResponseBodyTooLarge) {
+		sen = ErrReadingResponseBody
+	}
+	return fmt.Errorf("%w: %w", sen, err)
+}
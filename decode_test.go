@@ -0,0 +1,305 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"testing/iotest"
+
+	"github.com/blugnu/test"
+)
+
+func TestMaxDecodeSize(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	response := func(s string) *http.Response {
+		return &http.Response{Body: io.NopCloser(bytes.NewReader([]byte(s)))}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "UnmarshalJSON/within limit",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := UnmarshalJSON[map[string]string](ctx, response(`{"key":"value"}`), MaxDecodeSize(15))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(map[string]string{"key": "value"})
+			},
+		},
+		{scenario: "UnmarshalJSON/exceeds limit",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := UnmarshalJSON[map[string]string](ctx, response(`{"key":"value"}`), MaxDecodeSize(14))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "UnmarshalJSON/no limit",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := UnmarshalJSON[map[string]string](ctx, response(`{"key":"value"}`), MaxDecodeSize(0))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(map[string]string{"key": "value"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestDecodeEach(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	response := func(s string) *http.Response {
+		return &http.Response{Body: io.NopCloser(bytes.NewReader([]byte(s)))}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "array",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				result := []int{}
+
+				// ACT
+				err := DecodeEach(ctx, response(`[1, 2, 3]`), func(v int) error {
+					result = append(result, v)
+					return nil
+				})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals([]int{1, 2, 3})
+			},
+		},
+		{scenario: "empty array",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := 0
+
+				// ACT
+				err := DecodeEach(ctx, response(`[]`), func(v int) error { calls++; return nil })
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, calls).Equals(0)
+			},
+		},
+		{scenario: "not an array",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DecodeEach(ctx, response(`{"key":"value"}`), func(v int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "empty body",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DecodeEach(ctx, response(``), func(v int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "invalid element",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DecodeEach(ctx, response(`[1, "two"]`), func(v int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "unterminated array",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DecodeEach(ctx, response(`[1, 2`), func(v int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "function error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fnerr := errors.New("function error")
+
+				// ACT
+				err := DecodeEach(ctx, response(`[1, 2]`), func(v int) error { return fnerr })
+
+				// ASSERT
+				test.Error(t, err).Is(fnerr)
+			},
+		},
+		{scenario: "exceeds limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				result := []int{}
+
+				// ACT
+				err := DecodeEach(ctx, response(`[1, 2, 3]`), func(v int) error {
+					result = append(result, v)
+					return nil
+				}, MaxDecodeSize(5))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+				test.Error(t, err).Is(ErrReadingResponseBody)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestDecodeJSONStream(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	response := func(s string) *http.Response {
+		return &http.Response{Body: io.NopCloser(bytes.NewReader([]byte(s)))}
+	}
+
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	// decode decodes a response body, returning the items decoded
+	decode := func(body string, opts ...DecodeOption) ([]item, error) {
+		result := []item{}
+		err := DecodeJSONStream(ctx, response(body), func(v item) error {
+			result = append(result, v)
+			return nil
+		}, opts...)
+		return result, err
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ndjson",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result).Equals([]item{{ID: 1}, {ID: 2}, {ID: 3}})
+			},
+		},
+		{scenario: "ndjson/no trailing newline",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode("{\"id\":1}\r\n{\"id\":2}")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result).Equals([]item{{ID: 1}, {ID: 2}})
+			},
+		},
+		{scenario: "array",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode("\n  [{\"id\":1}, {\"id\":2}]")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result).Equals([]item{{ID: 1}, {ID: 2}})
+			},
+		},
+		{scenario: "empty body",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode(" \n")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(0)
+			},
+		},
+		{scenario: "ndjson/invalid value",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode("{\"id\":1}\n{\"id\":\"two\"}\n")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.Slice(t, result).Equals([]item{{ID: 1}})
+			},
+		},
+		{scenario: "array/invalid element",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := decode(`[{"id":1}, {"id":"two"}]`)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "function error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fnerr := errors.New("function error")
+				calls := 0
+
+				// ACT
+				err := DecodeJSONStream(ctx, response("1\n2\n"), func(int) error { calls++; return fnerr })
+
+				// ASSERT
+				test.Error(t, err).Is(fnerr)
+				test.That(t, calls).Equals(1)
+			},
+		},
+		{scenario: "read error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				readerr := errors.New("read error")
+				r := &http.Response{Body: io.NopCloser(iotest.ErrReader(readerr))}
+
+				// ACT
+				err := DecodeJSONStream(ctx, r, func(int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(readerr)
+			},
+		},
+		{scenario: "exceeds limit",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := decode("{\"id\":1}\n{\"id\":2}\n", MaxDecodeSize(12))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Slice(t, result).Equals([]item{{ID: 1}})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Warning is a warning conveyed by a Warning header of a response (RFC 7234).
+type Warning struct {
+	// Code is the warning code, e.g. 299 (Miscellaneous Persistent Warning)
+	Code int
+
+	// Agent identifies the server adding the warning ("-" if unknown)
+	Agent string
+
+	// Text is the warning text
+	Text string
+
+	// Date is the date of the warning; this will be the zero time if the
+	// warning has no (valid) date
+	Date time.Time
+}
+
+// DeprecationNotice describes the deprecation of a resource and any warnings
+// conveyed by the Deprecation, Sunset and Warning headers of a response.
+type DeprecationNotice struct {
+	// URL is the (redacted) url of the request that received the response
+	URL string
+
+	// Deprecated is true if the response has a Deprecation header
+	Deprecated bool
+
+	// Deprecation is the date at which the resource is (or was) deprecated;
+	// this will be the zero time if the Deprecation header does not specify
+	// a date (e.g. "Deprecation: true")
+	Deprecation time.Time
+
+	// Sunset is the date at which the resource is expected to become
+	// unresponsive (RFC 8594); this will be the zero time if the response
+	// has no (valid) Sunset header
+	Sunset time.Time
+
+	// Links holds the urls of any Link headers of the response with a
+	// relation of "deprecation" or "sunset", keyed by relation
+	Links map[string]string
+
+	// Warnings holds any warnings conveyed by Warning headers
+	Warnings []Warning
+}
+
+// OnDeprecation configures a function to be called whenever a client receives
+// a response with any Deprecation, Sunset or Warning headers, enabling teams to
+// learn of upcoming removals of upstream APIs from their own telemetry (e.g. by
+// logging the notice or incrementing a metric).
+//
+// The function is called with the context of the request, on the goroutine
+// performing the request, and should return promptly.
+func OnDeprecation(fn func(ctx context.Context, n DeprecationNotice)) ClientOption {
+	return func(c *client) error {
+		c.onDeprecation = fn
+		return nil
+	}
+}
+
+// ParseDeprecation parses the Deprecation, Sunset, Warning and Link headers of
+// a response.  If the response has no Deprecation, Sunset or Warning headers,
+// false is returned.
+func ParseDeprecation(r *http.Response) (DeprecationNotice, bool) {
+	if r == nil {
+		return DeprecationNotice{}, false
+	}
+
+	dep, hasDep := r.Header["Deprecation"]
+	sunset, hasSunset := r.Header["Sunset"]
+	warnings, hasWarnings := r.Header["Warning"]
+	if !hasDep && !hasSunset && !hasWarnings {
+		return DeprecationNotice{}, false
+	}
+
+	n := DeprecationNotice{Deprecated: hasDep}
+	if r.Request != nil && r.Request.URL != nil {
+		n.URL = r.Request.URL.Redacted()
+	}
+	if hasDep {
+		n.Deprecation = parseDeprecationDate(dep[0])
+	}
+	if hasSunset {
+		n.Sunset, _ = http.ParseTime(strings.TrimSpace(sunset[0]))
+	}
+	for _, h := range warnings {
+		n.Warnings = append(n.Warnings, parseWarnings(h)...)
+	}
+	links := ParseLinkHeader(r.Header.Values("Link")...)
+	for _, rel := range []string{"deprecation", "sunset"} {
+		if url, ok := links.Href(rel); ok {
+			if n.Links == nil {
+				n.Links = map[string]string{}
+			}
+			n.Links[rel] = url
+		}
+	}
+	return n, true
+}
+
+// parseDeprecationDate parses the value of a Deprecation header, which may be a
+// structured field date ("@1688169599", RFC 9745), an http date or "true"
+// (earlier drafts).  If the value does not specify a date, the zero time is
+// returned.
+func parseDeprecationDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if secs, ok := strings.CutPrefix(s, "@"); ok {
+		if n, err := strconv.ParseInt(secs, 10, 64); err == nil {
+			return time.Unix(n, 0).UTC()
+		}
+		return time.Time{}
+	}
+	t, _ := http.ParseTime(s)
+	return t
+}
+
+// parseWarnings parses the value of a Warning header, which may hold a
+// comma-separated list of warnings of the form: code agent "text" ["date"].
+// Any invalid warning is ignored.
+func parseWarnings(s string) []Warning {
+	result := []Warning{}
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, ", ") {
+		var w Warning
+		var ok bool
+
+		code, rest, _ := strings.Cut(s, " ")
+		agent, rest, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if w.Code, ok = parseWarnCode(code); !ok {
+			return result
+		}
+		w.Agent = agent
+
+		if w.Text, s, ok = cutQuoted(strings.TrimSpace(rest)); !ok {
+			return result
+		}
+		if date, rest, ok := cutQuoted(strings.TrimSpace(s)); ok {
+			w.Date, _ = http.ParseTime(date)
+			s = rest
+		}
+		result = append(result, w)
+	}
+	return result
+}
+
+// parseWarnCode parses a three digit warning code
+func parseWarnCode(s string) (int, bool) {
+	if len(s) != 3 {
+		return 0, false
+	}
+	code, err := strconv.Atoi(s)
+	return code, err == nil
+}
+
+// cutQuoted cuts a quoted string (with backslash escapes) from the start of a
+// string, returning the unquoted content and the remainder of the string.
+func cutQuoted(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", s, false
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i < len(s) {
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			return sb.String(), s[i+1:], true
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", s, false
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// defaultConcurrency is the maximum number of requests performed concurrently
+// by DoAll if no Concurrency option is specified
+const defaultConcurrency = 8
+
+// RequestSpec describes a request to be performed by DoAll.
+type RequestSpec struct {
+	// Method is the http method of the request
+	Method string
+
+	// Path is appended to the url of the client performing the request
+	Path string
+
+	// Options are applied to the request when it is constructed
+	Options []RequestOption
+}
+
+// Result holds the outcome of a request performed by DoAll (or of any request,
+// see: ResultOf), with methods for consuming the body of the response.
+type Result struct {
+	// Response is the response received, if any; a response may be
+	// returned together with an error (e.g. ErrUnexpectedStatusCode)
+	Response *http.Response
+
+	// Err is any error that occurred performing the request
+	Err error
+}
+
+// DoAllOption is a function that applies an option to a DoAll operation
+type DoAllOption func(*doAllOptions)
+
+// doAllOptions holds the configuration of a DoAll operation
+type doAllOptions struct {
+	concurrency int
+	failFast    bool
+}
+
+// Concurrency sets the maximum number of requests that DoAll will perform
+// concurrently.  Values less than 1 are treated as 1 (i.e. requests are
+// performed sequentially).
+func Concurrency(n int) DoAllOption {
+	return func(cfg *doAllOptions) {
+		cfg.concurrency = max(n, 1)
+	}
+}
+
+// FailFast configures DoAll to cancel any requests not yet completed as soon
+// as any request returns an error.  Requests that are cancelled will have a
+// Result with an Err wrapping context.Canceled.
+//
+// By default, all requests are performed and the results of each collected,
+// regardless of any errors.
+func FailFast() DoAllOption {
+	return func(cfg *doAllOptions) {
+		cfg.failFast = true
+	}
+}
+
+// DoAll performs a batch of requests concurrently using a specified client,
+// returning the results in the same order as the supplied request specs.
+//
+// At most 8 requests are performed concurrently unless a different limit is
+// specified using the Concurrency option.
+//
+// If the context is cancelled, any requests not yet started are not performed;
+// the Result for each such request will have an Err wrapping the context error.
+// Each request is performed with a context derived from the specified context,
+// which is not cancelled when DoAll returns, so the body of a streamed response
+// (see: request.StreamResponse) remains readable until the specified context
+// is done.  The context of a request is released once the body of the response
+// has been read to completion or closed, so the body of every response should
+// be consumed or closed.
+//
+// The returned error joins the errors (if any) of all requests, each identifying
+// the (zero-based) index of the request in the batch; the individual error for
+// each request is also available in the corresponding Result.
+func DoAll(
+	ctx context.Context,
+	c HttpClient,
+	rqs []RequestSpec,
+	opts ...DoAllOption,
+) ([]Result, error) {
+	cfg := &doAllOptions{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	batch, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(rqs))
+	sem := make(chan struct{}, cfg.concurrency)
+	wg := sync.WaitGroup{}
+
+	perform := func(ix int, spec RequestSpec) {
+		defer wg.Done()
+		defer func() { <-sem }()
+
+		if err := batch.Err(); err != nil {
+			results[ix] = Result{Err: err}
+			return
+		}
+
+		rqctx, detach, release := detachableContext(ctx, batch)
+		r, err := func() (*http.Response, error) {
+			rq, err := c.NewRequest(rqctx, spec.Method, spec.Path, spec.Options...)
+			if err != nil {
+				return nil, err
+			}
+			return c.Do(rq)
+		}()
+		detach()
+		releaseWithBody(r, release)
+		results[ix] = Result{Response: r, Err: err}
+
+		if err != nil && cfg.failFast {
+			cancel()
+		}
+	}
+
+loop:
+	for ix, spec := range rqs {
+		select {
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go perform(ix, spec)
+
+		case <-batch.Done():
+			for ; ix < len(rqs); ix++ {
+				results[ix] = Result{Err: batch.Err()}
+			}
+			break loop
+		}
+	}
+	wg.Wait()
+
+	errs := []error{}
+	for ix, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", ix, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// detachableContext returns a context derived from a specified context that
+// is also cancelled if a batch context is cancelled, until detached by calling
+// the first of the returned functions.  Once detached, the context is done only
+// when the specified context is done or it is released by calling the second
+// of the returned functions, so that a response obtained using the context
+// outlives the batch.
+func detachableContext(ctx, batch context.Context) (context.Context, func() bool, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, context.AfterFunc(batch, cancel), cancel
+}
+
+// releaseWithBody arranges for a function releasing the context of a request
+// to be called once the body of a response to the request has been read to
+// completion or closed.  If there is no response (or no body) the function is
+// called immediately.
+func releaseWithBody(r *http.Response, release func()) {
+	if r == nil || r.Body == nil || r.Body == http.NoBody {
+		release()
+		return
+	}
+	r.Body = &releasingBody{ReadCloser: r.Body, release: sync.OnceFunc(release)}
+}
+
+// releasingBody is a response body that calls a function when the body has
+// been read to completion (or a read fails) or is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+
+	// err is the error returned by the first read of the wrapped body to fail
+	// (including io.EOF); once set, the body is released and any further read
+	// returns the same error without reading the wrapped body
+	err error
+}
+
+// Read implements io.Reader, calling the release function of the body if the
+// read returns any error (including io.EOF).  Since releasing the body may
+// cause any further read of the wrapped body to fail (e.g. a streamed body is
+// read using the released context), any further read returns the same error.
+func (rb *releasingBody) Read(p []byte) (int, error) {
+	if rb.err != nil {
+		return 0, rb.err
+	}
+	n, err := rb.ReadCloser.Read(p)
+	if err != nil {
+		rb.err = err
+		rb.release()
+	}
+	return n, err
+}
+
+// Close implements io.Closer, closing the wrapped body and then calling the
+// release function of the body
+func (rb *releasingBody) Close() error {
+	defer rb.release()
+	return rb.ReadCloser.Close()
+}
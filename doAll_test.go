@@ -0,0 +1,279 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+// concurrentClient is a goroutine-safe fake client that responds to each
+// request with a status code and body derived from the request path (the
+// body is the path itself unless otherwise configured), recording the
+// maximum number of requests in-flight at any one time
+type concurrentClient struct {
+	delay       time.Duration
+	inflight    atomic.Int32
+	maxInflight atomic.Int32
+	requests    atomic.Int32
+	status      map[string]int
+	bodies      map[string]string
+}
+
+func (fake *concurrentClient) Do(rq *http.Request) (*http.Response, error) {
+	fake.requests.Add(1)
+	n := fake.inflight.Add(1)
+	defer fake.inflight.Add(-1)
+	for {
+		mx := fake.maxInflight.Load()
+		if n <= mx || fake.maxInflight.CompareAndSwap(mx, n) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(fake.delay):
+	case <-rq.Context().Done():
+		return nil, rq.Context().Err()
+	}
+
+	rec := httptest.NewRecorder()
+	if sc, ok := fake.status[rq.URL.Path]; ok {
+		rec.WriteHeader(sc)
+	}
+	body, ok := fake.bodies[rq.URL.Path]
+	if !ok {
+		body = rq.URL.Path
+	}
+	_, _ = rec.Write([]byte(body))
+	return rec.Result(), nil
+}
+
+func TestDoAll(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	specs := func(paths ...string) []RequestSpec {
+		result := make([]RequestSpec, len(paths))
+		for i, p := range paths {
+			result[i] = RequestSpec{Method: http.MethodGet, Path: p}
+		}
+		return result
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "results are in request order",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				results, err := DoAll(ctx, c, specs("a", "b", "c", "d"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(results)).Equals(4)
+				for i, p := range []string{"/a", "/b", "/c", "/d"} {
+					body, _ := ioReadAll(results[i].Response.Body)
+					test.That(t, string(body)).Equals(p)
+				}
+			},
+		},
+		{scenario: "concurrency is bounded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{delay: 5 * time.Millisecond}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				_, err := DoAll(ctx, c, specs("a", "b", "c", "d", "e", "f"), Concurrency(2))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, fake.requests.Load()).Equals(6)
+				test.IsTrue(t, fake.maxInflight.Load() <= 2, "at most 2 requests in-flight")
+			},
+		},
+		{scenario: "errors are collected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{status: map[string]int{"/b": http.StatusNotFound}}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				results, err := DoAll(ctx, c, specs("a", "b", "c"))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsTrue(t, strings.HasPrefix(err.Error(), "request 1: "), "identifies request index")
+				test.Error(t, results[0].Err).IsNil()
+				test.Error(t, results[1].Err).Is(ErrUnexpectedStatusCode)
+				test.That(t, results[1].Response.StatusCode).Equals(http.StatusNotFound)
+				test.Error(t, results[2].Err).IsNil()
+			},
+		},
+		{scenario: "fail fast",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{
+					delay:  5 * time.Millisecond,
+					status: map[string]int{"/a": http.StatusNotFound},
+				}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				results, err := DoAll(ctx, c, specs("a", "b", "c", "d"), Concurrency(1), FailFast())
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.Error(t, results[0].Err).Is(ErrUnexpectedStatusCode)
+				for _, r := range results[1:] {
+					test.Error(t, r.Err).Is(context.Canceled)
+				}
+				test.That(t, fake.requests.Load()).Equals(1)
+			},
+		},
+		{scenario: "context cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+
+				// ACT
+				results, err := DoAll(ctx, c, specs("a", "b"))
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.Error(t, results[0].Err).Is(context.Canceled)
+				test.Error(t, results[1].Err).Is(context.Canceled)
+				test.That(t, fake.requests.Load()).Equals(0)
+			},
+		},
+		{scenario: "request context outlives DoAll",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rqctx context.Context
+				c, _ := NewClient("test", URL("http://hostname"), Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					rqctx = rq.Context()
+					rec := httptest.NewRecorder()
+					_, _ = rec.Write([]byte("content"))
+					return rec.Result(), nil
+				})))
+
+				// ACT
+				results, err := DoAll(ctx, c, specs("a"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, rqctx.Err(), "before body is read").IsNil()
+
+				body, _ := io.ReadAll(results[0].Response.Body)
+				test.That(t, string(body)).Equals("content")
+				test.Error(t, rqctx.Err(), "after body is read").Is(context.Canceled)
+			},
+		},
+		{scenario: "streamed body read after release",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rqctx context.Context
+				c, _ := NewClient("test", URL("http://hostname"), Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					rqctx = rq.Context()
+					rec := httptest.NewRecorder()
+					_, _ = rec.Write([]byte("content"))
+					return rec.Result(), nil
+				})))
+				results, err := DoAll(ctx, c, []RequestSpec{{
+					Method:  http.MethodGet,
+					Path:    "a",
+					Options: []RequestOption{request.StreamResponse()},
+				}})
+				test.Error(t, err).IsNil()
+				body, err := io.ReadAll(results[0].Response.Body)
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("content")
+				test.Error(t, rqctx.Err(), "released").Is(context.Canceled)
+
+				// ACT
+				n, err := results[0].Response.Body.Read(make([]byte, 1))
+
+				// ASSERT
+				test.That(t, n).Equals(0)
+				test.Error(t, err).Is(io.EOF)
+				test.Error(t, results[0].Response.Body.Close()).IsNil()
+			},
+		},
+		{scenario: "request context is released when body is closed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rqctx context.Context
+				c, _ := NewClient("test", URL("http://hostname"), Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					rqctx = rq.Context()
+					rec := httptest.NewRecorder()
+					_, _ = rec.Write([]byte("content"))
+					return rec.Result(), nil
+				})))
+				results, _ := DoAll(ctx, c, specs("a"))
+
+				// ACT
+				err := results[0].Response.Body.Close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, rqctx.Err()).Is(context.Canceled)
+			},
+		},
+		{scenario: "request context is released without a response body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rqctx context.Context
+				c, _ := NewClient("test", URL("http://hostname"), Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					rqctx = rq.Context()
+					return httptest.NewRecorder().Result(), nil
+				})))
+
+				// ACT
+				_, err := DoAll(ctx, c, specs("a"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, rqctx.Err()).Is(context.Canceled)
+			},
+		},
+		{scenario: "request initialisation error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				c, _ := NewClient("test", URL("http://hostname"), Using(&concurrentClient{}))
+
+				// ACT
+				results, err := DoAll(ctx, c, []RequestSpec{{
+					Method:  http.MethodGet,
+					Path:    "a",
+					Options: []RequestOption{func(*http.Request) error { return opterr }},
+				}})
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+				test.Error(t, results[0].Err).Is(opterr)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blugnu/errorcontext"
+	"github.com/blugnu/http/request"
+)
+
+// downloadSuffix is appended to the name of a file being downloaded until
+// the download is complete
+const downloadSuffix = ".part"
+
+// validatorSuffix is appended to the name of a partial file to name a file
+// holding the validator (ETag or Last-Modified) of the response from which
+// the partial file was obtained
+const validatorSuffix = ".validator"
+
+// Download performs a Get request, appending the specified path to the client
+// url and applying any RequestOptions, streaming the body of the response to
+// a specified file.  The size of the downloaded file is returned.
+//
+// The body is written to a partial file (the filename with a .part suffix)
+// which is renamed to the specified filename only once the download is
+// complete; an existing file with the specified name is replaced.
+//
+// If a partial file exists from a previous, incomplete download, the download
+// is resumed by requesting the remainder of the file using a Range header.  If
+// the server does not support range requests the file is downloaded in full.
+//
+// The validator of the response from which a partial file was obtained (a
+// strong ETag or, if there is none, the Last-Modified date) is retained with
+// the partial file and sent in an If-Range header when the download is
+// resumed; if the file has since changed, the server responds with the
+// complete file which then replaces the partial file.
+//
+// If the response specifies a Content-Length and the number of bytes received
+// differs, ErrContentLengthMismatch is returned and the partial file is
+// retained, so that the download may be resumed.
+//
+// If an error occurs, the number of bytes in the partial file is returned
+// together with the error.
+//
+// Progress in downloading the file may be reported using the
+// request.DownloadProgressFunc option; the StreamResponse option is implied.
+func (c client) Download(
+	ctx context.Context,
+	path string,
+	filename string,
+	opts ...RequestOption,
+) (int64, error) {
+	handle := func(rq *http.Request, n int64, err error) (int64, error) {
+		cerr := ClientError{Client: c.name, Method: http.MethodGet, Err: err}
+		if rq != nil {
+			cerr.URL = rq.URL.Redacted()
+		}
+		return n, errorcontext.Errorf(ctx, "%w", cerr)
+	}
+
+	part := filename + downloadSuffix
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return handle(nil, 0, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return handle(nil, 0, err)
+	}
+	offset := info.Size()
+
+	opts = append(append([]RequestOption{}, opts...),
+		request.StreamResponse(),
+		request.AcceptStatus(http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable),
+	)
+	validator := part + validatorSuffix
+	if offset > 0 {
+		opts = append(opts, request.Header(HeaderRange, fmt.Sprintf("bytes=%d-", offset)))
+		if b, err := os.ReadFile(validator); err == nil && len(b) > 0 {
+			opts = append(opts, request.Header("If-Range", string(b)))
+		}
+	}
+
+	rq, err := c.NewRequest(ctx, http.MethodGet, path, opts...)
+	if err != nil {
+		return handle(nil, 0, err)
+	}
+
+	r, err := c.Do(rq)
+	if r != nil {
+		defer func() { _ = closeBody(ctx, r.Body) }()
+	}
+	if err != nil {
+		return offset, err
+	}
+
+	switch r.StatusCode {
+	case http.StatusPartialContent:
+		start, _, _, ok := parseContentRange(r.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return handle(rq, offset, fmt.Errorf("%w: %q (requested bytes=%d-)", ErrInvalidContentRange, r.Header.Get("Content-Range"), offset))
+		}
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is complete if its size is the size of the
+		// resource; otherwise it is invalid and is discarded
+		_, _, size, ok := parseContentRange(r.Header.Get("Content-Range"))
+		if !ok || offset == 0 || size != offset {
+			_ = f.Close()
+			_ = os.Remove(part)
+			_ = os.Remove(validator)
+			return handle(rq, 0, fmt.Errorf("%w: %q (requested bytes=%d-)", ErrInvalidContentRange, r.Header.Get("Content-Range"), offset))
+		}
+		return c.completeDownload(ctx, rq, f, part, filename, offset)
+
+	default:
+		// the complete file is received, either because the download is not
+		// being resumed, range requests are not supported or the file has
+		// changed since the partial file was obtained
+		if err := f.Truncate(0); err != nil {
+			return handle(rq, 0, err)
+		}
+		offset = 0
+	}
+
+	if v := rangeValidator(r.Header); v != "" {
+		if err := os.WriteFile(validator, []byte(v), 0o644); err != nil {
+			return handle(rq, offset, err)
+		}
+	} else {
+		_ = os.Remove(validator)
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return handle(rq, offset, err)
+	}
+
+	n, err := ioCopy(f, r.Body)
+	switch {
+	case err != nil:
+		return handle(rq, offset+n, fmt.Errorf("%w: %w", ErrReadingResponseBody, err))
+
+	case r.ContentLength >= 0 && n != r.ContentLength:
+		return handle(rq, offset+n, fmt.Errorf("%w: expected %d bytes, got %d", ErrContentLengthMismatch, r.ContentLength, n))
+	}
+
+	return c.completeDownload(ctx, rq, f, part, filename, offset+n)
+}
+
+// completeDownload closes a partial file and renames it to the name of the
+// downloaded file, returning the size of the file
+func (c client) completeDownload(
+	ctx context.Context,
+	rq *http.Request,
+	f *os.File,
+	part string,
+	filename string,
+	size int64,
+) (int64, error) {
+	err := f.Close()
+	if err == nil {
+		err = os.Rename(part, filename)
+	}
+	if err == nil {
+		_ = os.Remove(part + validatorSuffix)
+	}
+	if err != nil {
+		return size, errorcontext.Errorf(ctx, "%w", ClientError{
+			Client: c.name,
+			Method: rq.Method,
+			URL:    rq.URL.Redacted(),
+			Err:    err,
+		})
+	}
+	return size, nil
+}
+
+// rangeValidator returns the validator of a response that may be sent in an
+// If-Range header to resume a download: a strong ETag or, if the response has
+// no strong ETag, the Last-Modified date.  If the response has neither, an
+// empty string is returned.
+func rangeValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// parseContentRange parses the value of a Content-Range header, returning the
+// first and last byte positions of the range and the complete length of the
+// resource.  The complete length is -1 if it is not known ("*").  For an
+// unsatisfied range ("bytes */length") the first and last positions are -1.
+//
+// If the value is not a valid byte range, ok is false.
+func parseContentRange(v string) (start, end, size int64, ok bool) {
+	rng, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rng, length, found := strings.Cut(rng, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	size = -1
+	if length != "*" {
+		var err error
+		if size, err = strconv.ParseInt(length, 10, 64); err != nil || size < 0 {
+			return 0, 0, 0, false
+		}
+	}
+
+	if rng == "*" {
+		return -1, -1, size, size >= 0
+	}
+
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if err1 != nil || err2 != nil || start < 0 || end < start || (size >= 0 && end >= size) {
+		return 0, 0, 0, false
+	}
+	return start, end, size, true
+}
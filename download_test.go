@@ -0,0 +1,413 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestDownload(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+	// server returns a test server serving the content (supporting range
+	// requests), recording the Range header of each request
+	server := func(t *testing.T, ranges *[]string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	// fake returns a client responding to every request with a specified
+	// response
+	fake := func(r *http.Response) HttpClient {
+		c, _ := NewClient("name", URL("http://hostname"), Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+			return r, nil
+		})))
+		return c
+	}
+
+	// file returns the content of a file, or "<missing>" if the file does
+	// not exist
+	file := func(name string) string {
+		b, err := os.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			return "<missing>"
+		}
+		return string(b)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "new download",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ranges := []string{}
+				srv := server(t, &ranges)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+				test.That(t, file(dst+".part")).Equals("<missing>")
+				test.Strings(t, ranges).Equals([]string{""})
+			},
+		},
+		{scenario: "existing file is replaced",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ranges := []string{}
+				srv := server(t, &ranges)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst, []byte("existing content which is longer than the download"), 0o600)
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, file(dst)).Equals(string(content))
+			},
+		},
+		{scenario: "resumed download",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ranges := []string{}
+				srv := server(t, &ranges)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", content[:10], 0o600)
+
+				progress := []int64{}
+				total := int64(0)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst, request.DownloadProgressFunc(func(received, size int64) {
+					progress = append(progress, received)
+					total = size
+				}))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+				test.Strings(t, ranges).Equals([]string{"bytes=10-"})
+				test.That(t, progress[len(progress)-1]).Equals(int64(len(content)))
+				test.IsTrue(t, progress[0] > 10)
+				test.That(t, total).Equals(int64(len(content)))
+			},
+		},
+		{scenario: "resumed download/validator",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ifRange := []string{}
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ifRange = append(ifRange, r.Header.Get("If-Range"))
+					w.Header().Set("ETag", `"v1"`)
+					http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+				}))
+				t.Cleanup(srv.Close)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", content[:10], 0o600)
+				_ = os.WriteFile(dst+".part.validator", []byte(`"v1"`), 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+				test.That(t, file(dst+".part.validator")).Equals("<missing>")
+				test.Strings(t, ifRange).Equals([]string{`"v1"`})
+			},
+		},
+		{scenario: "resumed download/file changed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("ETag", `"v2"`)
+					http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+				}))
+				t.Cleanup(srv.Close)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", []byte("ABCDEFGHIJ"), 0o600)
+				_ = os.WriteFile(dst+".part.validator", []byte(`"v1"`), 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+			},
+		},
+		{scenario: "interrupted download/validator is retained",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{
+					StatusCode:    http.StatusOK,
+					Header:        http.Header{"Etag": {`"v1"`}, "Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+					ContentLength: int64(len(content)),
+					Body:          io.NopCloser(bytes.NewReader(content[:10])),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrContentLengthMismatch)
+				test.That(t, file(dst+".part.validator")).Equals(`"v1"`)
+			},
+		},
+		{scenario: "interrupted download/weak etag",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{
+					StatusCode:    http.StatusOK,
+					Header:        http.Header{"Etag": {`W/"v1"`}, "Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+					ContentLength: int64(len(content)),
+					Body:          io.NopCloser(bytes.NewReader(content[:10])),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrContentLengthMismatch)
+				test.That(t, file(dst+".part.validator")).Equals("Wed, 21 Oct 2015 07:28:00 GMT")
+			},
+		},
+		{scenario: "resumed download/range not supported",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: int64(len(content)),
+					Body:          io.NopCloser(bytes.NewReader(content)),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", []byte("stale partial content"), 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+			},
+		},
+		{scenario: "resumed download/partial file is complete",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ranges := []string{}
+				srv := server(t, &ranges)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", content, 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(int64(len(content)))
+				test.That(t, file(dst)).Equals(string(content))
+				test.That(t, file(dst+".part")).Equals("<missing>")
+			},
+		},
+		{scenario: "resumed download/partial file is too large",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ranges := []string{}
+				srv := server(t, &ranges)
+				c, _ := NewClient("name", URL(srv.URL))
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", append(content, "more"...), 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidContentRange)
+				test.That(t, n).Equals(int64(0))
+				test.That(t, file(dst)).Equals("<missing>")
+				test.That(t, file(dst+".part")).Equals("<missing>")
+			},
+		},
+		{scenario: "resumed download/unexpected range",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{
+					StatusCode: http.StatusPartialContent,
+					Header:     http.Header{"Content-Range": {"bytes 5-35/36"}},
+					Body:       io.NopCloser(bytes.NewReader(content[5:])),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+				_ = os.WriteFile(dst+".part", content[:10], 0o600)
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidContentRange)
+				test.That(t, n).Equals(int64(10))
+				test.That(t, file(dst+".part")).Equals(string(content[:10]))
+			},
+		},
+		{scenario: "content length mismatch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: int64(len(content)),
+					Body:          io.NopCloser(bytes.NewReader(content[:10])),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrContentLengthMismatch)
+				test.That(t, n).Equals(int64(10))
+				test.That(t, file(dst)).Equals("<missing>")
+				test.That(t, file(dst+".part")).Equals(string(content[:10]))
+			},
+		},
+		{scenario: "error reading body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				readerr := errors.New("read error")
+				c := fake(&http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: -1,
+					Body:          io.NopCloser(io.MultiReader(strings.NewReader("0123"), iotest.ErrReader(readerr))),
+				})
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				n, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(readerr)
+				test.That(t, n).Equals(int64(4))
+			},
+		},
+		{scenario: "unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody})
+				dst := filepath.Join(t.TempDir(), "file")
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, file(dst)).Equals("<missing>")
+			},
+		},
+		{scenario: "partial file cannot be created",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody})
+				dst := filepath.Join(t.TempDir(), "no", "such", "dir", "file")
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst)
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "invalid request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := fake(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody})
+				dst := filepath.Join(t.TempDir(), "file")
+				opterr := errors.New("option error")
+
+				// ACT
+				_, err := c.Download(ctx, "file", dst, func(*http.Request) error { return opterr })
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		value string
+		start int64
+		end   int64
+		size  int64
+		ok    bool
+	}{
+		{value: "bytes 0-499/1234", start: 0, end: 499, size: 1234, ok: true},
+		{value: "bytes 500-999/*", start: 500, end: 999, size: -1, ok: true},
+		{value: "bytes */1234", start: -1, end: -1, size: 1234, ok: true},
+		{value: ""},
+		{value: "items 0-1/2"},
+		{value: "bytes 0-499"},
+		{value: "bytes */*"},
+		{value: "bytes 0-499/x"},
+		{value: "bytes 0/1234"},
+		{value: "bytes a-499/1234"},
+		{value: "bytes 500-499/1234"},
+		{value: "bytes 0-1234/1234"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			// ACT
+			start, end, size, ok := parseContentRange(tc.value)
+
+			// ASSERT
+			test.That(t, ok).Equals(tc.ok)
+			if tc.ok {
+				test.That(t, start).Equals(tc.start)
+				test.That(t, end).Equals(tc.end)
+				test.That(t, size).Equals(tc.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"context"
+	"io"
+)
+
+// maxDrain is the maximum number of bytes read from the unconsumed remainder
+// of a response body before it is closed.  A body that is read to EOF before
+// being closed allows the underlying connection to be reused; the remainder of
+// a larger body is abandoned, and the connection closed, rather than reading
+// an unbounded amount of data that will not be used.
+var maxDrain int64 = 64 << 10
+
+// closeBody drains and closes a response body.  It is used wherever a body may
+// be abandoned before it has been read to EOF (e.g. when decoding fails part
+// way through a streamed body) so that the connection is either returned to
+// the pool in a clean state or closed, never left half-read.
+//
+// If the context is done the body is closed without draining; reading from
+// the body of a cancelled request would fail (or block) to no purpose.
+func closeBody(ctx context.Context, body io.ReadCloser) error {
+	if ctx.Err() == nil {
+		// reading one byte more than the limit ensures that a remainder of
+		// exactly maxDrain bytes is read to EOF
+		_, _ = io.CopyN(io.Discard, body, maxDrain+1)
+	}
+	return body.Close()
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// drainBody is a response body recording whether it has been read to EOF and
+// whether it has been closed
+type drainBody struct {
+	io.Reader
+	eof    bool
+	closed bool
+}
+
+func (b *drainBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.eof = b.eof || err == io.EOF
+	return n, err
+}
+
+func (b *drainBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCloseBody(t *testing.T) {
+	// ARRANGE
+	og := maxDrain
+	defer func() { maxDrain = og }()
+	maxDrain = 8
+
+	body := func(s string) *drainBody {
+		return &drainBody{Reader: bytes.NewReader([]byte(s))}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "remainder within limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := body("12345678")
+
+				// ACT
+				err := closeBody(context.Background(), b)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, b.eof, "drained")
+				test.IsTrue(t, b.closed, "closed")
+			},
+		},
+		{scenario: "remainder exceeds limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := body("123456789")
+
+				// ACT
+				err := closeBody(context.Background(), b)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, b.eof, "drained")
+				test.IsTrue(t, b.closed, "closed")
+			},
+		},
+		{scenario: "context done",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := body("1")
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				// ACT
+				err := closeBody(ctx, b)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, b.eof, "drained")
+				test.IsTrue(t, b.closed, "closed")
+			},
+		},
+		{scenario: "DecodeEach/abandoned",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := body(`[1,2,x]`)
+				r := &http.Response{Body: b}
+
+				// ACT
+				err := DecodeEach(context.Background(), r, func(int) error { return nil })
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.IsTrue(t, b.eof, "drained")
+				test.IsTrue(t, b.closed, "closed")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
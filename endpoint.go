@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/blugnu/errorcontext"
+	"github.com/blugnu/http/request"
+)
+
+// endpoint holds the definition of a named endpoint registered on a client
+type endpoint struct {
+	method string
+	path   string
+	opts   []RequestOption
+}
+
+// endpointKey is the key under which the name of an endpoint is held in the
+// context of a request made by invoking a named endpoint
+type endpointKey struct{}
+
+// Endpoint registers a named endpoint on a client.  The endpoint is invoked
+// using the Invoke method of the client, identifying the endpoint by name.
+//
+// The path may be a template containing parameters in the form {name}; values
+// for these parameters are supplied when the endpoint is invoked.
+//
+// Any request options specified are applied to every request made to the
+// endpoint, before any options supplied when the endpoint is invoked.  This
+// enables per-endpoint policies (e.g. retries or acceptable status codes) to
+// be established centrally.
+//
+// # Example
+//
+//	c, err := http.NewClient("users",
+//		http.URL("https://api.example.com"),
+//		http.Endpoint("getUser", http.MethodGet, "users/{id}", request.AcceptStatus(http.StatusNotFound)),
+//	)
+//
+//	r, err := c.Invoke(ctx, "getUser", map[string]any{"id": 42})
+func Endpoint(name string, method string, path string, opts ...RequestOption) ClientOption {
+	return func(c *client) error {
+		if _, ok := c.endpoints[name]; ok {
+			return fmt.Errorf("http: Endpoint option: %w: %s", ErrDuplicateEndpoint, name)
+		}
+		if c.endpoints == nil {
+			c.endpoints = map[string]endpoint{}
+		}
+		c.endpoints[name] = endpoint{method: method, path: path, opts: opts}
+		return nil
+	}
+}
+
+// EndpointName returns the name of the endpoint that was invoked to make a
+// request with a specified context.  If the request was not made by invoking a
+// named endpoint, an empty string is returned.
+//
+// This enables (for example) middleware to label metrics consistently for
+// each endpoint, regardless of the parameters in the url of each request.
+func EndpointName(ctx context.Context) string {
+	name, _ := ctx.Value(endpointKey{}).(string)
+	return name
+}
+
+// Invoke performs a request to a named endpoint registered on the client,
+// substituting any parameters in the endpoint path with the values supplied.
+// Parameter values are formatted using fmt.Sprint and url path escaped (see:
+// request.PathParams).
+//
+// Any request options specified are applied after those registered with the
+// endpoint.
+//
+// ErrUnknownEndpoint is returned if no endpoint is registered with the name
+// specified; ErrMissingParameter is returned if no value is supplied for any
+// parameter in the endpoint path.
+func (c client) Invoke(
+	ctx context.Context,
+	name string,
+	params map[string]any,
+	opts ...RequestOption,
+) (*http.Response, error) {
+	handle := func(method string, err error) (*http.Response, error) {
+		return nil, errorcontext.Errorf(ctx, "%w", ClientError{
+			Client: c.name,
+			Method: method,
+			Err:    fmt.Errorf("%s: %w", name, err),
+		})
+	}
+
+	ep, ok := c.endpoints[name]
+	if !ok {
+		return handle("", ErrUnknownEndpoint)
+	}
+
+	ctx = context.WithValue(ctx, endpointKey{}, name)
+	opts = append(append([]RequestOption{request.PathParams(params)}, ep.opts...), opts...)
+	return c.execute(ctx, ep.method, ep.path, opts...)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestEndpoint(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "duplicate endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("test",
+					Endpoint("getUser", http.MethodGet, "users/{id}"),
+					Endpoint("getUser", http.MethodGet, "users/{id}"),
+				)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDuplicateEndpoint)
+			},
+		},
+		{scenario: "Invoke/unknown endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				r, err := c.Invoke(ctx, "getUser", nil)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnknownEndpoint)
+				test.That(t, r).IsNil()
+				test.That(t, len(fake.requests)).Equals(0)
+			},
+		},
+		{scenario: "Invoke/missing parameter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake),
+					Endpoint("getUser", http.MethodGet, "users/{id}/{version}"),
+				)
+
+				// ACT
+				r, err := c.Invoke(ctx, "getUser", map[string]any{"id": 42})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMissingParameter)
+				test.Error(t, err).Is(request.ErrMissingPathParameter)
+				test.That(t, r).IsNil()
+				test.That(t, len(fake.requests)).Equals(0)
+			},
+		},
+		{scenario: "Invoke/successful",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &fakeClient{statusCode: http.StatusNotFound}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake),
+					Endpoint("getUser", http.MethodGet, "users/{id}", request.AcceptStatus(http.StatusNotFound)),
+				)
+
+				// ACT
+				r, err := c.Invoke(ctx, "getUser", map[string]any{"id": "a/b"}, request.Header("X-Header", "value"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusNotFound)
+
+				sent := fake.requests[0]
+				test.That(t, sent.Method).Equals(http.MethodGet)
+				test.That(t, sent.URL.String()).Equals("http://hostname/users/a%2Fb")
+				test.That(t, sent.Header.Get("X-Header")).Equals("value")
+				test.That(t, EndpointName(sent.Context())).Equals("getUser")
+			},
+		},
+		{scenario: "EndpointName/no endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result := EndpointName(ctx)
+
+				// ASSERT
+				test.That(t, result).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestErrorClassification(t *testing.T) {
+	// ARRANGE
+	wrap := func(err error) error {
+		return ClientError{
+			Client: "client",
+			Method: "GET",
+			URL:    "http://hostname",
+			Err:    &url.Error{Op: "Get", URL: "http://hostname", Err: err},
+		}
+	}
+
+	testcases := []struct {
+		scenario          string
+		err               error
+		timeout           bool
+		connectionRefused bool
+		dns               bool
+		tls               bool
+	}{
+		{scenario: "nil"},
+		{scenario: "other error", err: wrap(errors.New("other"))},
+		{scenario: "context deadline", err: wrap(context.DeadlineExceeded), timeout: true},
+		{scenario: "os deadline", err: wrap(os.ErrDeadlineExceeded), timeout: true},
+		{scenario: "net timeout", err: wrap(&net.DNSError{IsTimeout: true}), timeout: true, dns: true},
+		{scenario: "connection refused",
+			err:               wrap(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}),
+			connectionRefused: true,
+		},
+		{scenario: "dns", err: wrap(&net.DNSError{Err: "no such host", Name: "hostname"}), dns: true},
+		{scenario: "tls/record header", err: wrap(tls.RecordHeaderError{Msg: "bad record"}), tls: true},
+		{scenario: "tls/alert", err: wrap(tls.AlertError(42)), tls: true},
+		{scenario: "tls/verification",
+			err: wrap(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}),
+			tls: true,
+		},
+		{scenario: "tls/hostname", err: wrap(x509.HostnameError{Host: "hostname"}), tls: true},
+		{scenario: "wrapped by fmt", err: fmt.Errorf("context: %w", wrap(context.DeadlineExceeded)), timeout: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT & ASSERT
+			test.That(t, IsTimeout(tc.err), "IsTimeout").Equals(tc.timeout)
+			test.That(t, IsConnectionRefused(tc.err), "IsConnectionRefused").Equals(tc.connectionRefused)
+			test.That(t, IsDNSError(tc.err), "IsDNSError").Equals(tc.dns)
+			test.That(t, IsTLSError(tc.err), "IsTLSError").Equals(tc.tls)
+		})
+	}
+}
@@ -3,24 +3,57 @@ package http
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blugnu/http/request"
 )
 
 var (
-	ErrInitialisingClient   = errors.New("error initialising client")
-	ErrInitialisingRequest  = errors.New("error initialising request")
-	ErrInvalidJSON          = errors.New("invalid json")
-	ErrInvalidRequestHeader = errors.New("invalid request headers")
-	ErrInvalidURL           = errors.New("invalid url")
-	ErrMaxRetriesExceeded   = errors.New("http retries exceeded")
-	ErrNoResponseBody       = errors.New("response body was empty")
-	ErrReadingResponseBody  = errors.New("error reading response body")
-	ErrUnexpectedStatusCode = errors.New("unexpected status code")
+	ErrBodyIdleTimeout              = errors.New("response body idle timeout")
+	ErrCannotCloneBody              = errors.New("request body cannot be cloned")
+	ErrCertificateNotPinned         = errors.New("server certificate not pinned")
+	ErrConflictingOptions           = errors.New("conflicting request options")
+	ErrContentLengthMismatch        = errors.New("content length mismatch")
+	ErrDecodingResponseBody         = errors.New("error decoding response body")
+	ErrDependencyFailed             = errors.New("dependency failed")
+	ErrDuplicateEndpoint            = errors.New("duplicate endpoint")
+	ErrInitialisingClient           = errors.New("error initialising client")
+	ErrInitialisingRequest          = errors.New("error initialising request")
+	ErrInjectedFault                = errors.New("injected fault")
+	ErrInvalidContentRange          = errors.New("invalid content range")
+	ErrInvalidGraph                 = errors.New("invalid graph")
+	ErrInvalidJSON                  = errors.New("invalid json")
+	ErrInvalidOptions               = errors.New("invalid request options")
+	ErrInvalidRequestHeader         = errors.New("invalid request headers")
+	ErrInvalidURL                   = errors.New("invalid url")
+	ErrMaxRetriesExceeded           = errors.New("http retries exceeded")
+	ErrNoResponseBody               = errors.New("response body was empty")
+	ErrObtainingToken               = errors.New("error obtaining token")
+	ErrRateLimited                  = errors.New("rate limited")
+	ErrReadingResponseBody          = errors.New("error reading response body")
+	ErrResponseBodyTooLarge         = errors.New("response body too large")
+	ErrRetryDeadline                = errors.New("insufficient time to retry before deadline")
+	ErrTenant                       = errors.New("tenant configuration error")
+	ErrTimeoutsNotSupported         = errors.New("timeouts not supported")
+	ErrTLSOptionsNotSupported       = errors.New("tls options not supported")
+	ErrTransformingResponse         = errors.New("error transforming response")
+	ErrTransportOptionsNotSupported = errors.New("transport options not supported")
+	ErrUnexpectedStatusCode         = errors.New("unexpected status code")
+	ErrUnknownEndpoint              = errors.New("unknown endpoint")
+	ErrUnsupportedMediaType         = errors.New("unsupported media type")
 
 	// errors related to the mock client
 	ErrCannotChangeExpectations = errors.New("expectations cannot be changed")
 	ErrUnexpectedRequest        = errors.New("unexpected request")
 )
 
+// ErrMissingParameter is returned if no value is supplied for a parameter in
+// the path of a request (see: Invoke).  It is the same error as
+// request.ErrMissingPathParameter, returned by request.PathParams.
+var ErrMissingParameter = request.ErrMissingPathParameter
+
 // MockExpectationsError is the error returned by ExpectationsNotMet() when one or
 // more configured expectations have not been met.  It wraps all errors
 // representing the failed expectations.
@@ -39,3 +72,302 @@ func (err MockExpectationsError) Error() string {
 	}
 	return fmt.Sprintf("%s: expectations not met: [\n%s]", err.name, errs)
 }
+
+// ClientError is the error returned by a client when a request could not be
+// initialised or performed, or the response was not acceptable.  It identifies
+// the client, method and url involved, the number of attempts made and time
+// elapsed, and wraps the error that occurred.
+type ClientError struct {
+	// Client is the name of the client
+	Client string
+
+	// Method is the http method of the request
+	Method string
+
+	// URL is the url of the request, with any password redacted; this will be
+	// empty if the error occurred while initialising the request
+	URL string
+
+	// RequestID is the correlation id of the request, if any (see: RequestID
+	// and WithRequestID)
+	RequestID string
+
+	// Attempts is the number of attempts made to perform the request; this
+	// will be zero if the request was not attempted
+	Attempts uint
+
+	// Elapsed is the time taken from submitting the request to the error
+	// occurring, including all attempts
+	Elapsed time.Duration
+
+	// Err is the error that occurred
+	Err error
+}
+
+// Error implements the error interface for ClientError, returning a string
+// identifying the client, method, (if known) url and (if any) request id of
+// the request, followed by the wrapped error.
+func (err ClientError) Error() string {
+	rq := err.Method
+	if err.URL != "" {
+		rq += " " + err.URL
+	}
+	if err.RequestID != "" {
+		rq += fmt.Sprintf(" (request id: %s)", err.RequestID)
+	}
+	return fmt.Sprintf("%s: %s: %v", err.Client, rq, err.Err)
+}
+
+// Unwrap returns the error wrapped by the ClientError
+func (err ClientError) Unwrap() error {
+	return err.Err
+}
+
+// InvalidURLError is the error returned when a client or request url is invalid.
+// It satisfies errors.Is(err, ErrInvalidURL).
+type InvalidURLError struct {
+	// URL is the invalid url (or a representation of the value supplied)
+	URL string
+
+	// Err describes the reason the url is invalid
+	Err error
+}
+
+// Error implements the error interface for InvalidURLError
+func (err InvalidURLError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInvalidURL, err.Err)
+}
+
+// Is returns true if the target is ErrInvalidURL
+func (err InvalidURLError) Is(target error) bool {
+	return target == ErrInvalidURL
+}
+
+// Unwrap returns the error describing the reason the url is invalid
+func (err InvalidURLError) Unwrap() error {
+	return err.Err
+}
+
+// InvalidRequestHeaderError is the error returned when a request option header
+// has an invalid value.  It satisfies errors.Is(err, ErrInvalidRequestHeader).
+type InvalidRequestHeaderError struct {
+	// Header is the key of the invalid header
+	Header string
+
+	// Value is the (raw) value of the header
+	Value string
+
+	// Err describes the reason the header is invalid
+	Err error
+}
+
+// Error implements the error interface for InvalidRequestHeaderError
+func (err InvalidRequestHeaderError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrInvalidRequestHeader, err.Header, err.Err)
+}
+
+// Is returns true if the target is ErrInvalidRequestHeader
+func (err InvalidRequestHeaderError) Is(target error) bool {
+	return target == ErrInvalidRequestHeader
+}
+
+// Unwrap returns the error describing the reason the header is invalid
+func (err InvalidRequestHeaderError) Unwrap() error {
+	return err.Err
+}
+
+// InvalidOptionsError is the error returned when one or more request options
+// are invalid.  It lists each invalid option, identifying the header carrying
+// the option, its raw value and the reason it is invalid.
+//
+// It satisfies errors.Is(err, ErrInvalidOptions) and, by wrapping each
+// InvalidRequestHeaderError, errors.Is(err, ErrInvalidRequestHeader).
+type InvalidOptionsError struct {
+	// Options identifies each invalid option
+	Options []InvalidRequestHeaderError
+}
+
+// Error implements the error interface for InvalidOptionsError, listing each
+// invalid option with its raw value
+func (err InvalidOptionsError) Error() string {
+	s := make([]string, 0, len(err.Options))
+	for _, opt := range err.Options {
+		s = append(s, fmt.Sprintf("%s=%q: %v", opt.Header, opt.Value, opt.Err))
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidOptions, strings.Join(s, "; "))
+}
+
+// Is returns true if the target is ErrInvalidOptions
+func (err InvalidOptionsError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+// Unwrap returns an InvalidRequestHeaderError for each invalid option
+func (err InvalidOptionsError) Unwrap() []error {
+	errs := make([]error, len(err.Options))
+	for i, opt := range err.Options {
+		errs[i] = opt
+	}
+	return errs
+}
+
+// MaxRetriesExceededError is the error returned when a request has failed on
+// every permitted attempt.  It satisfies errors.Is(err, ErrMaxRetriesExceeded).
+type MaxRetriesExceededError struct {
+	// Attempts is the number of attempts made, including the initial attempt
+	Attempts uint
+
+	// Err is the error returned by the final attempt
+	Err error
+}
+
+// Error implements the error interface for MaxRetriesExceededError
+func (err MaxRetriesExceededError) Error() string {
+	return fmt.Sprintf("%s: %d attempts: %v", ErrMaxRetriesExceeded, err.Attempts, err.Err)
+}
+
+// Is returns true if the target is ErrMaxRetriesExceeded
+func (err MaxRetriesExceededError) Is(target error) bool {
+	return target == ErrMaxRetriesExceeded
+}
+
+// Unwrap returns the error returned by the final attempt
+func (err MaxRetriesExceededError) Unwrap() error {
+	return err.Err
+}
+
+// RateLimitedError is the error returned by a client configured to handle 429
+// Too Many Requests responses (see: HandleTooManyRequests) when a request is
+// rate limited.  It satisfies errors.Is(err, ErrRateLimited).
+//
+// If the error results from a 429 response, Err is an UnexpectedStatusCodeError
+// identifying the response status.  If the request was not sent because the
+// client was paused, Err is nil.
+type RateLimitedError struct {
+	// Reset is the time at which the rate limit is expected to reset; this
+	// will be the zero time if the reset time is not known
+	Reset time.Time
+
+	// Err is the error describing the rate limited response, if any
+	Err error
+}
+
+// Error implements the error interface for RateLimitedError
+func (err RateLimitedError) Error() string {
+	s := ErrRateLimited.Error()
+	if !err.Reset.IsZero() {
+		s += ": reset at " + err.Reset.Format(time.RFC3339)
+	}
+	if err.Err != nil {
+		s += ": " + err.Err.Error()
+	}
+	return s
+}
+
+// Is returns true if the target is ErrRateLimited
+func (err RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Unwrap returns the error describing the rate limited response, if any
+func (err RateLimitedError) Unwrap() error {
+	return err.Err
+}
+
+// RetryDeadlineError is the error returned when a request is not retried because
+// the retry could not complete before the deadline of the request context.  It
+// satisfies errors.Is(err, ErrRetryDeadline).
+type RetryDeadlineError struct {
+	// Attempts is the number of attempts made
+	Attempts uint
+
+	// Err is the error from the final attempt
+	Err error
+}
+
+// Error implements the error interface for RetryDeadlineError
+func (err RetryDeadlineError) Error() string {
+	return fmt.Sprintf("%s: %d attempts: %v", ErrRetryDeadline, err.Attempts, err.Err)
+}
+
+// Is returns true if the target is ErrRetryDeadline
+func (err RetryDeadlineError) Is(target error) bool {
+	return target == ErrRetryDeadline
+}
+
+// Unwrap returns the error from the final attempt
+func (err RetryDeadlineError) Unwrap() error {
+	return err.Err
+}
+
+// TenantError is the error returned when the configuration of a tenant cannot
+// be resolved (see: Tenants).  It satisfies errors.Is(err, ErrTenant).
+type TenantError struct {
+	// Tenant is the id of the tenant
+	Tenant string
+
+	// Err is the error returned by the TenantProvider
+	Err error
+}
+
+// Error implements the error interface for TenantError
+func (err TenantError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrTenant, err.Tenant, err.Err)
+}
+
+// Is returns true if the target is ErrTenant
+func (err TenantError) Is(target error) bool {
+	return target == ErrTenant
+}
+
+// Unwrap returns the error returned by the TenantProvider
+func (err TenantError) Unwrap() error {
+	return err.Err
+}
+
+// UnexpectedStatusCodeError is the error returned when a response is received
+// with a status code that is not acceptable.  It satisfies
+// errors.Is(err, ErrUnexpectedStatusCode).
+type UnexpectedStatusCodeError struct {
+	// StatusCode is the status code of the response
+	StatusCode int
+
+	// Status is the status of the response (e.g. "404 Not Found")
+	Status string
+
+	// Header holds a copy of the headers of the response
+	Header http.Header
+
+	// Body holds a copy of the body of the response, limited to the first
+	// 64 KiB; the body of the response itself may still be read in full
+	Body []byte
+
+	// Truncated is true if the body of the response exceeded 64 KiB and
+	// Body holds only the first 64 KiB
+	Truncated bool
+
+	// Problem holds any Problem Details (RFC 7807) decoded from an
+	// application/problem+json body of the response
+	Problem *ProblemDetails
+}
+
+// Error implements the error interface for UnexpectedStatusCodeError
+func (err UnexpectedStatusCodeError) Error() string {
+	if err.Problem != nil {
+		return fmt.Sprintf("%s: %s: %s", ErrUnexpectedStatusCode, err.Status, err.Problem)
+	}
+	return fmt.Sprintf("%s: %s", ErrUnexpectedStatusCode, err.Status)
+}
+
+// Is returns true if the target is ErrUnexpectedStatusCode
+func (err UnexpectedStatusCodeError) Is(target error) bool {
+	return target == ErrUnexpectedStatusCode
+}
+
+// Unwrap returns any ProblemDetails of the response
+func (err UnexpectedStatusCodeError) Unwrap() error {
+	if err.Problem == nil {
+		return nil
+	}
+	return err.Problem
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// randFloat64 is used to select requests for fault injection; it may be
+// replaced in tests for deterministic selection
+var randFloat64 = rand.Float64
+
+// FaultInjection configures the faults injected into requests by a client
+// configured using the InjectFaults option.
+type FaultInjection struct {
+	// Percent is the percentage (0-100) of request attempts into which faults
+	// are injected
+	Percent float64
+
+	// Delay, if non-zero, is the delay injected before an affected request
+	// attempt is sent
+	Delay time.Duration
+
+	// Err, if not nil, is returned (wrapped with ErrInjectedFault) by an
+	// affected request attempt instead of sending the request
+	Err error
+}
+
+// InjectFaults configures a client to inject delays and/or errors into a
+// percentage of request attempts, for chaos/resilience testing (e.g. in a
+// staging environment) without a service mesh.  Faults are never injected
+// unless this option is explicitly configured.
+//
+// An injected delay is reported to any OnWait function with a reason of
+// WaitInjectedDelay and is interrupted if the request context is cancelled.
+//
+// An injected error is treated as an error from the wrapped client; the
+// request is not sent and is subject to any retries configured for the
+// request.  Each attempt is subject to fault injection independently.
+//
+// An error is returned if Percent is not in the range 0-100.
+func InjectFaults(cfg FaultInjection) ClientOption {
+	return func(c *client) error {
+		if cfg.Percent < 0 || cfg.Percent > 100 {
+			return fmt.Errorf("http: InjectFaults option: percent must be between 0 and 100: %v", cfg.Percent)
+		}
+		c.faults = &cfg
+		return nil
+	}
+}
+
+// injectFault determines whether a fault is to be injected into a request
+// attempt, applying any configured delay and returning any configured error
+// (or any context error while delayed).
+func (c client) injectFault(ctx context.Context) error {
+	if c.faults == nil || c.faults.Percent == 0 || randFloat64()*100 >= c.faults.Percent {
+		return nil
+	}
+
+	if c.faults.Delay > 0 {
+		if err := c.wait(ctx, WaitInjectedDelay, c.faults.Delay); err != nil {
+			return err
+		}
+	}
+
+	if c.faults.Err != nil {
+		return fmt.Errorf("%w: %w", ErrInjectedFault, c.faults.Err)
+	}
+	return nil
+}
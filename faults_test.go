@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestInjectFaults(t *testing.T) {
+	// ARRANGE
+	ogRand := randFloat64
+	ogAfter := timeAfter
+	defer func() {
+		randFloat64 = ogRand
+		timeAfter = ogAfter
+	}()
+	timeAfter = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	errFault := errors.New("fault")
+	sent := 0
+	wrapped := DoerFunc(func(*http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/valid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := InjectFaults(FaultInjection{Percent: 10, Delay: time.Second})(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, *c.faults).Equals(FaultInjection{Percent: 10, Delay: time.Second})
+			},
+		},
+		{scenario: "option/invalid percent",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("name", InjectFaults(FaultInjection{Percent: 101}))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+			},
+		},
+		{scenario: "not configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				randFloat64 = func() float64 { panic("not expected") }
+				c := client{}
+
+				// ACT
+				err := c.injectFault(context.Background())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "request not selected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent = 0
+				randFloat64 = func() float64 { return 0.5 }
+				c, _ := NewClient("name", Using(wrapped), InjectFaults(FaultInjection{Percent: 50, Err: errFault}))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sent).Equals(1)
+			},
+		},
+		{scenario: "injected error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent = 0
+				randFloat64 = func() float64 { return 0.499 }
+				c, _ := NewClient("name", Using(wrapped), InjectFaults(FaultInjection{Percent: 50, Err: errFault}))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInjectedFault)
+				test.Error(t, err).Is(errFault)
+				test.That(t, sent).Equals(0)
+			},
+		},
+		{scenario: "injected error is retried",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent = 0
+				calls := 0
+				randFloat64 = func() float64 {
+					calls++
+					if calls == 1 {
+						return 0
+					}
+					return 1
+				}
+				c, _ := NewClient("name",
+					Using(wrapped),
+					MaxRetries(1),
+					Backoff(NoBackoff),
+					InjectFaults(FaultInjection{Percent: 50, Err: errFault}),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sent).Equals(1)
+			},
+		},
+		{scenario: "injected delay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent = 0
+				randFloat64 = func() float64 { return 0 }
+				waits := map[string]time.Duration{}
+				c, _ := NewClient("name",
+					Using(wrapped),
+					InjectFaults(FaultInjection{Percent: 100, Delay: time.Second}),
+					OnWait(func(reason string, d time.Duration) { waits[reason] = d }),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sent).Equals(1)
+				test.Map(t, waits).Equals(map[string]time.Duration{WaitInjectedDelay: time.Second})
+			},
+		},
+		{scenario: "context cancelled during injected delay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent = 0
+				randFloat64 = func() float64 { return 0 }
+				ctx, cancel := context.WithCancel(context.Background())
+				c, _ := NewClient("name",
+					Using(wrapped),
+					InjectFaults(FaultInjection{Percent: 100, Delay: time.Second}),
+					OnWait(func(string, time.Duration) { cancel() }),
+				)
+				defer func(og func(time.Duration) <-chan time.Time) { timeAfter = og }(timeAfter)
+				timeAfter = func(time.Duration) <-chan time.Time { return nil }
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.That(t, sent).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
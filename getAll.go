@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GetAll is a generic function that performs a GET request for each of a
+// supplied slice of paths, decoding the JSON body of each response into a value
+// of a specified type.  Any request options are applied to every request.
+//
+// Requests are performed concurrently using DoAll, with the default concurrency
+// limit.  The returned slice holds the decoded values in the same order as the
+// supplied paths; if any request fails or any response cannot be decoded, the
+// corresponding value in the slice will be the zero value of the generic type.
+//
+// The body of every response is closed, including any response to a failed
+// request.
+//
+// The returned error joins the errors of all failed requests and/or decoding,
+// each identifying the (zero-based) index of the request and the path involved.
+//
+// For finer control over the requests performed, use DoAll and UnmarshalJSON.
+func GetAll[T any](
+	ctx context.Context,
+	c HttpClient,
+	paths []string,
+	opts ...RequestOption,
+) ([]T, error) {
+	specs := make([]RequestSpec, len(paths))
+	for ix, path := range paths {
+		specs[ix] = RequestSpec{Method: http.MethodGet, Path: path, Options: opts}
+	}
+
+	// the error from DoAll is ignored as the errors of each request
+	// are reported individually, together with any decoding error
+	results, _ := DoAll(ctx, c, specs)
+
+	values := make([]T, len(results))
+	errs := []error{}
+	for ix, r := range results {
+		err := r.Err
+		switch {
+		case err == nil:
+			values[ix], err = UnmarshalJSON[T](ctx, r.Response)
+
+		// a response returned with an error (e.g. an unexpected status) is
+		// closed, releasing the connection and the context of the request
+		case r.Response != nil:
+			_ = closeBody(ctx, r.Response.Body)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %s: %w", ix, paths[ix], err))
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
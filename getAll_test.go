@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestGetAll(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "all requests succeed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{bodies: map[string]string{"/a": "1", "/b": "2", "/c": "3"}}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				result, err := GetAll[int](ctx, c, []string{"a", "b", "c"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals([]int{1, 2, 3})
+			},
+		},
+		{scenario: "request errors and decoding errors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fake := &concurrentClient{status: map[string]int{"/b": http.StatusNotFound}}
+				c, _ := NewClient("test", URL("http://hostname"), Using(fake))
+
+				// ACT
+				result, err := GetAll[string](ctx, c, []string{"a", "b"})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, result).Equals([]string{"", ""})
+			},
+		},
+		{scenario: "failed request/response is closed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rqctx context.Context
+				c, _ := NewClient("test", URL("http://hostname"), Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					rqctx = rq.Context()
+					rec := httptest.NewRecorder()
+					rec.WriteHeader(http.StatusNotFound)
+					_, _ = rec.Write([]byte("not found"))
+					return rec.Result(), nil
+				})))
+
+				// ACT
+				_, err := GetAll[string](ctx, c, []string{"a"}, request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.Error(t, rqctx.Err()).Is(context.Canceled)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestGetInto(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "successful",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{url: "http://hostname", wrapped: &fakeClient{body: []byte("content")}}
+				buf := &bytes.Buffer{}
+
+				// ACT
+				n, err := c.GetInto(ctx, "path", buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, n).Equals(7)
+				test.Bytes(t, buf.Bytes()).Equals([]byte("content"))
+			},
+		},
+		{scenario: "invalid url",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{url: "\n", wrapped: &fakeClient{}}
+
+				// ACT
+				n, err := c.GetInto(ctx, "path", io.Discard)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidURL)
+				test.That(t, n).Equals(0)
+			},
+		},
+		{scenario: "unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{url: "http://hostname", wrapped: &fakeClient{statusCode: http.StatusNotFound}}
+
+				// ACT
+				n, err := c.GetInto(ctx, "path", io.Discard)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(0)
+			},
+		},
+		{scenario: "copy error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				copyerr := errors.New("copy error")
+				c := client{url: "http://hostname", wrapped: &fakeClient{body: []byte("content")}}
+
+				og := ioCopy
+				defer func() { ioCopy = og }()
+				ioCopy = func(io.Writer, io.Reader) (int64, error) { return 3, copyerr }
+
+				// ACT
+				n, err := c.GetInto(ctx, "path", io.Discard)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(copyerr)
+				test.That(t, n).Equals(3)
+			},
+		},
+		{scenario: "content length mismatch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				// the fake client response does not set a ContentLength, so we
+				// wrap it to provide one
+				c := client{
+					url: "http://hostname",
+					wrapped: DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						r, err := (&fakeClient{body: []byte("content")}).Do(rq)
+						r.ContentLength = 7
+						return r, err
+					}),
+				}
+
+				og := ioCopy
+				defer func() { ioCopy = og }()
+				ioCopy = func(io.Writer, io.Reader) (int64, error) { return 3, nil }
+
+				// ACT
+				n, err := c.GetInto(ctx, "path", io.Discard)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrContentLengthMismatch)
+				test.That(t, n).Equals(3)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
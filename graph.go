@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Graph is a set of steps, each of which may depend on the results of steps
+// declared before it.  Steps are declared using the Step function and
+// performed by Run; each step is started as soon as all of its dependencies
+// have completed successfully, with at most a bounded number of steps being
+// performed concurrently.
+//
+// A zero value Graph is ready to use.
+type Graph struct {
+	steps []*graphStep
+	names map[string]bool
+	errs  []error
+}
+
+// Dependency identifies a step in a Graph upon which other steps may depend.
+// It is implemented by the *Future returned by Step.
+type Dependency interface {
+	node() *graphStep
+}
+
+// Future holds the result of a step in a Graph.  The result is available
+// once the Run method of the Graph has returned.
+type Future[T any] struct {
+	step  *graphStep
+	value T
+}
+
+// StepError is the error associated with a step in a Graph that failed or
+// was not performed.
+type StepError struct {
+	// Step is the name of the step
+	Step string
+
+	// Err is the error returned by the step or, if the step was not performed,
+	// an error wrapping either ErrDependencyFailed or the error of the context
+	Err error
+}
+
+// Error implements the error interface for StepError
+func (err StepError) Error() string {
+	return fmt.Sprintf("step %s: %v", err.Step, err.Err)
+}
+
+// Unwrap returns the error wrapped by the StepError
+func (err StepError) Unwrap() error {
+	return err.Err
+}
+
+// graphStep is a step in a Graph
+type graphStep struct {
+	graph *Graph
+	name  string
+	deps  []*graphStep
+	fn    func(context.Context) (any, error)
+	done  chan struct{}
+	err   error
+}
+
+// node implements the Dependency interface for *Future
+func (f *Future[T]) node() *graphStep {
+	return f.step
+}
+
+// Err returns the error of the step, if any.  If the step failed, or was not
+// performed, the error will be a StepError.
+func (f *Future[T]) Err() error {
+	return f.step.err
+}
+
+// Value returns the result of the step.  If the step failed or was not
+// performed, the zero value of the generic type is returned.
+func (f *Future[T]) Value() T {
+	return f.value
+}
+
+// Step declares a step in a Graph with a specified name, a function performing
+// the step and any dependencies on steps previously declared in the same Graph.
+// The function is called only when all dependencies have completed successfully,
+// and may safely obtain the Value of any of those dependencies.
+//
+// Step names must be unique within the Graph.  Any error in the declaration of
+// a step (a nil func, a duplicate name or a dependency on a step in a different
+// Graph) is returned by Run, wrapping ErrInvalidGraph.
+//
+// Since every dependency must have been declared before any step that depends
+// on it, a Graph cannot contain cycles.
+func Step[T any](
+	g *Graph,
+	name string,
+	fn func(context.Context) (T, error),
+	deps ...Dependency,
+) *Future[T] {
+	f := &Future[T]{}
+	f.step = &graphStep{graph: g, name: name}
+
+	invalid := func(reason string) *Future[T] {
+		g.errs = append(g.errs, fmt.Errorf("%w: step %s: %s", ErrInvalidGraph, name, reason))
+		return f
+	}
+
+	switch {
+	case fn == nil:
+		return invalid("no func")
+	case g.names[name]:
+		return invalid("duplicate step")
+	}
+	for _, dep := range deps {
+		if dep == nil || dep.node() == nil || dep.node().graph != g {
+			return invalid("dependency is not a step in the same graph")
+		}
+		f.step.deps = append(f.step.deps, dep.node())
+	}
+
+	f.step.fn = func(ctx context.Context) (result any, err error) {
+		f.value, err = fn(ctx)
+		return f.value, err
+	}
+
+	if g.names == nil {
+		g.names = map[string]bool{}
+	}
+	g.names[name] = true
+	g.steps = append(g.steps, f.step)
+
+	return f
+}
+
+// Run performs the steps in the Graph, returning when all steps have either
+// completed or been abandoned.  Each step is performed as soon as all of its
+// dependencies have completed successfully; steps that do not depend on each
+// other are performed concurrently.
+//
+// At most 8 steps are performed concurrently unless a different limit is
+// specified using the Concurrency option.  If the FailFast option is specified
+// the context passed to any step still being performed is cancelled as soon
+// as any step fails, and no further steps are started.  The context passed to a
+// step is released when the step completes unless the value of the step is an
+// *http.Response, in which case the context is released once the body of the
+// response has been read to completion or closed; a step may therefore return
+// a streamed response that remains readable after Run returns.
+//
+// A step with a dependency that failed (or was not performed) is not performed;
+// the error of such a step wraps ErrDependencyFailed.  Similarly, if the context
+// is cancelled any step not yet started is not performed; the error of such a
+// step wraps the context error.
+//
+// The returned error joins the StepError of every step that failed or was not
+// performed, in the order in which the steps were declared.  If any step was
+// not validly declared, no steps are performed and the returned error wraps
+// ErrInvalidGraph.
+func (g *Graph) Run(ctx context.Context, opts ...DoAllOption) error {
+	if len(g.errs) > 0 {
+		return errors.Join(g.errs...)
+	}
+
+	cfg := &doAllOptions{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	batch, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, step := range g.steps {
+		step.done = make(chan struct{})
+		step.err = nil
+	}
+
+	sem := make(chan struct{}, cfg.concurrency)
+	wg := sync.WaitGroup{}
+
+	perform := func(step *graphStep) {
+		defer wg.Done()
+		defer close(step.done)
+
+		fail := func(err error) {
+			step.err = StepError{Step: step.name, Err: err}
+			if cfg.failFast {
+				cancel()
+			}
+		}
+
+		for _, dep := range step.deps {
+			<-dep.done
+			if dep.err != nil {
+				fail(fmt.Errorf("%w: %s", ErrDependencyFailed, dep.name))
+				return
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-batch.Done():
+			fail(batch.Err())
+			return
+		}
+
+		if err := batch.Err(); err != nil {
+			fail(err)
+			return
+		}
+
+		stepctx, detach, release := detachableContext(ctx, batch)
+		result, err := step.fn(stepctx)
+		detach()
+		r, _ := result.(*http.Response)
+		releaseWithBody(r, release)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	wg.Add(len(g.steps))
+	for _, step := range g.steps {
+		go perform(step)
+	}
+	wg.Wait()
+
+	errs := []error{}
+	for _, step := range g.steps {
+		if step.err != nil {
+			errs = append(errs, step.err)
+		}
+	}
+	return errors.Join(errs...)
+}
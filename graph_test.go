@@ -0,0 +1,267 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestGraph(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	stepfail := errors.New("step failed")
+
+	value := func(v string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { return v, nil }
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "dependent steps receive results of dependencies",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				auth := Step(g, "auth", value("token"))
+				user := Step(g, "user", func(context.Context) (string, error) {
+					return "user(" + auth.Value() + ")", nil
+				}, auth)
+				orders := Step(g, "orders", func(context.Context) ([]string, error) {
+					return []string{"orders of " + user.Value()}, nil
+				}, user)
+				invoices := Step(g, "invoices", func(context.Context) (int, error) {
+					return len(user.Value()), nil
+				}, user)
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, user.Value()).Equals("user(token)")
+				test.Strings(t, orders.Value()).Equals([]string{"orders of user(token)"})
+				test.That(t, invoices.Value()).Equals(11)
+				test.Error(t, invoices.Err()).IsNil()
+			},
+		},
+		{scenario: "independent steps are performed concurrently",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				wg := sync.WaitGroup{}
+				wg.Add(2)
+				rendezvous := func(context.Context) (bool, error) {
+					wg.Done()
+					wg.Wait()
+					return true, nil
+				}
+				a := Step(g, "a", rendezvous)
+				b := Step(g, "b", rendezvous)
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, a.Value() && b.Value())
+			},
+		},
+		{scenario: "concurrency is bounded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				inflight := atomic.Int32{}
+				maxInflight := atomic.Int32{}
+				fn := func(context.Context) (int, error) {
+					n := inflight.Add(1)
+					defer inflight.Add(-1)
+					for {
+						mx := maxInflight.Load()
+						if n <= mx || maxInflight.CompareAndSwap(mx, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					return 0, nil
+				}
+				for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+					Step(g, name, fn)
+				}
+
+				// ACT
+				err := g.Run(ctx, Concurrency(2))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, maxInflight.Load() <= 2)
+			},
+		},
+		{scenario: "failed step",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				auth := Step(g, "auth", func(context.Context) (string, error) { return "", stepfail })
+				user := Step(g, "user", value("user"), auth)
+				orders := Step(g, "orders", value("orders"), user)
+				other := Step(g, "other", value("other"))
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).Is(stepfail)
+				test.Error(t, err).Is(ErrDependencyFailed)
+				test.That(t, err.Error()).Equals("step auth: step failed\n" +
+					"step user: dependency failed: auth\n" +
+					"step orders: dependency failed: user")
+
+				var stepErr StepError
+				test.IsTrue(t, errors.As(auth.Err(), &stepErr))
+				test.That(t, stepErr.Step).Equals("auth")
+				test.Error(t, user.Err()).Is(ErrDependencyFailed)
+				test.That(t, orders.Value()).Equals("")
+				test.That(t, other.Value()).Equals("other")
+			},
+		},
+		{scenario: "fail fast",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				Step(g, "fail", func(context.Context) (int, error) { return 0, stepfail })
+				slow := Step(g, "slow", func(ctx context.Context) (int, error) {
+					select {
+					case <-ctx.Done():
+						return 0, ctx.Err()
+					case <-time.After(time.Second):
+						return 1, nil
+					}
+				})
+
+				// ACT
+				err := g.Run(ctx, FailFast())
+
+				// ASSERT
+				test.Error(t, err).Is(stepfail)
+				test.Error(t, slow.Err()).Is(context.Canceled)
+			},
+		},
+		{scenario: "step context is released",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				step := Step(g, "step", func(ctx context.Context) (context.Context, error) { return ctx, nil })
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, step.Value().Err()).Is(context.Canceled)
+			},
+		},
+		{scenario: "step context outlives run with response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var stepctx context.Context
+				g := &Graph{}
+				step := Step(g, "step", func(ctx context.Context) (*http.Response, error) {
+					stepctx = ctx
+					return &http.Response{Body: io.NopCloser(strings.NewReader("content"))}, nil
+				})
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, stepctx.Err(), "before body is closed").IsNil()
+
+				_ = step.Value().Body.Close()
+				test.Error(t, stepctx.Err(), "after body is closed").Is(context.Canceled)
+			},
+		},
+		{scenario: "context cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				g := &Graph{}
+				performed := false
+				a := Step(g, "a", func(context.Context) (int, error) {
+					performed = true
+					return 1, nil
+				})
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.Error(t, a.Err()).Is(context.Canceled)
+				test.IsFalse(t, performed)
+			},
+		},
+		{scenario: "graph may be run more than once",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				g := &Graph{}
+				n := 0
+				a := Step(g, "a", func(context.Context) (int, error) {
+					n++
+					return n, nil
+				})
+				b := Step(g, "b", func(context.Context) (int, error) { return a.Value() * 10, nil }, a)
+
+				// ACT
+				err1 := g.Run(ctx)
+				err2 := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, b.Value()).Equals(20)
+			},
+		},
+		{scenario: "invalid graph",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				performed := false
+				other := &Graph{}
+				foreign := Step(other, "foreign", value("foreign"))
+
+				g := &Graph{}
+				Step(g, "a", func(context.Context) (int, error) {
+					performed = true
+					return 1, nil
+				})
+				Step(g, "a", value("duplicate"))
+				Step[int](g, "nil", nil)
+				Step(g, "b", value("b"), foreign)
+
+				// ACT
+				err := g.Run(ctx)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidGraph)
+				test.That(t, err.Error()).Equals("invalid graph: step a: duplicate step\n" +
+					"invalid graph: step nil: no func\n" +
+					"invalid graph: step b: dependency is not a step in the same graph")
+				test.IsFalse(t, performed)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// HAL is a resource decoded from a HAL (application/hal+json) document.  The
+// properties of the resource are decoded into Resource, with the reserved
+// _links and _embedded properties decoded into Links and Embedded:
+//
+//	order, err := http.Decode[http.HAL[Order]](ctx, r)
+//	if next, ok := order.Links.Next(); ok {
+//		...
+//	}
+type HAL[T any] struct {
+	// Resource holds the properties of the resource
+	Resource T
+
+	// Links holds the links of the resource
+	Links Links
+
+	// Embedded holds any embedded resources, keyed by relation; these
+	// are decoded using HALEmbedded
+	Embedded map[string]json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler for HAL.
+func (h *HAL[T]) UnmarshalJSON(b []byte) error {
+	var reserved struct {
+		Links    Links                      `json:"_links"`
+		Embedded map[string]json.RawMessage `json:"_embedded"`
+	}
+	if err := json.Unmarshal(b, &reserved); err != nil {
+		return err
+	}
+
+	var resource T
+	if err := json.Unmarshal(b, &resource); err != nil {
+		return err
+	}
+
+	*h = HAL[T]{Resource: resource, Links: reserved.Links, Embedded: reserved.Embedded}
+	return nil
+}
+
+// HALEmbedded decodes the resources embedded in a HAL resource with a
+// specified relation.  An embedded relation may be a single resource or an
+// array of resources; in either case a slice is returned.  If there are no
+// embedded resources with the relation, nil is returned.
+//
+//	items, err := http.HALEmbedded[OrderItem](order.Embedded, "items")
+func HALEmbedded[T any](embedded map[string]json.RawMessage, rel string) ([]HAL[T], error) {
+	raw := bytes.TrimSpace(embedded[rel])
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	if raw[0] != '[' {
+		raw = append(append([]byte{'['}, raw...), ']')
+	}
+
+	var result []HAL[T]
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("%w: embedded %q: %w", ErrInvalidJSON, rel, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestHAL(t *testing.T) {
+	// ARRANGE
+	type item struct {
+		SKU string `json:"sku"`
+	}
+	type order struct {
+		ID    string `json:"id"`
+		Total int    `json:"total"`
+	}
+	doc := []byte(`{
+		"id": "42",
+		"total": 100,
+		"_links": {"self": {"href": "/orders/42"}, "next": {"href": "/orders/43"}},
+		"_embedded": {
+			"items": [{"sku": "a", "_links": {"self": {"href": "/items/a"}}}, {"sku": "b"}],
+			"customer": {"sku": "c"}
+		}
+	}`)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "UnmarshalJSON",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut HAL[order]
+
+				// ACT
+				err := json.Unmarshal(doc, &sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.Resource).Equals(order{ID: "42", Total: 100})
+				next, _ := sut.Links.Next()
+				test.That(t, next).Equals("/orders/43")
+				test.That(t, len(sut.Embedded)).Equals(2)
+			},
+		},
+		{scenario: "UnmarshalJSON/invalid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut HAL[order]
+
+				// ACT
+				err1 := json.Unmarshal([]byte(`{"_links": []}`), &sut)
+				err2 := json.Unmarshal([]byte(`{"total": "not a number"}`), &sut)
+
+				// ASSERT
+				test.That(t, err1).IsNotNil()
+				test.That(t, err2).IsNotNil()
+			},
+		},
+		{scenario: "HALEmbedded/array",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut HAL[order]
+				_ = json.Unmarshal(doc, &sut)
+
+				// ACT
+				result, err := HALEmbedded[item](sut.Embedded, "items")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(2)
+				test.That(t, result[0].Resource).Equals(item{SKU: "a"})
+				self, _ := result[0].Links.Self()
+				test.That(t, self).Equals("/items/a")
+				test.That(t, result[1].Resource).Equals(item{SKU: "b"})
+			},
+		},
+		{scenario: "HALEmbedded/single resource",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut HAL[order]
+				_ = json.Unmarshal(doc, &sut)
+
+				// ACT
+				result, err := HALEmbedded[item](sut.Embedded, "customer")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(1)
+				test.That(t, result[0].Resource).Equals(item{SKU: "c"})
+			},
+		},
+		{scenario: "HALEmbedded/missing",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := HALEmbedded[item](nil, "items")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, result == nil)
+			},
+		},
+		{scenario: "HALEmbedded/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := HALEmbedded[item](map[string]json.RawMessage{"items": []byte(`[{"sku": 1}]`)}, "items")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
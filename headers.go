@@ -0,0 +1,28 @@
+package http
+
+import "net/http"
+
+// Headers configures headers to be set on every request initialised by the
+// client (using NewRequest, or any of the convenience methods such as Get
+// and Invoke), e.g. User-Agent or X-Api-Version.  Header keys are
+// canonicalised.
+//
+// The headers are set before any request options are applied, so that
+// options may override individual values (e.g. using request.Header).  As for
+// Auth, the headers are not set on requests initialised separately and
+// performed using Do.
+//
+// Headers may be specified more than once; the headers of each option are
+// merged with those of any previous option, replacing any value for the same
+// key.
+func Headers(headers map[string]string) ClientOption {
+	return func(c *client) error {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		for k, v := range headers {
+			c.headers.Set(k, v)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestHeaders(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option merges headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err1 := Headers(map[string]string{"user-agent": "agent/1.0", "X-Api-Version": "1"})(&c)
+				err2 := Headers(map[string]string{"x-api-version": "2"})(&c)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, c.headers.Get("User-Agent")).Equals("agent/1.0")
+				test.Strings(t, c.headers.Values("X-Api-Version")).Equals([]string{"2"})
+			},
+		},
+		{scenario: "set on requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"), Headers(map[string]string{"User-Agent": "agent/1.0"}))
+
+				// ACT
+				rq, err := c.NewRequest(ctx, "GET", "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("User-Agent")).Equals("agent/1.0")
+			},
+		},
+		{scenario: "overridden by request option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"), Headers(map[string]string{"X-Api-Version": "1"}))
+
+				// ACT
+				rq1, _ := c.NewRequest(ctx, "GET", "path", request.Header("X-Api-Version", "2"))
+				rq2, _ := c.NewRequest(ctx, "GET", "path")
+
+				// ASSERT
+				test.Strings(t, rq1.Header.Values("X-Api-Version")).Equals([]string{"2"})
+				test.Strings(t, rq2.Header.Values("X-Api-Version")).Equals([]string{"1"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Hedging configures a client to hedge idempotent requests: if an attempt to
+// perform a request has not completed within a specified delay, a second,
+// identical attempt is sent without cancelling the first.  The response to
+// whichever attempt first succeeds is returned and the other attempt is
+// cancelled.  This reduces the latency of requests to backends for which
+// a small proportion of requests are very slow (tail latency).
+//
+// An attempt succeeds if it results in a response without a 5xx (Server
+// Error) status.  If both attempts fail, the outcome of the attempt that
+// completed last is returned; if the first attempt fails before the delay
+// has elapsed, no second attempt is sent.  In either case, any retries
+// configured for the client are applied as usual, with each retry also
+// being hedged.
+//
+// Only requests with an idempotent method (GET, HEAD, OPTIONS, TRACE, PUT or
+// DELETE) are hedged, and only if any request body may be replayed (i.e. the
+// request has a GetBody function).
+//
+// The delay must be greater than zero.
+func Hedging(delay time.Duration) ClientOption {
+	return func(c *client) error {
+		if delay <= 0 {
+			return fmt.Errorf("http: Hedging option: delay must be greater than zero: %v", delay)
+		}
+		c.hedgeDelay = delay
+		return nil
+	}
+}
+
+// isIdempotent returns true if a specified request method is idempotent
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// hedgeDoer wraps a Doer, hedging each idempotent request (see: Hedging)
+type hedgeDoer struct {
+	Doer
+	delay time.Duration
+}
+
+// hedgeOutcome is the outcome of an attempt to perform a hedged request
+type hedgeOutcome struct {
+	attempt  int
+	response *http.Response
+	err      error
+}
+
+// succeeded returns true if the attempt resulted in a response without a
+// 5xx (Server Error) status
+func (o hedgeOutcome) succeeded() bool {
+	return o.err == nil && o.response != nil && !IsServerError(o.response.StatusCode)
+}
+
+// cancelBody wraps the body of the response to a hedged attempt, cancelling
+// the context of the attempt when the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer, closing the wrapped body and cancelling the
+// context of the attempt
+func (cb cancelBody) Close() error {
+	defer cb.cancel()
+	return cb.ReadCloser.Close()
+}
+
+// Do implements Doer.  A request that is not idempotent or that has a body
+// that cannot be replayed is passed to the wrapped Doer without hedging.
+func (hd hedgeDoer) Do(rq *http.Request) (*http.Response, error) {
+	if !isIdempotent(rq.Method) || (rq.Body != nil && rq.Body != http.NoBody && rq.GetBody == nil) {
+		return hd.Doer.Do(rq)
+	}
+
+	ctx := rq.Context()
+	outcomes := make(chan hedgeOutcome, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	pending := 0
+
+	send := func(rq *http.Request) {
+		actx, cancel := context.WithCancel(ctx)
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		pending++
+		go func() {
+			r, err := hd.Doer.Do(rq.WithContext(actx))
+			outcomes <- hedgeOutcome{attempt: attempt, response: r, err: err}
+		}()
+	}
+
+	// discard closes the body of the response to an attempt (if any) and
+	// cancels the context of the attempt
+	discard := func(o hedgeOutcome) {
+		if o.response != nil && o.response.Body != nil {
+			_ = closeBody(ctx, o.response.Body)
+		}
+		cancels[o.attempt]()
+	}
+
+	// complete cancels any attempt still pending, discarding its outcome when
+	// it completes, and returns a specified outcome, with the context of that
+	// attempt cancelled when the body of the response is closed
+	complete := func(o hedgeOutcome) (*http.Response, error) {
+		for ix, cancel := range cancels {
+			if ix != o.attempt {
+				cancel()
+			}
+		}
+		go func(n int) {
+			for ; n > 0; n-- {
+				discard(<-outcomes)
+			}
+		}(pending)
+
+		if o.response == nil || o.response.Body == nil {
+			cancels[o.attempt]()
+			return o.response, o.err
+		}
+		o.response.Body = cancelBody{ReadCloser: o.response.Body, cancel: cancels[o.attempt]}
+		return o.response, o.err
+	}
+
+	send(rq)
+
+	timer := time.NewTimer(hd.delay)
+	defer timer.Stop()
+	hedge := timer.C
+
+	var failed *hedgeOutcome
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			if cpy, err := CloneRequest(rq); err == nil {
+				send(cpy)
+			}
+
+		case o := <-outcomes:
+			pending--
+			if o.succeeded() || pending == 0 {
+				if failed != nil {
+					discard(*failed)
+				}
+				return complete(o)
+			}
+			failed = &o
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestHedging(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	delay := 10 * time.Millisecond
+
+	// attempt describes the behaviour of the fake server for each attempt
+	// received: the time taken to respond and the status of the response
+	type attempt struct {
+		after  time.Duration
+		status int
+	}
+
+	// server returns a hedging client responding to each attempt as
+	// described, with a body identifying the attempt; the number of attempts
+	// received and the error of the context of each attempt on completion
+	// are recorded
+	type server struct {
+		HttpClient
+		attempts atomic.Int32
+		mu       sync.Mutex
+		bodies   []string
+		ctxErrs  []error
+	}
+	newServer := func(attempts ...attempt) *server {
+		srv := &server{}
+		srv.HttpClient, _ = NewClient("name",
+			URL("http://service"),
+			Hedging(delay),
+			Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+				n := srv.attempts.Add(1)
+				a := attempts[n-1]
+
+				if rq.Body != nil {
+					body, _ := io.ReadAll(rq.Body)
+					srv.mu.Lock()
+					srv.bodies = append(srv.bodies, string(body))
+					srv.mu.Unlock()
+				}
+
+				var err error
+				select {
+				case <-rq.Context().Done():
+					err = rq.Context().Err()
+				case <-time.After(a.after):
+				}
+				srv.mu.Lock()
+				srv.ctxErrs = append(srv.ctxErrs, err)
+				srv.mu.Unlock()
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: a.status,
+					Body:       io.NopCloser(strings.NewReader("attempt " + string(rune('0'+n)))),
+				}, nil
+			})),
+		)
+		return srv
+	}
+
+	body := func(t *testing.T, r *http.Response) string {
+		t.Helper()
+		b, _ := io.ReadAll(r.Body)
+		return string(b)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/zero delay",
+			exec: func(t *testing.T) {
+				// ACT
+				err := Hedging(0)(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/positive delay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := &client{}
+
+				// ACT
+				err := Hedging(time.Second)(c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.hedgeDelay).Equals(time.Second)
+			},
+		},
+		{scenario: "attempt completes within delay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(attempt{status: http.StatusOK})
+
+				// ACT
+				r, err := srv.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("attempt 1")
+				test.That(t, srv.attempts.Load()).Equals(int32(1))
+			},
+		},
+		{scenario: "slow attempt is hedged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(
+					attempt{after: time.Second, status: http.StatusOK},
+					attempt{status: http.StatusOK},
+				)
+
+				// ACT
+				r, err := srv.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("attempt 2")
+				test.That(t, srv.attempts.Load()).Equals(int32(2))
+			},
+		},
+		{scenario: "slower attempt is cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(
+					attempt{after: time.Second, status: http.StatusOK},
+					attempt{status: http.StatusOK},
+				)
+
+				// ACT
+				_, _ = srv.Get(ctx, "path")
+
+				// ASSERT
+				for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+					srv.mu.Lock()
+					n := len(srv.ctxErrs)
+					srv.mu.Unlock()
+					if n == 2 {
+						break
+					}
+				}
+				srv.mu.Lock()
+				defer srv.mu.Unlock()
+				test.That(t, len(srv.ctxErrs)).Equals(2)
+				test.Error(t, srv.ctxErrs[0]).IsNil()
+				test.Error(t, srv.ctxErrs[1]).Is(context.Canceled)
+			},
+		},
+		{scenario: "failed attempt within delay is not hedged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(attempt{status: http.StatusServiceUnavailable})
+
+				// ACT
+				_, err := srv.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, srv.attempts.Load()).Equals(int32(1))
+			},
+		},
+		{scenario: "slow attempt fails before hedged attempt succeeds",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(
+					attempt{after: 2 * delay, status: http.StatusServiceUnavailable},
+					attempt{after: 4 * delay, status: http.StatusOK},
+				)
+
+				// ACT
+				r, err := srv.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("attempt 2")
+			},
+		},
+		{scenario: "both attempts fail",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(
+					attempt{after: 2 * delay, status: http.StatusServiceUnavailable},
+					attempt{after: 4 * delay, status: http.StatusBadGateway},
+				)
+
+				// ACT
+				r, err := srv.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusBadGateway)
+			},
+		},
+		{scenario: "request body is replayed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(
+					attempt{after: time.Second, status: http.StatusOK},
+					attempt{status: http.StatusOK},
+				)
+
+				// ACT
+				_, err := srv.Put(ctx, "path", request.JSONBody(map[string]string{"key": "value"}))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				srv.mu.Lock()
+				defer srv.mu.Unlock()
+				test.Strings(t, srv.bodies).Equals([]string{`{"key":"value"}`, `{"key":"value"}`})
+			},
+		},
+		{scenario: "non-idempotent request is not hedged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newServer(attempt{after: 2 * delay, status: http.StatusOK})
+
+				// ACT
+				r, err := srv.Post(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("attempt 1")
+				test.That(t, srv.attempts.Load()).Equals(int32(1))
+			},
+		},
+		{scenario: "isIdempotent",
+			exec: func(t *testing.T) {
+				for method, expected := range map[string]bool{
+					"":                 true,
+					http.MethodGet:     true,
+					http.MethodHead:    true,
+					http.MethodOptions: true,
+					http.MethodTrace:   true,
+					http.MethodPut:     true,
+					http.MethodDelete:  true,
+					http.MethodPost:    false,
+					http.MethodPatch:   false,
+				} {
+					test.That(t, isIdempotent(method), method).Equals(expected)
+				}
+			},
+		},
+		{scenario: "cancelBody cancels context when closed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cancelled := false
+				cb := cancelBody{ReadCloser: io.NopCloser(strings.NewReader("")), cancel: func() { cancelled = true }}
+
+				// ACT
+				err := cb.Close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, cancelled)
+			},
+		},
+		{scenario: "request error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rqerr := errors.New("request error")
+				c, _ := NewClient("name",
+					URL("http://service"),
+					Hedging(delay),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						return nil, rqerr
+					})),
+				)
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(rqerr)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+)
+
+// httpsPolicy holds the state of a client configured to UpgradeToHTTPS.
+//
+// A client is a value type; the policy is held by pointer so that hosts pinned
+// to HTTPS are shared by all copies of the client.
+type httpsPolicy struct {
+	mu sync.Mutex
+
+	// insecure identifies hosts for which cleartext requests are permitted
+	insecure map[string]bool
+
+	// pinned identifies hosts that have permanently redirected to HTTPS
+	pinned map[string]bool
+}
+
+// UpgradeToHTTPS configures a client to upgrade requests with an http:// url
+// (including any base URL of the client) to https://, preventing accidental
+// cleartext requests resulting from (e.g.) misconfiguration in production.
+//
+// Requests to any of the specified insecureHosts are not upgraded; this
+// provides an explicit escape hatch for hosts where cleartext is intended,
+// such as "localhost" in tests or development.  Hosts are identified by
+// hostname, without any port.
+//
+// In addition, when a request receives a permanent redirect (301 Moved
+// Permanently or 308 Permanent Redirect) from http:// to https:// on the same
+// host, the client remembers the host (HSTS-style pinning); subsequent requests
+// to that host are upgraded, even if it is one of the insecureHosts.
+//
+// When a url is upgraded, an explicit port of 80 is removed; any other explicit
+// port is retained.
+func UpgradeToHTTPS(insecureHosts ...string) ClientOption {
+	return func(c *client) error {
+		c.https = &httpsPolicy{
+			insecure: map[string]bool{},
+			pinned:   map[string]bool{},
+		}
+		for _, h := range insecureHosts {
+			c.https.insecure[h] = true
+		}
+		return nil
+	}
+}
+
+// upgrade upgrades the url of a specified request to https if required by
+// the policy.
+func (p *httpsPolicy) upgrade(rq *http.Request) {
+	if rq.URL.Scheme != "http" {
+		return
+	}
+
+	host := rq.URL.Hostname()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.insecure[host] && !p.pinned[host] {
+		return
+	}
+
+	rq.URL.Scheme = "https"
+	if rq.URL.Port() == "80" {
+		if rq.Host == rq.URL.Host {
+			rq.Host = host
+		}
+		rq.URL.Host = host
+	}
+}
+
+// pin records the host of any permanent redirect from http to https on the
+// same host, in a response or any redirects followed in obtaining it.
+func (p *httpsPolicy) pin(r *http.Response) {
+	for ; r != nil && r.Request != nil; r = r.Request.Response {
+		if r.StatusCode != http.StatusMovedPermanently && r.StatusCode != http.StatusPermanentRedirect {
+			continue
+		}
+
+		from := r.Request.URL
+		to, err := r.Location()
+		if err != nil || from.Scheme != "http" || to.Scheme != "https" || from.Hostname() != to.Hostname() {
+			continue
+		}
+
+		p.mu.Lock()
+		p.pinned[from.Hostname()] = true
+		p.mu.Unlock()
+	}
+}
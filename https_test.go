@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestUpgradeToHTTPS(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := UpgradeToHTTPS("localhost")(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, c.https.insecure).Equals(map[string]bool{"localhost": true})
+				test.Map(t, c.https.pinned).Equals(map[string]bool{})
+			},
+		},
+		{scenario: "upgrade",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				p := &httpsPolicy{insecure: map[string]bool{"localhost": true}, pinned: map[string]bool{"pinned": true}}
+
+				testcases := []struct {
+					url    string
+					result string
+				}{
+					{url: "http://example.com/path?q=1", result: "https://example.com/path?q=1"},
+					{url: "http://example.com:80/path", result: "https://example.com/path"},
+					{url: "http://example.com:8080/path", result: "https://example.com:8080/path"},
+					{url: "https://example.com/path", result: "https://example.com/path"},
+					{url: "http://localhost:8080/path", result: "http://localhost:8080/path"},
+					{url: "http://pinned/path", result: "https://pinned/path"},
+				}
+				for _, tc := range testcases {
+					rq, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+
+					// ACT
+					p.upgrade(rq)
+
+					// ASSERT
+					test.That(t, rq.URL.String()).Equals(tc.result, tc.url)
+					test.That(t, rq.Host).Equals(rq.URL.Host, tc.url)
+				}
+			},
+		},
+		{scenario: "pin",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				p := &httpsPolicy{insecure: map[string]bool{}, pinned: map[string]bool{}}
+
+				// redirect returns a response to a request for a specified url
+				// redirecting to a specified location, chained to any previous
+				// response
+				redirect := func(prev *http.Response, sc int, from, to string) *http.Response {
+					u, _ := url.Parse(from)
+					return &http.Response{
+						StatusCode: sc,
+						Header:     http.Header{"Location": {to}},
+						Request:    &http.Request{URL: u, Response: prev},
+					}
+				}
+
+				r := redirect(nil, http.StatusMovedPermanently, "http://a/path", "https://a/path")
+				r = redirect(r, http.StatusFound, "http://b/path", "https://b/path")
+				r = redirect(r, http.StatusPermanentRedirect, "http://c/path", "https://d/path")
+				r = redirect(r, http.StatusPermanentRedirect, "http://e:8080/path", "https://e/path")
+				r = redirect(r, http.StatusMovedPermanently, "http://f/path", "/other")
+
+				// ACT
+				p.pin(r)
+				p.pin(nil)
+
+				// ASSERT
+				test.Map(t, p.pinned).Equals(map[string]bool{"a": true, "e": true})
+			},
+		},
+		{scenario: "client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				urls := []string{}
+				c, _ := NewClient("name",
+					URL("http://insecure"),
+					UpgradeToHTTPS("insecure"),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						urls = append(urls, rq.URL.String())
+						if rq.URL.Scheme == "http" {
+							return &http.Response{
+								StatusCode: http.StatusMovedPermanently,
+								Header:     http.Header{"Location": {"https://insecure/path"}},
+								Request:    rq,
+								Body:       http.NoBody,
+							}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Request: rq, Body: http.NoBody}, nil
+					})),
+				)
+
+				// ACT
+				_, err1 := c.Get(context.Background(), "path")
+				_, err2 := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err1).Is(ErrUnexpectedStatusCode)
+				test.Error(t, err2).IsNil()
+				test.Strings(t, urls).Equals([]string{"http://insecure/path", "https://insecure/path"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
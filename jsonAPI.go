@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONAPIDocument is a JSON:API (application/vnd.api+json) document.  The
+// primary data is decoded into Data; this is typically a JSONAPIResource (for
+// a single resource) or a slice of JSONAPIResource (for a collection):
+//
+//	doc, err := http.Decode[http.JSONAPIDocument[[]http.JSONAPIResource[Order]]](ctx, r)
+//	if next, ok := doc.Links.Next(); ok {
+//		...
+//	}
+type JSONAPIDocument[T any] struct {
+	// Data holds the primary data of the document
+	Data T `json:"data"`
+
+	// Included holds any included (compound document) resources; the
+	// attributes of these are decoded using JSONAPIIncluded
+	Included []JSONAPIResource[json.RawMessage] `json:"included,omitempty"`
+
+	// Links holds the links of the document, e.g. for pagination
+	Links Links `json:"links,omitempty"`
+
+	// Meta holds any meta-information about the document
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// JSONAPIIdentifier identifies a JSON:API resource.
+type JSONAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIResource is a JSON:API resource object with attributes decoded into
+// a value of a specified type.
+type JSONAPIResource[A any] struct {
+	JSONAPIIdentifier
+
+	// Attributes holds the attributes of the resource
+	Attributes A `json:"attributes"`
+
+	// Relationships holds the relationships of the resource, keyed by name
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+
+	// Links holds the links of the resource
+	Links Links `json:"links,omitempty"`
+
+	// Meta holds any meta-information about the resource
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// Related returns the identifiers of the resources related to the resource by
+// a named relationship.  If the resource has no such relationship (or the
+// relationship has no data), nil is returned.
+func (r JSONAPIResource[A]) Related(name string) []JSONAPIIdentifier {
+	return r.Relationships[name].Data
+}
+
+// JSONAPIRelationship is a relationship of a JSON:API resource.
+type JSONAPIRelationship struct {
+	// Data identifies the related resources.  A to-one relationship is
+	// decoded as a slice with a single identifier (or none, if empty).
+	Data []JSONAPIIdentifier
+
+	// Links holds the links of the relationship, e.g. "related"
+	Links Links
+
+	// Meta holds any meta-information about the relationship
+	Meta map[string]any
+}
+
+// UnmarshalJSON implements json.Unmarshaler for JSONAPIRelationship.
+func (rel *JSONAPIRelationship) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Data  json.RawMessage `json:"data"`
+		Links Links           `json:"links"`
+		Meta  map[string]any  `json:"meta"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*rel = JSONAPIRelationship{Links: raw.Links, Meta: raw.Meta}
+
+	data := bytes.TrimSpace(raw.Data)
+	switch {
+	case len(data) == 0 || bytes.Equal(data, []byte("null")):
+		return nil
+	case data[0] == '[':
+		return json.Unmarshal(data, &rel.Data)
+	default:
+		var id JSONAPIIdentifier
+		if err := json.Unmarshal(data, &id); err != nil {
+			return err
+		}
+		rel.Data = []JSONAPIIdentifier{id}
+		return nil
+	}
+}
+
+// JSONAPIIncluded returns the included resource in a JSON:API document with a
+// specified identifier, with attributes decoded into a value of a specified
+// type.  If no such resource is included, false is returned.
+//
+//	for _, id := range order.Related("customer") {
+//		customer, ok, err := http.JSONAPIIncluded[Customer](doc.Included, id)
+//		...
+//	}
+func JSONAPIIncluded[A any](included []JSONAPIResource[json.RawMessage], id JSONAPIIdentifier) (JSONAPIResource[A], bool, error) {
+	for _, r := range included {
+		if r.JSONAPIIdentifier != id {
+			continue
+		}
+
+		result := JSONAPIResource[A]{
+			JSONAPIIdentifier: r.JSONAPIIdentifier,
+			Relationships:     r.Relationships,
+			Links:             r.Links,
+			Meta:              r.Meta,
+		}
+		if len(r.Attributes) > 0 {
+			if err := json.Unmarshal(r.Attributes, &result.Attributes); err != nil {
+				return result, true, fmt.Errorf("%w: included %s/%s: %w", ErrInvalidJSON, id.Type, id.ID, err)
+			}
+		}
+		return result, true, nil
+	}
+	return JSONAPIResource[A]{}, false, nil
+}
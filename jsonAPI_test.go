@@ -0,0 +1,129 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestJSONAPI(t *testing.T) {
+	// ARRANGE
+	type order struct {
+		Total int `json:"total"`
+	}
+	type customer struct {
+		Name string `json:"name"`
+	}
+	doc := `{
+		"data": [{
+			"type": "orders", "id": "1",
+			"attributes": {"total": 100},
+			"relationships": {
+				"customer": {"data": {"type": "customers", "id": "9"}, "links": {"related": "/orders/1/customer"}},
+				"items": {"data": [{"type": "items", "id": "a"}, {"type": "items", "id": "b"}]},
+				"notes": {"data": null}
+			},
+			"links": {"self": "/orders/1"}
+		}],
+		"included": [{"type": "customers", "id": "9", "attributes": {"name": "Jane"}}],
+		"links": {"next": "/orders?page[number]=2"},
+		"meta": {"total": 1}
+	}`
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "Decode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := &http.Response{
+					Header: http.Header{"Content-Type": {MediaTypeJSONAPI}},
+					Body:   io.NopCloser(bytes.NewReader([]byte(doc))),
+				}
+
+				// ACT
+				result, err := Decode[JSONAPIDocument[[]JSONAPIResource[order]]](context.Background(), r)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result.Data)).Equals(1)
+
+				o := result.Data[0]
+				test.That(t, o.JSONAPIIdentifier).Equals(JSONAPIIdentifier{Type: "orders", ID: "1"})
+				test.That(t, o.Attributes).Equals(order{Total: 100})
+				test.That(t, o.Related("customer")).Equals([]JSONAPIIdentifier{{Type: "customers", ID: "9"}})
+				test.That(t, o.Related("items")).Equals([]JSONAPIIdentifier{{Type: "items", ID: "a"}, {Type: "items", ID: "b"}})
+				test.IsTrue(t, o.Related("notes") == nil, "empty relationship")
+				test.IsTrue(t, o.Related("missing") == nil, "missing relationship")
+
+				related, _ := o.Relationships["customer"].Links.Href("related")
+				test.That(t, related).Equals("/orders/1/customer")
+				self, _ := o.Links.Self()
+				test.That(t, self).Equals("/orders/1")
+				next, _ := result.Links.Next()
+				test.That(t, next).Equals("/orders?page[number]=2")
+				test.That(t, result.Meta).Equals(map[string]any{"total": float64(1)})
+
+				c, ok, err := JSONAPIIncluded[customer](result.Included, o.Related("customer")[0])
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, ok, "customer included")
+				test.That(t, c.Attributes).Equals(customer{Name: "Jane"})
+			},
+		},
+		{scenario: "JSONAPIIncluded/not included",
+			exec: func(t *testing.T) {
+				// ACT
+				_, ok, err := JSONAPIIncluded[customer](nil, JSONAPIIdentifier{Type: "customers", ID: "9"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, ok)
+			},
+		},
+		{scenario: "JSONAPIIncluded/invalid attributes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				included := []JSONAPIResource[json.RawMessage]{{
+					JSONAPIIdentifier: JSONAPIIdentifier{Type: "customers", ID: "9"},
+					Attributes:        json.RawMessage(`{"name": 42}`),
+				}}
+
+				// ACT
+				_, ok, err := JSONAPIIncluded[customer](included, JSONAPIIdentifier{Type: "customers", ID: "9"})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.IsTrue(t, ok)
+			},
+		},
+		{scenario: "JSONAPIRelationship/invalid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut JSONAPIRelationship
+
+				// ACT
+				errs := []error{
+					json.Unmarshal([]byte(`[]`), &sut),
+					json.Unmarshal([]byte(`{"data": 42}`), &sut),
+					json.Unmarshal([]byte(`{"data": [42]}`), &sut),
+				}
+
+				// ASSERT
+				for _, err := range errs {
+					test.That(t, err).IsNotNil()
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/blugnu/http/request"
+)
+
+// GetJSON is a generic function that performs a GET request using a client,
+// decoding the JSON body of the response into a value of a specified type.
+//
+// The request accepts application/json and requires a non-empty response body;
+// any request options are applied after these.  As for any request, a response
+// with a status other than http.StatusOK (or any status identified as
+// acceptable using request.AcceptStatus) results in an error.
+//
+// If the request fails or the response cannot be decoded, the zero value of the
+// generic type is returned together with the error.  The zero value is also
+// returned (without error) for a 304 Not Modified response to a conditional
+// request (see: request.IfNoneMatch and GetJSONWithMeta).
+func GetJSON[T any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	opts ...RequestOption,
+) (T, error) {
+	v, _, err := getJSON[T](ctx, c, path, opts)
+	return v, err
+}
+
+// getJSON performs a GET request as for GetJSON, returning the response
+// together with the decoded value
+func getJSON[T any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	opts []RequestOption,
+) (T, *http.Response, error) {
+	opts = append([]RequestOption{
+		request.AcceptJSON(),
+		request.ResponseBodyRequired(),
+	}, opts...)
+
+	r, err := c.Get(ctx, path, opts...)
+	if err != nil || r.StatusCode == http.StatusNotModified {
+		return *new(T), r, err
+	}
+	v, err := UnmarshalJSON[T](ctx, r)
+	return v, r, err
+}
+
+// PostJSON is a generic function that performs a POST request using a client,
+// with a body containing a supplied value marshalled as JSON, decoding the JSON
+// body of the response into a value of a specified type.
+//
+// In addition to http.StatusOK, responses with a status of http.StatusCreated
+// or http.StatusNoContent are accepted.  If the response body is empty, the
+// zero value of the response type is returned.
+//
+// See GetJSON for details of the options and errors.
+func PostJSON[TReq any, TResp any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	body TReq,
+	opts ...RequestOption,
+) (TResp, error) {
+	return sendJSON[TResp](ctx, c.Post, path, body, opts)
+}
+
+// PutJSON is a generic function that performs a PUT request using a client,
+// with a body containing a supplied value marshalled as JSON, decoding the JSON
+// body of the response into a value of a specified type.
+//
+// See PostJSON for details of the statuses accepted and GetJSON for details of
+// the options and errors.
+func PutJSON[TReq any, TResp any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	body TReq,
+	opts ...RequestOption,
+) (TResp, error) {
+	return sendJSON[TResp](ctx, c.Put, path, body, opts)
+}
+
+// sendJSON performs a request with a JSON body using a supplied client method,
+// decoding any JSON body of the response
+func sendJSON[TResp any](
+	ctx context.Context,
+	method func(context.Context, string, ...RequestOption) (*http.Response, error),
+	path string,
+	body any,
+	opts []RequestOption,
+) (TResp, error) {
+	opts = append([]RequestOption{
+		request.AcceptJSON(),
+		request.AcceptStatus(http.StatusCreated, http.StatusNoContent),
+		request.JSONBody(body),
+	}, opts...)
+
+	r, err := method(ctx, path, opts...)
+	if err != nil {
+		return *new(TResp), err
+	}
+	if r.Body == http.NoBody {
+		return *new(TResp), nil
+	}
+	return UnmarshalJSON[TResp](ctx, r)
+}
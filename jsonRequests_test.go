@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestJSONRequests(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	// server returns a client responding to every request with a specified
+	// status and body, recording the request and its body
+	server := func(status int, body string, rq **http.Request, rqbody *string) HttpClient {
+		c, _ := NewClient("name", URL("https://example.com"),
+			Using(DoerFunc(func(r *http.Request) (*http.Response, error) {
+				*rq = r
+				if r.Body != nil {
+					b, _ := io.ReadAll(r.Body)
+					*rqbody = string(b)
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+			})),
+		)
+		return c
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "GetJSON/ok",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusOK, `{"id":1,"name":"one"}`, &rq, &body)
+
+				// ACT
+				result, err := GetJSON[item](ctx, c, "items/1")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(item{ID: 1, Name: "one"})
+				test.That(t, rq.Method).Equals(http.MethodGet)
+				test.That(t, rq.Header.Get("Accept")).Equals("application/json")
+			},
+		},
+		{scenario: "GetJSON/unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusNotFound, `{"id":1}`, &rq, &body)
+
+				// ACT
+				result, err := GetJSON[item](ctx, c, "items/1")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, result).Equals(item{})
+			},
+		},
+		{scenario: "GetJSON/empty body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusOK, "", &rq, &body)
+
+				// ACT
+				_, err := GetJSON[item](ctx, c, "items/1")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrNoResponseBody)
+			},
+		},
+		{scenario: "GetJSON/invalid json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusOK, "not json", &rq, &body)
+
+				// ACT
+				_, err := GetJSON[item](ctx, c, "items/1")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "PostJSON/created",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusCreated, `{"id":2,"name":"two"}`, &rq, &body)
+
+				// ACT
+				result, err := PostJSON[item, item](ctx, c, "items", item{Name: "two"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(item{ID: 2, Name: "two"})
+				test.That(t, rq.Method).Equals(http.MethodPost)
+				test.That(t, rq.Header.Get("Content-Type")).Equals("application/json")
+				sent := item{}
+				_ = json.Unmarshal([]byte(body), &sent)
+				test.That(t, sent).Equals(item{Name: "two"})
+			},
+		},
+		{scenario: "PostJSON/no content",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusNoContent, "", &rq, &body)
+
+				// ACT
+				result, err := PostJSON[item, *item](ctx, c, "items", item{Name: "two"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "PostJSON/unmarshallable body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusOK, "", &rq, &body)
+
+				// ACT
+				_, err := PostJSON[func(), item](ctx, c, "items", func() {})
+
+				// ASSERT
+				var typeErr *json.UnsupportedTypeError
+				test.IsTrue(t, errors.As(err, &typeErr), "is a json.UnsupportedTypeError")
+				test.That(t, rq).IsNil()
+			},
+		},
+		{scenario: "PutJSON/ok",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var rq *http.Request
+				var body string
+				c := server(http.StatusOK, `{"id":3,"name":"three"}`, &rq, &body)
+
+				// ACT
+				result, err := PutJSON[item, item](ctx, c, "items/3", item{ID: 3, Name: "three"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(item{ID: 3, Name: "three"})
+				test.That(t, rq.Method).Equals(http.MethodPut)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
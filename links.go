@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// Link is a hypermedia link, as found in HAL and JSON:API documents.
+type Link struct {
+	// Href is the url of the linked resource; it may be a URI template if
+	// Templated is true
+	Href string `json:"href"`
+
+	// Templated indicates that Href is a URI template (HAL)
+	Templated bool `json:"templated,omitempty"`
+
+	// Type is a hint of the media type of the linked resource (HAL)
+	Type string `json:"type,omitempty"`
+
+	// Name identifies a link amongst others with the same relation (HAL)
+	Name string `json:"name,omitempty"`
+
+	// Title is a human readable label for the link (HAL)
+	Title string `json:"title,omitempty"`
+
+	// Meta holds any meta-information about the link (JSON:API)
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// Links holds the links of a resource or document, keyed by relation (e.g.
+// "self", "next").  A relation may have more than one link.
+//
+// When unmarshalled from JSON, each relation may be a string (a url), a link
+// object or an array of link objects; this accommodates the representations
+// used by both HAL and JSON:API.
+type Links map[string][]Link
+
+// UnmarshalJSON implements json.Unmarshaler for Links.
+func (l *Links) UnmarshalJSON(b []byte) error {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	links := make(Links, len(raw))
+	for rel, v := range raw {
+		v = bytes.TrimSpace(v)
+		if len(v) == 0 || bytes.Equal(v, []byte("null")) {
+			continue
+		}
+
+		switch v[0] {
+		case '"':
+			var href string
+			if err := json.Unmarshal(v, &href); err != nil {
+				return fmt.Errorf("link %q: %w", rel, err)
+			}
+			links[rel] = []Link{{Href: href}}
+
+		case '[':
+			var ls []Link
+			if err := json.Unmarshal(v, &ls); err != nil {
+				return fmt.Errorf("link %q: %w", rel, err)
+			}
+			links[rel] = ls
+
+		default:
+			var link Link
+			if err := json.Unmarshal(v, &link); err != nil {
+				return fmt.Errorf("link %q: %w", rel, err)
+			}
+			links[rel] = []Link{link}
+		}
+	}
+	*l = links
+	return nil
+}
+
+// Href returns the href of the (first) link with a specified relation.  If
+// there is no such link, an empty string and false are returned.
+func (l Links) Href(rel string) (string, bool) {
+	if ls := l[rel]; len(ls) > 0 && ls[0].Href != "" {
+		return ls[0].Href, true
+	}
+	return "", false
+}
+
+// Self returns the href of the "self" link, if any.
+func (l Links) Self() (string, bool) { return l.Href("self") }
+
+// First returns the href of the "first" (page) link, if any.
+func (l Links) First() (string, bool) { return l.Href("first") }
+
+// Prev returns the href of the "prev" (page) link, if any.
+func (l Links) Prev() (string, bool) { return l.Href("prev") }
+
+// Next returns the href of the "next" (page) link, if any.
+func (l Links) Next() (string, bool) { return l.Href("next") }
+
+// Last returns the href of the "last" (page) link, if any.
+func (l Links) Last() (string, bool) { return l.Href("last") }
+
+// FollowLink performs a GET request for the url of a link (e.g. the href of a
+// "next" link, to obtain the next page of a collection) using a specified
+// client.  Any request options are applied to the request.
+//
+// A relative href is resolved against the base url of the client; unlike the
+// path supplied to Get, the href may include a query string.
+func FollowLink(ctx context.Context, c HttpClient, href string, opts ...RequestOption) (*http.Response, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, errorcontext.Errorf(ctx, "http.FollowLink: %w", InvalidURLError{URL: href, Err: err})
+	}
+
+	if b, ok := c.(baseURLer); ok && !u.IsAbs() {
+		base, err := url.Parse(b.baseURL())
+		if err != nil {
+			return nil, errorcontext.Errorf(ctx, "http.FollowLink: %w", InvalidURLError{URL: b.baseURL(), Err: err})
+		}
+		u = base.ResolveReference(u)
+	}
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errorcontext.Errorf(ctx, "http.FollowLink: %w: %w", ErrInitialisingRequest, err)
+	}
+	return c.DoWith(rq, opts...)
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestLinks(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "UnmarshalJSON",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut Links
+
+				// ACT
+				err := json.Unmarshal([]byte(`{
+					"self": "/orders?page=2",
+					"next": {"href": "/orders?page=3", "meta": {"count": 10}},
+					"item": [{"href": "/orders/1", "name": "1"}, {"href": "/orders/2", "name": "2"}],
+					"prev": null
+				}`), &sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut).Equals(Links{
+					"self": {{Href: "/orders?page=2"}},
+					"next": {{Href: "/orders?page=3", Meta: map[string]any{"count": float64(10)}}},
+					"item": {{Href: "/orders/1", Name: "1"}, {Href: "/orders/2", Name: "2"}},
+				})
+			},
+		},
+		{scenario: "UnmarshalJSON/invalid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sut Links
+
+				// ACT
+				errs := []error{
+					json.Unmarshal([]byte(`[]`), &sut),
+					json.Unmarshal([]byte(`{"self": 42}`), &sut),
+					json.Unmarshal([]byte(`{"self": [42]}`), &sut),
+				}
+
+				// ASSERT
+				for _, err := range errs {
+					test.That(t, err).IsNotNil()
+				}
+			},
+		},
+		{scenario: "accessors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := Links{
+					"self":  {{Href: "self"}},
+					"first": {{Href: "first"}},
+					"prev":  {{Href: "prev"}},
+					"next":  {{Href: "next"}},
+					"last":  {{Href: "last"}},
+					"empty": {},
+				}
+
+				// ACT & ASSERT
+				for _, fn := range []func() (string, bool){sut.Self, sut.First, sut.Prev, sut.Next, sut.Last} {
+					_, ok := fn()
+					test.IsTrue(t, ok)
+				}
+				href, _ := sut.Next()
+				test.That(t, href).Equals("next")
+
+				_, ok := sut.Href("empty")
+				test.IsFalse(t, ok, "empty relation")
+				_, ok = sut.Href("missing")
+				test.IsFalse(t, ok, "missing relation")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestFollowLink(t *testing.T) {
+	// ARRANGE
+	var got string
+	c, _ := NewClient("name",
+		URL("https://api.example.com/v1/"),
+		Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+			got = rq.URL.String()
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})),
+	)
+
+	testcases := []struct {
+		scenario string
+		href     string
+		result   string
+		err      error
+	}{
+		{scenario: "relative", href: "orders?page=2", result: "https://api.example.com/v1/orders?page=2"},
+		{scenario: "absolute path", href: "/v2/orders?page=2", result: "https://api.example.com/v2/orders?page=2"},
+		{scenario: "absolute url", href: "https://other.example.com/orders", result: "https://other.example.com/orders"},
+		{scenario: "invalid", href: ":", err: ErrInvalidURL},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			got = ""
+
+			// ACT
+			_, err := FollowLink(context.Background(), c, tc.href)
+
+			// ASSERT
+			test.Error(t, err).Is(tc.err)
+			test.That(t, got).Equals(tc.result)
+		})
+	}
+}
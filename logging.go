@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/blugnu/http/request"
+)
+
+// redacted replaces the value of any header redacted from logs
+const redacted = "[REDACTED]"
+
+// LoggingOption is a function that applies an option to the logging of
+// requests by a client (see: Logging)
+type LoggingOption func(*logging)
+
+// logging holds the logging configuration of a client
+type logging struct {
+	logger       *slog.Logger
+	level        slog.Level
+	errorLevel   slog.Level
+	headers      bool
+	redactHeader map[string]bool
+}
+
+// Logging configures a client to log every request performed using Do (and
+// so using any of the convenience methods such as Get), identifying the
+// method, (redacted) url, response status, duration, number of attempts and
+// any error.  Any endpoint name and any fields attached to the request using
+// request.LogFields are also included.
+//
+// If the logger is nil, slog.Default() is used.  By default requests are
+// logged at slog.LevelInfo and requests that fail at slog.LevelError; request
+// headers are not logged (see: LogLevels, LogRequestHeaders).
+func Logging(logger *slog.Logger, opts ...LoggingOption) ClientOption {
+	return func(c *client) error {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		c.logging = &logging{
+			logger:     logger,
+			level:      slog.LevelInfo,
+			errorLevel: slog.LevelError,
+			redactHeader: map[string]bool{
+				"Authorization":       true,
+				"Cookie":              true,
+				"Proxy-Authorization": true,
+			},
+		}
+		for _, opt := range opts {
+			opt(c.logging)
+		}
+		return nil
+	}
+}
+
+// LogLevels sets the levels at which requests are logged: successful requests
+// are logged at one level and requests that fail at another.
+func LogLevels(success slog.Level, failure slog.Level) LoggingOption {
+	return func(l *logging) {
+		l.level = success
+		l.errorLevel = failure
+	}
+}
+
+// LogRequestHeaders configures the headers of each request to be logged.  The
+// values of Authorization, Proxy-Authorization and Cookie headers, and of any
+// additional headers specified, are redacted.
+func LogRequestHeaders(redact ...string) LoggingOption {
+	return func(l *logging) {
+		l.headers = true
+		for _, h := range redact {
+			l.redactHeader[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+}
+
+// log logs a request performed by a client, together with the response
+// and/or error.
+func (l *logging) log(
+	ctx context.Context,
+	client string,
+	rq *http.Request,
+	r *http.Response,
+	d time.Duration,
+	attempts uint,
+	err error,
+) {
+	level := l.level
+	if err != nil {
+		level = l.errorLevel
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("client", client),
+		slog.String("method", rq.Method),
+		slog.String("url", rq.URL.Redacted()),
+	}
+	if r != nil {
+		attrs = append(attrs, slog.Int("status", r.StatusCode))
+	}
+	attrs = append(attrs,
+		slog.Duration("duration", d),
+		slog.Uint64("attempts", uint64(attempts)),
+	)
+	if name := EndpointName(ctx); name != "" {
+		attrs = append(attrs, slog.String("endpoint", name))
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if l.headers {
+		attrs = append(attrs, l.headerAttrs(rq.Header))
+	}
+	if fields := request.LogFieldsFromContext(ctx); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			attrs = append(attrs, slog.Any(k, fields[k]))
+		}
+	}
+
+	l.logger.LogAttrs(ctx, level, "http request", attrs...)
+}
+
+// headerAttrs returns a group of attributes holding the values of specified
+// headers, with sensitive values redacted
+func (l *logging) headerAttrs(h http.Header) slog.Attr {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]any, 0, len(keys))
+	for _, k := range keys {
+		v := h[k]
+		if l.redactHeader[http.CanonicalHeaderKey(k)] {
+			v = []string{redacted}
+		}
+		if len(v) == 1 {
+			attrs = append(attrs, slog.String(k, v[0]))
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	return slog.Group("headers", attrs...)
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/blugnu/errorcontext"
+	"github.com/blugnu/http/request"
+)
+
+// LongPoll repeatedly performs GET requests to a specified path, for apis that
+// support long-polling rather than streaming.  The results of each poll are
+// delivered on the returned channel, which is closed when the context is done
+// (or if the request for a poll cannot be initialised).
+//
+// An empty poll, i.e. a 204 No Content or 304 Not Modified response or a poll
+// that timed out without a response, is not delivered; any other response (or
+// error) is delivered as a Result.  The body of each response delivered must be
+// consumed (or closed) by the receiver; the Result methods may be used for this.
+//
+// Polls are performed at the interval specified, with a jitter of +/-10%; each
+// interval starts when the preceding poll completes and is reported to any
+// OnWait function configured on the client, with the reason WaitPollInterval.
+//
+// Any request options are applied to the request for every poll.  Conditional
+// headers are propagated from the most recent non-empty response to each
+// subsequent poll (ETag as If-None-Match and Last-Modified as
+// If-Modified-Since), together with any cursor (see: request.PollCursor).  The
+// request for the next poll is initialised once the response to the preceding
+// poll has been delivered.
+func (c client) LongPoll(
+	ctx context.Context,
+	path string,
+	interval time.Duration,
+	opts ...RequestOption,
+) <-chan Result {
+	opts = append(append([]RequestOption{}, opts...), request.AcceptStatus(http.StatusNoContent, http.StatusNotModified))
+
+	ch := make(chan Result)
+	go func() {
+		defer close(ch)
+
+		rq, err := c.nextPoll(ctx, path, opts, nil)
+		for err == nil {
+			r, doerr := c.Do(rq.Clone(rq.Context()))
+			if ctx.Err() != nil {
+				if r != nil {
+					_ = closeBody(ctx, r.Body)
+				}
+				return
+			}
+
+			switch {
+			case doerr == nil && (r.StatusCode == http.StatusNoContent || r.StatusCode == http.StatusNotModified):
+				_ = r.Body.Close()
+
+			case doerr != nil && IsTimeout(doerr):
+				// NO-OP: the poll timed out without a response
+
+			default:
+				// the response is delivered before the request for the next
+				// poll is derived from a copy of the response (without the
+				// body, which belongs to the receiver)
+				var prev *http.Response
+				if doerr == nil {
+					prev = withoutBody(r)
+				}
+				if !sendResult(ctx, ch, Result{Response: r, Err: doerr}) {
+					return
+				}
+				if prev != nil {
+					rq, err = c.nextPoll(ctx, path, opts, prev)
+				}
+			}
+			if err != nil {
+				break
+			}
+
+			if d := pollInterval(interval); d > 0 {
+				if c.wait(ctx, WaitPollInterval, d) != nil {
+					return
+				}
+			}
+		}
+		sendResult(ctx, ch, Result{Err: err})
+	}()
+	return ch
+}
+
+// nextPoll initialises the request for a poll, propagating any conditional
+// headers and cursor from the response to a previous poll (if any)
+func (c client) nextPoll(
+	ctx context.Context,
+	path string,
+	opts []RequestOption,
+	prev *http.Response,
+) (*http.Request, error) {
+	rq, err := c.NewRequest(ctx, http.MethodGet, path, opts...)
+	if err != nil || prev == nil {
+		return rq, err
+	}
+
+	if etag := prev.Header.Get("ETag"); etag != "" {
+		rq.Header.Set("If-None-Match", etag)
+	}
+	if lm := prev.Header.Get("Last-Modified"); lm != "" {
+		rq.Header.Set("If-Modified-Since", lm)
+	}
+	if cfg, _ := request.ConfigFromContext(rq.Context()); cfg.PollCursor != nil {
+		if err := cfg.PollCursor(prev, rq); err != nil {
+			return nil, errorcontext.Errorf(ctx, "LongPoll: cursor: %w", err)
+		}
+	}
+	return rq, nil
+}
+
+// withoutBody returns a copy of a response with a copy of the headers of the
+// response and no body
+func withoutBody(r *http.Response) *http.Response {
+	cpy := *r
+	cpy.Header = r.Header.Clone()
+	cpy.Trailer = nil
+	cpy.Body = http.NoBody
+	cpy.ContentLength = 0
+	return &cpy
+}
+
+// pollInterval returns a specified interval with a jitter of +/-10%
+func pollInterval(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	j := int64(d / 10)
+	return d - time.Duration(j) + time.Duration(randInt63n(2*j+1))
+}
+
+// sendResult sends a result on a channel unless the context is done first, in
+// which case the body of any response is closed and false is returned
+func sendResult(ctx context.Context, ch chan<- Result, res Result) bool {
+	select {
+	case ch <- res:
+		return true
+	case <-ctx.Done():
+		if res.Response != nil {
+			_ = res.Response.Body.Close()
+		}
+		return false
+	}
+}
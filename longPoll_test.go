@@ -0,0 +1,286 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestLongPoll(t *testing.T) {
+	// ARRANGE
+	ogAfter := timeAfter
+	ogRand := randInt63n
+	defer func() {
+		timeAfter = ogAfter
+		randInt63n = ogRand
+	}()
+	timeAfter = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	randInt63n = func(n int64) int64 { return n / 2 }
+
+	// respond returns a response with a specified status, headers and body
+	respond := func(status int, body string, hdr ...string) *http.Response {
+		r := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+		for i := 0; i < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		return r
+	}
+
+	// server returns a Doer responding to each request in turn with the
+	// results of a sequence of functions, recording the requests
+	server := func(rqs *[]*http.Request, fns ...func() (*http.Response, error)) Doer {
+		return DoerFunc(func(rq *http.Request) (*http.Response, error) {
+			*rqs = append(*rqs, rq)
+			if len(*rqs) > len(fns) {
+				<-rq.Context().Done()
+				return nil, rq.Context().Err()
+			}
+			return fns[len(*rqs)-1]()
+		})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "empty polls are not delivered",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rqs := []*http.Request{}
+				waits := []time.Duration{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) { return respond(http.StatusNoContent, ""), nil },
+						func() (*http.Response, error) { return nil, context.DeadlineExceeded },
+						func() (*http.Response, error) { return respond(http.StatusOK, "event", "ETag", `"1"`), nil },
+						func() (*http.Response, error) { return respond(http.StatusNotModified, ""), nil },
+						func() (*http.Response, error) { return respond(http.StatusOK, "next"), nil },
+					)),
+					OnWait(func(reason string, d time.Duration) {
+						test.That(t, reason).Equals(WaitPollInterval)
+						waits = append(waits, d)
+					}),
+				)
+
+				// ACT
+				ch := c.LongPoll(ctx, "events", time.Second)
+				r1 := <-ch
+				r2 := <-ch
+				cancel()
+				_, open := <-ch
+
+				// ASSERT
+				body, err := r1.Text()
+				test.Error(t, err).IsNil()
+				test.That(t, body).Equals("event")
+				body, err = r2.Text()
+				test.Error(t, err).IsNil()
+				test.That(t, body).Equals("next")
+				test.IsFalse(t, open, "channel open")
+
+				test.That(t, len(rqs) >= 5).Equals(true)
+				test.That(t, rqs[2].Header.Get("If-None-Match")).Equals("")
+				test.That(t, rqs[3].Header.Get("If-None-Match")).Equals(`"1"`)
+				test.That(t, rqs[4].Header.Get("If-None-Match")).Equals(`"1"`)
+				test.That(t, waits[0]).Equals(time.Second)
+			},
+		},
+		{scenario: "errors are delivered",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rqs := []*http.Request{}
+				connerr := errors.New("connection error")
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) { return respond(http.StatusBadRequest, "bad"), nil },
+						func() (*http.Response, error) { return nil, connerr },
+					)),
+				)
+
+				// ACT
+				ch := c.LongPoll(ctx, "events", 0)
+				r1 := <-ch
+				r2 := <-ch
+				cancel()
+
+				// ASSERT
+				test.Error(t, r1.Err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r1.StatusIs(http.StatusBadRequest)).Equals(true)
+				test.Error(t, r2.Err).Is(connerr)
+				test.That(t, r2.Response).IsNil()
+			},
+		},
+		{scenario: "cancelled while response in flight",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rqs := []*http.Request{}
+				body := &drainBody{Reader: strings.NewReader("event")}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) {
+							cancel()
+							return respond(http.StatusOK, "event"), nil
+						},
+					)),
+					TransformResponse(func(r *http.Response) (*http.Response, error) {
+						r.Body = body
+						return r, nil
+					}),
+				)
+
+				// ACT
+				results := []Result{}
+				for r := range c.LongPoll(ctx, "events", 0) {
+					results = append(results, r)
+				}
+
+				// ASSERT
+				test.That(t, len(results)).Equals(0)
+				test.IsTrue(t, body.closed, "body is closed")
+			},
+		},
+		{scenario: "cursor is propagated",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rqs := []*http.Request{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) { return respond(http.StatusOK, "a", "X-Cursor", "1"), nil },
+						func() (*http.Response, error) { return respond(http.StatusOK, "b", "X-Cursor", "2"), nil },
+					)),
+				)
+
+				// ACT
+				ch := c.LongPoll(ctx, "events", 0, request.PollCursor(func(prev *http.Response, next *http.Request) error {
+					return request.QueryP("after", prev.Header.Get("X-Cursor"))(next)
+				}))
+				<-ch
+				<-ch
+				cancel()
+				for range ch {
+				}
+
+				// ASSERT
+				test.That(t, rqs[0].URL.RawQuery).Equals("")
+				test.That(t, rqs[1].URL.RawQuery).Equals("after=1")
+				test.That(t, rqs[2].URL.RawQuery).Equals("after=2")
+			},
+		},
+		{scenario: "cursor does not consume the body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				rqs := []*http.Request{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) { return respond(http.StatusOK, "content", "X-Cursor", "1"), nil },
+					)),
+				)
+				cursor := make(chan string, 1)
+
+				// ACT
+				ch := c.LongPoll(ctx, "events", 0, request.PollCursor(func(prev *http.Response, next *http.Request) error {
+					b, err := io.ReadAll(prev.Body)
+					cursor <- prev.Header.Get("X-Cursor") + ":" + string(b)
+					return err
+				}))
+				r1 := <-ch
+				body, err := io.ReadAll(r1.Response.Body)
+				cursorValue := <-cursor
+				cancel()
+				for range ch {
+				}
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("content")
+				test.That(t, cursorValue).Equals("1:")
+			},
+		},
+		{scenario: "cursor error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cursorerr := errors.New("cursor error")
+				rqs := []*http.Request{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(server(&rqs,
+						func() (*http.Response, error) { return respond(http.StatusOK, "a"), nil },
+					)),
+				)
+
+				// ACT
+				ch := c.LongPoll(context.Background(), "events", 0, request.PollCursor(func(*http.Response, *http.Request) error {
+					return cursorerr
+				}))
+				r1 := <-ch
+				r2 := <-ch
+				_, open := <-ch
+
+				// ASSERT
+				test.Error(t, r1.Err).IsNil()
+				test.Error(t, r2.Err).Is(cursorerr)
+				test.IsFalse(t, open, "channel open")
+			},
+		},
+		{scenario: "invalid request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"))
+				opterr := errors.New("option error")
+
+				// ACT
+				ch := c.LongPoll(context.Background(), "events", 0, func(*http.Request) error { return opterr })
+				r1 := <-ch
+				_, open := <-ch
+
+				// ASSERT
+				test.Error(t, r1.Err).Is(opterr)
+				test.IsFalse(t, open, "channel open")
+			},
+		},
+		{scenario: "pollInterval",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer func() { randInt63n = ogRand }()
+				n := int64(0)
+				randInt63n = func(int64) int64 { return n }
+
+				// ACT
+				zero := pollInterval(0)
+				low := pollInterval(10 * time.Second)
+				n = int64(2 * time.Second)
+				high := pollInterval(10 * time.Second)
+
+				// ASSERT
+				test.That(t, zero).Equals(time.Duration(0))
+				test.That(t, low).Equals(9 * time.Second)
+				test.That(t, high).Equals(11 * time.Second)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// Media types for which decoders are registered by default
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeHAL     = "application/hal+json"
+	MediaTypeJSONAPI = "application/vnd.api+json"
+)
+
+// Decoder is a function that decodes the body of a response into a value;
+// json.Unmarshal is an example of a Decoder.
+type Decoder func(body []byte, v any) error
+
+// decoders holds the Decoder registered for each media type
+var decoders = struct {
+	sync.RWMutex
+	m map[string]Decoder
+}{m: map[string]Decoder{
+	MediaTypeJSON:    json.Unmarshal,
+	MediaTypeHAL:     json.Unmarshal,
+	MediaTypeJSONAPI: json.Unmarshal,
+}}
+
+// RegisterDecoder registers a Decoder to be used by Decode for responses with
+// a specified media type (e.g. "application/vnd.example+json"), replacing any
+// Decoder previously registered for that media type.  Media types are not
+// case-sensitive.
+//
+// Decoders are registered for MediaTypeJSON, MediaTypeHAL and MediaTypeJSONAPI
+// by default.  Decoders are usually registered when a program is initialised.
+func RegisterDecoder(mediaType string, dec Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+
+	decoders.m[strings.ToLower(mediaType)] = dec
+}
+
+// decoderFor returns the Decoder for a specified media type.  If no Decoder is
+// registered for the media type, any media type with a +json suffix is decoded
+// as JSON.
+func decoderFor(mediaType string) (Decoder, bool) {
+	mediaType = strings.ToLower(mediaType)
+
+	decoders.RLock()
+	defer decoders.RUnlock()
+
+	if dec, ok := decoders.m[mediaType]; ok {
+		return dec, true
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return decoders.m[MediaTypeJSON], true
+	}
+	return nil, false
+}
+
+// Decode is a generic function that decodes the body of a response into a
+// value of a specified type, using the Decoder registered for the media type
+// identified by the Content-Type header of the response (see: RegisterDecoder).
+// A response with no Content-Type is decoded as JSON.
+//
+// Vendor media types are typically decoded into types that expose links and
+// relationships, e.g. HAL[T] for application/hal+json or JSONAPIDocument[T]
+// for application/vnd.api+json:
+//
+//	doc, err := http.Decode[http.JSONAPIDocument[[]http.JSONAPIResource[Order]]](ctx, r)
+//
+// ErrUnsupportedMediaType is returned if no Decoder is registered for the media
+// type.  Any DecodeOption may be specified, e.g. to limit the size of the body.
+// The response body is always closed.
+func Decode[T any](ctx context.Context, r *http.Response, opts ...DecodeOption) (T, error) {
+	result := *new(T)
+
+	handle := func(sen, err error) (T, error) {
+		return result, errorcontext.Errorf(ctx, "http.Decode: %w: %w", sen, err)
+	}
+
+	defer func() { _ = closeBody(ctx, r.Body) }()
+
+	mediaType := MediaTypeJSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := parseMediaType(ct)
+		if err != nil {
+			return handle(ErrUnsupportedMediaType, err)
+		}
+		mediaType = mt
+	}
+
+	dec, ok := decoderFor(mediaType)
+	if !ok {
+		return result, errorcontext.Errorf(ctx, "http.Decode: %w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	body, err := ioReadAll(decodeReader(r, opts...))
+	if err != nil {
+		return handle(ErrReadingResponseBody, err)
+	}
+
+	if err := dec(body, &result); err != nil {
+		return handle(fmt.Errorf("%w: %s", ErrDecodingResponseBody, mediaType), err)
+	}
+
+	return result, nil
+}
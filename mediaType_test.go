@@ -0,0 +1,126 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestDecode(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	response := func(ct string, body string) *http.Response {
+		r := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader([]byte(body)))}
+		if ct != "" {
+			r.Header.Set("Content-Type", ct)
+		}
+		return r
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no content type",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Decode[map[string]string](ctx, response("", `{"key":"value"}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, result).Equals(map[string]string{"key": "value"})
+			},
+		},
+		{scenario: "json with parameters",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Decode[map[string]string](ctx, response("application/json; charset=utf-8", `{"key":"value"}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, result).Equals(map[string]string{"key": "value"})
+			},
+		},
+		{scenario: "unregistered +json media type",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Decode[map[string]string](ctx, response("application/vnd.example.v2+json", `{"key":"value"}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, result).Equals(map[string]string{"key": "value"})
+			},
+		},
+		{scenario: "registered media type",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				RegisterDecoder("Text/Plain", func(body []byte, v any) error {
+					*(v.(*string)) = string(body)
+					return nil
+				})
+				defer func() {
+					decoders.Lock()
+					delete(decoders.m, "text/plain")
+					decoders.Unlock()
+				}()
+
+				// ACT
+				result, err := Decode[string](ctx, response("text/plain", "content"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals("content")
+			},
+		},
+		{scenario: "unsupported media type",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := Decode[string](ctx, response("text/csv", "a,b"))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnsupportedMediaType)
+			},
+		},
+		{scenario: "invalid content type",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := Decode[string](ctx, response("/", ""))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnsupportedMediaType)
+			},
+		},
+		{scenario: "body too large",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := Decode[map[string]string](ctx, response(MediaTypeJSON, `{"key":"value"}`), MaxDecodeSize(4))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(ErrResponseBodyTooLarge)
+			},
+		},
+		{scenario: "decoder error",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := Decode[map[string]string](ctx, response(MediaTypeHAL, `not json`))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDecodingResponseBody)
+				var syntaxErr *json.SyntaxError
+				test.IsTrue(t, errors.As(err, &syntaxErr), "is json.SyntaxError")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
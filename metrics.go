@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestMetrics describes a request performed by a client, for recording by
+// a MetricsRecorder.  The fields are suitable for use as (low cardinality)
+// metric labels and observations; the url of the request is deliberately not
+// included.
+type RequestMetrics struct {
+	// Client is the name of the client
+	Client string
+
+	// Endpoint is the name of any endpoint invoked (see: Endpoint)
+	Endpoint string
+
+	// Method is the method of the request
+	Method string
+
+	// StatusCode is the status code of the response; zero if no response
+	// was received
+	StatusCode int
+
+	// StatusClass is the class of the status code of the response ("1xx",
+	// "2xx", "3xx", "4xx" or "5xx"), or "error" if no response was received
+	StatusClass string
+
+	// Duration is the time taken to perform the request, including any
+	// retries
+	Duration time.Duration
+
+	// Attempts is the number of attempts made
+	Attempts uint
+
+	// BytesSent is the number of bytes sent in the body of the request,
+	// including any retries
+	BytesSent int64
+
+	// BytesReceived is the number of bytes received in the body of the
+	// response, including the bodies of any responses that were retried; for
+	// a streamed response, which is read once metrics are recorded, the
+	// Content-Length of the response (if known) is counted
+	BytesReceived int64
+
+	// Err is any error returned for the request
+	Err error
+}
+
+// Retries returns the number of retries made for the request.
+func (m RequestMetrics) Retries() uint {
+	if m.Attempts == 0 {
+		return 0
+	}
+	return m.Attempts - 1
+}
+
+// MetricsRecorder records metrics for requests performed by a client; an
+// implementation typically increments counters and observes histograms, e.g.
+// using Prometheus collectors labelled by client, method and status class.
+type MetricsRecorder interface {
+	RecordRequest(ctx context.Context, m RequestMetrics)
+}
+
+// MetricsRecorderFunc adapts an ordinary function to the MetricsRecorder
+// interface.
+type MetricsRecorderFunc func(ctx context.Context, m RequestMetrics)
+
+// RecordRequest calls the function with the context and metrics.
+func (fn MetricsRecorderFunc) RecordRequest(ctx context.Context, m RequestMetrics) {
+	fn(ctx, m)
+}
+
+// Metrics configures a client to record metrics for every request performed
+// using Do (and so using any of the convenience methods such as Get).  The
+// recorder is called when each request completes, on the goroutine performing
+// the request, and should return promptly.
+func Metrics(m MetricsRecorder) ClientOption {
+	return func(c *client) error {
+		c.metrics = m
+		return nil
+	}
+}
+
+// statusClass returns the class of a status code ("2xx" etc), or "error" if
+// the status code is zero (no response)
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "error"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// observe logs and/or records metrics for a completed request, as configured
+// on the client
+func (c client) observe(
+	ctx context.Context,
+	rq *http.Request,
+	r *http.Response,
+	d time.Duration,
+	attempts uint,
+	transfer *transferCount,
+	err error,
+) {
+	if c.logging != nil {
+		c.logging.log(ctx, c.name, rq, r, d, attempts, err)
+	}
+	if c.metrics != nil {
+		m := RequestMetrics{
+			Client:   c.name,
+			Endpoint: EndpointName(ctx),
+			Method:   rq.Method,
+			Duration: d,
+			Attempts: attempts,
+			Err:      err,
+		}
+		if r != nil {
+			m.StatusCode = r.StatusCode
+		}
+		if transfer != nil {
+			m.BytesSent = transfer.sent.Load()
+			m.BytesReceived = transfer.received.Load()
+		}
+		m.StatusClass = statusClass(m.StatusCode)
+		c.metrics.RecordRequest(ctx, m)
+	}
+}
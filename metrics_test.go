@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestMetrics(t *testing.T) {
+	// ARRANGE
+	og := timeSince
+	defer func() { timeSince = og }()
+	timeSince = func(time.Time) time.Duration { return time.Second }
+
+	// recorder returns a MetricsRecorder appending metrics to a slice
+	recorder := func(result *[]RequestMetrics) MetricsRecorder {
+		return MetricsRecorderFunc(func(_ context.Context, m RequestMetrics) {
+			*result = append(*result, m)
+		})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "successful request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				got := []RequestMetrics{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+					})),
+					Metrics(recorder(&got)),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, got).Equals([]RequestMetrics{{
+					Client:      "name",
+					Method:      http.MethodGet,
+					StatusCode:  http.StatusOK,
+					StatusClass: "2xx",
+					Duration:    time.Second,
+					Attempts:    1,
+				}})
+			},
+		},
+		{scenario: "retried request to endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				got := []RequestMetrics{}
+				n := 0
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						if n++; n == 1 {
+							return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+					})),
+					MaxRetries(1),
+					Backoff(NoBackoff),
+					Endpoint("orders", http.MethodGet, "orders/{id}"),
+					Metrics(recorder(&got)),
+				)
+
+				// ACT
+				_, err := c.Invoke(context.Background(), "orders", map[string]any{"id": 42})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(got)).Equals(1)
+				test.That(t, got[0].Endpoint).Equals("orders")
+				test.That(t, got[0].StatusClass).Equals("2xx")
+				test.That(t, got[0].Attempts).Equals(uint(2))
+				test.That(t, got[0].Retries()).Equals(uint(1))
+			},
+		},
+		{scenario: "failed request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				got := []RequestMetrics{}
+				doerr := errors.New("doer error")
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) { return nil, doerr })),
+					Metrics(recorder(&got)),
+				)
+
+				// ACT
+				_, err := c.Post(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(doerr)
+				test.That(t, len(got)).Equals(1)
+				test.That(t, got[0].Method).Equals(http.MethodPost)
+				test.That(t, got[0].StatusCode).Equals(0)
+				test.That(t, got[0].StatusClass).Equals("error")
+				test.Error(t, got[0].Err).Is(doerr)
+			},
+		},
+		{scenario: "bytes sent and received",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				got := []RequestMetrics{}
+				n := 0
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						_, _ = io.ReadAll(rq.Body)
+						if n++; n == 1 {
+							return &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("retry"))}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("response"))}, nil
+					})),
+					MaxRetries(1),
+					Backoff(NoBackoff),
+					Metrics(recorder(&got)),
+				)
+
+				// ACT
+				_, err := c.Post(context.Background(), "path", request.Body([]byte("request")))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(got)).Equals(1)
+				test.That(t, got[0].BytesSent).Equals(int64(2 * len("request")))
+				test.That(t, got[0].BytesReceived).Equals(int64(len("retry") + len("response")))
+			},
+		},
+		{scenario: "bytes received/streamed response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				got := []RequestMetrics{}
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						return &http.Response{StatusCode: http.StatusOK, ContentLength: 8, Body: io.NopCloser(strings.NewReader("response"))}, nil
+					})),
+					Metrics(recorder(&got)),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				_ = r.Body.Close()
+				test.That(t, len(got)).Equals(1)
+				test.That(t, got[0].BytesReceived).Equals(int64(8))
+			},
+		},
+		{scenario: "retries/no attempts",
+			exec: func(t *testing.T) {
+				// ACT
+				result := RequestMetrics{}.Retries()
+
+				// ASSERT
+				test.That(t, result).Equals(uint(0))
+			},
+		},
+		{scenario: "statusClass",
+			exec: func(t *testing.T) {
+				test.That(t, statusClass(0)).Equals("error")
+				test.That(t, statusClass(101)).Equals("1xx")
+				test.That(t, statusClass(308)).Equals("3xx")
+				test.That(t, statusClass(404)).Equals("4xx")
+				test.That(t, statusClass(599)).Equals("5xx")
+				test.That(t, statusClass(600)).Equals("error")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
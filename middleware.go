@@ -0,0 +1,51 @@
+package http
+
+import "net/http"
+
+// Middleware is a function that wraps a Doer, returning a Doer that may
+// intercept requests and responses, e.g. for logging, metrics or refreshing
+// credentials.
+//
+// Middleware is an alias for an anonymous function type, so middleware
+// declared in other modules using an equivalent signature may be used without
+// any coupling to this package.
+type Middleware = func(next Doer) Doer
+
+// DoerFunc adapts an ordinary function to the Doer interface, e.g. to
+// implement Middleware:
+//
+//	func Logging(next http.Doer) http.Doer {
+//		return http.DoerFunc(func(rq *http.Request) (*http.Response, error) {
+//			log.Printf("%s %s", rq.Method, rq.URL)
+//			return next.Do(rq)
+//		})
+//	}
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+// Do calls the function with the request.
+func (fn DoerFunc) Do(rq *http.Request) (*http.Response, error) {
+	return fn(rq)
+}
+
+// Use registers middleware wrapping the underlying client (see: Using) of the
+// client.  The option may be applied more than once; middleware is accumulated.
+//
+// Middleware is applied in the order registered; the first middleware is the
+// outermost, receiving each request first and each response last.  Middleware
+// wraps each attempt to perform a request, so is called again for any retry.
+//
+// Each middleware is applied once, when the client is initialised (see:
+// NewClient); the Doer returned is used for every request performed by the
+// client, so any state it holds persists across requests.
+//
+// Any nil middleware is ignored.
+func Use(middleware ...Middleware) ClientOption {
+	return func(c *client) error {
+		for _, mw := range middleware {
+			if mw != nil {
+				c.middleware = append(c.middleware, mw)
+			}
+		}
+		return nil
+	}
+}
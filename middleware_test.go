@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestDoerFunc(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	var got *http.Request
+	sut := DoerFunc(func(rq *http.Request) (*http.Response, error) {
+		got = rq
+		return nil, nil
+	})
+
+	// ACT
+	_, err := sut.Do(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.IsTrue(t, got == rq, "function called with request")
+}
+
+func TestUse(t *testing.T) {
+	// ARRANGE
+	calls := []string{}
+
+	// tag returns middleware recording the calls to it, before and after
+	// calling the next Doer
+	tag := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(rq *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" >")
+				defer func() { calls = append(calls, name+" <") }()
+				return next.Do(rq)
+			})
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err1 := Use(tag("a"), nil)(&c)
+				err2 := Use(tag("b"))(&c)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, len(c.middleware)).Equals(2)
+			},
+		},
+		{scenario: "middleware is applied in order",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls = []string{}
+				c, _ := NewClient("name",
+					Use(tag("a"), tag("b")),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						calls = append(calls, "client")
+						return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+					})),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, calls).Equals([]string{"a >", "b >", "client", "b <", "a <"})
+			},
+		},
+		{scenario: "middleware is applied to retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls = []string{}
+				c, _ := NewClient("name",
+					Use(tag("a")),
+					MaxRetries(1),
+					Backoff(NoBackoff),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						return nil, errors.New("failed")
+					})),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMaxRetriesExceeded)
+				test.Strings(t, calls).Equals([]string{"a >", "a <", "a >", "a <"})
+			},
+		},
+		{scenario: "middleware may intercept a request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name",
+					Use(func(Doer) Doer {
+						return DoerFunc(func(*http.Request) (*http.Response, error) {
+							return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+						})
+					}),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						return nil, errors.New("not expected")
+					})),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusNoContent)
+			},
+		},
+		{scenario: "middleware is applied once",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				applied := 0
+				c, _ := NewClient("name",
+					Use(func(next Doer) Doer {
+						applied++
+						return next
+					}),
+					Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+						return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+					})),
+				)
+
+				// ACT
+				for i := 0; i < 3; i++ {
+					_, err := c.Get(context.Background(), "path")
+					test.Error(t, err).IsNil()
+				}
+				_, err := c.Get(context.Background(), "path", request.NoFollowRedirects())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, applied).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
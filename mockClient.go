@@ -1,10 +1,16 @@
 package http
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
+	"strings"
+	"sync"
 )
 
 const (
@@ -17,27 +23,49 @@ var (
 )
 
 // MockClient is an interface that described the methods provided
-// for mocking expectations on a client
+// for mocking expectations on a client.
+//
+// A MockClient (and the HttpClient with which it is provided) is safe for
+// concurrent use; requests may be made concurrently and ExpectationsWereMet
+// may be called while requests are in flight.  Expectations should be
+// configured before any requests are made.
 type MockClient interface {
+	AssertExpectations(t TestingT) bool
 	Expect(method string, path string) *MockRequest
 	ExpectDelete(path string) *MockRequest
 	ExpectGet(path string) *MockRequest
 	ExpectPatch(path string) *MockRequest
 	ExpectPost(path string) *MockRequest
 	ExpectPut(path string) *MockRequest
+	ExpectNoRequestsTo(method string, pathPattern string)
 	ExpectationsWereMet() error
+	MatchAnyOrder()
+	ReplayCassette(path string) error
 	Reset()
+	Scope(t ScopeT) MockScope
 }
 
 // mockClient implements the HttpClient interface, providing additional
 // methods for configuring request and response expectations and
 // verifying that those expectations have been met.
 type mockClient struct {
+	sync.Mutex
 	name         string
 	hostname     string
 	expectations []*MockRequest
 	unexpected   []*http.Request
 	next         int
+
+	// anyOrder indicates that requests are matched against any expected
+	// request, rather than in the order expected (see: MatchAnyOrder)
+	anyOrder bool
+
+	// forbidden holds requests that must not be made (see: ExpectNoRequestsTo)
+	// and any requests made that match them
+	forbidden []*mockForbidden
+
+	// parent is the mock client of which this is a scope (nil if not a scope)
+	parent *mockClient
 }
 
 // NewMockClient returns a new http.HttpClient to be used for making
@@ -56,23 +84,18 @@ type mockClient struct {
 //	MockClient    // used to configure expected requests and provide details of responses
 //	              // to be mocked for each request
 //
-// Note the use of an anonymous interface in the exported function signature.
-// This avoids creating coupling modules thru a shared reference to an interface
-// type.  In Go (currently at least) interfaces are fungible but interface types are not.
-func NewMockClient(name string, wrap ...func(c interface {
-	Do(*http.Request) (*http.Response, error)
-}) interface {
-	Do(*http.Request) (*http.Response, error)
-}) (HttpClient, MockClient) {
+// Wrapper functions are declared in terms of Doer, which is an alias for an
+// anonymous interface type.  This avoids coupling modules thru a shared reference
+// to an interface type; wrapper functions declared using an equivalent anonymous
+// interface may also be used.
+func NewMockClient(name string, wrap ...func(c Doer) Doer) (HttpClient, MockClient) {
 	def := &mockClient{
 		name:     name,
 		hostname: "mock://hostname",
 		next:     noExpectedRequests,
 	}
 
-	// internally we can use an interface type for brevity/clarity as this
-	// is not part of the contract exported by the function
-	var mock ClientInterface = def
+	var mock Doer = def
 	for _, wrap := range wrap {
 		if wrap == nil {
 			continue
@@ -88,35 +111,35 @@ func NewMockClient(name string, wrap ...func(c interface {
 	return c.(client), def
 }
 
-// defaultResponse provides the response configured as expected from the supplied
-// expected request.  If no respond properties are configured, a simple OK response
-// is returned.
+// defaultResponse provides the response configured as expected for an actual
+// request.  If no response is configured, a simple OK response is returned.
 func (mock *mockClient) defaultResponse(
-	expected *MockRequest,
+	actual *http.Request,
+	expected *mockResponse,
 ) (response *http.Response, err error) {
 	var bodyerr error
 	rec := httptest.NewRecorder()
 	func(rw http.ResponseWriter, _ *http.Request) {
-		if expected.Response == nil {
+		if expected == nil {
 			rw.WriteHeader(http.StatusOK)
 			return
 		}
 
-		if expected.Response.headers != nil {
-			for k, v := range expected.Response.headers {
+		if expected.headers != nil {
+			for k, v := range expected.headers {
 				rw.Header()[k] = []string{v}
 			}
 		}
 
-		if expected.Response.statusCode != nil {
-			rw.WriteHeader(*expected.Response.statusCode)
+		if expected.statusCode != nil {
+			rw.WriteHeader(*expected.statusCode)
 		}
 
-		if len(expected.Response.body) > 0 {
-			_, bodyerr = writeBody(rw, expected.Response.body)
+		if len(expected.body) > 0 {
+			_, bodyerr = writeBody(rw, expected.body)
 		}
 
-		err = expected.Response.Err
+		err = expected.Err
 	}(rec, nil)
 
 	// if there was an error writing the response body then the response is
@@ -130,30 +153,110 @@ func (mock *mockClient) defaultResponse(
 	// if there is no configured response expectation or the expected
 	// response has no body or an empty body then the response Body will be
 	// http.NoBody
-	if expected.Response == nil || len(expected.Response.body) == 0 {
+	if expected == nil || len(expected.body) == 0 {
 		response.Body = http.NoBody
+		return
 	}
 
+	// as for an http.Transport, a gzip encoded body is transparently
+	// decompressed unless the request specified an Accept-Encoding
+	if actual != nil && actual.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+	if err := decompressMockResponse(response); err != nil {
+		return nil, err
+	}
 	return
 }
 
+// decompressMockResponse decompresses the body of a response with a gzip
+// Content-Encoding, removing the Content-Encoding and Content-Length headers
+// and setting Uncompressed, as for a response received using an http.Transport.
+func decompressMockResponse(r *http.Response) error {
+	if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return fmt.Errorf("mock response: %w", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("mock response: %w", err)
+	}
+
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	r.Uncompressed = true
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
 // Do implements the ClientInterface interface to perform any http request.
 // The mock client takes the request to be performed, checks it against
 // the next expected request and constructs any configured expected
 // response either by passing it to a configured request handler or
 // constructing a default response.
+//
+// If the response is configured with a delay (or to time out), the response
+// is returned only once the delay has elapsed; if the context of the request
+// is done before then, the error of the context is returned.
 func (mock *mockClient) Do(rq *http.Request) (*http.Response, error) {
-	if mock.next != noExpectedRequests && mock.next < len(mock.expectations) {
-		expected := mock.expectations[mock.next]
-		expected.actual = rq
-		mock.next++
+	if scope, ok := mock.scopeFor(rq); ok {
+		return scope.Do(rq)
+	}
+
+	response, err := mock.respondTo(rq)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.wait(rq.Context()); err != nil {
+		return nil, err
+	}
+	if response != nil && response.handler != nil {
+		return response.handle(rq)
+	}
+	return mock.defaultResponse(rq, response)
+}
+
+// respondTo matches a request against the expected requests, returning the
+// response configured for the expected request that it satisfies.  If the
+// request is forbidden or not expected, ErrUnexpectedRequest is returned.
+func (mock *mockClient) respondTo(rq *http.Request) (*mockResponse, error) {
+	mock.Lock()
+	defer mock.Unlock()
+
+	// a request satisfying an expected request is not forbidden, even if it
+	// also matches a forbidden pattern
+	expected := mock.nextExpected(rq)
+	if expected == nil || !expected.matches(rq) {
+		for _, f := range mock.forbidden {
+			if f.matches(rq) {
+				f.actual = append(f.actual, rq)
+				return nil, ErrUnexpectedRequest
+			}
+		}
+	}
+
+	if over := mock.overcalled(rq, expected); over != nil {
+		over.excess = append(over.excess, snapshot(rq))
+		return nil, ErrUnexpectedRequest
+	}
+
+	if expected != nil {
+		response := expected.record(rq)
+		if !mock.anyOrder && (expected.satisfied() || !expected.isExpected) {
+			mock.next++
+		}
 
 		switch {
 		case !expected.isExpected:
 			// NO-OP - the request will be recorded as unexpected
 
 		default:
-			return mock.defaultResponse(expected)
+			return response, nil
 		}
 	}
 
@@ -161,9 +264,69 @@ func (mock *mockClient) Do(rq *http.Request) (*http.Response, error) {
 	return nil, ErrUnexpectedRequest
 }
 
+// nextExpected returns the expected request to be matched by an actual
+// request, or nil if there is none.  Requests are matched in the order in
+// which they are expected; an expected request that may be made any number of
+// times (see: MockRequest.AnyTimes) is skipped if the actual request does not
+// match it.
+//
+// If the mock matches requests in any order (see: MatchAnyOrder), the first
+// expected request matching the actual request that has not yet been made (as
+// many times as expected) is returned.
+func (mock *mockClient) nextExpected(rq *http.Request) *MockRequest {
+	if mock.anyOrder {
+		for _, expected := range mock.expectations {
+			if !expected.satisfied() && expected.matches(rq) {
+				return expected
+			}
+		}
+		return nil
+	}
+
+	for mock.next != noExpectedRequests && mock.next < len(mock.expectations) {
+		expected := mock.expectations[mock.next]
+		if expected.anyTimes && !expected.matches(rq) {
+			mock.next++
+			continue
+		}
+		return expected
+	}
+	return nil
+}
+
+// overcalled returns the expected request of which an actual request is an
+// excess repeat, i.e. an expected request that has already been made as many
+// times as expected and that is matched by the actual request.  If the actual
+// request matches the next expected request, nil is returned.
+//
+// When requests are matched in order, only the expected request preceding the
+// next expected request is considered.
+func (mock *mockClient) overcalled(rq *http.Request, next *MockRequest) *MockRequest {
+	if next != nil && next.matches(rq) {
+		return nil
+	}
+
+	candidates := mock.expectations
+	if !mock.anyOrder {
+		if mock.next < 1 {
+			return nil
+		}
+		candidates = mock.expectations[mock.next-1 : mock.next]
+	}
+	for _, expected := range candidates {
+		if expected.isExpected && expected.satisfied() && expected.matches(rq) {
+			return expected
+		}
+	}
+	return nil
+}
+
 // ExpectationsWereMet checks the expected requests against actual requests made
 // and returns an error if any expectations were not met.
-func (mock mockClient) ExpectationsWereMet() error {
+func (mock *mockClient) ExpectationsWereMet() error {
+	mock.Lock()
+	defer mock.Unlock()
+
 	errs := []error{}
 
 	for _, rq := range mock.expectations {
@@ -173,7 +336,7 @@ func (mock mockClient) ExpectationsWereMet() error {
 			if rq.method != nil {
 				m = *rq.method
 			}
-			errs = append(errs, fmt.Errorf("request #%d: expecting: %s %s", rq.index+1, m, rq.url))
+			errs = append(errs, fmt.Errorf("request #%d: expecting: %s %s%s", rq.index+1, m, rq.urlString(), requestIDOf(rq.actual)))
 			for _, s := range rpt {
 				errs = append(errs, fmt.Errorf("   %s", s))
 			}
@@ -181,13 +344,25 @@ func (mock mockClient) ExpectationsWereMet() error {
 	}
 
 	for ix, rq := range mock.unexpected {
-		errs = append(errs, fmt.Errorf("request #%d: unexpected: %s %s",
+		errs = append(errs, fmt.Errorf("request #%d: unexpected: %s %s%s",
 			len(mock.expectations)+ix+1,
 			rq.Method,
 			rq.URL.String(),
+			requestIDOf(rq),
 		))
 	}
 
+	for _, f := range mock.forbidden {
+		for _, rq := range f.actual {
+			errs = append(errs, fmt.Errorf("forbidden: %s %s%s (no requests expected to: %s)",
+				rq.Method,
+				rq.URL.String(),
+				requestIDOf(rq),
+				f,
+			))
+		}
+	}
+
 	if len(errs) > 0 {
 		return MockExpectationsError{mock.name, errs}
 	}
@@ -195,6 +370,21 @@ func (mock mockClient) ExpectationsWereMet() error {
 	return nil
 }
 
+// AssertExpectations checks the expected requests against actual requests made
+// and reports any expectations that were not met as a failure of a test, e.g.
+//
+//	defer mock.AssertExpectations(t)
+//
+// Returns true if all expectations were met.
+func (mock *mockClient) AssertExpectations(t TestingT) bool {
+	t.Helper()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
 // Expect registers an expected request of an identified http method. The expected
 // request is returned which may be used to configure additional properties of the
 // expected request.
@@ -205,7 +395,10 @@ func (mock mockClient) ExpectationsWereMet() error {
 // This method will panic if called after a mock client has already received at least
 // one request.
 func (mock *mockClient) Expect(method string, path string) *MockRequest {
-	if mock.next > 0 {
+	mock.Lock()
+	defer mock.Unlock()
+
+	if mock.started() {
 		msg := "requests have already been made"
 		panic(fmt.Errorf("%s: %w: %s", mock.name, ErrCannotChangeExpectations, msg))
 	}
@@ -216,7 +409,7 @@ func (mock *mockClient) Expect(method string, path string) *MockRequest {
 			mock.hostname,
 			path,
 		)
-		panic(fmt.Errorf("%w: %s: %w", ErrInvalidURL, msg, err))
+		panic(fmt.Errorf("%s: %w", msg, InvalidURLError{URL: path, Err: err}))
 	}
 
 	rq := &MockRequest{
@@ -236,6 +429,69 @@ func (mock *mockClient) Expect(method string, path string) *MockRequest {
 	return rq
 }
 
+// MatchAnyOrder configures the mock to match each request against any expected
+// request that has not yet been made (as many times as expected), rather than
+// strictly in the order in which requests are expected.  This is essential when
+// the code under test performs requests concurrently.
+//
+// In this mode a request satisfies an expected request only if it matches the
+// expected method, url, headers and body; a request matching no expected request
+// is an unexpected request.  Where a request matches more than one expected
+// request, it satisfies the first of them to have been expected.
+//
+// The mode is not changed by Reset.
+func (mock *mockClient) MatchAnyOrder() {
+	mock.Lock()
+	defer mock.Unlock()
+
+	mock.anyOrder = true
+}
+
+// started returns true if any expected request has been made
+func (mock *mockClient) started() bool {
+	if mock.next > 0 {
+		return true
+	}
+	for _, expected := range mock.expectations {
+		if expected.actual != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectNoRequestsTo registers requests that must not be made.  Any request
+// made using the method specified and with a url path matching the pattern is
+// rejected with ErrUnexpectedRequest and causes ExpectationsWereMet to
+// return an error.  Requests that are rejected do not satisfy (or consume) any
+// expected request.
+//
+// Expected requests take precedence: a request that satisfies an expected
+// request is not rejected, even if it matches the forbidden pattern.  This
+// enables all requests to some path, other than those expected, to be
+// forbidden.
+//
+// An empty method matches requests made using any method.  The pattern is
+// matched against the path of the request url (without any leading '/'),
+// using the syntax of path.Match; e.g. "users/*" matches "users/1" but not
+// "users/1/orders".
+//
+// This method will panic if the pattern is invalid.
+func (mock *mockClient) ExpectNoRequestsTo(method string, pathPattern string) {
+	pattern := strings.TrimPrefix(pathPattern, "/")
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Errorf("%s: invalid path pattern (%s): %w", mock.name, pathPattern, err))
+	}
+
+	mock.Lock()
+	defer mock.Unlock()
+
+	mock.forbidden = append(mock.forbidden, &mockForbidden{
+		method:  method,
+		pattern: pattern,
+	})
+}
+
 // ExpectDelete is a convenience method that returns a new expectation
 // of a request, made using the DELETE method, with a specified url
 // (appended to the base url as configured in the client).
@@ -274,7 +530,38 @@ func (mock *mockClient) ExpectPut(path string) *MockRequest {
 // Reset clears all expectations in a mock client and prepares it to be
 // configured with a new set of request expectations.
 func (mock *mockClient) Reset() {
+	mock.Lock()
+	defer mock.Unlock()
+
 	mock.expectations = []*MockRequest{}
 	mock.unexpected = []*http.Request{}
+	mock.forbidden = []*mockForbidden{}
 	mock.next = noExpectedRequests
 }
+
+// mockForbidden identifies requests that must not be made to a mock client,
+// recording any matching requests that were made
+type mockForbidden struct {
+	method  string
+	pattern string
+	actual  []*http.Request
+}
+
+// matches returns true if a request is made using the method of the forbidden
+// request (if specified) with a url path matching the pattern
+func (f *mockForbidden) matches(rq *http.Request) bool {
+	if f.method != "" && f.method != rq.Method {
+		return false
+	}
+	ok, _ := path.Match(f.pattern, strings.TrimPrefix(rq.URL.Path, "/"))
+	return ok
+}
+
+// String returns a description of the forbidden request
+func (f *mockForbidden) String() string {
+	m := "<ANY METHOD>"
+	if f.method != "" {
+		m = f.method
+	}
+	return m + " " + f.pattern
+}
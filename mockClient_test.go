@@ -1,12 +1,20 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/blugnu/http/request"
 	"github.com/blugnu/test"
 )
 
@@ -14,7 +22,9 @@ func TestNewMockClient(t *testing.T) {
 	// ARRANGE
 	defer test.ExpectPanic(nil).Assert(t) // the nil wrapper func should not cause a panic
 	wrappersAreApplied := false
+	doerWrapperApplied := false
 
+	// wrappers declared using an anonymous interface are compatible with Doer
 	wrappers := []func(c interface {
 		Do(*http.Request) (*http.Response, error)
 	}) interface {
@@ -26,7 +36,11 @@ func TestNewMockClient(t *testing.T) {
 			Do(*http.Request) (*http.Response, error)
 		} {
 			wrappersAreApplied = true
-			return c.(ClientInterface)
+			return c
+		},
+		func(c Doer) Doer {
+			doerWrapperApplied = true
+			return c
 		},
 		nil,
 	}
@@ -44,6 +58,7 @@ func TestNewMockClient(t *testing.T) {
 		test.That(t, m.hostname).Equals("mock://hostname")
 	}
 	test.IsTrue(t, wrappersAreApplied)
+	test.IsTrue(t, doerWrapperApplied)
 }
 
 func TestMockClient(t *testing.T) {
@@ -71,7 +86,7 @@ func TestMockClient(t *testing.T) {
 				c := mockClient{}
 
 				// ACT
-				result, err := c.defaultResponse(&rq)
+				result, err := c.defaultResponse(nil, rq.Response)
 
 				// ASSERT
 				body, _ := io.ReadAll(result.Body)
@@ -93,10 +108,8 @@ func TestMockClient(t *testing.T) {
 				writeBody = func(rw http.ResponseWriter, d []byte) (int, error) { return 0, rwerr }
 
 				// ACT
-				result, err := c.defaultResponse(&MockRequest{
-					Response: &mockResponse{
-						body: []byte("non-empty"),
-					},
+				result, err := c.defaultResponse(nil, &mockResponse{
+					body: []byte("non-empty"),
 				})
 
 				// ASSERT
@@ -110,7 +123,7 @@ func TestMockClient(t *testing.T) {
 				c := mockClient{}
 
 				// ACT
-				result, err := c.defaultResponse(&MockRequest{})
+				result, err := c.defaultResponse(nil, nil)
 
 				// ASSERT
 				body, _ := io.ReadAll(result.Body)
@@ -215,6 +228,28 @@ func TestMockClient(t *testing.T) {
 				})
 			},
 		},
+		{scenario: "ExpectationsWereMet/unexpected request with request id",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx := WithRequestID(context.Background(), "id")
+				rq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://hostname/path", nil)
+				client := &mockClient{
+					name:       "foo",
+					next:       noExpectedRequests,
+					unexpected: []*http.Request{rq},
+				}
+
+				// ACT
+				test := test.Helper(t, func(t *testing.T) {
+					test.Error(t, client.ExpectationsWereMet()).IsNil()
+				})
+
+				// ASSERT
+				test.Report.Contains([]string{
+					"request #1: unexpected: GET http://hostname/path (request id: id)",
+				})
+			},
+		},
 		{scenario: "ExpectationsWereMet/one expected request/one unexpected",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -325,6 +360,433 @@ func TestMockClient(t *testing.T) {
 			},
 		},
 
+		{scenario: "Do/sequential responses",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").
+					WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+					Then().
+					WillRespond().WithStatusCode(http.StatusOK).WithBody([]byte("ok"))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.MaxRetries(1), request.Backoff(NoBackoff))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("ok")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/Times",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").Times(2).
+					WillRespond().WithStatusCode(http.StatusNoContent)
+				mock.ExpectGet("other")
+
+				// ACT
+				_, _ = c.Get(context.Background(), "path", request.AcceptStatus(http.StatusNoContent))
+				_, _ = c.Get(context.Background(), "path", request.AcceptStatus(http.StatusNoContent))
+				_, err := c.Get(context.Background(), "other")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/Times/too few requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").Times(2)
+
+				// ACT
+				_, _ = c.Get(context.Background(), "path")
+
+				// ASSERT
+				err := mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "expected 2 requests, got 1"), "expectations not met")
+			},
+		},
+		{scenario: "Do/Times/too many requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").Times(3).
+					WillRespond().WithStatusCode(http.StatusServiceUnavailable)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.MaxRetries(3), request.Backoff(NoBackoff))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedRequest)
+				err = mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "expected 3 requests, got 4"))
+				test.IsFalse(t, strings.Contains(err.Error(), "unexpected: GET"), "not reported as unexpected")
+			},
+		},
+		{scenario: "Do/Times/too many requests/next expected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").Times(2)
+				mock.ExpectPost("path")
+
+				// ACT
+				_, err1 := c.Get(context.Background(), "path")
+				_, err2 := c.Get(context.Background(), "path")
+				_, err3 := c.Get(context.Background(), "path")
+				_, err4 := c.Post(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Error(t, err3).Is(ErrUnexpectedRequest)
+				test.Error(t, err4).IsNil()
+				err := mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "expected 2 requests, got 3"))
+				test.IsFalse(t, strings.Contains(err.Error(), "request #2"), "POST expectation met")
+			},
+		},
+		{scenario: "Do/Times/retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").Times(3).
+					WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+					Then().
+					WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+					Then().
+					WillRespond().WithStatusCode(http.StatusOK)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.MaxRetries(5), request.Backoff(NoBackoff))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusOK)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/AnyTimes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("status").AnyTimes()
+				mock.ExpectPost("path")
+				mock.ExpectGet("unused").AnyTimes()
+
+				// ACT
+				for i := 0; i < 3; i++ {
+					_, _ = c.Get(context.Background(), "status")
+				}
+				_, err := c.Post(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/AnyTimes/not matched",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("status").AnyTimes()
+
+				// ACT
+				_, err := c.Post(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedRequest)
+			},
+		},
+
+		{scenario: "Do/MatchAnyOrder",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.MatchAnyOrder()
+				mock.ExpectGet("a").WillRespond().WithBody([]byte("a"))
+				mock.ExpectGet("b").WillRespond().WithBody([]byte("b"))
+				mock.ExpectPost("a").WithBody([]byte("body")).WillRespond().WithStatusCode(http.StatusCreated)
+
+				// ACT
+				rc, _ := c.Post(context.Background(), "a", request.Body([]byte("body")), request.AcceptStatus(http.StatusCreated))
+				rb, _ := c.Get(context.Background(), "b")
+				ra, _ := c.Get(context.Background(), "a")
+
+				// ASSERT
+				a, _ := io.ReadAll(ra.Body)
+				b, _ := io.ReadAll(rb.Body)
+				test.That(t, string(a)).Equals("a")
+				test.That(t, string(b)).Equals("b")
+				test.That(t, rc.StatusCode).Equals(http.StatusCreated)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/MatchAnyOrder/concurrent requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.MatchAnyOrder()
+				for i := 0; i < 10; i++ {
+					mock.ExpectGet(fmt.Sprintf("items/%d", i))
+				}
+
+				// ACT
+				wg := sync.WaitGroup{}
+				for i := 9; i >= 0; i-- {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						_, _ = c.Get(context.Background(), fmt.Sprintf("items/%d", i))
+					}(i)
+				}
+				wg.Wait()
+
+				// ASSERT
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/MatchAnyOrder/unmatched and repeated",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.MatchAnyOrder()
+				mock.ExpectGet("a")
+				mock.ExpectGet("b")
+
+				// ACT
+				_, err1 := c.Get(context.Background(), "a")
+				_, err2 := c.Get(context.Background(), "a")
+				_, err3 := c.Get(context.Background(), "c")
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).Is(ErrUnexpectedRequest)
+				test.Error(t, err3).Is(ErrUnexpectedRequest)
+				err := mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "request #2: expecting: GET mock://hostname/b"), "b not requested")
+				test.IsTrue(t, strings.Contains(err.Error(), "request #1: expecting: GET mock://hostname/a"), "a repeated")
+				test.IsTrue(t, strings.Contains(err.Error(), "expected 1 requests, got 2"), "a repeated")
+				test.IsTrue(t, strings.Contains(err.Error(), "request #3: unexpected: GET mock://hostname/c"), "c unexpected")
+			},
+		},
+
+		{scenario: "Do/url matchers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("users").WithPathPattern("users/{id}").WithQuery("expand", "orders")
+				mock.ExpectGet("users").WithQueryParams(map[string]string{"page": "2", "size": "10"})
+
+				// ACT
+				_, err1 := c.Get(context.Background(), "users/42", request.Query(map[string]any{"expand": "orders"}))
+				_, err2 := c.Get(context.Background(), "users", request.RawQuery("size=10&page=2"))
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+
+		{scenario: "Do/WithDelay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").WillRespond().WithDelay(time.Hour)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(context.DeadlineExceeded)
+				test.IsTrue(t, IsTimeout(err), "is a timeout")
+			},
+		},
+		{scenario: "Do/WillTimeout then respond",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").
+					WillTimeout().
+					Then().
+					WillRespond().WithBody([]byte("ok"))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.MaxRetries(1), request.Backoff(NoBackoff))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("ok")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/WillTimeout/concurrent requests are not blocked",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.MatchAnyOrder()
+				mock.ExpectGet("slow").WillTimeout()
+				mock.ExpectGet("fast")
+				ctx, cancel := context.WithCancel(context.Background())
+
+				// ACT
+				errs := make(chan error, 1)
+				go func() {
+					_, err := c.Get(ctx, "slow")
+					errs <- err
+				}()
+				_, err := c.Get(context.Background(), "fast")
+				cancel()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, <-errs).Is(context.Canceled)
+			},
+		},
+		{scenario: "Do/WillRespondWith",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectPost("orders").
+					WillRespondWith(func(rq *http.Request) (*http.Response, error) {
+						body, _ := io.ReadAll(rq.Body)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{"echo":` + string(body) + `}`)),
+						}, nil
+					}).
+					Then().
+					WillRespond().WithBody([]byte("ok"))
+
+				// ACT
+				r1, err1 := c.Post(context.Background(), "orders", request.JSONBody(map[string]int{"id": 42}))
+				r2, err2 := c.Post(context.Background(), "orders")
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				body, _ := io.ReadAll(r1.Body)
+				test.That(t, string(body)).Equals(`{"echo":{"id":42}}`)
+				test.That(t, r1.Request.URL.String()).Equals("mock://hostname/orders")
+
+				test.Error(t, err2).IsNil()
+				body, _ = io.ReadAll(r2.Body)
+				test.That(t, string(body)).Equals("ok")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/WillRespondWith/error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				fnerr := errors.New("handler error")
+				mock.ExpectGet("path").WillRespondWith(func(*http.Request) (*http.Response, error) {
+					return nil, fnerr
+				})
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(fnerr)
+			},
+		},
+		{scenario: "Do/concurrent requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectPost("path").WithBody([]byte("body")).Times(10)
+
+				// ACT
+				wg := sync.WaitGroup{}
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, _ = c.Post(context.Background(), "path", request.Body([]byte("body")))
+						_ = mock.ExpectationsWereMet()
+					}()
+				}
+				wg.Wait()
+
+				// ASSERT
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Do/ExpectationsWereMet with request in flight",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				inflight := make(chan struct{})
+				release := make(chan struct{})
+				mock.ExpectPost("path").WithBody([]byte("body")).
+					WillRespondWith(func(rq *http.Request) (*http.Response, error) {
+						close(inflight)
+						<-release
+						body, _ := io.ReadAll(rq.Body)
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+					})
+
+				// ACT
+				results := make(chan *http.Response, 1)
+				go func() {
+					r, _ := c.Post(context.Background(), "path", request.Body([]byte("body")))
+					results <- r
+				}()
+				<-inflight
+				err := mock.ExpectationsWereMet()
+				close(release)
+				r := <-results
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("body")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		// AssertExpectations tests
+		{scenario: "AssertExpectations/met",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path")
+				_, _ = c.Get(context.Background(), "path")
+				ft := &fakeT{}
+
+				// ACT
+				result := mock.AssertExpectations(ft)
+
+				// ASSERT
+				test.IsTrue(t, result)
+				test.That(t, len(ft.failures)).Equals(0)
+			},
+		},
+		{scenario: "AssertExpectations/not met",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_, mock := NewMockClient("foo")
+				mock.ExpectGet("path")
+				ft := &fakeT{}
+
+				// ACT
+				result := mock.AssertExpectations(ft)
+
+				// ASSERT
+				test.IsFalse(t, result)
+				test.That(t, len(ft.failures)).Equals(1)
+				test.That(t, ft.failures[0]).Equals(mock.ExpectationsWereMet().Error())
+			},
+		},
+
 		// Expect tests
 		{scenario: "Expect/initialises expected request",
 			exec: func(t *testing.T) {
@@ -363,6 +825,18 @@ func TestMockClient(t *testing.T) {
 				client.Expect("any", "any")
 			},
 		},
+		{scenario: "Expect/when repeatable request already made",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.ExpectPanic(ErrCannotChangeExpectations).Assert(t)
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("path").AnyTimes()
+				_, _ = c.Get(context.Background(), "path")
+
+				// ACT
+				mock.ExpectGet("other")
+			},
+		},
 		{scenario: "Expect/when url is invalid",
 			exec: func(t *testing.T) {
 				// any path appended to a url will be escaped; it is impossible
@@ -454,6 +928,61 @@ func TestMockClient(t *testing.T) {
 			},
 		},
 
+		// ExpectNoRequestsTo tests
+		{scenario: "ExpectNoRequestsTo/invalid pattern",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.ExpectPanic(path.ErrBadPattern).Assert(t)
+				client := &mockClient{}
+
+				// ACT
+				client.ExpectNoRequestsTo(http.MethodGet, "users/[")
+			},
+		},
+		{scenario: "ExpectNoRequestsTo/no matching requests made",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("users/1")
+				mock.ExpectNoRequestsTo(http.MethodDelete, "/users/*")
+				mock.ExpectNoRequestsTo(http.MethodGet, "users/*/orders")
+
+				// ACT
+				_, err := c.Get(context.Background(), "users/1")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "ExpectNoRequestsTo/matching requests made",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				mock.ExpectGet("users/1")
+				mock.ExpectNoRequestsTo("", "users/*")
+
+				// ACT
+				_, err1 := c.Delete(context.Background(), "users/2")
+				_, err2 := c.Get(context.Background(), "users/1")
+				_, err3 := c.Get(context.Background(), "users/1")
+
+				// ASSERT
+				test.Error(t, err1).Is(ErrUnexpectedRequest)
+				test.Error(t, err2).IsNil()
+				test.Error(t, err3).Is(ErrUnexpectedRequest)
+
+				test := test.Helper(t, func(t *testing.T) {
+					test.Error(t, mock.ExpectationsWereMet()).IsNil()
+				})
+				test.Report.Contains([]string{
+					"unexpected error: foo: expectations not met",
+					"forbidden: DELETE mock://hostname/users/2 (no requests expected to: <ANY METHOD> users/*)",
+					"forbidden: GET mock://hostname/users/1 (no requests expected to: <ANY METHOD> users/*)",
+				})
+			},
+		},
+
 		// Reset tests
 		{scenario: "Reset",
 			exec: func(t *testing.T) {
@@ -462,6 +991,7 @@ func TestMockClient(t *testing.T) {
 					next:         1,
 					expectations: []*MockRequest{{}},
 					unexpected:   []*http.Request{{}},
+					forbidden:    []*mockForbidden{{}},
 				}
 
 				// ACT
@@ -471,6 +1001,7 @@ func TestMockClient(t *testing.T) {
 				test.That(t, client.next).Equals(noExpectedRequests)
 				test.Slice(t, client.expectations).IsEmpty()
 				test.Slice(t, client.unexpected).IsEmpty()
+				test.Slice(t, client.forbidden).IsEmpty()
 			},
 		},
 	}
@@ -2,12 +2,21 @@ package http
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/textproto"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 )
 
+// pathParameter matches a parameter in a path pattern (see: WithPathPattern)
+var pathParameter = regexp.MustCompile(`\{[^/{}]*\}`)
+
 // MockRequest holds details of a request expected by a MockClient
 type MockRequest struct {
 	// index of the request in the associated client
@@ -25,22 +34,65 @@ type MockRequest struct {
 	// bodies must match)
 	body *[]byte
 
-	// expected url (required; the url must match exactly including any query parameters)
+	// expected body predicate (optional; if set, the body must satisfy the
+	// predicate, which returns an error describing any difference)
+	bodyMatcher func([]byte) error
+
+	// expected url (required; the url must match exactly including any query
+	// parameters, unless query parameters or a url pattern are expected)
 	url string
 
+	// expected query parameters (optional; if set, the url must match without
+	// any query and the query must include these parameters, in any order)
+	query url.Values
+
+	// expected url pattern (optional; if set, the url must match the pattern
+	// rather than the expected url)
+	urlPattern *regexp.Regexp
+
+	// indicates that the url pattern is matched against the url without any
+	// query (see: WithPathPattern)
+	pathOnly bool
+
 	// expected headers (optional; a key with a nil value indicates a header which
 	// must be present regardless of value; a key with a non-nil value indicates
 	// a header that must have a specific value)
 	headers map[string]*string
 
-	// records the actual request made
+	// records the actual request made (the first, if the request is expected
+	// to be made more than once)
 	actual *http.Request
 
+	// records any further requests made, if the request is expected to be
+	// made more than once (see: Times and AnyTimes)
+	repeats []*http.Request
+
+	// records any requests made after the request was made as many times as
+	// expected, which are rejected (see: Times)
+	excess []*http.Request
+
+	// the number of times the request is expected to be made (optional; if
+	// zero, the request is expected once for each response configured)
+	times int
+
+	// indicates that the request may be made any number of times, including
+	// not at all
+	anyTimes bool
+
 	// indicates whether the request is expected or not
 	isExpected bool
 
+	// indicates that the next response configured is to follow any response
+	// already configured, rather than replace it (see: mockResponse.Then)
+	then bool
+
 	// configuration of the response to be mocked in response to the request
+	// (the first response, if more than one response is configured)
 	Response *mockResponse
+
+	// configuration of responses to be mocked in response to any repeats of
+	// the request, in order
+	responses []*mockResponse
 }
 
 // analyse performs expectation analysis for a request and returns a
@@ -55,18 +107,121 @@ func (rq *MockRequest) checkExpectations() []string {
 		}
 		result = append(result, fmt.Sprintf("  got: %s %s", rq.actual.Method, rq.actual.URL.String()))
 
+	case rq.actual == nil && rq.anyTimes:
+		return nil
+
 	case rq.actual == nil:
 		result = append(result, "  got: <no request>")
 
 	default:
-		result = append(result, rq.checkMethodExpectation()...)
-		result = append(result, rq.checkURLExpectation()...)
-		result = append(result, rq.checkHeadersExpectation()...)
-		result = append(result, rq.checkBodyExpectation()...)
+		if n, got := rq.expectedCalls(), len(rq.calls())+len(rq.excess); !rq.anyTimes && got != n {
+			result = append(result, fmt.Sprintf("expected %d requests, got %d", n, got))
+		}
+		for i, actual := range rq.calls() {
+			call := *rq
+			call.actual = actual
+
+			rpt := call.checkMethodExpectation()
+			rpt = append(rpt, call.checkURLExpectation()...)
+			rpt = append(rpt, call.checkHeadersExpectation()...)
+			rpt = append(rpt, call.checkBodyExpectation()...)
+			if len(rpt) > 0 && i > 0 {
+				result = append(result, fmt.Sprintf("repeat #%d:", i))
+			}
+			result = append(result, rpt...)
+		}
 	}
 	return result
 }
 
+// calls returns the actual requests made, in order
+func (rq *MockRequest) calls() []*http.Request {
+	if rq.actual == nil {
+		return nil
+	}
+	return append([]*http.Request{rq.actual}, rq.repeats...)
+}
+
+// expectedCalls returns the number of times the request is expected to be
+// made; unless specified (see: Times), this is the number of responses
+// configured (at least 1)
+func (rq *MockRequest) expectedCalls() int {
+	if rq.times > 0 {
+		return rq.times
+	}
+	return 1 + len(rq.responses)
+}
+
+// satisfied returns true if the request has been made as many times as it is
+// expected to be made; a request that may be made any number of times is
+// never satisfied
+func (rq *MockRequest) satisfied() bool {
+	return !rq.anyTimes && len(rq.calls()) >= rq.expectedCalls()
+}
+
+// record records an actual request made, returning the response configured
+// for the request.  If the request is made more times than the number of
+// responses configured, the last response configured is repeated.
+//
+// A snapshot of the request is recorded, so that expectations may be checked
+// while the request (and its body) remains in use by the client.
+func (rq *MockRequest) record(actual *http.Request) *mockResponse {
+	actual = snapshot(actual)
+	if rq.actual == nil {
+		rq.actual = actual
+		return rq.Response
+	}
+	rq.repeats = append(rq.repeats, actual)
+	if len(rq.responses) == 0 {
+		return rq.Response
+	}
+	return rq.responses[min(len(rq.repeats), len(rq.responses))-1]
+}
+
+// snapshot returns a copy of an actual request with its own copy of any body;
+// the body of the actual request is replaced so that it may still be read
+func snapshot(actual *http.Request) *http.Request {
+	cpy := actual.Clone(actual.Context())
+	if actual.Body == nil || actual.Body == http.NoBody {
+		return cpy
+	}
+	body, _ := io.ReadAll(actual.Body)
+	actual.Body = io.NopCloser(bytes.NewReader(body))
+	cpy.Body = io.NopCloser(bytes.NewReader(body))
+	return cpy
+}
+
+// matches returns true if an actual request satisfies the expected method,
+// url, headers and body of the request.  Any body of the actual request is
+// replaced so that it may be read again.
+func (rq *MockRequest) matches(actual *http.Request) bool {
+	if rq.method != nil && *rq.method != actual.Method {
+		return false
+	}
+	if !rq.urlMatches(actual.URL) {
+		return false
+	}
+	for k, v := range rq.headers {
+		av, ok := actual.Header[k]
+		if !ok || (v != nil && av[0] != *v) {
+			return false
+		}
+	}
+	if rq.body == nil && rq.bodyMatcher == nil {
+		return true
+	}
+
+	var body []byte
+	if actual.Body != nil {
+		body, _ = io.ReadAll(actual.Body)
+		actual.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if rq.bodyMatcher != nil {
+		return rq.bodyMatcher(body) == nil
+	}
+	return bytes.Equal(*rq.body, body)
+}
+
 // checkMethod returns a report describing any exception if the method
 // expected to be used by a request was not the method used by the
 // corresponding actual request
@@ -84,17 +239,66 @@ func (rq *MockRequest) checkMethodExpectation() []string {
 // expected to be used by a request was not the URL used by the
 // corresponding actual request
 func (rq *MockRequest) checkURLExpectation() []string {
-	u := rq.url
+	if rq.urlMatches(rq.actual.URL) {
+		return nil
+	}
+	if rq.urlPattern != nil {
+		return []string{
+			fmt.Sprintf("expected url matching: %s", rq.urlPattern),
+			fmt.Sprintf("   got               : %s", rq.actual.URL.String()),
+		}
+	}
+	u := rq.urlString()
 	if u == "" {
 		u = "<not specified>"
 	}
-	if rq.url != rq.actual.URL.String() {
-		return []string{
-			fmt.Sprintf("expected url: %s", u),
-			fmt.Sprintf("   got      : %s", rq.actual.URL.String()),
+	return []string{
+		fmt.Sprintf("expected url: %s", u),
+		fmt.Sprintf("   got      : %s", rq.actual.URL.String()),
+	}
+}
+
+// urlMatches returns true if an actual url satisfies the url expectations
+// of the request
+func (rq *MockRequest) urlMatches(actual *url.URL) bool {
+	withoutQuery := *actual
+	withoutQuery.RawQuery, withoutQuery.ForceQuery = "", false
+
+	var ok bool
+	switch {
+	case rq.urlPattern != nil && rq.pathOnly:
+		ok = rq.urlPattern.MatchString(withoutQuery.String())
+	case rq.urlPattern != nil:
+		ok = rq.urlPattern.MatchString(actual.String())
+	case rq.query != nil:
+		ok = rq.url == withoutQuery.String()
+	default:
+		ok = rq.url == actual.String()
+	}
+	if !ok {
+		return false
+	}
+
+	query := actual.Query()
+	for k, v := range rq.query {
+		if !slices.Equal(query[k], v) {
+			return false
 		}
 	}
-	return nil
+	return true
+}
+
+// urlString returns a description of the expected url, including any
+// expected query parameters
+func (rq *MockRequest) urlString() string {
+	u := rq.url
+	if rq.urlPattern != nil {
+		u = rq.urlPattern.String()
+	}
+	if len(rq.query) > 0 {
+		u += "?" + rq.query.Encode()
+	}
+	return u
 }
 
 // checkHeaders returns a report describing any exception if the headers
@@ -141,13 +345,30 @@ func (rq *MockRequest) checkHeadersExpectation() (rpt []string) {
 // corresponding actual request
 func (rq *MockRequest) checkBodyExpectation() []string {
 	// check the request body vs expected
-	if rq.body == nil {
+	if rq.body == nil && rq.bodyMatcher == nil {
 		return nil
 	}
 
+	// the body is replaced once read so that expectations may be checked
+	// more than once
+	var actual []byte
+	if rq.actual.Body != nil {
+		actual, _ = io.ReadAll(rq.actual.Body)
+		rq.actual.Body = io.NopCloser(bytes.NewReader(actual))
+	}
+	if rq.bodyMatcher != nil {
+		err := rq.bodyMatcher(actual)
+		if err == nil {
+			return nil
+		}
+		rpt := []string{"request body does not match"}
+		for _, s := range strings.Split(err.Error(), "\n") {
+			rpt = append(rpt, "   "+s)
+		}
+		return rpt
+	}
+
 	expected := *rq.body
-	actual, _ := io.ReadAll(rq.actual.Body)
-	defer rq.actual.Body.Close()
 	if bytes.Equal(expected, actual) {
 		return nil
 	}
@@ -188,12 +409,88 @@ func (rq MockRequest) String() string {
 	if rq.method != nil {
 		m = *rq.method
 	}
-	if rq.url != "" {
-		u = rq.url
+	if s := rq.urlString(); s != "" {
+		u = s
 	}
 	return fmt.Sprintf("%s %s", m, u)
 }
 
+// AnyTimes indicates that the request may be made any number of times,
+// including not at all.  Every request is provided with the response(s)
+// configured for the request, with the last response repeated as required.
+//
+// When requests are matched in order, an expected request that may be made
+// any number of times is satisfied by each consecutive request that matches
+// the expected method, url, headers and body; the first request that does
+// not match is matched against the next expected request.
+func (mock *MockRequest) AnyTimes() *MockRequest {
+	mock.anyTimes = true
+	mock.times = 0
+	return mock
+}
+
+// Times indicates that the request is expected to be made a specified number
+// of times.  The requests are provided with the responses configured for the
+// request, in order (see: mockResponse.Then), with the last response repeated
+// as required.
+//
+// If Times is not specified, the request is expected to be made once for each
+// response configured.  Times(0) is equivalent to WillNotBeCalled.
+//
+// This may be used to verify the retry behaviour of a client, with both fewer
+// and more requests than expected reported by ExpectationsWereMet.  A request
+// matching a request already made as many times as expected is rejected with
+// ErrUnexpectedRequest and reported as an excess request, unless it matches
+// the next expected request.
+//
+// e.g. to expect a request that is retried twice after failing:
+//
+//	mock.ExpectGet("path").Times(3).
+//		WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+//		Then().
+//		WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+//		Then().
+//		WillRespond().WithStatusCode(http.StatusOK)
+func (mock *MockRequest) Times(n int) *MockRequest {
+	if n < 1 {
+		mock.WillNotBeCalled()
+		return mock
+	}
+	mock.times = n
+	mock.anyTimes = false
+	return mock
+}
+
+// Received returns a copy of the actual request recorded for the expected
+// request (the first, if the request is expected to be made more than once),
+// or nil if no request has been recorded.  The request may be
+// used to make custom assertions, e.g. on the content of the body; the body
+// of the copy may be read without affecting the checking of expectations.
+func (mock *MockRequest) Received() *http.Request {
+	if mock.client != nil {
+		mock.client.Lock()
+		defer mock.client.Unlock()
+	}
+	if mock.actual == nil {
+		return nil
+	}
+	return snapshot(mock.actual)
+}
+
+// ReceivedAll returns copies of all actual requests recorded for the expected
+// request, in the order made (see: Received).
+func (mock *MockRequest) ReceivedAll() []*http.Request {
+	if mock.client != nil {
+		mock.client.Lock()
+		defer mock.client.Unlock()
+	}
+	result := []*http.Request{}
+	for _, actual := range mock.calls() {
+		result = append(result, snapshot(actual))
+	}
+	return result
+}
+
 // WillNotBeCalled indicates that the request is not expected to be made.  If a
 // corresponding request is made by the client, this will be reflected as a failed
 // expectation.
@@ -204,23 +501,183 @@ func (mock *MockRequest) WillNotBeCalled() {
 // WillRespond establishes a default response for the request, returning a mock
 // response to be used to provide details of the response such as status code,
 // headers or a body etc.
+//
+// To configure a sequence of responses to repeats of the request, the next
+// response is established by calling WillRespond (or WillReturnError) after
+// Then(), e.g. to simulate a 503 followed by a 200:
+//
+//	mock.ExpectGet("customers/1").
+//		WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+//		Then().
+//		WillRespond().WithStatusCode(http.StatusOK)
 func (mock *MockRequest) WillRespond() *mockResponse {
-	mock.Response = &mockResponse{
+	return mock.addResponse(&mockResponse{
 		headers: map[string]string{},
-	}
-	return mock.Response
+	})
 }
 
 // WillReturnError establishes an error to be returned by the client when
 // attempting to perform this request.  Any other response configuration is
 // discarded if a request is configured to return an error.
-func (mock *MockRequest) WillReturnError(err error) {
-	mock.Response = &mockResponse{Err: err}
+//
+// As for WillRespond, an error may be established as one of a sequence of
+// responses using Then().
+func (mock *MockRequest) WillReturnError(err error) *mockResponse {
+	return mock.addResponse(&mockResponse{Err: err})
+}
+
+// WillRespondWith establishes a function to be called to compute the response
+// to the request from the actual request, e.g. to echo an id from the body of
+// the request.  The function may also be used to make assertions on the
+// request as it is made.
+//
+// The response returned by the function is returned by the client, with an
+// empty Header, a Body of http.NoBody and the actual request set if not
+// provided.  If the function returns an error, the error is returned by the
+// client.
+//
+// As for WillRespond, the function may be established as one of a sequence of
+// responses using Then().  A delay may be configured using WithDelay(); any
+// other configuration of the response is ignored.
+func (mock *MockRequest) WillRespondWith(fn func(*http.Request) (*http.Response, error)) *mockResponse {
+	return mock.addResponse(&mockResponse{handler: fn})
+}
+
+// WillTimeout establishes that no response is returned for the request; the
+// client waits until the context of the request is done and returns the error
+// of the context (e.g. context.DeadlineExceeded), simulating a server that does
+// not respond.  If the context of the request can never be done, an error
+// wrapping os.ErrDeadlineExceeded is returned immediately, as for a transport
+// timeout.
+//
+// As for WillRespond, a timeout may be established as one of a sequence of
+// responses using Then(), e.g. to exercise retries.
+func (mock *MockRequest) WillTimeout() *mockResponse {
+	return mock.addResponse(&mockResponse{timeout: true})
+}
+
+// addResponse establishes a response for the request, replacing any response
+// already configured unless the response is to follow it (see:
+// mockResponse.Then)
+func (mock *MockRequest) addResponse(resp *mockResponse) *mockResponse {
+	resp.request = mock
+	if mock.then && mock.Response != nil {
+		mock.responses = append(mock.responses, resp)
+	} else {
+		mock.Response = resp
+		mock.responses = nil
+	}
+	mock.then = false
+	return resp
 }
 
 // WithBody identifies the expected body to be sent with the request.
 func (mock *MockRequest) WithBody(b []byte) *MockRequest {
 	mock.body = &b
+	mock.bodyMatcher = nil
+	return mock
+}
+
+// WithBodyMatching identifies a function to be satisfied by the body expected
+// to be sent with the request, replacing any expected body.  The function is
+// called with the body of the request and returns an error describing how the
+// body differs from that expected, or nil if the body is as expected.
+func (mock *MockRequest) WithBodyMatching(fn func([]byte) error) *MockRequest {
+	mock.body = nil
+	mock.bodyMatcher = fn
+	return mock
+}
+
+// WithJSONBody identifies the expected body to be sent with the request as JSON
+// equivalent to a specified value when marshalled as JSON.  The comparison is
+// semantic; the order of object keys and any whitespace is not significant.
+func (mock *MockRequest) WithJSONBody(v any) *MockRequest {
+	return mock.WithBodyMatching(func(body []byte) error {
+		wb, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("error marshalling expected json: %w", err)
+		}
+		var wv any
+		_ = json.Unmarshal(wb, &wv)
+
+		var gv any
+		if err := json.Unmarshal(body, &gv); err != nil {
+			return fmt.Errorf("expected json: %s\n   got      : %s (%w)", wb, body, err)
+		}
+		if !reflect.DeepEqual(gv, wv) {
+			gb, _ := json.Marshal(gv)
+			return fmt.Errorf("expected json: %s\n   got      : %s", wb, gb)
+		}
+		return nil
+	})
+}
+
+// WithPathPattern identifies a pattern to be matched by the path of the url of
+// the request (appended to the base url as configured in the client), replacing
+// the path specified when the request was expected.  Each {name} element of the
+// pattern matches any (non-empty) path segment, e.g. "users/{id}" matches
+// "users/1" but not "users/1/orders".  Any query of the url is disregarded
+// unless query parameters are also expected (see: WithQuery).
+//
+// e.g.
+//
+//	mock.ExpectGet("users").WithPathPattern("users/{id}")
+func (mock *MockRequest) WithPathPattern(pattern string) *MockRequest {
+	base := "/"
+	if mock.client != nil {
+		base = strings.TrimSuffix(mock.client.hostname, "/") + "/"
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := strings.Builder{}
+	expr.WriteString("^" + regexp.QuoteMeta(base))
+	i := 0
+	for _, loc := range pathParameter.FindAllStringIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[i:loc[0]]) + "[^/]+")
+		i = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[i:]) + "$")
+
+	mock.urlPattern = regexp.MustCompile(expr.String())
+	mock.pathOnly = true
+	return mock
+}
+
+// WithQuery identifies a query parameter expected to be included in the url of
+// the request with a specified value; the query may include other parameters
+// and the parameters may be in any order.  If the parameter is expected more
+// than once, each value is expected (in order).
+//
+// Any query included in the url specified when the request was expected is
+// also matched in this way.
+func (mock *MockRequest) WithQuery(k, v string) *MockRequest {
+	if mock.query == nil {
+		mock.query = url.Values{}
+		if u, err := url.Parse(mock.url); err == nil && u.RawQuery != "" {
+			mock.query = u.Query()
+			u.RawQuery = ""
+			mock.url = u.String()
+		}
+	}
+	mock.query.Add(k, v)
+	return mock
+}
+
+// WithQueryParams identifies query parameters expected to be included in the
+// url of the request, as for WithQuery.
+func (mock *MockRequest) WithQueryParams(params map[string]string) *MockRequest {
+	for k, v := range params {
+		mock.WithQuery(k, v)
+	}
+	return mock
+}
+
+// WithURLMatching identifies a regular expression to be matched by the url of
+// the request (including any query), replacing the url specified when the
+// request was expected.
+func (mock *MockRequest) WithURLMatching(re *regexp.Regexp) *MockRequest {
+	mock.urlPattern = re
+	mock.pathOnly = false
 	return mock
 }
 
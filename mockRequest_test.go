@@ -2,10 +2,16 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/blugnu/http/request"
 	"github.com/blugnu/test"
 )
 
@@ -59,6 +65,51 @@ func TestMockRequest(t *testing.T) {
 				})
 			},
 		},
+		{scenario: "checkExpectations/any times/no actual",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true, anyTimes: true}
+
+				// ACT
+				result := rq.checkExpectations()
+
+				// ASSERT
+				test.Strings(t, result).IsEmpty()
+			},
+		},
+		{scenario: "checkExpectations/repeats",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				m := http.MethodGet
+				a, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				b, _ := http.NewRequest(http.MethodPost, "http://hostname/path", nil)
+				rq := &MockRequest{isExpected: true, method: &m, url: "http://hostname/path", times: 3, actual: a, repeats: []*http.Request{b}}
+
+				// ACT
+				result := rq.checkExpectations()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{
+					"expected 3 requests, got 2",
+					"repeat #1:",
+					"expected method: GET",
+					"   got         : POST",
+				})
+			},
+		},
+		{scenario: "checkExpectations/excess",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				rq := &MockRequest{isExpected: true, url: "http://hostname/path", times: 2, actual: a, repeats: []*http.Request{a}, excess: []*http.Request{a}}
+
+				// ACT
+				result := rq.checkExpectations()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{"expected 2 requests, got 3"})
+			},
+		},
 
 		// checkMethodExpectation tests
 		{scenario: "checkMethodExpectation/expect any method",
@@ -139,6 +190,118 @@ func TestMockRequest(t *testing.T) {
 			},
 		},
 
+		{scenario: "checkURLExpectation/pattern not matched",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodGet, "http://hostname/users/1/orders", nil)
+				rq := (&MockRequest{isExpected: true, actual: a, client: &mockClient{hostname: "http://hostname"}}).
+					WithPathPattern("users/{id}")
+
+				// ACT
+				result := rq.checkURLExpectation()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{
+					`expected url matching: ^http://hostname/users/[^/]+$`,
+					"   got               : http://hostname/users/1/orders",
+				})
+			},
+		},
+		{scenario: "checkURLExpectation/query not matched",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodGet, "http://hostname/path?b=2", nil)
+				rq := (&MockRequest{isExpected: true, actual: a, url: "http://hostname/path"}).
+					WithQuery("a", "1").
+					WithQuery("b", "2")
+
+				// ACT
+				result := rq.checkURLExpectation()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{
+					"expected url: http://hostname/path?a=1&b=2",
+					"   got      : http://hostname/path?b=2",
+				})
+			},
+		},
+		{scenario: "urlMatches",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &mockClient{hostname: "http://hostname"}
+				testcases := []struct {
+					name   string
+					rq     *MockRequest
+					url    string
+					result bool
+				}{
+					{name: "exact", rq: &MockRequest{url: "http://hostname/path?a=1"}, url: "http://hostname/path?a=1", result: true},
+					{name: "exact/different query", rq: &MockRequest{url: "http://hostname/path?a=1"}, url: "http://hostname/path?a=2"},
+					{name: "query/any order",
+						rq:     (&MockRequest{url: "http://hostname/path"}).WithQueryParams(map[string]string{"a": "1", "b": "2"}),
+						url:    "http://hostname/path?b=2&c=3&a=1",
+						result: true,
+					},
+					{name: "query/missing",
+						rq:  (&MockRequest{url: "http://hostname/path"}).WithQuery("a", "1"),
+						url: "http://hostname/path?b=2",
+					},
+					{name: "query/different path",
+						rq:  (&MockRequest{url: "http://hostname/path"}).WithQuery("a", "1"),
+						url: "http://hostname/other?a=1",
+					},
+					{name: "query/merged with expected url",
+						rq:     (&MockRequest{url: "http://hostname/path?a=1"}).WithQuery("b", "2"),
+						url:    "http://hostname/path?b=2&a=1",
+						result: true,
+					},
+					{name: "query/multiple values",
+						rq:     (&MockRequest{url: "http://hostname/path"}).WithQuery("a", "1").WithQuery("a", "2"),
+						url:    "http://hostname/path?a=1&a=2",
+						result: true,
+					},
+					{name: "path pattern",
+						rq:     (&MockRequest{client: client}).WithPathPattern("/users/{id}/orders/{order}"),
+						url:    "http://hostname/users/1/orders/2?expand=true",
+						result: true,
+					},
+					{name: "path pattern/empty segment",
+						rq:  (&MockRequest{client: client}).WithPathPattern("users/{id}"),
+						url: "http://hostname/users/",
+					},
+					{name: "path pattern/literal",
+						rq:  (&MockRequest{client: client}).WithPathPattern("users.json"),
+						url: "http://hostname/usersxjson",
+					},
+					{name: "path pattern/with query",
+						rq:  (&MockRequest{client: client}).WithPathPattern("users/{id}").WithQuery("expand", "true"),
+						url: "http://hostname/users/1",
+					},
+					{name: "url matching",
+						rq:     (&MockRequest{}).WithURLMatching(regexp.MustCompile(`/users/\d+\?page=\d+$`)),
+						url:    "http://hostname/users/1?page=2",
+						result: true,
+					},
+					{name: "url matching/not matched",
+						rq:  (&MockRequest{}).WithURLMatching(regexp.MustCompile(`/users/\d+$`)),
+						url: "http://hostname/users/abc",
+					},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ARRANGE
+						u, _ := url.Parse(tc.url)
+
+						// ACT
+						result := tc.rq.urlMatches(u)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
 		// checkHeadersExpectation tests
 		{scenario: "checkHeadersExpectation/any value/submitted",
 			exec: func(t *testing.T) {
@@ -228,6 +391,114 @@ func TestMockRequest(t *testing.T) {
 		},
 
 		// checkBodyExpectation tests
+		{scenario: "checkBodyExpectation/json body/equivalent",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`{ "b": [1, 2], "a": "x" }`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).WithJSONBody(map[string]any{"a": "x", "b": []int{1, 2}})
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "checkBodyExpectation/json body/different",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`{"a":"y"}`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).WithJSONBody(map[string]any{"a": "x"})
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{
+					"request body does not match",
+					`   expected json: {"a":"x"}`,
+					`      got      : {"a":"y"}`,
+				})
+			},
+		},
+		{scenario: "checkBodyExpectation/json body/not json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`not json`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).WithJSONBody(map[string]any{"a": "x"})
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.That(t, len(result)).Equals(3)
+				test.IsTrue(t, strings.HasPrefix(result[2], "      got      : not json ("))
+			},
+		},
+		{scenario: "checkBodyExpectation/json body/unmarshallable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`{}`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).WithJSONBody(func() {})
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.That(t, len(result)).Equals(2)
+				test.IsTrue(t, strings.HasPrefix(result[1], "   error marshalling expected json:"))
+			},
+		},
+		{scenario: "checkBodyExpectation/body matching",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`body`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).
+					WithBody([]byte("other")).
+					WithBodyMatching(func(b []byte) error {
+						if !bytes.HasPrefix(b, []byte("b")) {
+							return errors.New("body must start with b")
+						}
+						return nil
+					})
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "checkBodyExpectation/body matching/not matched",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`other`)))
+				rq := (&MockRequest{isExpected: true, actual: a}).
+					WithBodyMatching(func(b []byte) error { return errors.New("body must start with b") })
+
+				// ACT
+				result := rq.checkBodyExpectation()
+
+				// ASSERT
+				test.Strings(t, result).Equals([]string{
+					"request body does not match",
+					"   body must start with b",
+				})
+				test.That(t, rq.matches(a)).Equals(false)
+			},
+		},
+		{scenario: "WithBody/replaces body matching",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := (&MockRequest{isExpected: true}).WithJSONBody(1)
+
+				// ACT
+				rq.WithBody([]byte("1"))
+
+				// ASSERT
+				test.IsTrue(t, rq.bodyMatcher == nil, "body matcher removed")
+			},
+		},
 		{scenario: "checkBodyExpectation/any body/with body",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -400,9 +671,143 @@ func TestMockRequest(t *testing.T) {
 				// ASSERT
 				test.That(t, rq.Response).Equals(&mockResponse{
 					headers: map[string]string{},
+					request: rq,
 				})
 			},
 		},
+		{scenario: "WillRespond/replaces response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rq.WillRespond().WithStatusCode(http.StatusNotFound)
+
+				// ACT
+				rq.WillRespond().WithStatusCode(http.StatusOK)
+
+				// ASSERT
+				test.That(t, *rq.Response.statusCode).Equals(http.StatusOK)
+				test.That(t, len(rq.responses)).Equals(0)
+				test.That(t, rq.expectedCalls()).Equals(1)
+			},
+		},
+		{scenario: "WillRespond/Then",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rqerr := errors.New("request error")
+
+				// ACT
+				rq.WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+					Then().
+					WillReturnError(rqerr).
+					Then().
+					WillRespond().WithStatusCode(http.StatusOK)
+
+				// ASSERT
+				test.That(t, *rq.Response.statusCode).Equals(http.StatusServiceUnavailable)
+				test.That(t, len(rq.responses)).Equals(2)
+				test.That(t, rq.responses[0].Err).Equals(rqerr)
+				test.That(t, *rq.responses[1].statusCode).Equals(http.StatusOK)
+				test.That(t, rq.expectedCalls()).Equals(3)
+			},
+		},
+		{scenario: "Times",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rq.AnyTimes()
+
+				// ACT
+				rq.Times(3)
+
+				// ASSERT
+				test.That(t, rq.expectedCalls()).Equals(3)
+				test.IsFalse(t, rq.anyTimes)
+			},
+		},
+		{scenario: "Times/zero",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+
+				// ACT
+				rq.Times(0)
+
+				// ASSERT
+				test.IsFalse(t, rq.isExpected)
+			},
+		},
+		{scenario: "AnyTimes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rq.Times(2)
+
+				// ACT
+				rq.AnyTimes()
+
+				// ASSERT
+				test.IsTrue(t, rq.anyTimes)
+				test.That(t, rq.times).Equals(0)
+			},
+		},
+		{scenario: "record",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rq.WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+					Then().
+					WillRespond().WithStatusCode(http.StatusOK)
+				rq.Times(3)
+				a, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+
+				// ACT
+				statuses := []int{}
+				for i := 0; i < 3; i++ {
+					statuses = append(statuses, *rq.record(a).statusCode)
+				}
+
+				// ASSERT
+				test.Slice(t, statuses).Equals([]int{503, 200, 200})
+				test.That(t, len(rq.calls())).Equals(3)
+				test.IsTrue(t, rq.satisfied())
+			},
+		},
+		{scenario: "matches",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				m := http.MethodPost
+				v := "value"
+				rq := &MockRequest{method: &m, url: "http://hostname/path", headers: map[string]*string{"Key": &v}}
+				rq.WithBody([]byte("body"))
+
+				testcases := []struct {
+					method, url, header, body string
+					result                    bool
+				}{
+					{method: http.MethodPost, url: "http://hostname/path", header: "value", body: "body", result: true},
+					{method: http.MethodGet, url: "http://hostname/path", header: "value", body: "body"},
+					{method: http.MethodPost, url: "http://hostname/other", header: "value", body: "body"},
+					{method: http.MethodPost, url: "http://hostname/path", header: "other", body: "body"},
+					{method: http.MethodPost, url: "http://hostname/path", body: "body"},
+					{method: http.MethodPost, url: "http://hostname/path", header: "value", body: "other"},
+				}
+				for _, tc := range testcases {
+					a, _ := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte(tc.body)))
+					if tc.header != "" {
+						a.Header.Set("Key", tc.header)
+					}
+
+					// ACT
+					result := rq.matches(a)
+
+					// ASSERT
+					test.That(t, result).Equals(tc.result)
+					body, _ := io.ReadAll(a.Body)
+					test.That(t, string(body)).Equals(tc.body)
+				}
+			},
+		},
 		{scenario: "WillReturnError",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -416,6 +821,59 @@ func TestMockRequest(t *testing.T) {
 				test.That(t, rq.Response.Err).Equals(rqerr)
 			},
 		},
+		{scenario: "Received",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("foo")
+				expected := mock.ExpectPost("path").WithBody([]byte("body")).Times(2)
+				_, _ = c.Post(context.Background(), "path", request.Body([]byte("body")))
+
+				// ACT
+				received := expected.Received()
+
+				// ASSERT
+				test.That(t, received.URL.String()).Equals("mock://hostname/path")
+				body, _ := io.ReadAll(received.Body)
+				test.That(t, string(body)).Equals("body")
+				test.That(t, len(expected.ReceivedAll())).Equals(1)
+
+				_, _ = c.Post(context.Background(), "path", request.Body([]byte("body")))
+				all := expected.ReceivedAll()
+				test.That(t, len(all)).Equals(2)
+				body, _ = io.ReadAll(all[1].Body)
+				test.That(t, string(body)).Equals("body")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "Received/no request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+
+				// ACT
+				received := rq.Received()
+
+				// ASSERT
+				test.IsTrue(t, received == nil, "no request")
+				test.That(t, len(rq.ReceivedAll())).Equals(0)
+			},
+		},
+		{scenario: "WillRespondWith",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &MockRequest{isExpected: true}
+				rq.WillRespond()
+				fn := func(*http.Request) (*http.Response, error) { return nil, nil }
+
+				// ACT
+				result := rq.WillRespondWith(fn)
+
+				// ASSERT
+				test.IsTrue(t, rq.Response == result, "replaces response")
+				test.IsTrue(t, result.handler != nil, "has handler")
+				test.IsTrue(t, result.request == rq, "has request")
+			},
+		},
 		{scenario: "WithBody",
 			exec: func(t *testing.T) {
 				// ARRANGE
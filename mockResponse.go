@@ -1,10 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/textproto"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/blugnu/http/multipart"
 )
@@ -23,6 +30,87 @@ type mockResponse struct {
 
 	// an error to return
 	Err error
+
+	// the delay before the response is returned (optional)
+	delay time.Duration
+
+	// indicates that no response is returned before the request context is
+	// done (see: MockRequest.WillTimeout)
+	timeout bool
+
+	// a function computing the response from the actual request (optional;
+	// if set, any other configuration of the response, other than a delay,
+	// is ignored; see: MockRequest.WillRespondWith)
+	handler func(*http.Request) (*http.Response, error)
+
+	// the request to which the response is configured
+	request *MockRequest
+}
+
+// Then returns the request to which the response is configured, so that a
+// further response may be configured to be returned when the request is
+// repeated, e.g.
+//
+//	mock.ExpectGet("customers/1").
+//		WillRespond().WithStatusCode(http.StatusServiceUnavailable).
+//		Then().
+//		WillRespond().WithStatusCode(http.StatusOK)
+//
+// Unless otherwise specified (see: MockRequest.Times), the request is expected
+// to be made once for each response configured.
+func (resp *mockResponse) Then() *MockRequest {
+	resp.request.then = true
+	return resp.request
+}
+
+// wait waits for any delay configured for the response, returning the error of
+// a specified context if it is done first.  A response configured to time out
+// waits until the context is done; if the context can never be done, an error
+// wrapping os.ErrDeadlineExceeded is returned immediately.
+func (resp *mockResponse) wait(ctx context.Context) error {
+	switch {
+	case resp == nil:
+		return nil
+
+	case resp.timeout && ctx.Done() == nil:
+		return fmt.Errorf("mock response: %w", os.ErrDeadlineExceeded)
+
+	case resp.timeout:
+		<-ctx.Done()
+		return ctx.Err()
+
+	case resp.delay > 0:
+		timer := time.NewTimer(resp.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// handle returns the response computed by the handler of the response for an
+// actual request.  As for a response received using an http.Transport, a
+// response with no Header, Body or Request is given an empty Header, a Body
+// of http.NoBody and the actual request.
+func (resp *mockResponse) handle(rq *http.Request) (*http.Response, error) {
+	r, err := resp.handler(rq)
+	if r == nil {
+		return nil, err
+	}
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+	if r.Request == nil {
+		r.Request = rq
+	}
+	return r, err
 }
 
 // WithBody sets a body to be returned with the response.
@@ -31,6 +119,72 @@ func (resp *mockResponse) WithBody(b []byte) *mockResponse {
 	return resp
 }
 
+// WithBodyFromFile sets the response to be returned from the contents of a
+// specified file.
+//
+// If the file is a fixture recorded using RecordResponse, the status code,
+// headers and body of the recorded response are returned (any Content-Length
+// header is omitted; the length of the body is used).  Otherwise the contents
+// of the file are returned as the body of the response.
+//
+// If the file cannot be read, the response has a status code of 500 (Internal
+// Server Error) and a body describing the error.
+func (resp *mockResponse) WithBodyFromFile(path string) *mockResponse {
+	handle := func(err error) *mockResponse {
+		sc := http.StatusInternalServerError
+		resp.statusCode = &sc
+		resp.body = []byte(fmt.Sprintf("MockResponse: WithBodyFromFile: %s", err))
+		return resp
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return handle(err)
+	}
+	if !bytes.HasPrefix(b, []byte("HTTP/")) {
+		resp.body = b
+		return resp
+	}
+
+	r, err := parseFixture(b)
+	if err != nil {
+		return handle(err)
+	}
+	resp.WithStatusCode(r.StatusCode)
+	for k, v := range r.Header {
+		if k != "Content-Length" {
+			resp.WithNonCanonicalHeader(k, strings.Join(v, ", "))
+		}
+	}
+	resp.body, _ = io.ReadAll(r.Body) // the body of a parsed fixture is in memory
+	return resp
+}
+
+// WithDelay sets a delay before the response is returned, e.g. to exercise
+// timeouts.  If the context of the request is done before the delay has
+// elapsed, the error of the context is returned instead of the response.
+func (resp *mockResponse) WithDelay(d time.Duration) *mockResponse {
+	resp.delay = d
+	return resp
+}
+
+// WithGzippedBody sets a body to be returned with the response, compressed
+// using gzip, with a Content-Encoding header of "gzip".
+//
+// As for a response received using an http.Transport, if the request did not
+// specify an Accept-Encoding header, the body is transparently decompressed
+// and the Content-Encoding header removed (with Uncompressed set true on the
+// response).  If the request specified an Accept-Encoding header (e.g. using
+// request.AcceptEncoding("gzip")), the compressed body is returned.
+func (resp *mockResponse) WithGzippedBody(b []byte) *mockResponse {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, _ = gz.Write(b) // writes to a bytes.Buffer cannot fail
+	_ = gz.Close()
+
+	resp.body = buf.Bytes()
+	return resp.WithHeader("Content-Encoding", "gzip")
+}
+
 // WithJSON sets a body to be returned with the response by marshalling
 // a specified value as JSON.
 func (resp *mockResponse) WithJSON(v any) *mockResponse {
@@ -1,12 +1,20 @@
 package http
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blugnu/http/multipart"
+	"github.com/blugnu/http/request"
 	"github.com/blugnu/test"
 )
 
@@ -22,6 +30,93 @@ func TestMockResponse(t *testing.T) {
 		scenario string
 		exec     func(*testing.T)
 	}{
+		{scenario: "WithDelay",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				response := &mockResponse{}
+
+				// ACT
+				result := response.WithDelay(time.Second)
+
+				// ASSERT
+				test.That(t, result.delay).Equals(time.Second)
+			},
+		},
+		{scenario: "wait",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cancelled, cancel := context.WithCancel(context.Background())
+				cancel()
+				testcases := []struct {
+					name     string
+					response *mockResponse
+					ctx      context.Context
+					err      error
+				}{
+					{name: "no response", ctx: cancelled},
+					{name: "no delay", response: &mockResponse{}, ctx: cancelled},
+					{name: "delay", response: &mockResponse{delay: time.Millisecond}, ctx: context.Background()},
+					{name: "delay/context done", response: &mockResponse{delay: time.Hour}, ctx: cancelled, err: context.Canceled},
+					{name: "timeout", response: &mockResponse{timeout: true}, ctx: cancelled, err: context.Canceled},
+					{name: "timeout/context never done", response: &mockResponse{timeout: true}, ctx: context.Background(), err: os.ErrDeadlineExceeded},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						err := tc.response.wait(tc.ctx)
+
+						// ASSERT
+						test.Error(t, err).Is(tc.err)
+					})
+				}
+			},
+		},
+		{scenario: "handle",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname/path", nil)
+				handlererr := errors.New("handler error")
+				testcases := []struct {
+					name    string
+					handler func(*http.Request) (*http.Response, error)
+					assert  func(*testing.T, *http.Response, error)
+				}{
+					{name: "response",
+						handler: func(rq *http.Request) (*http.Response, error) {
+							return &http.Response{StatusCode: http.StatusAccepted}, nil
+						},
+						assert: func(t *testing.T, r *http.Response, err error) {
+							test.Error(t, err).IsNil()
+							test.That(t, r.StatusCode).Equals(http.StatusAccepted)
+							test.That(t, r.Header).Equals(http.Header{})
+							test.That(t, r.Body).Equals(http.NoBody)
+							test.IsTrue(t, r.Request == rq, "has actual request")
+						},
+					},
+					{name: "error",
+						handler: func(rq *http.Request) (*http.Response, error) {
+							return nil, handlererr
+						},
+						assert: func(t *testing.T, r *http.Response, err error) {
+							test.Error(t, err).Is(handlererr)
+							test.IsTrue(t, r == nil, "no response")
+						},
+					},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ARRANGE
+						response := &mockResponse{handler: tc.handler}
+
+						// ACT
+						r, err := response.handle(rq)
+
+						// ASSERT
+						tc.assert(t, r, err)
+					})
+				}
+			},
+		},
 		{scenario: "WithBody",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -35,6 +130,132 @@ func TestMockResponse(t *testing.T) {
 				test.IsTrue(t, result == response)
 			},
 		},
+		{scenario: "WithBodyFromFile/raw body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "body.json")
+				_ = os.WriteFile(path, []byte(`{"id":1}`), 0o644)
+				response := &mockResponse{}
+
+				// ACT
+				result := response.WithBodyFromFile(path)
+
+				// ASSERT
+				test.That(t, response.body).Equals([]byte(`{"id":1}`))
+				test.That(t, response.statusCode).IsNil()
+				test.IsTrue(t, result == response)
+			},
+		},
+		{scenario: "WithBodyFromFile/fixture",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "fixture.http")
+				_ = os.WriteFile(path, []byte("HTTP/1.1 404 Not Found\r\nContent-Length: 3\r\nX-Foo: bar\r\n\r\nfoo"), 0o644)
+				response := &mockResponse{}
+
+				// ACT
+				response.WithBodyFromFile(path)
+
+				// ASSERT
+				test.That(t, *response.statusCode).Equals(http.StatusNotFound)
+				test.That(t, response.headers).Equals(map[string]string{"X-Foo": "bar"})
+				test.That(t, response.body).Equals([]byte("foo"))
+			},
+		},
+		{scenario: "WithBodyFromFile/invalid fixture",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "fixture.http")
+				_ = os.WriteFile(path, []byte("HTTP/1.1 not-a-status\r\n\r\n"), 0o644)
+				response := &mockResponse{}
+
+				// ACT
+				response.WithBodyFromFile(path)
+
+				// ASSERT
+				test.That(t, *response.statusCode).Equals(http.StatusInternalServerError)
+			},
+		},
+		{scenario: "WithBodyFromFile/missing file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				response := &mockResponse{}
+
+				// ACT
+				response.WithBodyFromFile(filepath.Join(t.TempDir(), "missing"))
+
+				// ASSERT
+				test.That(t, *response.statusCode).Equals(http.StatusInternalServerError)
+				test.IsTrue(t, bytes.HasPrefix(response.body, []byte("MockResponse: WithBodyFromFile: ")))
+			},
+		},
+		{scenario: "WithGzippedBody",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				response := &mockResponse{}
+
+				// ACT
+				result := response.WithGzippedBody([]byte("foo"))
+
+				// ASSERT
+				zr, err := gzip.NewReader(bytes.NewReader(response.body))
+				test.Error(t, err).IsNil()
+				b, _ := io.ReadAll(zr)
+				test.That(t, b).Equals([]byte("foo"))
+				test.That(t, response.headers).Equals(map[string]string{"Content-Encoding": "gzip"})
+				test.IsTrue(t, result == response)
+			},
+		},
+		{scenario: "WithGzippedBody/decompressed by default",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("name")
+				mock.ExpectGet("path").WillRespond().WithGzippedBody([]byte("foo"))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				b, _ := io.ReadAll(r.Body)
+				test.That(t, b).Equals([]byte("foo"))
+				test.That(t, r.Header.Get("Content-Encoding")).Equals("")
+				test.IsTrue(t, r.Uncompressed, "uncompressed")
+			},
+		},
+		{scenario: "WithGzippedBody/accept-encoding specified",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("name")
+				mock.ExpectGet("path").WillRespond().WithGzippedBody([]byte("foo"))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.AcceptEncoding("gzip"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.Header.Get("Content-Encoding")).Equals("gzip")
+				zr, err := gzip.NewReader(r.Body)
+				test.Error(t, err).IsNil()
+				b, _ := io.ReadAll(zr)
+				test.That(t, b).Equals([]byte("foo"))
+			},
+		},
+		{scenario: "WithGzippedBody/invalid gzip body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("name")
+				mock.ExpectGet("path").WillRespond().
+					WithHeader("Content-Encoding", "gzip").
+					WithBody([]byte("this is not a gzipped body"))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(gzip.ErrHeader)
+			},
+		},
 		{scenario: "WithHeader",
 			exec: func(t *testing.T) {
 				// ARRANGE
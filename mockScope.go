@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"net/http"
+)
+
+// ScopeT describes the methods of a *testing.T used by a mock scope
+type ScopeT interface {
+	TestingT
+	Cleanup(func())
+	Name() string
+}
+
+// MockScope is an isolated set of expectations on a mock client, typically
+// for a single (possibly parallel) subtest.  A MockScope provides the same
+// methods as a MockClient for configuring expectations, together with a
+// Context method.
+//
+// Requests made using the client shared by all scopes are matched against the
+// expectations of a scope only if the context of the request was obtained from
+// the Context method of that scope; any other request is matched against the
+// expectations of the mock client itself.
+type MockScope interface {
+	MockClient
+
+	// Context returns a context derived from a specified parent, identifying
+	// the scope.  Requests made with the context (or any context derived
+	// from it) are matched against the expectations of the scope.
+	Context(ctx context.Context) context.Context
+}
+
+// mockScopeKey is the key under which a scope is held in a request context
+type mockScopeKey struct{}
+
+// mockScope implements MockScope
+type mockScope struct {
+	*mockClient
+}
+
+// Context implements MockScope, returning a context identifying the scope
+func (scope mockScope) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mockScopeKey{}, scope.mockClient)
+}
+
+// Scope returns a new MockScope for a test.  The expectations of the scope are
+// independent of those of the mock client and of any other scope, so that
+// parallel subtests sharing a mock client do not interfere with each other.
+//
+// When the test completes, the expectations of the scope are verified and any
+// expectations not met are reported as a test failure.
+//
+// # Example
+//
+//	client, mock := http.NewMockClient("api")
+//	sut := NewService(client)
+//
+//	t.Run("get", func(t *testing.T) {
+//		t.Parallel()
+//		scope := mock.Scope(t)
+//		scope.ExpectGet("users/1")
+//
+//		sut.GetUser(scope.Context(context.Background()), 1)
+//	})
+func (mock *mockClient) Scope(t ScopeT) MockScope {
+	t.Helper()
+
+	scope := &mockClient{
+		name:     mock.name + "/" + t.Name(),
+		hostname: mock.hostname,
+		next:     noExpectedRequests,
+		parent:   mock,
+	}
+
+	t.Cleanup(func() {
+		t.Helper()
+		scope.AssertExpectations(t)
+	})
+
+	return mockScope{scope}
+}
+
+// scopeFor returns the scope of this mock client identified by the context of
+// a request, if any
+func (mock *mockClient) scopeFor(rq *http.Request) (*mockClient, bool) {
+	scope, ok := rq.Context().Value(mockScopeKey{}).(*mockClient)
+	if !ok || scope.parent != mock {
+		return nil, false
+	}
+	return scope, true
+}
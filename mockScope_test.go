@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// fakeScopeT is a ScopeT recording failures and cleanup functions
+type fakeScopeT struct {
+	fakeT
+	name    string
+	cleanup []func()
+}
+
+func (t *fakeScopeT) Cleanup(fn func()) { t.cleanup = append(t.cleanup, fn) }
+func (t *fakeScopeT) Name() string      { return t.name }
+
+// runCleanup runs any cleanup functions, as when a test completes
+func (t *fakeScopeT) runCleanup() {
+	for i := len(t.cleanup) - 1; i >= 0; i-- {
+		t.cleanup[i]()
+	}
+}
+
+func TestMockScope(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "expectations met",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("mock")
+				ft := &fakeScopeT{name: "test"}
+				scope := mock.Scope(ft)
+				scope.ExpectGet("path")
+
+				// ACT
+				_, err := c.Get(scope.Context(context.Background()), "path")
+				ft.runCleanup()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, ft.failures).IsEmpty()
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "expectations not met",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_, mock := NewMockClient("mock")
+				ft := &fakeScopeT{name: "test"}
+				scope := mock.Scope(ft)
+				scope.ExpectGet("path")
+
+				// ACT
+				ft.runCleanup()
+
+				// ASSERT
+				test.Strings(t, ft.failures).Equals([]string{
+					"mock/test: expectations not met: [\n   request #1: expecting: GET mock://hostname/path\n        got: <no request>\n]",
+				})
+			},
+		},
+		{scenario: "request without scope context",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("mock")
+				ft := &fakeScopeT{name: "test"}
+				scope := mock.Scope(ft)
+				scope.ExpectGet("path")
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+				ft.runCleanup()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedRequest)
+				test.That(t, len(ft.failures)).Equals(1)
+				test.IsTrue(t, mock.ExpectationsWereMet() != nil, "expectations not met")
+			},
+		},
+		{scenario: "scope of another mock",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewMockClient("mock")
+				_, other := NewMockClient("other")
+				ft := &fakeScopeT{name: "test"}
+				scope := other.Scope(ft)
+				scope.ExpectGet("path")
+
+				// ACT
+				_, err := c.Get(scope.Context(context.Background()), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedRequest)
+			},
+		},
+		{scenario: "parallel subtests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := NewMockClient("mock")
+
+				// ACT
+				t.Run("group", func(t *testing.T) {
+					for i := 0; i < 10; i++ {
+						path := fmt.Sprintf("path/%d", i)
+						t.Run(path, func(t *testing.T) {
+							t.Parallel()
+							scope := mock.Scope(t)
+							scope.ExpectGet(path)
+							scope.ExpectPost(path)
+							ctx := scope.Context(context.Background())
+
+							_, err := c.Get(ctx, path)
+							test.Error(t, err).IsNil()
+							_, err = c.Post(ctx, path)
+							test.Error(t, err).IsNil()
+						})
+					}
+				})
+
+				// ASSERT
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
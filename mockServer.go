@@ -0,0 +1,75 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewMockServer returns a new (started) httptest.Server together with a
+// MockClient on which the requests expected by the server, and corresponding
+// responses, may be configured.  The expectation api is that of a mock
+// client (see: NewMockClient), with expected urls relative to the url of the
+// server, but requests are made using real HTTP.  This enables the same
+// expectations to be used to test code that requires a concrete *http.Client
+// or some other transport that cannot be replaced by a mock client:
+//
+//	srv, mock := http.NewMockServer("api")
+//	defer srv.Close()
+//
+//	mock.ExpectGet("v1/customer/1").
+//		WillRespond().WithJSON(customer)
+//
+//	sut := NewService(srv.URL, srv.Client())
+//
+// A request that is not expected is answered with a 500 Internal Server Error
+// response, with a body describing the request.  If an expected request is
+// configured to return an error (see: MockRequest.WillReturnError), or to time
+// out (see: MockRequest.WillTimeout), the connection is aborted so that the
+// request fails in the client; a response configured to time out is not
+// aborted until the client abandons the request.
+//
+// Scopes are not supported by a mock server, since the context of a request
+// made using real HTTP cannot identify a scope.
+func NewMockServer(name string) (*httptest.Server, MockClient) {
+	mock := &mockClient{
+		name: name,
+		next: noExpectedRequests,
+	}
+	srv := httptest.NewServer(mockHandler(mock))
+	mock.hostname = srv.URL
+	return srv, mock
+}
+
+// mockHandler returns a handler serving requests received by a mock server
+// with the responses configured on a mock client
+func mockHandler(mock *mockClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the request received by the server has a url identifying only the
+		// path and query; the request made to the mock has the complete url,
+		// as for a request made using a mock client
+		rq := r.Clone(r.Context())
+		rq.URL.Scheme = "http"
+		rq.URL.Host = r.Host
+		rq.RequestURI = ""
+
+		response, err := mock.Do(rq)
+		switch {
+		case errors.Is(err, ErrUnexpectedRequest):
+			msg := fmt.Sprintf("%s: %s: %s %s", mock.name, err, rq.Method, rq.URL)
+			http.Error(w, msg, http.StatusInternalServerError)
+			return
+		case err != nil:
+			panic(http.ErrAbortHandler)
+		}
+		defer response.Body.Close()
+
+		for k, v := range response.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(response.StatusCode)
+		_, _ = io.Copy(w, response.Body)
+	}
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestMockServer(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "expected request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+				mock.ExpectPost("customers").
+					WithQuery("dryrun", "true").
+					WithHeader("X-Api-Key", "key").
+					WithBody([]byte("jane")).
+					WillRespond().
+					WithStatusCode(http.StatusCreated).
+					WithHeader("Location", "customers/1").
+					WithBody([]byte(`{"id":1}`))
+
+				rq, _ := http.NewRequest(http.MethodPost, srv.URL+"/customers?dryrun=true", strings.NewReader("jane"))
+				rq.Header.Set("X-Api-Key", "key")
+
+				// ACT
+				r, err := srv.Client().Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				defer r.Body.Close()
+				test.That(t, r.StatusCode).Equals(http.StatusCreated)
+				test.That(t, r.Header.Get("Location")).Equals("customers/1")
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals(`{"id":1}`)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "gzipped response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+				mock.ExpectGet("path").WillRespond().WithGzippedBody([]byte("content"))
+
+				// ACT
+				r, err := srv.Client().Get(srv.URL + "/path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				defer r.Body.Close()
+				test.IsTrue(t, r.Uncompressed, "decompressed by transport")
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("content")
+			},
+		},
+		{scenario: "using a client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+				mock.ExpectGet("v1/customer/1").WillRespond().WithJSON(map[string]int{"id": 1})
+				c, _ := NewClient("client", URL(srv.URL+"/v1"), Using(srv.Client()))
+
+				// ACT
+				r, err := c.Get(context.Background(), "customer/1")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				result, err := UnmarshalJSON[map[string]int](context.Background(), r)
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(map[string]int{"id": 1})
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "unexpected request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+
+				// ACT
+				r, err := srv.Client().Get(srv.URL + "/path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				defer r.Body.Close()
+				test.That(t, r.StatusCode).Equals(http.StatusInternalServerError)
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("api: unexpected request: GET " + srv.URL + "/path\n")
+
+				err = mock.ExpectationsWereMet()
+				test.IsTrue(t, err != nil && strings.Contains(err.Error(), "request #1: unexpected: GET "+srv.URL+"/path"))
+			},
+		},
+		{scenario: "request error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+				mock.ExpectGet("path").WillReturnError(errors.New("request error"))
+
+				// ACT
+				_, err := srv.Client().Get(srv.URL + "/path")
+
+				// ASSERT
+				test.IsTrue(t, err != nil, "request fails")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv, mock := NewMockServer("api")
+				defer srv.Close()
+				mock.ExpectGet("path").WillTimeout()
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				rq, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/path", nil)
+
+				// ACT
+				_, err := srv.Client().Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(context.DeadlineExceeded)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
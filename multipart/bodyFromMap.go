@@ -103,6 +103,10 @@ func TransformMap[K comparable, V any](fn func(K, V) (string, string, []byte, er
 // will be returned as the error from BodyFromMap; the returned body and
 // content type will be empty and should be ignored.
 //
+// The returned body is the buffer into which the body was written; it is not
+// copied.  To write a body to some other destination or a reusable buffer, use
+// WriteFromMap; to stream a body, use ReaderFromMap.
+//
 // # Example
 //
 // Demonstrates using the `BodyFromMap` function to create a multipart/formdata
@@ -119,10 +123,78 @@ func BodyFromMap[K comparable, V any](
 	m map[K]V,
 	opts ...func(Options),
 ) (string, []byte, error) {
-	handle := func(err error) (string, []byte, error) {
+	buf := &bytes.Buffer{}
+	ct, err := write(buf, m, opts...)
+	if err != nil {
 		return "", nil, fmt.Errorf("multipart.BodyFromMap: %w", err)
 	}
 
+	return ct, buf.Bytes(), nil
+}
+
+// WriteFromMap writes a multipart/formdata encoded body to a supplied writer,
+// applying a transform function to generate form parts for each item in a map,
+// returning the content type for the body.  The configuration functions are the
+// same as those for BodyFromMap.
+//
+// This enables a body to be written directly to a destination, or to a buffer
+// obtained from a pool and reused, avoiding the allocation of a new buffer for
+// each body.
+//
+// If an error is returned the content type should be ignored and the content
+// of the writer is undefined.
+func WriteFromMap[K comparable, V any](
+	w io.Writer,
+	m map[K]V,
+	opts ...func(Options),
+) (string, error) {
+	ct, err := write(w, m, opts...)
+	if err != nil {
+		return "", fmt.Errorf("multipart.WriteFromMap: %w", err)
+	}
+	return ct, nil
+}
+
+// ReaderFromMap returns a reader from which a multipart/formdata encoded body
+// may be read, applying a transform function to generate form parts for each
+// item in a map as the body is read.  The configuration functions are the same
+// as those for BodyFromMap.
+//
+// The body is streamed; it is never held in memory in its entirety.  Any error
+// arising from transforming or writing a part is returned by the Read method of
+// the reader.  The reader must be closed when no longer required; closing the
+// reader before the body has been completely read abandons the encoding of any
+// remaining parts.
+//
+// If an error is returned, the content type and reader should be ignored.
+func ReaderFromMap[K comparable, V any](
+	m map[K]V,
+	opts ...func(Options),
+) (string, io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+
+	mpw, cfg, err := newWriter[K, V](pw, opts...)
+	if err != nil {
+		return "", nil, fmt.Errorf("multipart.ReaderFromMap: %w", err)
+	}
+
+	go func() {
+		if err := writeParts(mpw, m, cfg); err != nil {
+			pw.CloseWithError(fmt.Errorf("multipart.ReaderFromMap: %w", err))
+			return
+		}
+		pw.Close()
+	}()
+
+	return mpw.FormDataContentType(), pr, nil
+}
+
+// newWriter returns a multipart writer writing to a specified writer, together
+// with the configuration resulting from applying any options.
+func newWriter[K comparable, V any](
+	w io.Writer,
+	opts ...func(Options),
+) (*multipart.Writer, *options[K, V], error) {
 	cfg := &options[K, V]{
 		boundary: "boundary",
 		xform: func(k K, v V) (string, string, []byte, error) {
@@ -133,35 +205,60 @@ func BodyFromMap[K comparable, V any](
 		opt(cfg)
 	}
 
-	buf := &bytes.Buffer{}
-	mpw := multipart.NewWriter(buf)
+	mpw := multipart.NewWriter(w)
 	if err := mpwSetBoundary(mpw, cfg.boundary); err != nil {
-		return handle(fmt.Errorf("writer.SetBoundary: %w", err))
+		return nil, nil, fmt.Errorf("writer.SetBoundary: %w", err)
 	}
 
+	return mpw, cfg, nil
+}
+
+// writeParts writes a part for each item in a map to a multipart writer,
+// closing the writer once all parts have been written.
+func writeParts[K comparable, V any](
+	mpw *multipart.Writer,
+	m map[K]V,
+	cfg *options[K, V],
+) error {
 	for k, v := range m {
 		fld, filename, data, err := cfg.xform(k, v)
 		if err != nil {
-			return handle(err)
+			return err
 		}
 
 		file, err := mpwCreateFormFile(mpw, fld, filename)
 		if err != nil {
-			return handle(fmt.Errorf("writer.CreateFormFile: %w", err))
+			return fmt.Errorf("writer.CreateFormFile: %w", err)
 		}
 
 		_, err = ioCopy(file, bytes.NewReader(data))
 		if err != nil {
-			return handle(fmt.Errorf("io.Copy: %w", err))
+			return fmt.Errorf("io.Copy: %w", err)
 		}
 	}
 
 	if err := mpwClose(mpw); err != nil {
-		return handle(fmt.Errorf("writer.Close: %w", err))
+		return fmt.Errorf("writer.Close: %w", err)
+	}
+
+	return nil
+}
+
+// write writes a multipart body to a specified writer, returning the content
+// type of the body
+func write[K comparable, V any](
+	w io.Writer,
+	m map[K]V,
+	opts ...func(Options),
+) (string, error) {
+	mpw, cfg, err := newWriter[K, V](w, opts...)
+	if err != nil {
+		return "", err
 	}
 
-	ct := mpw.FormDataContentType()
-	body := append([]byte{}, buf.Bytes()...)
+	if err := writeParts(mpw, m, cfg); err != nil {
+		return "", err
+	}
 
-	return ct, body, nil
+	return mpw.FormDataContentType(), nil
 }
@@ -1,6 +1,7 @@
 package multipart
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -155,3 +156,126 @@ func TestBodyFromMap(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFromMap(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "successful",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := &bytes.Buffer{}
+
+				// ACT
+				ct, err := WriteFromMap(buf, map[string]string{"part-id": "content data"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, ct).Equals("multipart/form-data; boundary=boundary")
+				test.Bytes(t, buf.Bytes()).Equals([]byte("--boundary\r\n" +
+					"Content-Disposition: form-data; name=\"part-id\"; filename=\"\"\r\n" +
+					"Content-Type: application/octet-stream\r\n" +
+					"\r\n" +
+					"content data\r\n" +
+					"--boundary--\r\n"))
+			},
+		},
+		{scenario: "error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				berr := errors.New("set boundary error")
+
+				og := mpwSetBoundary
+				defer func() { mpwSetBoundary = og }()
+				mpwSetBoundary = func(writer *multipart.Writer, s string) error { return berr }
+
+				// ACT
+				ct, err := WriteFromMap(io.Discard, map[string]string{})
+
+				// ASSERT
+				test.Error(t, err).Is(berr)
+				test.That(t, ct).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestReaderFromMap(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "successful",
+			exec: func(t *testing.T) {
+				// ACT
+				ct, r, err := ReaderFromMap(map[string]string{"part-id": "content data"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, ct).Equals("multipart/form-data; boundary=boundary")
+
+				body, err := io.ReadAll(r)
+				defer r.Close()
+				test.Error(t, err).IsNil()
+				test.Bytes(t, body).Equals([]byte("--boundary\r\n" +
+					"Content-Disposition: form-data; name=\"part-id\"; filename=\"\"\r\n" +
+					"Content-Type: application/octet-stream\r\n" +
+					"\r\n" +
+					"content data\r\n" +
+					"--boundary--\r\n"))
+			},
+		},
+		{scenario: "set boundary error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				berr := errors.New("set boundary error")
+
+				og := mpwSetBoundary
+				defer func() { mpwSetBoundary = og }()
+				mpwSetBoundary = func(writer *multipart.Writer, s string) error { return berr }
+
+				// ACT
+				ct, r, err := ReaderFromMap(map[string]string{})
+
+				// ASSERT
+				test.Error(t, err).Is(berr)
+				test.That(t, ct).Equals("")
+				test.That(t, r).IsNil()
+			},
+		},
+		{scenario: "transformation function error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				maperr := errors.New("map error")
+
+				// ACT
+				_, r, err := ReaderFromMap(
+					map[string]string{"part": "data"},
+					TransformMap(func(k, v string) (string, string, []byte, error) {
+						return "", "", nil, maperr
+					}),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+
+				_, err = io.ReadAll(r)
+				defer r.Close()
+				test.Error(t, err).Is(maperr)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
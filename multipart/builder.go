@@ -0,0 +1,166 @@
+package multipart
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// quoteEscaper escapes the quotes and backslashes in field names and filenames
+// of a Content-Disposition header (as does mime/multipart)
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Builder builds a multipart/form-data encoded body from fields and files
+// added in order, each file with its own content type.  Unlike BodyFromMap,
+// the order of the parts is preserved and the content of files is read from
+// readers, rather than being held in a map.
+//
+// Methods adding parts return the Builder, so that calls may be chained:
+//
+//	b := &multipart.Builder{}
+//	b.AddField("owner", "jane").
+//		AddFile("document", "report.pdf", "application/pdf", f)
+//
+// A zero value Builder is ready to use.
+type Builder struct {
+	parts []builderPart
+}
+
+// builderPart is a field or file added to a Builder
+type builderPart struct {
+	name        string
+	filename    string
+	contentType string
+	value       string
+	r           io.Reader
+	start       int64 // the initial offset of a seekable reader, or -1
+}
+
+// AddField adds a form field with a specified name and value
+func (b *Builder) AddField(name, value string) *Builder {
+	b.parts = append(b.parts, builderPart{name: name, value: value})
+	return b
+}
+
+// AddFile adds a file with a specified field name, filename and content type,
+// with content read from a supplied reader when the body is built or streamed.
+// If no content type is specified, "application/octet-stream" is used.
+//
+// If the reader is an io.Seeker the reader is returned to its current position
+// each time the body is written, so that the body may be written (and a request
+// with the body replayed) more than once.  The reader is not closed.
+func (b *Builder) AddFile(name, filename, contentType string, r io.Reader) *Builder {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	start := int64(-1)
+	if s, ok := r.(io.Seeker); ok {
+		// a reader may implement io.Seeker without being seekable (e.g. an
+		// *os.File for a pipe); such readers are treated as not seekable
+		if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+			start = pos
+		}
+	}
+
+	b.parts = append(b.parts, builderPart{
+		name:        name,
+		filename:    filename,
+		contentType: contentType,
+		r:           r,
+		start:       start,
+	})
+	return b
+}
+
+// Replayable returns true if the body may be written more than once, i.e. the
+// reader of every file added to the builder is seekable.
+func (b *Builder) Replayable() bool {
+	for _, part := range b.parts {
+		if part.r != nil && part.start < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteParts writes the parts added to the builder to a multipart writer, in
+// the order in which they were added.  The writer is not closed.
+//
+// WriteParts may be used to write the parts of a Builder together with other
+// parts, e.g. using Stream or request.MultipartFormDataStream.
+func (b *Builder) WriteParts(w *Writer) error {
+	for _, part := range b.parts {
+		if part.r == nil {
+			if err := w.WriteField(part.name, part.value); err != nil {
+				return fmt.Errorf("field %s: %w", part.name, err)
+			}
+			continue
+		}
+
+		if part.start >= 0 {
+			if _, err := part.r.(io.Seeker).Seek(part.start, io.SeekStart); err != nil {
+				return fmt.Errorf("file %s: seek: %w", part.filename, err)
+			}
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(part.name),
+			quoteEscaper.Replace(part.filename),
+		))
+		h.Set("Content-Type", part.contentType)
+
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return fmt.Errorf("file %s: %w", part.filename, err)
+		}
+		if _, err := ioCopy(pw, part.r); err != nil {
+			return fmt.Errorf("file %s: %w", part.filename, err)
+		}
+	}
+	return nil
+}
+
+// Build returns the content type and the body comprising the parts added to
+// the builder.  The content of every file is read into the body, which is held
+// in memory in its entirety; to avoid this, use Stream.
+//
+// The Boundary option may be used to set the boundary string for the body; if
+// no boundary is configured, a random boundary is used.
+//
+// If an error is returned, the content type and body should be ignored.
+func (b *Builder) Build(opts ...func(Options)) (string, []byte, error) {
+	cfg := &options[string, []byte]{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	buf := &bytes.Buffer{}
+	mpw := multipart.NewWriter(buf)
+	if cfg.boundary != "" {
+		if err := mpwSetBoundary(mpw, cfg.boundary); err != nil {
+			return "", nil, fmt.Errorf("multipart.Builder: %w", err)
+		}
+	}
+
+	if err := b.WriteParts(mpw); err != nil {
+		return "", nil, fmt.Errorf("multipart.Builder: %w", err)
+	}
+	if err := mpwClose(mpw); err != nil {
+		return "", nil, fmt.Errorf("multipart.Builder: writer.Close: %w", err)
+	}
+
+	return mpw.FormDataContentType(), buf.Bytes(), nil
+}
+
+// Stream returns the content type and a reader from which a body comprising
+// the parts added to the builder may be read.  The content of files is read
+// as the body is read; the body is never held in memory in its entirety.  The
+// options and behaviour of the reader are those of the Stream function.
+func (b *Builder) Stream(opts ...func(Options)) (string, io.ReadCloser, error) {
+	return Stream(b.WriteParts, opts...)
+}
@@ -0,0 +1,251 @@
+package multipart
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/blugnu/test"
+)
+
+func TestBuilder(t *testing.T) {
+	// ARRANGE
+	wantBody := "--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"owner\"\r\n" +
+		"\r\n" +
+		"jane\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"document\"; filename=\"report.pdf\"\r\n" +
+		"Content-Type: application/pdf\r\n" +
+		"\r\n" +
+		"pdf content\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"notes\"; filename=\"notes.txt\"\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"\r\n" +
+		"notes\r\n" +
+		"--boundary--\r\n"
+
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "Build",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Builder{}
+				b.AddField("owner", "jane").
+					AddFile("document", "report.pdf", "application/pdf", strings.NewReader("pdf content")).
+					AddFile("notes", "notes.txt", "", strings.NewReader("notes"))
+
+				// ACT
+				ct, body, err := b.Build(Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, ct).Equals("multipart/form-data; boundary=boundary")
+				test.That(t, string(body)).Equals(wantBody)
+			},
+		},
+		{scenario: "Build/random boundary",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Builder{}
+
+				// ACT
+				ct, _, err := b.Build()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, strings.HasPrefix(ct, "multipart/form-data; boundary="))
+				test.IsTrue(t, ct != "multipart/form-data; boundary=boundary")
+			},
+		},
+		{scenario: "Build/set boundary error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				berr := errors.New("set boundary error")
+
+				og := mpwSetBoundary
+				defer func() { mpwSetBoundary = og }()
+				mpwSetBoundary = func(*multipart.Writer, string) error { return berr }
+
+				// ACT
+				ct, body, err := (&Builder{}).Build(Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).Is(berr)
+				test.That(t, ct).Equals("")
+				test.That(t, body).IsNil()
+			},
+		},
+		{scenario: "Build/read error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				readerr := errors.New("read error")
+				b := (&Builder{}).AddFile("file", "file.txt", "", iotest.ErrReader(readerr))
+
+				// ACT
+				_, body, err := b.Build()
+
+				// ASSERT
+				test.Error(t, err).Is(readerr)
+				test.That(t, body).IsNil()
+			},
+		},
+		{scenario: "Build/close error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				closeerr := errors.New("close error")
+
+				og := mpwClose
+				defer func() { mpwClose = og }()
+				mpwClose = func(*multipart.Writer) error { return closeerr }
+
+				// ACT
+				_, _, err := (&Builder{}).Build()
+
+				// ASSERT
+				test.Error(t, err).Is(closeerr)
+			},
+		},
+		{scenario: "Build/seekable readers are replayed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := strings.NewReader("xxpdf content")
+				_, _ = r.Seek(2, io.SeekStart)
+				b := &Builder{}
+				b.AddField("owner", "jane").
+					AddFile("document", "report.pdf", "application/pdf", r).
+					AddFile("notes", "notes.txt", "", strings.NewReader("notes"))
+
+				// ACT
+				_, first, _ := b.Build(Boundary("boundary"))
+				_, second, err := b.Build(Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(first)).Equals(wantBody)
+				test.That(t, string(second)).Equals(wantBody)
+			},
+		},
+		{scenario: "Stream",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Builder{}
+				b.AddField("owner", "jane").
+					AddFile("document", "report.pdf", "application/pdf", strings.NewReader("pdf content")).
+					AddFile("notes", "notes.txt", "", strings.NewReader("notes"))
+
+				// ACT
+				ct, r, err := b.Stream(Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				defer r.Close()
+				body, err := io.ReadAll(r)
+				test.Error(t, err).IsNil()
+				test.That(t, ct).Equals("multipart/form-data; boundary=boundary")
+				test.That(t, string(body)).Equals(wantBody)
+			},
+		},
+		{scenario: "WriteParts/escapes names",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := (&Builder{}).AddFile(`a"b`, `c\d`, "text/plain", strings.NewReader(""))
+				sb := &strings.Builder{}
+				mpw := multipart.NewWriter(sb)
+				_ = mpw.SetBoundary("boundary")
+
+				// ACT
+				err := b.WriteParts(mpw)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, strings.Contains(sb.String(), `name="a\"b"; filename="c\\d"`))
+			},
+		},
+		{scenario: "WriteParts/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				writeerr := errors.New("write error")
+				mpw := multipart.NewWriter(failingWriter{writeerr})
+
+				// ACT
+				fielderr := (&Builder{}).AddField("name", "value").WriteParts(mpw)
+				fileerr := (&Builder{}).AddFile("file", "file.txt", "", strings.NewReader("content")).WriteParts(mpw)
+
+				// ASSERT
+				test.Error(t, fielderr).Is(writeerr)
+				test.Error(t, fileerr).Is(writeerr)
+			},
+		},
+		{scenario: "WriteParts/seek error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				seekerr := errors.New("seek error")
+				s := &failingSeeker{Reader: strings.NewReader("content")}
+				b := (&Builder{}).AddFile("file", "file.txt", "", s)
+				s.err = seekerr
+
+				// ACT
+				err := b.WriteParts(multipart.NewWriter(io.Discard))
+
+				// ASSERT
+				test.Error(t, err).Is(seekerr)
+			},
+		},
+		{scenario: "Replayable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				pr, pw, err := os.Pipe()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer pr.Close()
+				defer pw.Close()
+
+				// ACT
+				fields := (&Builder{}).AddField("name", "value")
+				seekable := (&Builder{}).AddFile("file", "file.txt", "", strings.NewReader("content"))
+				reader := (&Builder{}).AddFile("file", "file.txt", "", io.MultiReader())
+				pipe := (&Builder{}).AddFile("file", "file.txt", "", pr)
+
+				// ASSERT
+				test.IsTrue(t, fields.Replayable(), "fields")
+				test.IsTrue(t, seekable.Replayable(), "seekable reader")
+				test.IsFalse(t, reader.Replayable(), "reader")
+				test.IsFalse(t, pipe.Replayable(), "pipe")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+// failingWriter is an io.Writer that fails to write
+type failingWriter struct{ err error }
+
+// Write implements io.Writer, returning the configured error
+func (fw failingWriter) Write([]byte) (int, error) {
+	return 0, fw.err
+}
+
+// failingSeeker is an io.ReadSeeker that fails to seek once an error is
+// configured
+type failingSeeker struct {
+	io.Reader
+	err error
+}
+
+// Seek implements io.Seeker, returning the configured error (if any)
+func (fs *failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, fs.err
+}
@@ -0,0 +1,110 @@
+package multipart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sync"
+)
+
+// Writer is the multipart writer used to write the parts of a body streamed
+// by Stream.  It is an alias for the mime/multipart Writer, so that functions
+// writing parts need not import both packages.
+type Writer = multipart.Writer
+
+// streamReader is an io.ReadCloser providing a multipart body written to a
+// pipe by a goroutine, which is started when the reader is first read.
+type streamReader struct {
+	*io.PipeReader
+	once  sync.Once
+	write func()
+	done  chan struct{}
+}
+
+// Read implements io.Reader, starting the writing goroutine on the first read
+func (r *streamReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {
+		go func() {
+			defer close(r.done)
+			r.write()
+		}()
+	})
+	return r.PipeReader.Read(p)
+}
+
+// Close implements io.Closer, closing the pipe so that any further write by
+// the writing goroutine fails.  If the reader has not been read, the writing
+// goroutine is never started.
+func (r *streamReader) Close() error {
+	err := r.PipeReader.Close()
+	r.once.Do(func() { close(r.done) })
+	return err
+}
+
+// Done returns a channel that is closed when the goroutine writing the body
+// has returned or, if the reader was closed before being read, when the reader
+// was closed.
+func (r *streamReader) Done() <-chan struct{} {
+	return r.done
+}
+
+// Stream returns a reader from which a multipart/form-data encoded body may be
+// read, together with the content type for the body.  The parts of the body
+// are written by a supplied function as the body is read, so that (for
+// example) the content of large files may be written directly to the wire
+// without the body being held in memory in its entirety.
+//
+// The function is called when the reader is first read, and must not close
+// the writer; the closing boundary is written when the function returns.  Any
+// error returned by the function is returned by the Read method of the reader.
+//
+// The reader must be closed when no longer required; closing the reader before
+// the body has been completely read causes any subsequent write to the writer
+// to fail with io.ErrClosedPipe.
+//
+// The reader also has a Done method, returning a channel that is closed once
+// the function has returned (or, if the reader is closed before being read,
+// once the reader is closed).  This enables (for example) a source read by the
+// function to be safely re-used once a reader has been closed:
+//
+//	_ = body.Close()
+//	<-body.(interface{ Done() <-chan struct{} }).Done()
+//
+// The Boundary option may be used to set the boundary string for the body.
+// Unlike BodyFromMap, if no boundary is configured, a random boundary is used,
+// since the content of streamed parts is typically not known in advance.
+//
+// If an error is returned, the content type and reader should be ignored.
+func Stream(write func(*Writer) error, opts ...func(Options)) (string, io.ReadCloser, error) {
+	cfg := &options[string, []byte]{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	pr, pw := io.Pipe()
+	mpw := multipart.NewWriter(pw)
+	if cfg.boundary != "" {
+		if err := mpwSetBoundary(mpw, cfg.boundary); err != nil {
+			return "", nil, fmt.Errorf("multipart.Stream: %w", err)
+		}
+	}
+
+	r := &streamReader{PipeReader: pr, done: make(chan struct{})}
+	r.write = func() {
+		err := write(mpw)
+		if err == nil {
+			err = mpwClose(mpw)
+		}
+		switch {
+		case err == nil:
+			_ = pw.Close()
+		case errors.Is(err, io.ErrClosedPipe):
+			return
+		default:
+			_ = pw.CloseWithError(fmt.Errorf("multipart.Stream: %w", err))
+		}
+	}
+
+	return mpw.FormDataContentType(), r, nil
+}
@@ -0,0 +1,201 @@
+package multipart
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestStream(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "successful",
+			exec: func(t *testing.T) {
+				// ACT
+				ct, r, err := Stream(func(w *Writer) error {
+					if err := w.WriteField("name", "value"); err != nil {
+						return err
+					}
+					part, err := w.CreateFormFile("file", "file.txt")
+					if err != nil {
+						return err
+					}
+					_, err = io.Copy(part, strings.NewReader("file content"))
+					return err
+				}, Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, ct).Equals("multipart/form-data; boundary=boundary")
+
+				body, err := io.ReadAll(r)
+				defer r.Close()
+				test.Error(t, err).IsNil()
+				test.Bytes(t, body).Equals([]byte("--boundary\r\n" +
+					"Content-Disposition: form-data; name=\"name\"\r\n" +
+					"\r\n" +
+					"value\r\n" +
+					"--boundary\r\n" +
+					"Content-Disposition: form-data; name=\"file\"; filename=\"file.txt\"\r\n" +
+					"Content-Type: application/octet-stream\r\n" +
+					"\r\n" +
+					"file content\r\n" +
+					"--boundary--\r\n"))
+			},
+		},
+		{scenario: "random boundary",
+			exec: func(t *testing.T) {
+				// ACT
+				ct1, r1, _ := Stream(func(*Writer) error { return nil })
+				ct2, r2, _ := Stream(func(*Writer) error { return nil })
+				defer r1.Close()
+				defer r2.Close()
+
+				// ASSERT
+				test.IsTrue(t, strings.HasPrefix(ct1, "multipart/form-data; boundary="))
+				test.IsTrue(t, ct1 != ct2, "boundaries differ")
+			},
+		},
+		{scenario: "parts are written when first read",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				written := make(chan struct{})
+
+				// ACT
+				_, r, _ := Stream(func(*Writer) error {
+					close(written)
+					return nil
+				})
+				defer r.Close()
+
+				// ASSERT
+				select {
+				case <-written:
+					t.Fatal("parts written before the body was read")
+				default:
+				}
+				_, _ = io.ReadAll(r)
+				<-written
+			},
+		},
+		{scenario: "set boundary error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				berr := errors.New("set boundary error")
+
+				og := mpwSetBoundary
+				defer func() { mpwSetBoundary = og }()
+				mpwSetBoundary = func(writer *multipart.Writer, s string) error { return berr }
+
+				// ACT
+				ct, r, err := Stream(func(*Writer) error { return nil }, Boundary("boundary"))
+
+				// ASSERT
+				test.Error(t, err).Is(berr)
+				test.That(t, ct).Equals("")
+				test.That(t, r).IsNil()
+			},
+		},
+		{scenario: "write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				writeerr := errors.New("write error")
+
+				// ACT
+				_, r, err := Stream(func(*Writer) error { return writeerr })
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				_, err = io.ReadAll(r)
+				test.Error(t, err).Is(writeerr)
+			},
+		},
+		{scenario: "close error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				closeerr := errors.New("close error")
+
+				og := mpwClose
+				defer func() { mpwClose = og }()
+				mpwClose = func(*multipart.Writer) error { return closeerr }
+
+				// ACT
+				_, r, _ := Stream(func(*Writer) error { return nil })
+
+				// ASSERT
+				_, err := io.ReadAll(r)
+				test.Error(t, err).Is(closeerr)
+			},
+		},
+		{scenario: "reader closed before body is read",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				result := make(chan error)
+				_, r, _ := Stream(func(w *Writer) error {
+					err := w.WriteField("name", strings.Repeat("x", 1024))
+					result <- err
+					return err
+				})
+				buf := make([]byte, 1)
+				_, _ = r.Read(buf)
+
+				// ACT
+				err := r.Close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Error(t, <-result).Is(io.ErrClosedPipe)
+			},
+		},
+		{scenario: "done when writer returns",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				returned := false
+				_, r, _ := Stream(func(w *Writer) error {
+					defer func() { returned = true }()
+					return w.WriteField("name", strings.Repeat("x", 1024))
+				})
+				buf := make([]byte, 1)
+				_, _ = r.Read(buf)
+
+				// ACT
+				_ = r.Close()
+				<-r.(interface{ Done() <-chan struct{} }).Done()
+
+				// ASSERT
+				test.IsTrue(t, returned, "writer returned")
+			},
+		},
+		{scenario: "done when closed before read",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				called := false
+				_, r, _ := Stream(func(*Writer) error {
+					called = true
+					return nil
+				})
+
+				// ACT
+				_ = r.Close()
+				<-r.(interface{ Done() <-chan struct{} }).Done()
+				_, err := r.Read(make([]byte, 1))
+
+				// ASSERT
+				test.Error(t, err).Is(io.ErrClosedPipe)
+				test.IsFalse(t, called, "writer not called")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// JSONPart returns a function for use with MapFromMultipartFormData, which
+// unmarshals the data of each part as JSON into a value of type V, keyed by
+// the field name of the part.
+//
+// An error wrapping ErrInvalidJSON is returned for any part that does not
+// contain valid JSON.
+func JSONPart[K ~string, V any]() func(field, filename string, data []byte) (K, V, error) {
+	return func(field, _ string, data []byte) (K, V, error) {
+		var v V
+		if err := json.Unmarshal(data, &v); err != nil {
+			return K(field), v, fmt.Errorf("%w: part %q: %w", ErrInvalidJSON, field, err)
+		}
+		return K(field), v, nil
+	}
+}
+
+// MapFromMultipartFormDataJSON is a generic function that parses an http.Response
+// body expected to contain multipart form data in which each part is a JSON
+// document, unmarshalling each part into a value of type V, keyed by the field
+// name of the part.
+//
+// It is equivalent to MapFromMultipartFormData with a JSONPart transform
+// function.
+func MapFromMultipartFormDataJSON[K ~string, V any](ctx context.Context, r *http.Response) (map[K]V, error) {
+	return MapFromMultipartFormData(ctx, r, JSONPart[K, V]())
+}
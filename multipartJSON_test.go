@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestMapFromMultipartFormDataJSON(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	type customer struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type field string
+
+	response := func(parts ...string) *http.Response {
+		body := ""
+		for _, p := range parts {
+			body += "--boundary\r\n" + p + "\r\n"
+		}
+		body += "--boundary--"
+		return &http.Response{
+			Header: http.Header{"Content-Type": {"multipart/form-data; boundary=boundary"}},
+			Body:   io.NopCloser(bytes.NewReader([]byte(body))),
+		}
+	}
+	part := func(name, data string) string {
+		return "Content-Disposition: form-data; name=\"" + name + "\"\r\n" +
+			"Content-Type: application/json\r\n" +
+			"\r\n" +
+			data
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "parts unmarshalled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response(
+					part("a", `{"id":1,"name":"Jane"}`),
+					part("b", `{"id":2,"name":"John"}`),
+				)
+
+				// ACT
+				result, err := MapFromMultipartFormDataJSON[field, customer](ctx, r)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, result).Equals(map[field]customer{
+					"a": {ID: 1, Name: "Jane"},
+					"b": {ID: 2, Name: "John"},
+				})
+			},
+		},
+		{scenario: "invalid json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response(part("a", `not json`))
+
+				// ACT
+				result, err := MapFromMultipartFormDataJSON[string, customer](ctx, r)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "JSONPart with MapFromMultipartFormData",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response(part("a", `[1,2,3]`))
+
+				// ACT
+				result, err := MapFromMultipartFormData(ctx, r, JSONPart[string, []int]())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result["a"]).Equals([]int{1, 2, 3})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
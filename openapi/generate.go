@@ -0,0 +1,488 @@
+// Package openapi provides a generator of typed clients from OpenAPI 3.x
+// specifications.  Generated clients are built on the http.HttpClient
+// interface and request options provided by the github.com/blugnu/http
+// module, so retain the retry, error handling and mocking capabilities of
+// that module.
+//
+// The generator is usually invoked using the openapi-gen command, typically
+// from a go:generate directive:
+//
+//	//go:generate go run github.com/blugnu/http/cmd/openapi-gen -spec api.json -package users -out client.go
+//
+// Only JSON encoded specifications are supported.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidSpec    = errors.New("invalid specification")
+	ErrGeneratingCode = errors.New("error generating code")
+)
+
+// Config holds the configuration of the code to be generated
+type Config struct {
+	// Package is the name of the package of the generated code (required)
+	Package string
+
+	// ClientType is the name of the generated client type; if not
+	// specified, "Client" is used
+	ClientType string
+}
+
+// generator holds the state of a code generation
+type generator struct {
+	cfg     Config
+	spec    *spec
+	buf     *bytes.Buffer
+	imports map[string]bool
+}
+
+// Generate generates Go source code for a typed client from a JSON encoded
+// OpenAPI 3.x specification.
+//
+// The generated code provides:
+//
+//   - a struct type for each object schema in the components of the spec;
+//   - a client type wrapping an http.HttpClient, with a constructor;
+//   - a method on the client type for each operation in the spec.
+//
+// Each method accepts a context, a parameter for each path parameter of the
+// operation, a body parameter if the operation accepts a JSON request body
+// and any additional request options (e.g. to set query parameters).
+//
+// If the operation has a successful (2xx) response with JSON content, the
+// method returns the decoded response body and an error; otherwise the method
+// returns the *http.Response and an error.
+//
+// Every successful status code declared for the operation is accepted (see:
+// request.AcceptStatus); a "2XX" response accepts any successful status.  If
+// a response has a status declared without JSON content (e.g. 204), the method
+// returns the zero value of the result type without decoding the body.
+func Generate(b []byte, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("openapi.Generate: %w: package name is required", ErrGeneratingCode)
+	}
+	if cfg.ClientType == "" {
+		cfg.ClientType = "Client"
+	}
+
+	s := &spec{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("openapi.Generate: %w: %w", ErrInvalidSpec, err)
+	}
+	if !strings.HasPrefix(s.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi.Generate: %w: unsupported version: %q", ErrInvalidSpec, s.OpenAPI)
+	}
+
+	g := &generator{
+		cfg:     cfg,
+		spec:    s,
+		buf:     &bytes.Buffer{},
+		imports: map[string]bool{},
+	}
+
+	body := &bytes.Buffer{}
+	g.buf = body
+	g.writeSchemas()
+	if err := g.writeClient(); err != nil {
+		return nil, fmt.Errorf("openapi.Generate: %w: %w", ErrGeneratingCode, err)
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "// Code generated by openapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(src, "package %s\n\n", cfg.Package)
+	if len(g.imports) > 0 {
+		fmt.Fprintf(src, "import (\n")
+		// standard library imports are grouped before any others
+		std, other := []string{}, []string{}
+		for _, imp := range sortedKeys(g.imports) {
+			if strings.Contains(imp, ".") {
+				other = append(other, imp)
+				continue
+			}
+			std = append(std, imp)
+		}
+		for _, imp := range std {
+			fmt.Fprintf(src, "\t%q\n", imp)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			fmt.Fprintf(src, "\n")
+		}
+		for _, imp := range other {
+			fmt.Fprintf(src, "\t%q\n", imp)
+		}
+		fmt.Fprintf(src, ")\n\n")
+	}
+	src.Write(body.Bytes())
+
+	result, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("openapi.Generate: %w: %w", ErrGeneratingCode, err)
+	}
+	return result, nil
+}
+
+// printf writes formatted output to the generated code
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+// comment writes a (possibly multi-line) comment to the generated code
+func (g *generator) comment(indent string, s string) {
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		g.printf("%s// %s\n", indent, strings.TrimRight(line, " \t\r"))
+	}
+}
+
+// writeSchemas writes a type declaration for each schema in the components
+// of the spec
+func (g *generator) writeSchemas() {
+	for _, name := range sortedKeys(g.spec.Components.Schemas) {
+		s := g.spec.Components.Schemas[name]
+		typeName := exportedName(name)
+		if s.Description != "" {
+			g.comment("", typeName+": "+s.Description)
+		}
+		g.printf("type %s %s\n\n", typeName, g.goType(s))
+	}
+}
+
+// goType returns the Go type corresponding to a schema
+func (g *generator) goType(s *schema) string {
+	if s == nil {
+		return "any"
+	}
+
+	if s.Ref != "" {
+		return exportedName(s.Ref[strings.LastIndex(s.Ref, "/")+1:])
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+
+	case "boolean":
+		return "bool"
+
+	case "array":
+		return "[]" + g.goType(s.Items)
+
+	case "object", "":
+		if len(s.Properties) > 0 {
+			return g.structType(s)
+		}
+		if len(s.AdditionalProperties) > 0 && s.AdditionalProperties[0] == '{' {
+			ap := &schema{}
+			if err := json.Unmarshal(s.AdditionalProperties, ap); err == nil {
+				return "map[string]" + g.goType(ap)
+			}
+		}
+		if s.Type == "object" {
+			return "map[string]any"
+		}
+	}
+	return "any"
+}
+
+// structType returns a struct type literal for an object schema with
+// properties; properties that are not required are tagged omitempty
+func (g *generator) structType(s *schema) string {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("struct {\n")
+	for _, name := range sortedKeys(s.Properties) {
+		p := s.Properties[name]
+		if p.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(p.Description), "\n") {
+				fmt.Fprintf(sb, "\t// %s\n", strings.TrimRight(line, " \t\r"))
+			}
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(sb, "\t%s %s `json:%q`\n", exportedName(name), g.goType(p), tag)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// writeClient writes the client type, its constructor and a method for each
+// operation in the spec
+func (g *generator) writeClient() error {
+	g.imports["context"] = true
+	g.imports["github.com/blugnu/http"] = true
+
+	ct := g.cfg.ClientType
+	g.printf("// %s is a client for the API, wrapping an http.HttpClient\n", ct)
+	g.printf("type %s struct {\n\tclient http.HttpClient\n}\n\n", ct)
+	g.printf("// New%s returns a new %s performing requests using a specified http.HttpClient\n", ct, ct)
+	g.printf("func New%s(c http.HttpClient) *%s {\n\treturn &%s{client: c}\n}\n\n", ct, ct, ct)
+	g.printf("// do constructs and performs a request\n")
+	g.printf("func (c *%s) do(ctx context.Context, method string, path string, opts []http.RequestOption) (*http.Response, error) {\n", ct)
+	g.printf("\trq, err := c.client.NewRequest(ctx, method, path, opts...)\n")
+	g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	g.printf("\treturn c.client.Do(rq)\n}\n\n")
+
+	names := map[string]string{}
+	for _, path := range sortedKeys(g.spec.Paths) {
+		item := g.spec.Paths[path]
+		for _, method := range methods {
+			op, ok := item.Operations[method]
+			if !ok {
+				continue
+			}
+
+			name := exportedName(op.OperationID)
+			if op.OperationID == "" {
+				name = exportedName(method + " " + path)
+			}
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("%s %s: operation name %s is also used by %s", strings.ToUpper(method), path, name, other)
+			}
+			names[name] = strings.ToUpper(method) + " " + path
+
+			if err := g.writeOperation(name, strings.ToUpper(method), path, item.Parameters, op); err != nil {
+				return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeOperation writes the method for an operation
+func (g *generator) writeOperation(name, method, path string, common []parameter, op *operation) error {
+	// path parameters are determined by the path template; the type of each
+	// is taken from any corresponding parameter definition (string by default)
+	params := map[string]parameter{}
+	for _, p := range append(append([]parameter{}, common...), op.Parameters...) {
+		if p.In == "path" {
+			params[p.Name] = p
+		}
+	}
+
+	type arg struct{ name, goName, goType string }
+	args := []arg{}
+	segments := []string{}
+	rest := strings.TrimPrefix(path, "/")
+	for {
+		i := strings.Index(rest, "{")
+		j := strings.Index(rest, "}")
+		if i < 0 || j < i {
+			if rest != "" {
+				segments = append(segments, fmt.Sprintf("%q", rest))
+			}
+			break
+		}
+		if i > 0 {
+			segments = append(segments, fmt.Sprintf("%q", rest[:i]))
+		}
+		pn := rest[i+1 : j]
+		a := arg{name: pn, goName: unexportedName(pn), goType: "string"}
+		if p, ok := params[pn]; ok && p.Schema != nil {
+			a.goType = g.goType(p.Schema)
+		}
+		args = append(args, a)
+		segments = append(segments, fmt.Sprintf("url.PathEscape(fmt.Sprint(%s))", a.goName))
+		rest = rest[j+1:]
+	}
+	if len(args) > 0 {
+		g.imports["fmt"] = true
+		g.imports["net/url"] = true
+	}
+	if len(segments) == 0 {
+		segments = []string{`""`}
+	}
+
+	var bodyType string
+	if op.RequestBody != nil {
+		if s := jsonContent(op.RequestBody.Content); s != nil {
+			bodyType = g.goType(s)
+		}
+	}
+
+	// the successful (2xx) status codes declared for the operation must be
+	// accepted by the client, which otherwise accepts only 200; the result
+	// type (if any) is that of the first of these with JSON content, and no
+	// result is decoded from a response with any status declared without
+	// JSON content (e.g. 204)
+	var resultType string
+	codes := []string{}
+	empty := []string{}
+	anySuccess := false
+	for _, code := range sortedKeys(op.Responses) {
+		s := jsonContent(op.Responses[code].Content)
+		switch {
+		case strings.EqualFold(code, "2XX"):
+			anySuccess = true
+		case isSuccessCode(code):
+			codes = append(codes, code)
+			if s == nil {
+				empty = append(empty, code)
+			}
+		default:
+			continue
+		}
+		if s != nil && resultType == "" {
+			resultType = g.goType(s)
+		}
+	}
+	if anySuccess && !slices.Contains(empty, "204") {
+		empty = append(empty, "204")
+	}
+
+	preopts := []string{}
+	switch {
+	case anySuccess:
+		preopts = append(preopts, "request.AcceptStatusRange(200, 299)")
+	case len(codes) > 0:
+		preopts = append(preopts, "request.AcceptStatus("+strings.Join(codes, ", ")+")")
+	}
+	if bodyType != "" {
+		preopts = append(preopts, "request.JSONBody(body)")
+	}
+	if len(preopts) > 0 {
+		g.imports["github.com/blugnu/http/request"] = true
+	}
+
+	// doc comment
+	g.printf("// %s performs %s %s\n", name, method, path)
+	if op.Summary != "" {
+		g.printf("//\n")
+		g.comment("", op.Summary)
+	}
+	if op.Description != "" {
+		g.printf("//\n")
+		g.comment("", op.Description)
+	}
+	if op.Deprecated {
+		g.printf("//\n// Deprecated: this operation is deprecated by the API.\n")
+	}
+
+	// signature
+	sig := []string{"ctx context.Context"}
+	for _, a := range args {
+		sig = append(sig, a.goName+" "+a.goType)
+	}
+	if bodyType != "" {
+		sig = append(sig, "body "+bodyType)
+	}
+	sig = append(sig, "opts ...http.RequestOption")
+
+	result := "(*http.Response, error)"
+	if resultType != "" {
+		result = "(" + resultType + ", error)"
+	}
+	g.printf("func (c *%s) %s(%s) %s {\n", g.cfg.ClientType, name, strings.Join(sig, ", "), result)
+
+	// body
+	g.printf("\tpath := %s\n", strings.Join(segments, " + "))
+	if len(preopts) > 0 {
+		g.printf("\topts = append([]http.RequestOption{%s}, opts...)\n", strings.Join(preopts, ", "))
+	}
+	method = "http.Method" + string(method[0]) + strings.ToLower(method[1:])
+	if resultType == "" {
+		g.printf("\treturn c.do(ctx, %s, path, opts)\n}\n\n", method)
+		return nil
+	}
+	g.printf("\tr, err := c.do(ctx, %s, path, opts)\n", method)
+	g.printf("\tif err != nil {\n\t\tvar zero %s\n\t\treturn zero, err\n\t}\n", resultType)
+	if len(empty) > 0 {
+		cond := make([]string, len(empty))
+		for i, code := range empty {
+			cond[i] = "r.StatusCode == " + code
+		}
+		g.printf("\tif %s {\n\t\tvar zero %s\n\t\treturn zero, nil\n\t}\n", strings.Join(cond, " || "), resultType)
+	}
+	g.printf("\treturn http.UnmarshalJSON[%s](ctx, r)\n}\n\n", resultType)
+	return nil
+}
+
+// isSuccessCode returns true if a response code of an operation is a specific
+// successful (2xx) status code
+func isSuccessCode(code string) bool {
+	return len(code) == 3 && code[0] == '2' && strings.Trim(code, "0123456789") == ""
+}
+
+// exportedName returns an exported Go identifier derived from a name,
+// removing any characters not valid in an identifier and capitalising
+// the first letter of each word
+func exportedName(s string) string {
+	sb := &strings.Builder{}
+	upper := true
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			sb.WriteRune(r)
+		default:
+			upper = true
+		}
+	}
+	result := sb.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "X" + result
+	}
+	return result
+}
+
+// unexportedName returns an unexported Go identifier derived from a name
+func unexportedName(s string) string {
+	name := exportedName(s)
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	name = string(r)
+	if isKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// keywords holds the Go keywords (and predeclared identifiers used by the
+// generated code) that cannot be used as parameter names
+var keywords = func() map[string]bool {
+	m := map[string]bool{}
+	for _, k := range []string{
+		"break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type",
+		"var", "ctx", "body", "opts", "path", "c", "r", "err", "zero", "url", "fmt",
+		"http", "request", "context",
+	} {
+		m[k] = true
+	}
+	return m
+}()
+
+// isKeyword returns true if a name cannot be used as a parameter name
+func isKeyword(s string) bool {
+	return keywords[s]
+}
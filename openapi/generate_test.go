@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestGenerate(t *testing.T) {
+	// ARRANGE
+	testSpec, err := os.ReadFile("testdata/users.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no package name",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate(testSpec, Config{})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrGeneratingCode)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "invalid json",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate([]byte("not json"), Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidSpec)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "unsupported version",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate([]byte(`{"swagger":"2.0"}`), Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidSpec)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "duplicate operation name",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				spec := `{"openapi":"3.0.0","paths":{
+					"/a":{"get":{"operationId":"op"}},
+					"/b":{"get":{"operationId":"op"}}
+				}}`
+
+				// ACT
+				result, err := Generate([]byte(spec), Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrGeneratingCode)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "valid spec",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate(testSpec, Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				src := string(result)
+				for _, want := range []string{
+					"// Code generated by openapi-gen. DO NOT EDIT.",
+					"package api",
+					"\"net/url\"\n\n\t\"github.com/blugnu/http\"",
+					"// User: a user",
+					"Id     int32             `json:\"id\"`",
+					"Name   string            `json:\"name,omitempty\"`",
+					"Score  float64           `json:\"score,omitempty\"`",
+					"Active bool              `json:\"active,omitempty\"`",
+					"Tags   map[string]string `json:\"tags,omitempty\"`",
+					"func NewClient(c http.HttpClient) *Client {",
+					"func (c *Client) GetUsers(ctx context.Context, opts ...http.RequestOption) ([]User, error) {",
+					"func (c *Client) CreateUser(ctx context.Context, body User, opts ...http.RequestOption) (User, error) {",
+					"opts = append([]http.RequestOption{request.AcceptStatus(200, 204)}, opts...)",
+					"if r.StatusCode == 204 {\n\t\tvar zero []User\n\t\treturn zero, nil\n\t}",
+					"opts = append([]http.RequestOption{request.AcceptStatus(201), request.JSONBody(body)}, opts...)",
+					"// returns a user",
+					"func (c *Client) GetUser(ctx context.Context, id int64, opts ...http.RequestOption) (User, error) {",
+					"path := \"users/\" + url.PathEscape(fmt.Sprint(id))",
+					"return http.UnmarshalJSON[User](ctx, r)",
+					"// Deprecated: this operation is deprecated by the API.",
+					"func (c *Client) DeleteUser(ctx context.Context, id int64, opts ...http.RequestOption) (*http.Response, error) {",
+					"opts = append([]http.RequestOption{request.AcceptStatus(204)}, opts...)\n\treturn c.do(ctx, http.MethodDelete, path, opts)",
+				} {
+					test.IsTrue(t, strings.Contains(src, want), want)
+				}
+			},
+		},
+		{scenario: "any successful status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				spec := `{"openapi":"3.0.0","paths":{
+					"/a":{"get":{"operationId":"op","responses":{
+						"2XX":{"content":{"application/json":{"schema":{"type":"string"}}}},
+						"default":{"description":"error"}
+					}}}
+				}}`
+
+				// ACT
+				result, err := Generate([]byte(spec), Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				src := string(result)
+				test.IsTrue(t, strings.Contains(src, "opts = append([]http.RequestOption{request.AcceptStatusRange(200, 299)}, opts...)"))
+				test.IsTrue(t, strings.Contains(src, "if r.StatusCode == 204 {"))
+			},
+		},
+		{scenario: "generated client is up to date",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				generated, err := os.ReadFile("internal/users/client.go")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// ACT
+				result, err := Generate(testSpec, Config{Package: "users"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(result), "run go generate ./openapi/...").Equals(string(generated))
+			},
+		},
+		{scenario: "custom client type",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate(testSpec, Config{Package: "api", ClientType: "Users"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, strings.Contains(string(result), "func NewUsers(c http.HttpClient) *Users {"))
+			},
+		},
+		{scenario: "no operations",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := Generate([]byte(`{"openapi":"3.1.0"}`), Config{Package: "api"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, strings.Contains(string(result), "net/url"))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		name       string
+		exported   string
+		unexported string
+	}{
+		{name: "id", exported: "Id", unexported: "id"},
+		{name: "user-id", exported: "UserId", unexported: "userId"},
+		{name: "get /users/{id}", exported: "GetUsersId", unexported: "getUsersId"},
+		{name: "2fa", exported: "X2fa", unexported: "x2fa"},
+		{name: "type", exported: "Type", unexported: "type_"},
+		{name: "path", exported: "Path", unexported: "path_"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT & ASSERT
+			test.That(t, exportedName(tc.name), "exported").Equals(tc.exported)
+			test.That(t, unexportedName(tc.name), "unexported").Equals(tc.unexported)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/http"
+	"github.com/blugnu/test"
+)
+
+func TestClient(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "GetUsers/200 OK",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := http.NewMockClient("users")
+				mock.ExpectGet("users").WillRespond().WithJSON([]User{{Id: 1, Name: "jane"}})
+
+				// ACT
+				result, err := NewClient(c).GetUsers(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals([]User{{Id: 1, Name: "jane"}})
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "GetUsers/204 No Content",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := http.NewMockClient("users")
+				mock.ExpectGet("users").WillRespond().WithStatusCode(http.StatusNoContent)
+
+				// ACT
+				result, err := NewClient(c).GetUsers(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, result == nil, "no users")
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "CreateUser/201 Created",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := http.NewMockClient("users")
+				mock.ExpectPost("users").
+					WithBody([]byte(`{"id":0,"name":"jane"}`)).
+					WillRespond().
+					WithStatusCode(http.StatusCreated).
+					WithJSON(User{Id: 1, Name: "jane"})
+
+				// ACT
+				result, err := NewClient(c).CreateUser(ctx, User{Name: "jane"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(User{Id: 1, Name: "jane"})
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "DeleteUser/204 No Content",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := http.NewMockClient("users")
+				mock.ExpectDelete("users/1").WillRespond().WithStatusCode(http.StatusNoContent)
+
+				// ACT
+				r, err := NewClient(c).DeleteUser(ctx, 1)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusNoContent)
+				test.Error(t, mock.ExpectationsWereMet()).IsNil()
+			},
+		},
+		{scenario: "undeclared status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, mock := http.NewMockClient("users")
+				mock.ExpectDelete("users/1").WillRespond().WithStatusCode(http.StatusAccepted)
+
+				// ACT
+				_, err := NewClient(c).DeleteUser(ctx, 1)
+
+				// ASSERT
+				test.Error(t, err).Is(http.ErrUnexpectedStatusCode)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
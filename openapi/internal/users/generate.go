@@ -0,0 +1,6 @@
+// Package users provides a client generated from the specification used to
+// test the openapi package, ensuring that generated code compiles and performs
+// requests as expected.
+package users
+
+//go:generate go run ../../../cmd/openapi-gen -spec ../../testdata/users.json -package users -out client.go
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// spec holds the parts of an OpenAPI 3.x document used by the generator
+type spec struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// pathItem holds the operations for a path, keyed by (lowercase) http method.
+// Path-level parameters are held separately and apply to every operation.
+type pathItem struct {
+	Parameters []parameter
+	Operations map[string]*operation
+}
+
+// methods identifies the keys of a path item which describe operations
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// UnmarshalJSON implements json.Unmarshaler for a pathItem, separating the
+// operations from any other (non-operation) properties of the path item
+func (p *pathItem) UnmarshalJSON(b []byte) error {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	p.Operations = map[string]*operation{}
+	for _, m := range methods {
+		if r, ok := raw[m]; ok {
+			op := &operation{}
+			if err := json.Unmarshal(r, op); err != nil {
+				return err
+			}
+			p.Operations[m] = op
+		}
+	}
+
+	if r, ok := raw["parameters"]; ok {
+		return json.Unmarshal(r, &p.Parameters)
+	}
+	return nil
+}
+
+// operation describes an operation on a path
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description"`
+	Deprecated  bool                `json:"deprecated"`
+	Parameters  []parameter         `json:"parameters"`
+	RequestBody *requestBody        `json:"requestBody"`
+	Responses   map[string]response `json:"responses"`
+}
+
+// parameter describes a parameter of an operation
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+// requestBody describes the request body of an operation
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+// response describes a response to an operation
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content"`
+}
+
+// mediaType describes the content of a request or response body of a
+// particular media type
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// schema describes a data type
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+// schemaType is the type of a schema.  In OpenAPI 3.1 a type may be specified
+// as an array of types (e.g. ["string", "null"]); the first type other than
+// "null" is used.
+type schemaType string
+
+// UnmarshalJSON implements json.Unmarshaler for schemaType, accepting either
+// a string or an array of strings
+func (t *schemaType) UnmarshalJSON(b []byte) error {
+	if strings.HasPrefix(string(b), "[") {
+		types := []string{}
+		if err := json.Unmarshal(b, &types); err != nil {
+			return err
+		}
+		for _, s := range types {
+			if s != "null" {
+				*t = schemaType(s)
+				return nil
+			}
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*t = schemaType(s)
+	return nil
+}
+
+// jsonContent returns the schema of the JSON content (if any) in a map of
+// media types.  Any media type of application/json or with a +json suffix
+// is considered to be JSON.
+//
+// If more than one JSON media type is present, the first in lexical order
+// is used.
+func jsonContent(content map[string]mediaType) *schema {
+	for _, k := range sortedKeys(content) {
+		ct, _, _ := strings.Cut(k, ";")
+		if ct == "application/json" || strings.HasSuffix(ct, "+json") {
+			if s := content[k].Schema; s != nil {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
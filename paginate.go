@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// PageFunc is a function that decodes a page of a paginated collection from a
+// response, returning the items of the page together with the href of the
+// next page, or an empty string if the page is the last.  A relative href is
+// resolved against the url of the request for the page (r.Request.URL).
+//
+// NextLink and NextPageToken provide PageFuncs for common pagination schemes.
+type PageFunc[T any] func(ctx context.Context, r *http.Response) (items []T, next string, err error)
+
+// Pager iterates over the items of a paginated collection, requesting each
+// page as required (see: Paginate).
+type Pager[T any] struct {
+	ctx   context.Context
+	c     HttpClient
+	opts  []RequestOption
+	page  PageFunc[T]
+	next  func() (*http.Request, *http.Response, error)
+	items []T
+	item  T
+	meta  ResponseMeta
+	err   error
+}
+
+// Paginate returns a Pager iterating over the items of a paginated collection,
+// starting with a GET request for a specified path.  A supplied PageFunc
+// decodes the items of each page and identifies the next page (if any); each
+// page is requested only when the items of the preceding page have been
+// consumed:
+//
+//	pager := http.Paginate(ctx, client, "customers", http.NextLink[Customer])
+//	for pager.Next() {
+//		customer := pager.Item()
+//		...
+//	}
+//	if err := pager.Err(); err != nil {
+//		return err
+//	}
+//
+// Any request options (and any authentication or headers configured on the
+// client) are applied to the request for every page, except that the url of
+// the request for each subsequent page is the url identified by the preceding
+// page; options modifying the url (e.g. request.Query) affect only the request
+// for the first page.
+func Paginate[T any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	page PageFunc[T],
+	opts ...RequestOption,
+) *Pager[T] {
+	p := &Pager[T]{ctx: ctx, c: c, opts: opts, page: page}
+	p.next = func() (*http.Request, *http.Response, error) {
+		rq, err := c.NewRequest(ctx, http.MethodGet, path, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := c.Do(rq)
+		return rq, r, err
+	}
+	return p
+}
+
+// Next advances the pager to the next item, requesting the next page of the
+// collection if required, returning false when there are no more items or if
+// a request fails (see: Err).
+func (p *Pager[T]) Next() bool {
+	for len(p.items) == 0 {
+		if p.next == nil || p.err != nil {
+			p.item = *new(T)
+			return false
+		}
+		p.fetch()
+	}
+	p.item, p.items = p.items[0], p.items[1:]
+	return true
+}
+
+// Item returns the current item, i.e. the item to which the pager was
+// advanced by the most recent call to Next
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Meta returns the metadata of the response for the most recently requested
+// page, e.g. to obtain any X-Total-Count of the collection
+func (p *Pager[T]) Meta() ResponseMeta {
+	return p.meta
+}
+
+// Err returns any error that ended the iteration, i.e. an error performing
+// the request for a page or decoding the response
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns all (remaining) items of the collection, requesting all
+// (remaining) pages.  If any request fails, the items obtained up to that
+// point are returned together with the error.
+func (p *Pager[T]) All() ([]T, error) {
+	items := []T{}
+	for p.Next() {
+		items = append(items, p.item)
+	}
+	return items, p.err
+}
+
+// fetch requests the next page, updating the items of the pager and
+// establishing the request for any subsequent page
+func (p *Pager[T]) fetch() {
+	rq, r, err := p.next()
+	p.next = nil
+	p.meta = MetaOf(r)
+	if r != nil {
+		defer func() { _ = closeBody(p.ctx, r.Body) }()
+	}
+	if err != nil {
+		p.err = errorcontext.Errorf(p.ctx, "http.Paginate: %w", err)
+		return
+	}
+	if r.Request == nil {
+		r.Request = rq
+	}
+
+	items, next, err := p.page(p.ctx, r)
+	if err != nil {
+		p.err = errorcontext.Errorf(p.ctx, "http.Paginate: %w", err)
+		return
+	}
+	p.items = items
+
+	if next == "" {
+		return
+	}
+	u, err := r.Request.URL.Parse(next)
+	if err != nil {
+		p.err = errorcontext.Errorf(p.ctx, "http.Paginate: %w", InvalidURLError{URL: next, Err: err})
+		return
+	}
+	p.next = func() (*http.Request, *http.Response, error) {
+		// the request is initialised with any options but the url is that of
+		// the next page, which already includes any query of the first page
+		rq, err := p.c.NewRequest(p.ctx, http.MethodGet, "", p.opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		cpy := *u
+		rq.URL, rq.Host = &cpy, u.Host
+		r, err := p.c.Do(rq)
+		return rq, r, err
+	}
+}
+
+// NextLink is a PageFunc for collections with pages comprising a JSON array
+// of items, identifying the next page using a "next" link in the Link header
+// of each response (RFC 8288), e.g.
+//
+//	Link: <https://api.example.com/customers?page=2>; rel="next"
+func NextLink[T any](ctx context.Context, r *http.Response) ([]T, string, error) {
+	items, err := UnmarshalJSON[[]T](ctx, r)
+	if err != nil {
+		return nil, "", err
+	}
+	next, _ := ParseLinkHeader(r.Header.Values("Link")...).Next()
+	return items, next, nil
+}
+
+// NextPageToken returns a PageFunc for collections with pages comprising a JSON
+// object holding the items of the page in an array field together with a token
+// identifying the next page in a string field, e.g.
+//
+//	{"items": [...], "nextPageToken": "abc"}
+//
+// The next page is requested by setting a specified query parameter to the
+// token, on the url of the request for the current page.  An empty (or
+// absent) token identifies the last page:
+//
+//	pager := http.Paginate(ctx, client, "customers",
+//		http.NextPageToken[Customer]("items", "nextPageToken", "pageToken"),
+//	)
+func NextPageToken[T any](itemsField, tokenField, param string) PageFunc[T] {
+	return func(ctx context.Context, r *http.Response) ([]T, string, error) {
+		page, err := UnmarshalJSON[map[string]json.RawMessage](ctx, r)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := []T{}
+		if raw, ok := page[itemsField]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, "", fmt.Errorf("%w: %s: %w", ErrInvalidJSON, itemsField, err)
+			}
+		}
+
+		token := ""
+		if raw, ok := page[tokenField]; ok && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &token); err != nil {
+				return nil, "", fmt.Errorf("%w: %s: %w", ErrInvalidJSON, tokenField, err)
+			}
+		}
+		if token == "" {
+			return items, "", nil
+		}
+
+		u := *r.Request.URL
+		q := u.Query()
+		q.Set(param, token)
+		u.RawQuery = q.Encode()
+		return items, u.String(), nil
+	}
+}
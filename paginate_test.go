@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestPaginate(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server returns a test server providing a collection of 5 items in pages
+	// of 2, using Link headers or page tokens, recording the url and
+	// X-Test header of each request
+	server := func(t *testing.T, requests *[]string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests = append(*requests, r.URL.String()+" "+r.Header.Get("X-Test"))
+
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if token := r.URL.Query().Get("token"); token != "" {
+				page, _ = strconv.Atoi(token)
+			}
+			items := []int{}
+			for i := page * 2; i < min(page*2+2, 5); i++ {
+				items = append(items, i+1)
+			}
+
+			switch r.URL.Path {
+			case "/links":
+				if page < 2 {
+					w.Header().Set("Link", fmt.Sprintf(`<links?page=%d&size=2>; rel="next"`, page+1))
+				}
+				w.Header().Set("X-Total-Count", "5")
+				b, _ := json.Marshal(items)
+				_, _ = w.Write(b)
+
+			case "/tokens":
+				token := ""
+				if page < 2 {
+					token = strconv.Itoa(page + 1)
+				}
+				b, _ := json.Marshal(items)
+				_, _ = fmt.Fprintf(w, `{"items":%s,"next":%q}`, b, token)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "NextLink",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []string{}
+				srv := server(t, &requests)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				pager := Paginate(ctx, c, "links", NextLink[int],
+					request.QueryP("size", 2),
+					request.Header("X-Test", "value"),
+				)
+				items := []int{}
+				for pager.Next() {
+					items = append(items, pager.Item())
+				}
+
+				// ASSERT
+				test.Error(t, pager.Err()).IsNil()
+				test.Slice(t, items).Equals([]int{1, 2, 3, 4, 5})
+				test.That(t, pager.Meta().TotalCount).Equals(5)
+				test.That(t, pager.Item()).Equals(0)
+				test.Strings(t, requests).Equals([]string{
+					"/links?size=2 value",
+					"/links?page=1&size=2 value",
+					"/links?page=2&size=2 value",
+				})
+			},
+		},
+		{scenario: "NextPageToken",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []string{}
+				srv := server(t, &requests)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				items, err := Paginate(ctx, c, "tokens", NextPageToken[int]("items", "next", "token")).All()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, items).Equals([]int{1, 2, 3, 4, 5})
+				test.Strings(t, requests).Equals([]string{
+					"/tokens ",
+					"/tokens?token=1 ",
+					"/tokens?token=2 ",
+				})
+			},
+		},
+		{scenario: "pages are requested as required",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []string{}
+				srv := server(t, &requests)
+				c, _ := NewClient("name", URL(srv.URL))
+				pager := Paginate(ctx, c, "links", NextLink[int])
+
+				// ACT
+				_ = pager.Next()
+				_ = pager.Next()
+
+				// ASSERT
+				test.That(t, len(requests)).Equals(1)
+				_ = pager.Next()
+				test.That(t, len(requests)).Equals(2)
+			},
+		},
+		{scenario: "request fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []string{}
+				srv := server(t, &requests)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				items, err := Paginate(ctx, c, "missing", NextLink[int]).All()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, len(items)).Equals(0)
+			},
+		},
+		{scenario: "request fails/response is closed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				body := &drainBody{Reader: strings.NewReader("not found")}
+				c, _ := NewClient("name", URL("http://hostname"), Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: body}, nil
+				})))
+
+				// ACT
+				_, err := Paginate(ctx, c, "missing", NextLink[int], request.StreamResponse()).All()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsTrue(t, strings.HasPrefix(err.Error(), "http.Paginate: "), "error: http.Paginate")
+				test.IsTrue(t, body.closed, "body closed")
+			},
+		},
+		{scenario: "invalid request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				c, _ := NewClient("name", URL("http://hostname"))
+
+				// ACT
+				items, err := Paginate(ctx, c, "links", NextLink[int], func(*http.Request) error { return opterr }).All()
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+				test.That(t, len(items)).Equals(0)
+			},
+		},
+		{scenario: "page func error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []string{}
+				srv := server(t, &requests)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				items, err := Paginate(ctx, c, "tokens", NextLink[int]).All()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.That(t, len(items)).Equals(0)
+			},
+		},
+		{scenario: "invalid next href",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("http://hostname"), Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				})))
+				page := func(context.Context, *http.Response) ([]int, string, error) {
+					return []int{1}, "http://host name", nil
+				}
+
+				// ACT
+				items, err := Paginate(ctx, c, "links", page).All()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidURL)
+				test.Slice(t, items).Equals([]int{1})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestNextPageToken(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "http://hostname/items?size=2", nil)
+	ctx := context.Background()
+
+	testcases := []struct {
+		body  string
+		items []int
+		next  string
+		err   error
+	}{
+		{body: `{"items":[1,2],"next":"abc"}`, items: []int{1, 2}, next: "http://hostname/items?size=2&token=abc"},
+		{body: `{"items":[1,2],"next":null}`, items: []int{1, 2}},
+		{body: `{"items":[1,2]}`, items: []int{1, 2}},
+		{body: `{}`, items: []int{}},
+		{body: `[]`, err: ErrInvalidJSON},
+		{body: `{"items":{}}`, err: ErrInvalidJSON},
+		{body: `{"next":1}`, err: ErrInvalidJSON},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.body, func(t *testing.T) {
+			// ARRANGE
+			r := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tc.body)), Request: rq}
+
+			// ACT
+			items, next, err := NextPageToken[int]("items", "next", "token")(ctx, r)
+
+			// ASSERT
+			test.Error(t, err).Is(tc.err)
+			test.Slice(t, items).Equals(tc.items)
+			test.That(t, next).Equals(tc.next)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// MediaTypeProblemJSON is the media type of a Problem Details (RFC 7807)
+// response body
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails holds the Problem Details (RFC 7807) describing an error
+// reported by a response with a Content-Type of application/problem+json.
+//
+// When a response with an unacceptable status code has a problem details body,
+// the UnexpectedStatusCodeError returned by the client wraps the decoded
+// ProblemDetails, which may be obtained using errors.As:
+//
+//	var problem *http.ProblemDetails
+//	if errors.As(err, &problem) {
+//		log.Println(problem.Type, problem.Detail)
+//	}
+type ProblemDetails struct {
+	// Type is a URI reference identifying the problem type
+	Type string `json:"type,omitempty"`
+
+	// Title is a short, human-readable summary of the problem type
+	Title string `json:"title,omitempty"`
+
+	// Status is the status code generated by the origin server
+	Status int `json:"status,omitempty"`
+
+	// Detail is a human-readable explanation specific to this occurrence
+	// of the problem
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI reference identifying the specific occurrence of
+	// the problem
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions holds any extension members of the problem details, keyed
+	// by name
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+// Error implements the error interface for ProblemDetails
+func (p *ProblemDetails) Error() string {
+	s := p.Title
+	switch {
+	case s == "" && p.Type != "":
+		s = p.Type
+	case s == "":
+		s = "problem"
+	}
+	if p.Detail != "" {
+		s += ": " + p.Detail
+	}
+	return s
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing any extension members
+// in addition to the standard members
+func (p *ProblemDetails) UnmarshalJSON(b []byte) error {
+	type problem ProblemDetails
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, k)
+	}
+	p.Extensions = nil
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// problemDetails decodes the problem details in the body of a response with a
+// Content-Type of application/problem+json.  If the Content-Type identifies
+// some other media type, or the body cannot be decoded, nil is returned.
+func problemDetails(h http.Header, body []byte) *ProblemDetails {
+	mediaType, _, err := parseMediaType(h.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(mediaType, MediaTypeProblemJSON) {
+		return nil
+	}
+
+	p := &ProblemDetails{}
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil
+	}
+	return p
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestProblemDetails(t *testing.T) {
+	// ARRANGE
+	header := func(contentType string) http.Header {
+		return http.Header{"Content-Type": {contentType}}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "problem details",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				body := `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.",` +
+					`"status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",` +
+					`"balance":30}`
+
+				// ACT
+				result := problemDetails(header("application/problem+json; charset=utf-8"), []byte(body))
+
+				// ASSERT
+				test.That(t, result).IsNotNil()
+				test.That(t, result.Type).Equals("https://example.com/probs/out-of-credit")
+				test.That(t, result.Title).Equals("You do not have enough credit.")
+				test.That(t, result.Status).Equals(403)
+				test.That(t, result.Detail).Equals("Your current balance is 30, but that costs 50.")
+				test.That(t, result.Instance).Equals("/account/12345/msgs/abc")
+				test.That(t, result.Extensions).Equals(map[string]json.RawMessage{"balance": json.RawMessage("30")})
+			},
+		},
+		{scenario: "no extensions",
+			exec: func(t *testing.T) {
+				// ACT
+				result := problemDetails(header(MediaTypeProblemJSON), []byte(`{"title":"Not Found"}`))
+
+				// ASSERT
+				test.That(t, result).IsNotNil()
+				test.That(t, result.Title).Equals("Not Found")
+				test.That(t, result.Extensions).IsNil()
+			},
+		},
+		{scenario: "not problem details",
+			exec: func(t *testing.T) {
+				// ACT
+				result := problemDetails(header("application/json"), []byte(`{"title":"not a problem"}`))
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "invalid problem details",
+			exec: func(t *testing.T) {
+				// ACT
+				result := problemDetails(header(MediaTypeProblemJSON), []byte(`{"status":"forbidden"}`))
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "errors.As from client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("http://hostname"), Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusBadRequest,
+						Status:     "400 Bad Request",
+						Header:     header(MediaTypeProblemJSON),
+						Body:       io.NopCloser(strings.NewReader(`{"title":"Invalid order","detail":"quantity must be positive"}`)),
+					}, nil
+				})))
+
+				// ACT
+				r, err := c.Post(context.Background(), "orders")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				var problem *ProblemDetails
+				test.IsTrue(t, errors.As(err, &problem), "is a *ProblemDetails")
+				test.That(t, problem.Title).Equals("Invalid order")
+				test.IsTrue(t, strings.HasSuffix(err.Error(), "400 Bad Request: Invalid order: quantity must be positive"))
+
+				content, _ := io.ReadAll(r.Body)
+				test.That(t, string(content)).Equals(`{"title":"Invalid order","detail":"quantity must be positive"}`)
+			},
+		},
+		{scenario: "Error",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					problem ProblemDetails
+					result  string
+				}{
+					{problem: ProblemDetails{}, result: "problem"},
+					{problem: ProblemDetails{Type: "about:blank"}, result: "about:blank"},
+					{problem: ProblemDetails{Type: "about:blank", Title: "Not Found"}, result: "Not Found"},
+					{problem: ProblemDetails{Title: "Not Found", Detail: "no such order"}, result: "Not Found: no such order"},
+				}
+				for _, tc := range testcases {
+					// ACT
+					result := tc.problem.Error()
+
+					// ASSERT
+					test.That(t, result).Equals(tc.result)
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"io"
+	"net/http"
+)
+
+// progressReader wraps the body of a request, reporting the number of bytes
+// read (i.e. sent) to a progress function
+type progressReader struct {
+	io.ReadCloser
+	sent  int64
+	total int64
+	fn    func(int64, int64)
+}
+
+// Read implements io.Reader, reading from the wrapped body and reporting
+// the total number of bytes read so far
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.ReadCloser.Read(p)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.fn(pr.sent, pr.total)
+	}
+	return n, err
+}
+
+// reportProgress wraps the body of a request (and any GetBody function) such
+// that progress in sending the body is reported to a specified function.  If
+// the request has no body, the request is not modified.
+func reportProgress(rq *http.Request, fn func(int64, int64)) {
+	if rq.Body == nil || rq.Body == http.NoBody {
+		return
+	}
+
+	total := rq.ContentLength
+	if total == 0 {
+		total = -1
+	}
+
+	rq.Body = &progressReader{ReadCloser: rq.Body, total: total, fn: fn}
+
+	if getBody := rq.GetBody; getBody != nil {
+		rq.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressReader{ReadCloser: body, total: total, fn: fn}, nil
+		}
+	}
+}
+
+// reportDownloadProgress wraps the body of a response such that progress in
+// receiving the body is reported to a specified function.  For a 206 Partial
+// Content response with a valid Content-Range header, progress is reported
+// relative to the complete resource.  If the response has no body, the
+// response is not modified.
+func reportDownloadProgress(r *http.Response, fn func(int64, int64)) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	received, total := int64(0), r.ContentLength
+	if r.StatusCode == http.StatusPartialContent {
+		if start, _, size, ok := parseContentRange(r.Header.Get("Content-Range")); ok {
+			received, total = start, size
+		}
+	}
+
+	r.Body = &progressReader{ReadCloser: r.Body, sent: received, total: total, fn: fn}
+}
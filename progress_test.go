@@ -0,0 +1,175 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestProgress(t *testing.T) {
+	// ARRANGE
+	type report struct{ sent, total int64 }
+
+	// reader is a client that reads the request body in chunks of 4 bytes
+	reader := DoerFunc(func(rq *http.Request) (*http.Response, error) {
+		if rq.Body != nil {
+			buf := make([]byte, 4)
+			for {
+				if _, err := rq.Body.Read(buf); err != nil {
+					break
+				}
+			}
+		}
+		return httptest.NewRecorder().Result(), nil
+	})
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "body with known length",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				reports := []report{}
+				c := client{wrapped: reader}
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				_, err := c.DoWith(rq,
+					request.ProgressFunc(func(sent, total int64) { reports = append(reports, report{sent, total}) }),
+					request.Body([]byte("0123456789")),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, reports).Equals([]report{{4, 10}, {8, 10}, {10, 10}})
+			},
+		},
+		{scenario: "body with unknown length",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				reports := []report{}
+				c := client{wrapped: reader}
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				rq.Body = io.NopCloser(bytes.NewReader([]byte("012345")))
+
+				// ACT
+				_, err := c.DoWith(rq,
+					request.ProgressFunc(func(sent, total int64) { reports = append(reports, report{sent, total}) }),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, reports).Equals([]report{{4, -1}, {6, -1}})
+			},
+		},
+		{scenario: "replayed body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				reports := []report{}
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				_ = request.Body([]byte("0123"))(rq)
+
+				// ACT
+				reportProgress(rq, func(sent, total int64) { reports = append(reports, report{sent, total}) })
+				body, _ := rq.GetBody()
+				_, _ = io.ReadAll(body)
+
+				// ASSERT
+				test.That(t, reports).Equals([]report{{4, 4}})
+			},
+		},
+		{scenario: "no body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname", nil)
+
+				// ACT
+				reportProgress(rq, func(int64, int64) {})
+
+				// ASSERT
+				test.IsTrue(t, rq.Body == nil, "body is not wrapped")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestReportDownloadProgress(t *testing.T) {
+	// ARRANGE
+	type report struct{ received, total int64 }
+
+	testcases := []struct {
+		scenario string
+		response *http.Response
+		result   []report
+	}{
+		{scenario: "no body",
+			response: &http.Response{StatusCode: http.StatusOK, Body: http.NoBody},
+			result:   []report{},
+		},
+		{scenario: "body with known length",
+			response: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: 6,
+				Body:          io.NopCloser(bytes.NewReader([]byte("abcdef"))),
+			},
+			result: []report{{4, 6}, {6, 6}},
+		},
+		{scenario: "body with unknown length",
+			response: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: -1,
+				Body:          io.NopCloser(bytes.NewReader([]byte("abcdef"))),
+			},
+			result: []report{{4, -1}, {6, -1}},
+		},
+		{scenario: "partial content",
+			response: &http.Response{
+				StatusCode:    http.StatusPartialContent,
+				ContentLength: 6,
+				Header:        http.Header{"Content-Range": {"bytes 10-15/16"}},
+				Body:          io.NopCloser(bytes.NewReader([]byte("abcdef"))),
+			},
+			result: []report{{14, 16}, {16, 16}},
+		},
+		{scenario: "partial content with invalid range",
+			response: &http.Response{
+				StatusCode:    http.StatusPartialContent,
+				ContentLength: 6,
+				Header:        http.Header{"Content-Range": {"invalid"}},
+				Body:          io.NopCloser(bytes.NewReader([]byte("abcdef"))),
+			},
+			result: []report{{4, 6}, {6, 6}},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			reports := []report{}
+
+			// ACT
+			reportDownloadProgress(tc.response, func(received, total int64) {
+				reports = append(reports, report{received, total})
+			})
+			buf := make([]byte, 4)
+			for {
+				if _, err := tc.response.Body.Read(buf); err != nil {
+					break
+				}
+			}
+
+			// ASSERT
+			test.Slice(t, reports).Equals(tc.result)
+		})
+	}
+}
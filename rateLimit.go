@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimit holds the rate limiting state of a client.  A client is paused
+// when a 429 Too Many Requests response is received, until the time indicated
+// by any Retry-After header of the response.
+//
+// A client is a value type; the rateLimit is held by pointer so that the state
+// is shared by all copies of the client.
+type rateLimit struct {
+	mu      sync.Mutex
+	until   time.Time
+	retries uint
+}
+
+// HandleTooManyRequests configures a client to handle 429 Too Many Requests
+// responses:
+//
+//   - any Retry-After header on the response (in seconds or as an http date) is
+//     parsed to determine when the rate limit will reset;
+//
+//   - the client (not just the request) is paused until that time; requests
+//     made using the client while it is paused fail with a RateLimitedError
+//     without being sent;
+//
+//   - a 429 response (not otherwise acceptable to the request) results in a
+//     RateLimitedError identifying the reset time.
+//
+// If retries is non-zero, a request that receives a 429 response with a
+// Retry-After header is retried (up to the number of retries specified) after
+// waiting for the rate limit to reset, and a request made while the client is
+// paused waits for the pause to end, rather than failing.  A request waits only
+// if the reset time is before the deadline (if any) of the request context.
+//
+// Retries of rate limited requests are independent of any retries configured
+// using MaxRetries.
+func HandleTooManyRequests(retries uint) ClientOption {
+	return func(c *client) error {
+		c.rateLimit = &rateLimit{retries: retries}
+		return nil
+	}
+}
+
+// pausedUntil returns the time until which the client is paused, or the
+// zero time if the client is not paused
+func (rl *rateLimit) pausedUntil() time.Time {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.until.After(timeNow()) {
+		return rl.until
+	}
+	return time.Time{}
+}
+
+// pause pauses the client until the reset time indicated by a 429 response.
+// The reset time is returned; this will be the zero time if the response has
+// no (valid) Retry-After header.
+func (rl *rateLimit) pause(r *http.Response) time.Time {
+	reset := retryAfter(r.Header.Get("Retry-After"))
+	if reset.IsZero() {
+		return reset
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if reset.After(rl.until) {
+		rl.until = reset
+	}
+	return reset
+}
+
+// canWait returns true if a request may wait for a specified reset time.  A
+// request may wait only if the client is configured to retry rate limited
+// requests, the reset time is known and is before the deadline (if any) of
+// the request context.
+func (rl *rateLimit) canWait(ctx context.Context, reset time.Time) bool {
+	if rl.retries == 0 || reset.IsZero() {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && !reset.Before(deadline) {
+		return false
+	}
+	return true
+}
+
+// awaitRateLimit is called before each attempt to perform a request.  If the client
+// is paused the request waits for the pause to end if permitted, otherwise
+// a RateLimitedError is returned.
+func (c client) awaitRateLimit(ctx context.Context) error {
+	until := c.rateLimit.pausedUntil()
+	switch {
+	case until.IsZero():
+		return nil
+	case c.rateLimit.canWait(ctx, until):
+		return c.wait(ctx, WaitRateLimited, until.Sub(timeNow()))
+	default:
+		return RateLimitedError{Reset: until}
+	}
+}
+
+// retryAfter parses the value of a Retry-After header, returning the time
+// indicated.  The value may be a number of seconds or an http date; if the
+// value is empty or invalid the zero time is returned.
+func retryAfter(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if secs, err := strconv.Atoi(s); err == nil && secs >= 0 {
+		return timeNow().Add(time.Duration(secs) * time.Second)
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestHandleTooManyRequests(t *testing.T) {
+	// ARRANGE
+	now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+
+	// the clock is advanced by any wait, so no time is actually spent waiting
+	clock := now
+	ogNow, ogAfter := timeNow, timeAfter
+	defer func() { timeNow, timeAfter = ogNow, ogAfter }()
+	timeNow = func() time.Time { return clock }
+	timeAfter = func(d time.Duration) <-chan time.Time {
+		clock = clock.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- clock
+		return ch
+	}
+
+	// responder returns a Doer that responds with each of a number of
+	// status codes in turn, recording the number of requests made
+	responder := func(count *int, statusCodes ...int) Doer {
+		return DoerFunc(func(rq *http.Request) (*http.Response, error) {
+			sc := statusCodes[*count]
+			*count++
+			r := &http.Response{
+				StatusCode: sc,
+				Status:     http.StatusText(sc),
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("")),
+			}
+			if sc == http.StatusTooManyRequests {
+				r.Header.Set("Retry-After", "30")
+			}
+			return r, nil
+		})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := HandleTooManyRequests(2)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.rateLimit.retries).Equals(2)
+			},
+		},
+		{scenario: "rate limited/no retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clock = now
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 200)), HandleTooManyRequests(0))
+				ctx := context.Background()
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				var rlerr RateLimitedError
+				test.Error(t, err).Is(ErrRateLimited)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsTrue(t, errors.As(err, &rlerr), "is a RateLimitedError")
+				test.That(t, rlerr.Reset).Equals(now.Add(30 * time.Second))
+
+				// ACT: a further request while the client is paused is not sent
+				_, err = c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRateLimited)
+				test.That(t, count).Equals(1)
+
+				// ACT: a request after the reset time is sent
+				clock = clock.Add(30 * time.Second)
+				_, err = c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, count).Equals(2)
+			},
+		},
+		{scenario: "rate limited/retried",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clock = now
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 429, 200)), HandleTooManyRequests(2))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusOK)
+				test.That(t, count).Equals(3)
+				test.That(t, clock).Equals(now.Add(60 * time.Second))
+			},
+		},
+		{scenario: "rate limited/retries exhausted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clock = now
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 429, 200)), HandleTooManyRequests(1))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRateLimited)
+				test.That(t, count).Equals(2)
+			},
+		},
+		{scenario: "rate limited/reset after deadline",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clock = now
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 200)), HandleTooManyRequests(1))
+				ctx, cancel := context.WithDeadline(context.Background(), now.Add(10*time.Second))
+				defer cancel()
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRateLimited)
+				test.That(t, count).Equals(1)
+			},
+		},
+		{scenario: "rate limited/acceptable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clock = now
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 200)), HandleTooManyRequests(0))
+				ctx := context.Background()
+
+				// ACT
+				r, err := c.Get(ctx, "path", request.AcceptStatus(http.StatusTooManyRequests))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusTooManyRequests)
+
+				// ACT: the client is paused
+				_, err = c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrRateLimited)
+			},
+		},
+		{scenario: "not configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				count := 0
+				c, _ := NewClient("name", Using(responder(&count, 429, 200)))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsFalse(t, errors.Is(err, ErrRateLimited))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	// ARRANGE
+	now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	og := timeNow
+	defer func() { timeNow = og }()
+	timeNow = func() time.Time { return now }
+
+	testcases := []struct {
+		value  string
+		result time.Time
+	}{
+		{value: ""},
+		{value: "invalid"},
+		{value: "-1"},
+		{value: "0", result: now},
+		{value: "120", result: now.Add(2 * time.Minute)},
+		{value: "Wed, 08 Sep 2010 08:00:00 GMT", result: time.Date(2010, 9, 8, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			// ACT
+			result := retryAfter(tc.value)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
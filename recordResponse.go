@@ -0,0 +1,121 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// fixtureRedactedHeaders identifies headers which are always redacted in a
+// fixture recorded by RecordResponse
+var fixtureRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+}
+
+// fixtureOmittedHeaders identifies headers which are specific to a connection
+// or transfer and so are omitted from a fixture recorded by RecordResponse
+var fixtureOmittedHeaders = []string{
+	"Connection",
+	"Date",
+	"Keep-Alive",
+	"Transfer-Encoding",
+}
+
+// RecordResponse writes a fixture to a specified path, recording the status,
+// headers and body of a response, e.g. to refresh test fixtures from the real
+// behaviour of an upstream service.  The fixture may be replayed by a mock
+// client using the WithBodyFromFile() response option, or read using
+// LoadResponse().
+//
+// The fixture is sanitized: the values of any Authorization, Cookie,
+// Proxy-Authorization and Set-Cookie headers, and of any additional headers
+// specified, are redacted; headers specific to the connection (Connection,
+// Date, Keep-Alive and Transfer-Encoding) are omitted.
+//
+// The body of the response is read in order to record it and is replaced by
+// a copy, so that the response may continue to be used.
+func RecordResponse(r *http.Response, path string, redact ...string) error {
+	var body []byte
+	if r.Body != nil {
+		b, err := ioReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("RecordResponse: %w: %w", ErrReadingResponseBody, err)
+		}
+		body = b
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	header := sanitizeHeader(r.Header, redact)
+
+	fixture := &http.Response{
+		Status:        r.Status,
+		StatusCode:    r.StatusCode,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	}
+
+	buf := &bytes.Buffer{}
+	if err := fixture.Write(buf); err != nil {
+		return fmt.Errorf("RecordResponse: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("RecordResponse: %w", err)
+	}
+	return nil
+}
+
+// sanitizeHeader returns a copy of a header for recording in a fixture, with
+// the values of any sensitive (or specified) headers redacted and any headers
+// specific to the connection omitted
+func sanitizeHeader(h http.Header, redact []string) http.Header {
+	header := h.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for _, k := range fixtureOmittedHeaders {
+		header.Del(k)
+	}
+	for _, k := range append(fixtureRedactedHeaders, redact...) {
+		if _, ok := header[http.CanonicalHeaderKey(k)]; ok {
+			header.Set(k, redacted)
+		}
+	}
+	return header
+}
+
+// LoadResponse reads a fixture recorded by RecordResponse, returning a
+// response with the recorded status, headers and body.
+func LoadResponse(path string) (*http.Response, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadResponse: %w", err)
+	}
+	return parseFixture(b)
+}
+
+// parseFixture parses a fixture recorded by RecordResponse.  The body of the
+// returned response is fully read, so the response need not be closed.
+func parseFixture(b []byte) (*http.Response, error) {
+	r, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("LoadResponse: %w", err)
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("LoadResponse: %w: %w", ErrReadingResponseBody, err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return r, nil
+}
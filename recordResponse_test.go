@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRecordResponse(t *testing.T) {
+	// ARRANGE
+	newResponse := func() *http.Response {
+		h := http.Header{}
+		h.Set("Content-Type", "application/json")
+		h.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+		h.Add("Set-Cookie", "session=secret")
+		h.Add("Set-Cookie", "other=secret")
+		h.Set("X-Api-Key", "secret")
+		return &http.Response{
+			Status:     "201 Created",
+			StatusCode: http.StatusCreated,
+			Header:     h,
+			Body:       io.NopCloser(strings.NewReader(`{"id":1}`)),
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "records sanitized fixture",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "fixture.http")
+				r := newResponse()
+
+				// ACT
+				err := RecordResponse(r, path, "x-api-key")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals(`{"id":1}`)
+
+				fixture, err := LoadResponse(path)
+				test.Error(t, err).IsNil()
+				test.That(t, fixture.StatusCode).Equals(http.StatusCreated)
+				test.That(t, fixture.Header.Get("Content-Type")).Equals("application/json")
+				test.That(t, fixture.Header.Get("Date")).Equals("")
+				test.Strings(t, fixture.Header.Values("Set-Cookie")).Equals([]string{redacted})
+				test.That(t, fixture.Header.Get("X-Api-Key")).Equals(redacted)
+				body, _ = io.ReadAll(fixture.Body)
+				test.That(t, string(body)).Equals(`{"id":1}`)
+			},
+		},
+		{scenario: "no body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "fixture.http")
+				r := &http.Response{StatusCode: http.StatusNoContent}
+
+				// ACT
+				err := RecordResponse(r, path)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				fixture, err := LoadResponse(path)
+				test.Error(t, err).IsNil()
+				test.That(t, fixture.StatusCode).Equals(http.StatusNoContent)
+			},
+		},
+		{scenario: "error reading body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				readerr := errors.New("read error")
+				og := ioReadAll
+				defer func() { ioReadAll = og }()
+				ioReadAll = func(io.Reader) ([]byte, error) { return nil, readerr }
+
+				// ACT
+				err := RecordResponse(newResponse(), filepath.Join(t.TempDir(), "fixture.http"))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(readerr)
+			},
+		},
+		{scenario: "error writing file",
+			exec: func(t *testing.T) {
+				// ACT
+				err := RecordResponse(newResponse(), filepath.Join(t.TempDir(), "missing", "fixture.http"))
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "load/missing file",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := LoadResponse(filepath.Join(t.TempDir(), "missing.http"))
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "load/not a fixture",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "body.json")
+				_ = os.WriteFile(path, []byte(`{"id":1}`), 0o644)
+
+				// ACT
+				_, err := LoadResponse(path)
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "replayed by mock",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx := context.Background()
+				path := filepath.Join(t.TempDir(), "fixture.http")
+				_ = RecordResponse(newResponse(), path)
+
+				c, mock := NewMockClient("mock")
+				mock.ExpectPost("/orders").WillRespond().WithBodyFromFile(path)
+
+				// ACT
+				r, err := c.Post(ctx, "/orders", request.AcceptStatus(http.StatusCreated))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusCreated)
+				test.That(t, r.Header.Get("Content-Type")).Equals("application/json")
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals(`{"id":1}`)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultMaxRedirects is the maximum number of redirects followed for a
+// request by a client configured with a redirect policy that does not
+// specify a limit (e.g. when only request.NoFollowRedirects is used); it is
+// the same limit as applied by default by an *http.Client
+const DefaultMaxRedirects = 10
+
+// Redirect describes an intermediate (redirect) response received when
+// following redirects.
+type Redirect struct {
+	// URL is the url of the request that received the redirect response
+	URL string
+
+	// StatusCode is the status code of the redirect response
+	StatusCode int
+
+	// Location is the value of the Location header of the redirect response
+	Location string
+
+	// SetCookie holds the values of any Set-Cookie headers of the redirect
+	// response
+	SetCookie []string
+}
+
+// RedirectHistory returns the redirects followed in obtaining a response, in
+// the order in which they were received.  If no redirects were followed (or
+// the response is nil), nil is returned.
+//
+// The history is obtained from the chain of requests and responses maintained
+// by net/http when following redirects; the bodies of intermediate responses
+// are not available.
+func RedirectHistory(r *http.Response) []Redirect {
+	if r == nil || r.Request == nil {
+		return nil
+	}
+
+	var history []Redirect
+	for prev := r.Request.Response; prev != nil; {
+		rd := Redirect{
+			StatusCode: prev.StatusCode,
+			Location:   prev.Header.Get("Location"),
+			SetCookie:  prev.Header.Values("Set-Cookie"),
+		}
+		if prev.Request != nil {
+			rd.URL = prev.Request.URL.Redacted()
+		}
+		history = append([]Redirect{rd}, history...)
+
+		if prev.Request == nil {
+			break
+		}
+		prev = prev.Request.Response
+	}
+	return history
+}
+
+// MaxRedirects limits the number of redirects followed for requests made using
+// the client.  If a further redirect is received once the limit is reached, the
+// redirect response is returned; unless its status code is acceptable (see:
+// request.AcceptStatus) an UnexpectedStatusCodeError results.  MaxRedirects(0)
+// disables following redirects.  Individual requests may be configured not to
+// follow redirects using request.NoFollowRedirects.
+//
+// If the wrapped client is an *http.Client, the limit is applied by (a copy
+// of) that client; any CheckRedirect function configured on the wrapped client
+// is still called for each redirect within the limit.  Any other wrapped client
+// is wrapped such that redirects are followed by the client, with the chain of
+// redirects recorded on the response as for an *http.Client (see:
+// RedirectHistory).
+func MaxRedirects(n int) ClientOption {
+	return func(c *client) error {
+		if n < 0 {
+			return fmt.Errorf("http: MaxRedirects option: maximum must not be negative: %d", n)
+		}
+		c.maxRedirects = &n
+		return nil
+	}
+}
+
+// withRedirects returns a Doer following at most a specified number of
+// redirects.  If the Doer is an *http.Client, a copy of the client is returned
+// with a CheckRedirect function enforcing the limit.  Otherwise the Doer is
+// wrapped by a redirectDoer.
+func withRedirects(d Doer, limit int) Doer {
+	hc, ok := d.(*http.Client)
+	if !ok {
+		return redirectDoer{Doer: d, limit: limit}
+	}
+
+	check := hc.CheckRedirect
+	cpy := *hc
+	cpy.CheckRedirect = func(rq *http.Request, via []*http.Request) error {
+		if len(via) > limit {
+			return http.ErrUseLastResponse
+		}
+		if check != nil {
+			return check(rq, via)
+		}
+		return nil
+	}
+	return &cpy
+}
+
+// redirectDoer wraps a Doer that does not follow redirects, following at most
+// a specified number of redirects.  As for an *http.Client, the request for
+// each redirect references the response that was redirected.
+type redirectDoer struct {
+	Doer
+	limit int
+}
+
+// Do implements Doer, following any redirect responses within the limit.  The
+// body of each redirect response that is followed is drained and closed.
+func (rd redirectDoer) Do(rq *http.Request) (*http.Response, error) {
+	for redirects := 0; ; redirects++ {
+		r, err := rd.Doer.Do(rq)
+		if err != nil || redirects >= rd.limit {
+			return r, err
+		}
+
+		next, err := redirectRequest(rq, r)
+		if err != nil || next == nil {
+			return r, err
+		}
+		if r.Body != nil {
+			_ = closeBody(rq.Context(), r.Body)
+		}
+		rq = next
+	}
+}
+
+// redirectRequest returns the request following a redirect response to a
+// specified request, or nil if the response is not a redirect that may be
+// followed (e.g. a 307 or 308 redirect of a request with a body that cannot
+// be replayed).
+//
+// As for an *http.Client, a 301, 302 or 303 redirect of a request other than
+// a GET or HEAD request is followed by a GET request with no body, and any
+// Authorization or Cookie headers are not sent to a different host.
+func redirectRequest(rq *http.Request, r *http.Response) (*http.Request, error) {
+	if r == nil {
+		return nil, nil
+	}
+	loc := r.Header.Get("Location")
+	if loc == "" {
+		return nil, nil
+	}
+
+	next := rq.Clone(rq.Context())
+	switch r.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+		if rq.Method != http.MethodGet && rq.Method != http.MethodHead {
+			next.Method = http.MethodGet
+			next.Body, next.GetBody, next.ContentLength = http.NoBody, nil, 0
+			next.Header.Del("Content-Type")
+			next.Header.Del("Content-Length")
+		}
+
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		if rq.Body != nil && rq.Body != http.NoBody {
+			if rq.GetBody == nil {
+				return nil, nil
+			}
+			body, err := rq.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrCannotCloneBody, err)
+			}
+			next.Body = body
+		}
+
+	default:
+		return nil, nil
+	}
+
+	u, err := rq.URL.Parse(loc)
+	if err != nil {
+		return nil, nil
+	}
+	if u.Hostname() != rq.URL.Hostname() {
+		for _, h := range []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"} {
+			next.Header.Del(h)
+		}
+	}
+	next.URL, next.Host = u, u.Host
+	next.Response = r
+	return next, nil
+}
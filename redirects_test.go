@@ -0,0 +1,343 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRedirectHistory(t *testing.T) {
+	// ARRANGE
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "step", Value: "a"})
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "nil response",
+			exec: func(t *testing.T) {
+				// ACT
+				result := RedirectHistory(nil)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "no redirects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL))
+				r, _ := c.Get(context.Background(), "c")
+
+				// ACT
+				result := RedirectHistory(r)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "redirects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL))
+				r, err := c.Get(context.Background(), "a")
+				test.Error(t, err).IsNil()
+
+				// ACT
+				result := RedirectHistory(r)
+
+				// ASSERT
+				test.That(t, result).Equals([]Redirect{
+					{URL: srv.URL + "/a", StatusCode: http.StatusFound, Location: "/b", SetCookie: []string{"step=a"}},
+					{URL: srv.URL + "/b", StatusCode: http.StatusMovedPermanently, Location: "/c"},
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestMaxRedirects(t *testing.T) {
+	// ARRANGE
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusFound)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "negative",
+			exec: func(t *testing.T) {
+				// ACT
+				err := MaxRedirects(-1)(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "within limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL), MaxRedirects(2))
+
+				// ACT
+				r, err := c.Get(ctx, "a")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.Request.URL.Path).Equals("/c")
+				test.That(t, len(RedirectHistory(r))).Equals(2)
+			},
+		},
+		{scenario: "limit exceeded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL), MaxRedirects(1))
+
+				// ACT
+				r, err := c.Get(ctx, "a", request.AcceptStatus(http.StatusFound))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusFound)
+				test.That(t, r.Header.Get("Location")).Equals("/c")
+				test.That(t, len(RedirectHistory(r))).Equals(1)
+			},
+		},
+		{scenario: "disabled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL), MaxRedirects(0))
+
+				// ACT
+				r, err := c.Get(ctx, "a")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusFound)
+			},
+		},
+		{scenario: "request.NoFollowRedirects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				r, err := c.Get(ctx, "a", request.NoFollowRedirects(), request.AcceptStatus(http.StatusFound))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.Header.Get("Location")).Equals("/b")
+			},
+		},
+		{scenario: "wrapped client CheckRedirect",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				checked := 0
+				hc := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+					checked++
+					return nil
+				}}
+				c, _ := NewClient("name", URL(srv.URL), Using(hc), MaxRedirects(1))
+
+				// ACT
+				_, _ = c.Get(ctx, "a")
+
+				// ASSERT
+				test.That(t, checked).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestRedirectDoer(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// doer returns a Doer that redirects requests for /a to /b with a specified
+	// status code, recording each request, and responds 200 OK to any other
+	type recorded struct {
+		method, url, auth, body string
+	}
+	doer := func(status int, location string, requests *[]recorded) DoerFunc {
+		return func(rq *http.Request) (*http.Response, error) {
+			body := ""
+			if rq.Body != nil {
+				b, _ := io.ReadAll(rq.Body)
+				body = string(b)
+			}
+			*requests = append(*requests, recorded{rq.Method, rq.URL.String(), rq.Header.Get("Authorization"), body})
+			if rq.URL.Path == "/a" {
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Location": {location}},
+					Body:       http.NoBody,
+					Request:    rq,
+				}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: rq}, nil
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "302 of POST",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusFound, "/b", &requests)), MaxRedirects(5))
+
+				// ACT
+				r, err := c.Post(ctx, "a", request.Header("Authorization", "token"), request.JSONBody("body"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, requests).Equals([]recorded{
+					{http.MethodPost, "http://hostname/a", "token", `"body"`},
+					{http.MethodGet, "http://hostname/b", "token", ""},
+				})
+				test.That(t, RedirectHistory(r)).Equals([]Redirect{
+					{URL: "http://hostname/a", StatusCode: http.StatusFound, Location: "/b"},
+				})
+			},
+		},
+		{scenario: "307 of POST",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusTemporaryRedirect, "/b", &requests)), MaxRedirects(5))
+
+				// ACT
+				_, err := c.Post(ctx, "a", request.JSONBody("body"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, requests).Equals([]recorded{
+					{http.MethodPost, "http://hostname/a", "", `"body"`},
+					{http.MethodPost, "http://hostname/b", "", `"body"`},
+				})
+			},
+		},
+		{scenario: "307 of body that cannot be replayed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusTemporaryRedirect, "/b", &requests)), MaxRedirects(5))
+				rq, _ := c.NewRequest(ctx, http.MethodPost, "a")
+				rq.Body = io.NopCloser(strings.NewReader("body"))
+
+				// ACT
+				r, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusTemporaryRedirect)
+				test.That(t, len(requests)).Equals(1)
+			},
+		},
+		{scenario: "different host",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusFound, "http://other/b", &requests)), MaxRedirects(5))
+
+				// ACT
+				_, err := c.Get(ctx, "a", request.Header("Authorization", "token"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, requests).Equals([]recorded{
+					{http.MethodGet, "http://hostname/a", "token", ""},
+					{http.MethodGet, "http://other/b", "", ""},
+				})
+			},
+		},
+		{scenario: "no location",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusFound, "", &requests)), MaxRedirects(5))
+
+				// ACT
+				r, err := c.Get(ctx, "a")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusFound)
+			},
+		},
+		{scenario: "not a redirect",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusNotModified, "/b", &requests)), MaxRedirects(5))
+
+				// ACT
+				r, _ := c.Get(ctx, "a")
+
+				// ASSERT
+				test.That(t, r.StatusCode).Equals(http.StatusNotModified)
+				test.That(t, len(requests)).Equals(1)
+			},
+		},
+		{scenario: "request.NoFollowRedirects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				requests := []recorded{}
+				c, _ := NewClient("name", URL("http://hostname"), Using(doer(http.StatusFound, "/b", &requests)))
+
+				// ACT
+				r, _ := c.Get(ctx, "a", request.NoFollowRedirects())
+
+				// ASSERT
+				test.That(t, r.StatusCode).Equals(http.StatusFound)
+				test.That(t, len(requests)).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AcceptEncoding sets the canonical Accept-Encoding header on a request,
+// replacing any existing value, with the encodings specified (e.g. "gzip",
+// "br").
+//
+// When a request has an explicit Accept-Encoding header, the transport does
+// not negotiate compression itself and will not transparently decompress the
+// response; the body of the response is returned exactly as received, with
+// any Content-Encoding header of the response intact.
+func AcceptEncoding(encodings ...string) func(rq *http.Request) error {
+	return func(rq *http.Request) error {
+		rq.Header.Set("Accept-Encoding", strings.Join(encodings, ", "))
+		return nil
+	}
+}
+
+// DisableCompression disables compression of the response to a request, by
+// setting the Accept-Encoding header to "identity".  This prevents the transport
+// negotiating compression, so that the response body and Content-Length are
+// exactly as sent by the server, e.g. when proxying bytes verbatim.
+func DisableCompression() func(rq *http.Request) error {
+	return AcceptEncoding("identity")
+}
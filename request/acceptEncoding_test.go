@@ -0,0 +1,68 @@
+package request
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	// ARRANGE
+	rq, err := http.NewRequest(http.MethodTrace, "notused", nil)
+	test.Error(t, err).IsNil()
+	rq.Header.Set("Accept-Encoding", "existing")
+
+	// ACT
+	err = AcceptEncoding("gzip", "br")(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.Value(t, rq.Header.Get("accept-encoding")).Equals("gzip, br")
+}
+
+func TestDisableCompression(t *testing.T) {
+	// ARRANGE
+	// the server compresses the response if the client will accept gzip
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			_, _ = w.Write([]byte("identity"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte("compressed"))
+		_ = gz.Close()
+	}))
+	defer srv.Close()
+
+	testcases := []struct {
+		scenario string
+		opts     []func(*http.Request) error
+		result   string
+	}{
+		{scenario: "default", result: "compressed"},
+		{scenario: "disabled", opts: []func(*http.Request) error{DisableCompression()}, result: "identity"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			rq, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			for _, opt := range tc.opts {
+				_ = opt(rq)
+			}
+
+			// ACT
+			r, err := http.DefaultClient.Do(rq)
+
+			// ASSERT
+			test.Error(t, err).IsNil()
+			b, _ := io.ReadAll(r.Body)
+			_ = r.Body.Close()
+			test.That(t, string(b)).Equals(tc.result)
+			test.That(t, r.Header.Get("Content-Encoding")).Equals("")
+		})
+	}
+}
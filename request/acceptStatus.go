@@ -1,34 +1,58 @@
 package request
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
+// AcceptStatusHeader identifies a header that may be used to specify
+// acceptable status codes as a JSON array.  The header is supported for
+// backwards compatibility only; the AcceptStatus() option configures the
+// request context and does not set this header.
+//
 // canonical casing avoids go-staticcheck flagging the constant with SA1008
 const AcceptStatusHeader = "X-Blugnu-Http-Accept-Status"
 
+// AcceptStatus configures one or more status codes that are acceptable in a
+// response to the request, in addition to http.StatusOK.  The option may be
+// applied more than once; status codes are accumulated.
 func AcceptStatus(statusCodes ...int) func(*http.Request) error {
 	return func(rq *http.Request) error {
-		handle := func(err error) error {
-			return fmt.Errorf("request.AcceptStatus: %w", err)
-		}
-
-		acc := []int{http.StatusOK}
-		if h, ok := rq.Header[AcceptStatusHeader]; ok {
-			if err := json.Unmarshal([]byte(h[0]), &acc); err != nil {
-				return handle(fmt.Errorf("%w: %w", ErrInvalidJSON, err))
-			}
-		}
+		configure(rq, func(cfg *Config) {
+			cfg.AcceptStatus = append(cfg.AcceptStatus, statusCodes...)
+		})
+		return nil
+	}
+}
 
-		acc = append(acc, statusCodes...)
+// AcceptStatusRange configures a range of status codes, from min to max
+// inclusive, that are acceptable in a response to the request, in addition to
+// http.StatusOK, e.g. to accept any 2xx status:
+//
+//	AcceptStatusRange(200, 299)
+//
+// The option may be applied more than once and in combination with
+// AcceptStatus and AcceptStatusFunc; a status is acceptable if accepted by
+// any of them.
+func AcceptStatusRange(min, max int) func(*http.Request) error {
+	return AcceptStatusFunc(func(sc int) bool { return sc >= min && sc <= max })
+}
 
-		// we can safely ignore the returned error value as marshalling a
-		// slice of int cannot error.  This avoids creating an irrelevant
-		// and untestable code path
-		h, _ := json.Marshal(acc)
-		rq.Header[AcceptStatusHeader] = []string{string(h)}
+// AcceptStatusFunc configures a function identifying status codes that are
+// acceptable in a response to the request, in addition to http.StatusOK.  The
+// function is called with the status code of the response and returns true
+// if the status is acceptable.  A nil function is ignored.
+//
+// The option may be applied more than once and in combination with
+// AcceptStatus and AcceptStatusRange; a status is acceptable if accepted by
+// any of them.
+func AcceptStatusFunc(fn func(statusCode int) bool) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		if fn == nil {
+			return nil
+		}
+		configure(rq, func(cfg *Config) {
+			cfg.AcceptStatusFuncs = append(cfg.AcceptStatusFuncs, fn)
+		})
 		return nil
 	}
 }
@@ -13,7 +13,7 @@ func TestAcceptStatus(t *testing.T) {
 		scenario string
 		exec     func(*testing.T)
 	}{
-		{scenario: "no header/add status",
+		{scenario: "no config/add status",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
@@ -23,35 +23,72 @@ func TestAcceptStatus(t *testing.T) {
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[AcceptStatusHeader][0]).Equals("[200,404]")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotFound})
+				test.That(t, rq.Header[AcceptStatusHeader]).IsNil()
 			},
 		},
-		{scenario: "existing header/add status",
+		{scenario: "existing config/add status",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
-				rq.Header[AcceptStatusHeader] = []string{"[200,401]"}
+				_ = AcceptStatus(http.StatusUnauthorized)(rq)
 
 				// ACT
 				err := AcceptStatus(http.StatusNotFound)(rq)
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[AcceptStatusHeader][0]).Equals("[200,401,404]")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusUnauthorized, http.StatusNotFound})
 			},
 		},
-		{scenario: "existing header/malformed",
+		{scenario: "AcceptStatusRange",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
-				rq.Header[AcceptStatusHeader] = []string{"this is not valid"}
 
 				// ACT
-				err := AcceptStatus(http.StatusNotFound)(rq)
+				err := AcceptStatusRange(200, 299)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, len(cfg.AcceptStatusFuncs)).Equals(1)
+				accept := cfg.AcceptStatusFuncs[0]
+				test.IsFalse(t, accept(199), "199 accepted")
+				test.IsTrue(t, accept(200), "200 accepted")
+				test.IsTrue(t, accept(299), "299 accepted")
+				test.IsFalse(t, accept(300), "300 accepted")
+			},
+		},
+		{scenario: "AcceptStatusFunc/accumulates",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = AcceptStatusFunc(func(int) bool { return false })(rq)
+
+				// ACT
+				err := AcceptStatusFunc(func(int) bool { return true })(rq)
 
 				// ASSERT
-				test.Error(t, err).Is(ErrInvalidJSON)
-				test.That(t, rq.Header[AcceptStatusHeader][0]).Equals("this is not valid")
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, len(cfg.AcceptStatusFuncs)).Equals(2)
+			},
+		},
+		{scenario: "AcceptStatusFunc/nil",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := AcceptStatusFunc(nil)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				_, ok := ConfigFromContext(rq.Context())
+				test.IsFalse(t, ok, "configured")
 			},
 		},
 	}
@@ -0,0 +1,19 @@
+package request
+
+import "net/http"
+
+// Affinity specifies an affinity key for the request, pinning the request to
+// the same backend as any other request with the same key, when performed by
+// a client configured with multiple backends (see: http.Backends).  The key
+// overrides any affinity key carried by the request context.
+//
+// If the client is not configured with multiple backends the option has no
+// effect.
+func Affinity(key string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.Affinity = key
+		})
+		return nil
+	}
+}
@@ -0,0 +1,21 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestAffinity(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := Affinity("session-1")(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.That(t, cfg.Affinity).Equals("session-1")
+}
@@ -4,20 +4,24 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
 
 	"github.com/blugnu/errorcontext"
 )
 
-// BearerToken sets a canonical Authorisation header with a BearerToken value,
+// BearerToken sets a canonical Authorization header with a BearerToken value,
 // using the result of a provided function.
 //
 // The token value is not supplied directly; instead, the provided function will
-// be called to obtain a token, or an error if a token is not available.
+// be called to obtain a token, or an error if a token is not available.  The
+// function is called with the context of the request, without the Config of
+// the request (see: WithoutConfig).
 func BearerToken(fn func(context.Context) (string, error)) func(*http.Request) error {
 	return func(rq *http.Request) error {
 		ctx := rq.Context()
 
-		t, err := fn(ctx)
+		t, err := fn(WithoutConfig(ctx))
 		if err != nil {
 			return errorcontext.Errorf(ctx, "BearerToken: %w", err)
 		}
@@ -27,3 +31,103 @@ func BearerToken(fn func(context.Context) (string, error)) func(*http.Request) e
 		return nil
 	}
 }
+
+// BearerTokenString sets a canonical Authorization header with a Bearer value
+// using a specified token, e.g. a long-lived token obtained from configuration.
+//
+// To obtain a token when the request is initialised, use BearerToken.
+func BearerTokenString(token string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.Header.Add("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// BasicAuth sets a canonical Authorization header using HTTP Basic
+// Authentication with a specified username and password, replacing any
+// Authorization header already set on the request.
+func BasicAuth(username, password string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// APIKey sets a specified header to an API key.  The header is canonicalised;
+// for example:
+//
+//	// sets an "X-Api-Key" header
+//	APIKey("x-api-key", key)
+//
+// The header is identified as carrying credentials in the Config of the
+// request (see: Config.CredentialHeaders), so that a client caching responses
+// does not serve a response obtained using one key to a request bearing some
+// other key.
+//
+// An error is returned if the header or key is empty.
+func APIKey(header, key string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		if header == "" || key == "" {
+			return fmt.Errorf("APIKey: %w: header and key are required", ErrMissingCredentials)
+		}
+		rq.Header.Set(header, key)
+		configure(rq, func(cfg *Config) {
+			header = http.CanonicalHeaderKey(header)
+			if !slices.Contains(cfg.CredentialHeaders, header) {
+				cfg.CredentialHeaders = append(cfg.CredentialHeaders, header)
+			}
+		})
+		return nil
+	}
+}
+
+// OAuth2Token describes a token which sets the Authorization header of a
+// request.  It is satisfied by *oauth2.Token (golang.org/x/oauth2) without
+// any dependency on that module.
+type OAuth2Token interface {
+	SetAuthHeader(*http.Request)
+}
+
+// OAuth2TokenSource describes a source of tokens.  It is satisfied by an
+// oauth2.TokenSource (golang.org/x/oauth2), such as that returned by the
+// TokenSource method of a clientcredentials.Config, without any dependency
+// on that module.
+type OAuth2TokenSource[T OAuth2Token] interface {
+	Token() (T, error)
+}
+
+// BearerTokenSource sets the Authorization header of a request using a token
+// obtained from a token source, such as an oauth2.TokenSource, when the
+// request is initialised.  The header is set by the token (for an
+// *oauth2.Token, using the token type, which is usually "Bearer").
+//
+// Caching and refreshing of tokens is the responsibility of the token source
+// (an oauth2.TokenSource returned by an oauth2 Config caches tokens, refreshing
+// them as required).  The option may be configured for every request made by
+// a client using the http.Auth client option:
+//
+//	ts := cfg.TokenSource(ctx) // e.g. a clientcredentials.Config
+//	c, err := http.NewClient("billing",
+//		http.URL("https://billing.example.com"),
+//		http.Auth(request.BearerTokenSource(ts)),
+//	)
+//
+// An error wrapping ErrMissingCredentials is returned if the source returns a
+// nil token.
+func BearerTokenSource[T OAuth2Token](ts OAuth2TokenSource[T]) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		ctx := rq.Context()
+
+		t, err := ts.Token()
+		if err != nil {
+			return errorcontext.Errorf(ctx, "BearerTokenSource: %w", err)
+		}
+		if v := reflect.ValueOf(t); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+			return errorcontext.Errorf(ctx, "BearerTokenSource: %w: nil token", ErrMissingCredentials)
+		}
+
+		t.SetAuthHeader(rq)
+
+		return nil
+	}
+}
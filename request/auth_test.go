@@ -9,6 +9,24 @@ import (
 	"github.com/blugnu/test"
 )
 
+// oauth2Token mimics an *oauth2.Token (golang.org/x/oauth2)
+type oauth2Token struct {
+	AccessToken string
+}
+
+func (t *oauth2Token) SetAuthHeader(rq *http.Request) {
+	rq.Header.Set("Authorization", "Bearer "+t.AccessToken)
+}
+
+// oauth2TokenSource mimics an oauth2.TokenSource (golang.org/x/oauth2)
+type oauth2TokenSource interface {
+	Token() (*oauth2Token, error)
+}
+
+type tokenSourceFunc func() (*oauth2Token, error)
+
+func (fn tokenSourceFunc) Token() (*oauth2Token, error) { return fn() }
+
 func TestAuth(t *testing.T) {
 	// ARRANGE
 	tokenerr := errors.New("token error")
@@ -37,6 +55,112 @@ func TestAuth(t *testing.T) {
 				test.Value(t, rq.Header.Get("Authorization")).Equals("Bearer token-value")
 			},
 		},
+		{scenario: "BearerToken/config is not carried",
+			act: func(rq *http.Request) error {
+				_ = AcceptStatus(http.StatusNotFound)(rq)
+				return BearerToken(func(ctx context.Context) (string, error) {
+					if _, ok := ConfigFromContext(ctx); ok {
+						return "", errors.New("config carried")
+					}
+					return "token-value", nil
+				})(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("Authorization")).Equals("Bearer token-value")
+			},
+		},
+		// BearerTokenString tests
+		{scenario: "BearerTokenString",
+			act: func(rq *http.Request) error {
+				return BearerTokenString("token-value")(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("Authorization")).Equals("Bearer token-value")
+			},
+		},
+
+		// BearerTokenSource tests
+		{scenario: "BearerTokenSource",
+			act: func(rq *http.Request) error {
+				var ts oauth2TokenSource = tokenSourceFunc(func() (*oauth2Token, error) {
+					return &oauth2Token{AccessToken: "token-value"}, nil
+				})
+				return BearerTokenSource(ts)(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("Authorization")).Equals("Bearer token-value")
+			},
+		},
+		{scenario: "BearerTokenSource/token error",
+			act: func(rq *http.Request) error {
+				var ts oauth2TokenSource = tokenSourceFunc(func() (*oauth2Token, error) {
+					return nil, tokenerr
+				})
+				return BearerTokenSource(ts)(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(tokenerr)
+				test.Value(t, rq.Header.Get("Authorization")).Equals("")
+			},
+		},
+		{scenario: "BearerTokenSource/nil token",
+			act: func(rq *http.Request) error {
+				var ts oauth2TokenSource = tokenSourceFunc(func() (*oauth2Token, error) {
+					return nil, nil
+				})
+				return BearerTokenSource(ts)(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(ErrMissingCredentials)
+			},
+		},
+
+		// BasicAuth tests
+		{scenario: "BasicAuth",
+			act: func(rq *http.Request) error {
+				return BasicAuth("user", "pass")(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).IsNil()
+				user, pass, ok := rq.BasicAuth()
+				test.IsTrue(t, ok, "basic auth")
+				test.Value(t, user).Equals("user")
+				test.Value(t, pass).Equals("pass")
+			},
+		},
+
+		// APIKey tests
+		{scenario: "APIKey",
+			act: func(rq *http.Request) error {
+				return APIKey("x-api-key", "key-value")(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("X-Api-Key")).Equals("key-value")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.Strings(t, cfg.CredentialHeaders).Equals([]string{"X-Api-Key"})
+			},
+		},
+		{scenario: "APIKey/no header",
+			act: func(rq *http.Request) error {
+				return APIKey("", "key-value")(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(ErrMissingCredentials)
+			},
+		},
+		{scenario: "APIKey/no key",
+			act: func(rq *http.Request) error {
+				return APIKey("X-Api-Key", "")(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(ErrMissingCredentials)
+				test.Value(t, rq.Header.Get("X-Api-Key")).Equals("")
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"net/http"
+	"time"
+)
+
+// Backoff configures the delay before each retry of a request, overriding any
+// backoff policy configured on the client used to make the request.  The
+// function is called with the number of the retry (1 for the first retry) and
+// returns the delay before that retry is attempted.
+//
+// Any http.BackoffPolicy may be specified:
+//
+//	request.Backoff(http.ConstantBackoff(time.Second))
+func Backoff(fn func(retry uint) time.Duration) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.Backoff = fn
+		})
+		return nil
+	}
+}
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestBackoff(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := Backoff(func(retry uint) time.Duration { return time.Duration(retry) * time.Second })(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.IsTrue(t, cfg.Backoff != nil, "backoff configured")
+	test.That(t, cfg.Backoff(2)).Equals(2 * time.Second)
+}
@@ -17,7 +17,8 @@ var cpy = func(dst, src []byte) int { return copy(dst, src) }
 var ErrCopyFailed = errors.New("copy() operation failed or was incomplete")
 
 // Body sets the body of a request to the contents of a supplied byte slice
-// and the ContentLength to the length of the slice.
+// and the ContentLength to the length of the slice.  The body may be replayed
+// using the GetBody function of the request.
 //
 // request.ErrCopyFailed is returned if the provided slice cannot be completely
 // copied to the request Body.
@@ -28,9 +29,19 @@ func Body(data []byte) func(*http.Request) error {
 			return ErrCopyFailed
 		}
 
-		rq.Body = io.NopCloser(bytes.NewReader(b))
-		rq.ContentLength = int64(len(b))
+		setBody(rq, b)
 
 		return nil
 	}
 }
+
+// setBody sets the body of a request to a supplied byte slice, setting the
+// ContentLength accordingly.  GetBody is also set so that the body may be
+// replayed, e.g. when cloning the request or following a redirect.
+func setBody(rq *http.Request, b []byte) {
+	rq.Body = io.NopCloser(bytes.NewReader(b))
+	rq.ContentLength = int64(len(b))
+	rq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
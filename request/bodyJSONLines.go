@@ -0,0 +1,156 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// BodyJSONLines sets the body of a request to a slice of values, encoded as
+// newline-delimited JSON (one JSON value per line), e.g. for a bulk import
+// endpoint.  A Content-Type header is added with the value
+// application/x-ndjson.
+//
+// The values are encoded as the body is sent, rather than being encoded in
+// memory beforehand, so the ContentLength of the request is unknown and the
+// body is sent using chunked encoding.  GetBody is set so that the body may be
+// replayed, e.g. when a request is retried.
+//
+// If a value cannot be encoded the request fails with an error wrapping
+// ErrMarshallingJSON.
+func BodyJSONLines[T any](items []T) func(*http.Request) error {
+	return jsonLinesBody("BodyJSONLines", func() func(<-chan struct{}) (T, bool) {
+		ix := 0
+		return func(<-chan struct{}) (T, bool) {
+			if ix == len(items) {
+				var zero T
+				return zero, false
+			}
+			ix++
+			return items[ix-1], true
+		}
+	}, true)
+}
+
+// BodyJSONLinesFrom sets the body of a request to values received from a
+// channel, encoded as newline-delimited JSON (one JSON value per line), e.g.
+// for a bulk import endpoint.  A Content-Type header is added with the value
+// application/x-ndjson.
+//
+// Values are received and encoded as the body is sent, so that items may be
+// streamed as they are produced; the body is complete when the channel is
+// closed.  The ContentLength of the request is unknown and the body is sent
+// using chunked encoding.
+//
+// Values received from the channel cannot be replayed, so the request should
+// not be retried.  If the request fails or is cancelled before the channel is
+// closed, no further values are received from it.
+//
+// If a value cannot be encoded the request fails with an error wrapping
+// ErrMarshallingJSON.
+func BodyJSONLinesFrom[T any](ch <-chan T) func(*http.Request) error {
+	return jsonLinesBody("BodyJSONLinesFrom", func() func(<-chan struct{}) (T, bool) {
+		return func(done <-chan struct{}) (T, bool) {
+			select {
+			case v, ok := <-ch:
+				return v, ok
+			case <-done:
+				var zero T
+				return zero, false
+			}
+		}
+	}, false)
+}
+
+// jsonLinesBody returns a request option setting the body of a request to a
+// newly initialised ndjsonReader for the values returned by an iterator.  If
+// the body is replayable, GetBody returns a new ndjsonReader with a newly
+// initialised iterator.
+//
+// All readers provided for the request are closed when the request is
+// complete (see Cleanup), ensuring that no encoding goroutine outlives the
+// request.
+func jsonLinesBody[T any](name string, iterator func() func(<-chan struct{}) (T, bool), replayable bool) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		mu := sync.Mutex{}
+		readers := []*ndjsonReader{}
+
+		newBody := func() *ndjsonReader {
+			mu.Lock()
+			defer mu.Unlock()
+			r := newNDJSONReader(name, iterator())
+			readers = append(readers, r)
+			return r
+		}
+
+		rq.Body = newBody()
+		rq.ContentLength = -1
+		rq.GetBody = nil
+		if replayable {
+			rq.GetBody = func() (io.ReadCloser, error) { return newBody(), nil }
+		}
+		rq.Header.Set("Content-Type", "application/x-ndjson")
+
+		return Cleanup(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, r := range readers {
+				_ = r.Close()
+			}
+		})(rq)
+	}
+}
+
+// ndjsonReader is an io.ReadCloser providing newline-delimited JSON encoded
+// values returned by an iterator.  Values are encoded by a goroutine, writing
+// to a pipe, which is started when the reader is first read.
+type ndjsonReader struct {
+	*io.PipeReader
+	start func()
+	once  sync.Once
+	done  chan struct{}
+}
+
+// newNDJSONReader returns a new ndjsonReader for the values returned by a
+// specified iterator.  The iterator is passed a channel which is closed when
+// the reader is closed.
+func newNDJSONReader[T any](name string, next func(<-chan struct{}) (T, bool)) *ndjsonReader {
+	pr, pw := io.Pipe()
+	r := &ndjsonReader{PipeReader: pr, done: make(chan struct{})}
+	r.start = sync.OnceFunc(func() {
+		go func() {
+			enc := json.NewEncoder(pw)
+			for {
+				v, ok := next(r.done)
+				if !ok {
+					_ = pw.Close()
+					return
+				}
+				if err := enc.Encode(v); err != nil {
+					if errors.Is(err, io.ErrClosedPipe) {
+						return
+					}
+					_ = pw.CloseWithError(fmt.Errorf("%s: %w: %w", name, ErrMarshallingJSON, err))
+					return
+				}
+			}
+		}()
+	})
+	return r
+}
+
+// Read implements io.Reader, starting the encoding goroutine on the first read
+func (r *ndjsonReader) Read(p []byte) (int, error) {
+	r.start()
+	return r.PipeReader.Read(p)
+}
+
+// Close implements io.Closer, closing the pipe (causing any blocked write by
+// the encoding goroutine to fail) and signalling the iterator
+func (r *ndjsonReader) Close() error {
+	r.once.Do(func() { close(r.done) })
+	return r.PipeReader.Close()
+}
@@ -0,0 +1,133 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestBodyJSONLines(t *testing.T) {
+	// ARRANGE
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "slice",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+
+				// ACT
+				err := BodyJSONLines([]item{{ID: 1}, {ID: 2}})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.ContentLength).Equals(-1)
+				test.That(t, rq.Header.Get("Content-Type")).Equals("application/x-ndjson")
+				body, err := io.ReadAll(rq.Body)
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("{\"id\":1}\n{\"id\":2}\n")
+			},
+		},
+		{scenario: "slice/replayed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+				_ = BodyJSONLines([]int{1, 2, 3})(rq)
+				_, _ = io.ReadAll(rq.Body)
+
+				// ACT
+				rc, err := rq.GetBody()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(rc)
+				test.That(t, string(body)).Equals("1\n2\n3\n")
+			},
+		},
+		{scenario: "slice/empty",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+
+				// ACT
+				_ = BodyJSONLines([]int{})(rq)
+
+				// ASSERT
+				body, err := io.ReadAll(rq.Body)
+				test.Error(t, err).IsNil()
+				test.That(t, len(body)).Equals(0)
+			},
+		},
+		{scenario: "slice/marshalling error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+				_ = BodyJSONLines([]any{1, func() {}})(rq)
+
+				// ACT
+				body, err := io.ReadAll(rq.Body)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMarshallingJSON)
+				test.That(t, string(body)).Equals("1\n")
+			},
+		},
+		{scenario: "channel",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+				ch := make(chan item)
+				go func() {
+					defer close(ch)
+					for i := 1; i <= 3; i++ {
+						ch <- item{ID: i}
+					}
+				}()
+
+				// ACT
+				err := BodyJSONLinesFrom(ch)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.GetBody).IsNil()
+				test.That(t, rq.Header.Get("Content-Type")).Equals("application/x-ndjson")
+				body, err := io.ReadAll(rq.Body)
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n")
+			},
+		},
+		{scenario: "channel/closed by cleanup",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "", nil)
+				ch := make(chan int, 1)
+				ch <- 1
+				_ = BodyJSONLinesFrom(ch)(rq)
+				buf := make([]byte, 2)
+				_, _ = io.ReadFull(rq.Body, buf)
+
+				// ACT
+				for _, fn := range CleanupFuncs(rq) {
+					fn()
+				}
+
+				// ASSERT
+				test.That(t, string(buf)).Equals("1\n")
+				_, err := rq.Body.Read(buf)
+				test.Error(t, err).Is(io.ErrClosedPipe)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
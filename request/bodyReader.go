@@ -0,0 +1,49 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BodyReader sets the body of a request to a supplied reader, for large bodies
+// (e.g. uploads) that should not be buffered in memory.  The ContentLength of
+// the request is set to the length specified; a negative length indicates that
+// the length is unknown, in which case the body is sent using chunked encoding.
+//
+// If the reader is an io.Seeker, GetBody is set so that the body may be
+// replayed from its current position, e.g. when a request is retried; the
+// reader is not closed by the client (the caller remains responsible for
+// closing it, e.g. an *os.File).
+//
+// If the reader is not seekable the body cannot be replayed, so the request
+// should not be retried.  A reader that is not seekable but is an io.ReadCloser
+// is closed when the request is sent.
+func BodyReader(r io.Reader, contentLength int64) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.ContentLength = max(contentLength, -1)
+		rq.GetBody = nil
+
+		if s, ok := r.(io.Seeker); ok {
+			start, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return fmt.Errorf("BodyReader: %w", err)
+			}
+			rq.Body = io.NopCloser(r)
+			rq.GetBody = func() (io.ReadCloser, error) {
+				if _, err := s.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(r), nil
+			}
+			return nil
+		}
+
+		if rc, ok := r.(io.ReadCloser); ok {
+			rq.Body = rc
+			return nil
+		}
+		rq.Body = io.NopCloser(r)
+		return nil
+	}
+}
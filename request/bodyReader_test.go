@@ -0,0 +1,144 @@
+package request
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// badSeeker is a reader that fails to seek
+type badSeeker struct{ io.Reader }
+
+func (badSeeker) Seek(int64, int) (int64, error) { return 0, errors.New("seek error") }
+
+// readCloser is a reader that records whether it has been closed
+type readCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (rc *readCloser) Close() error {
+	rc.closed = true
+	return nil
+}
+
+func TestBodyReader(t *testing.T) {
+	// ARRANGE
+	newRequest := func() *http.Request {
+		rq, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+		return rq
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "seekable reader",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest()
+				r := bytes.NewReader([]byte("skip:body"))
+				_, _ = r.Seek(5, io.SeekStart)
+
+				// ACT
+				err := BodyReader(r, 4)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.ContentLength).Equals(int64(4))
+				body, _ := io.ReadAll(rq.Body)
+				test.That(t, string(body)).Equals("body")
+
+				replay, err := rq.GetBody()
+				test.Error(t, err).IsNil()
+				body, _ = io.ReadAll(replay)
+				test.That(t, string(body)).Equals("body")
+			},
+		},
+		{scenario: "seek error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest()
+
+				// ACT
+				err := BodyReader(badSeeker{strings.NewReader("body")}, 4)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "replay seek error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest()
+				seekerr := errors.New("seek error")
+				calls := 0
+				r := &seekFunc{Reader: strings.NewReader("body"), fn: func() error {
+					if calls++; calls > 1 {
+						return seekerr
+					}
+					return nil
+				}}
+
+				// ACT
+				err := BodyReader(r, 4)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				_, err = rq.GetBody()
+				test.Error(t, err).Is(seekerr)
+			},
+		},
+		{scenario: "non-seekable read closer",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest()
+				rq.GetBody = func() (io.ReadCloser, error) { return nil, nil }
+				rc := &readCloser{Reader: strings.NewReader("body")}
+
+				// ACT
+				err := BodyReader(rc, -10)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.ContentLength).Equals(int64(-1))
+				test.That(t, rq.GetBody).IsNil()
+				_ = rq.Body.Close()
+				test.IsTrue(t, rc.closed, "reader closed")
+			},
+		},
+		{scenario: "non-seekable reader",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest()
+
+				// ACT
+				err := BodyReader(io.MultiReader(strings.NewReader("bo"), strings.NewReader("dy")), 4)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.GetBody).IsNil()
+				body, _ := io.ReadAll(rq.Body)
+				test.That(t, string(body)).Equals("body")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+// seekFunc is a reader calling a function to determine the result of each seek
+type seekFunc struct {
+	io.Reader
+	fn func() error
+}
+
+func (s *seekFunc) Seek(int64, int) (int64, error) { return 0, s.fn() }
@@ -42,6 +42,19 @@ func TestBody(t *testing.T) {
 				test.Bytes(t, body).Equals([]byte("body bytes"))
 			},
 		},
+		{scenario: "Body/replayable",
+			act: func(rq *http.Request) error {
+				return Body([]byte("body bytes"))(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				rc, gerr := rq.GetBody()
+				body, _ := io.ReadAll(rc)
+
+				test.Error(t, err).IsNil()
+				test.Error(t, gerr).IsNil()
+				test.Bytes(t, body).Equals([]byte("body bytes"))
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
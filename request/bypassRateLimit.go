@@ -0,0 +1,19 @@
+package request
+
+import "net/http"
+
+// BypassRateLimit configures the request to be performed without waiting for
+// any rate limit configured on the client (using the http.RateLimit client
+// option), e.g. for a health check that must not be delayed behind other
+// requests.
+//
+// The request is still subject to any pause of the client following a 429
+// Too Many Requests response.
+func BypassRateLimit() func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.BypassRateLimit = true
+		})
+		return nil
+	}
+}
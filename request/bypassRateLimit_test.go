@@ -0,0 +1,21 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestBypassRateLimit(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := BypassRateLimit()(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.IsTrue(t, cfg.BypassRateLimit, "bypass rate limit")
+}
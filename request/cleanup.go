@@ -0,0 +1,61 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// cleanupKey is the key under which the cleanup functions of a request are
+// held in the context of the request
+type cleanupKey struct{}
+
+// cleanups holds the cleanup functions registered for a request, together
+// with the request for which they were registered
+type cleanups struct {
+	rq  *http.Request
+	fns []func()
+}
+
+// Cleanup registers a function to be called by the client performing the
+// request once the request is complete, i.e. when the request fails or, if
+// successful, when the response body has been consumed:
+//
+//   - for a buffered response, once the body has been read by the client;
+//   - for a streamed response (see StreamResponse), when the caller closes
+//     the response body.
+//
+// This enables options that acquire resources for the duration of a request
+// (temporary files, pipes, progress tickers etc) to release them reliably.
+// An option may register a cleanup function by applying this option to the
+// request it is configuring.
+//
+// Cleanup functions are called at most once, in the reverse of the order in
+// which they were registered.  A nil function is ignored.
+//
+// Cleanup functions belong to the request for which they are registered; they
+// are not inherited by any other request, including a clone of the request or
+// a request made using a context derived from the context of the request.
+//
+// Cleanup functions are only called by a client provided by this module; if
+// the request is submitted by some other client they are not called.
+func Cleanup(fn func()) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		if fn == nil {
+			return nil
+		}
+		cl := &cleanups{rq: rq, fns: append(slices.Clip(CleanupFuncs(rq)), fn)}
+		*rq = *rq.WithContext(context.WithValue(rq.Context(), cleanupKey{}, cl))
+		return nil
+	}
+}
+
+// CleanupFuncs returns the cleanup functions registered for a specified
+// request, in the order in which they were registered (see: Cleanup).  If no
+// cleanup functions are registered for the request, nil is returned.
+func CleanupFuncs(rq *http.Request) []func() {
+	if cl, ok := rq.Context().Value(cleanupKey{}).(*cleanups); ok && cl.rq == rq {
+		return cl.fns
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestCleanup(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "registers functions",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err1 := Cleanup(func() {})(rq)
+				err2 := Cleanup(func() {})(rq)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, len(CleanupFuncs(rq))).Equals(2)
+			},
+		},
+		{scenario: "nil function",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := Cleanup(nil)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, CleanupFuncs(rq)).IsNil()
+			},
+		},
+		{scenario: "not inherited by a clone",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = Cleanup(func() {})(rq)
+				clone := rq.Clone(rq.Context())
+
+				// ACT
+				_ = Cleanup(func() {})(clone)
+
+				// ASSERT
+				test.That(t, len(CleanupFuncs(rq))).Equals(1)
+				test.That(t, len(CleanupFuncs(clone))).Equals(1)
+			},
+		},
+		{scenario: "not inherited by a request using the context",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				parent, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = Cleanup(func() {})(parent)
+
+				// ACT
+				rq, _ := http.NewRequestWithContext(parent.Context(), http.MethodGet, "", nil)
+
+				// ASSERT
+				test.That(t, CleanupFuncs(rq)).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IfNoneMatch makes the request conditional upon the current representation
+// of the resource not matching a specified entity tag, setting the
+// If-None-Match header.  An unquoted tag is quoted; a weak tag (W/"...") or
+// "*" is used as-is.
+//
+// A 304 Not Modified response is accepted, so that a request to revalidate a
+// previously received representation does not fail if the resource has not
+// changed:
+//
+//	r, err := client.Get(ctx, "customers/42", request.IfNoneMatch(etag))
+//	if err == nil && r.StatusCode == http.StatusNotModified {
+//		// the previously received representation is current
+//	}
+func IfNoneMatch(etag string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.Header.Set("If-None-Match", quoteETag(etag))
+		return AcceptStatus(http.StatusNotModified)(rq)
+	}
+}
+
+// IfMatch makes the request conditional upon the current representation of
+// the resource matching a specified entity tag, setting the If-Match header,
+// e.g. to avoid a lost update when modifying a resource.  An unquoted tag is
+// quoted; a weak tag (W/"...") or "*" is used as-is.
+//
+// If the tag does not match, the server responds with 412 Precondition Failed
+// which (unless accepted) results in an error.
+func IfMatch(etag string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.Header.Set("If-Match", quoteETag(etag))
+		return nil
+	}
+}
+
+// IfModifiedSince makes the request conditional upon the resource having been
+// modified after a specified time, setting the If-Modified-Since header.  The
+// time is formatted as an HTTP date (in UTC, with a resolution of one second).
+//
+// As for IfNoneMatch, a 304 Not Modified response is accepted.
+func IfModifiedSince(t time.Time) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rq.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+		return AcceptStatus(http.StatusNotModified)(rq)
+	}
+}
+
+// quoteETag returns an entity tag quoted (if necessary) for use in a header
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, `W/"`) || strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
@@ -0,0 +1,91 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestConditional(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "IfNoneMatch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := IfNoneMatch(`"v1"`)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("If-None-Match")).Equals(`"v1"`)
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotModified})
+			},
+		},
+		{scenario: "IfMatch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPut, "", nil)
+
+				// ACT
+				err := IfMatch("v1")(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("If-Match")).Equals(`"v1"`)
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, len(cfg.AcceptStatus)).Equals(0)
+			},
+		},
+		{scenario: "IfModifiedSince",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				tm := time.Date(2010, 9, 8, 9, 6, 5, 0, time.FixedZone("CEST", 2*60*60))
+
+				// ACT
+				err := IfModifiedSince(tm)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("If-Modified-Since")).Equals("Wed, 08 Sep 2010 07:06:05 GMT")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotModified})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestQuoteETag(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		etag   string
+		result string
+	}{
+		{etag: "v1", result: `"v1"`},
+		{etag: `"v1"`, result: `"v1"`},
+		{etag: `W/"v1"`, result: `W/"v1"`},
+		{etag: "*", result: "*"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.etag, func(t *testing.T) {
+			// ACT
+			result := quoteETag(tc.etag)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
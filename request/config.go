@@ -0,0 +1,154 @@
+package request
+
+import (
+	"context"
+	"crypto/sha256"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Config holds request-scoped configuration determining how a request is
+// performed by a client and the initial handling of any response.
+//
+// Config is carried in the context of a request and so, unlike a header, is
+// never transmitted to a server, even if the request is submitted using some
+// client other than that provided by this module.
+//
+// A Config applies only to the request that it configures; a request created
+// by a client provided by this module does not inherit any Config carried by
+// the context from which the request is created (see: WithoutConfig).
+//
+// Config is established using request options such as AcceptStatus(),
+// MaxRetries(), ResponseBodyRequired() and StreamResponse(); it is not
+// usually necessary to reference it directly.
+type Config struct {
+	// AcceptStatus holds any status codes to be accepted in addition to
+	// http.StatusOK
+	AcceptStatus []int
+
+	// AcceptStatusFuncs holds functions identifying status codes to be
+	// accepted in addition to http.StatusOK and any AcceptStatus codes
+	AcceptStatusFuncs []func(int) bool
+
+	// Affinity, if not empty, pins the request to the same backend as other
+	// requests with the same key, when performed by a client configured with
+	// multiple backends
+	Affinity string
+
+	// Backoff, if not nil, overrides the backoff policy configured on the
+	// client performing the request, returning the delay before each retry
+	Backoff func(retry uint) time.Duration
+
+	// BypassRateLimit indicates that the request is to be performed without
+	// waiting for any rate limit configured on the client
+	BypassRateLimit bool
+
+	// CredentialHeaders holds the (canonical) names of any headers other than
+	// Authorization carrying credentials for the request, e.g. as set by
+	// APIKey
+	CredentialHeaders []string
+
+	// DownloadProgress, if not nil, is called to report progress in
+	// receiving the body of the response
+	DownloadProgress func(received, total int64)
+
+	// LogFields holds structured fields to be included in any logging or
+	// metrics relating to the request
+	LogFields map[string]any
+
+	// MaxResponseBytes, if not nil, overrides the maximum size of a response
+	// body read by the client performing the request; zero imposes no limit
+	MaxResponseBytes *int64
+
+	// MaxRetries, if not nil, overrides the maximum number of retries
+	// configured on the client performing the request; zero disables retries
+	MaxRetries *uint
+
+	// NoFollowRedirects indicates that redirects are not to be followed; any
+	// redirect response is returned by the client performing the request
+	NoFollowRedirects bool
+
+	// PinnedCertificates holds the SHA-256 fingerprints of certificates, any
+	// of which must be presented by the server in the TLS handshake
+	PinnedCertificates [][sha256.Size]byte
+
+	// PollCursor, if not nil, propagates a cursor from the response to each
+	// poll of a long-polling request to the request for the next poll
+	PollCursor func(prev *http.Response, next *http.Request) error
+
+	// Progress, if not nil, is called to report progress in sending the
+	// body of the request
+	Progress func(sent, total int64)
+
+	// RequestID, if not empty, is the correlation id to be sent with the
+	// request by a client configured to send request ids
+	RequestID string
+
+	// ResponseBodyRequired indicates that a non-empty response body is
+	// required
+	ResponseBodyRequired bool
+
+	// RetryOnStatus, if not nil, overrides the status codes for which a
+	// response is retried, as configured on the client performing the request
+	RetryOnStatus []int
+
+	// StreamResponse indicates that the response body is to be streamed
+	StreamResponse bool
+
+	// TLSServerName, if not empty, overrides the server name used to verify
+	// the server certificate and sent in the TLS handshake (SNI)
+	TLSServerName string
+
+	// UnlimitedRetriesWithin, if not nil, configures the request to be
+	// retried without limit on the number of retries, within the duration
+	// specified (zero: bounded only by the request context)
+	UnlimitedRetriesWithin *time.Duration
+}
+
+// configKey is the key under which a Config is held in a context
+type configKey struct{}
+
+// ConfigFromContext returns any Config carried in a specified context.  If
+// the context does not carry a Config then a zero-value Config is returned
+// together with false.
+func ConfigFromContext(ctx context.Context) (Config, bool) {
+	cfg, ok := ctx.Value(configKey{}).(Config)
+	return cfg, ok
+}
+
+// WithoutConfig returns a context derived from a specified context that does
+// not carry any Config carried by that context.  If the context does not carry
+// a Config it is returned unchanged.
+//
+// A Config applies only to the request that it configures; a context derived
+// from the context of a request (e.g. in a function called while performing
+// the request) must not configure any other request made using it.
+func WithoutConfig(ctx context.Context) context.Context {
+	if _, ok := ConfigFromContext(ctx); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, configKey{}, nil)
+}
+
+// configure applies a function to a copy of any Config carried by the context
+// of a request, replacing the context of the request with one carrying the
+// modified copy.
+//
+// A Config is never modified in place; this ensures that configuring a request
+// has no effect on any other request sharing the same (parent) context.
+func configure(rq *http.Request, fn func(*Config)) {
+	ctx := rq.Context()
+
+	cfg, _ := ConfigFromContext(ctx)
+	cfg.AcceptStatus = slices.Clone(cfg.AcceptStatus)
+	cfg.AcceptStatusFuncs = slices.Clone(cfg.AcceptStatusFuncs)
+	cfg.CredentialHeaders = slices.Clone(cfg.CredentialHeaders)
+	cfg.LogFields = maps.Clone(cfg.LogFields)
+	cfg.PinnedCertificates = slices.Clone(cfg.PinnedCertificates)
+	cfg.RetryOnStatus = slices.Clone(cfg.RetryOnStatus)
+	fn(&cfg)
+
+	*rq = *rq.WithContext(context.WithValue(ctx, configKey{}, cfg))
+}
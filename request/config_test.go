@@ -0,0 +1,109 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestConfigFromContext(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "no config",
+			exec: func(t *testing.T) {
+				// ACT
+				cfg, ok := ConfigFromContext(context.Background())
+
+				// ASSERT
+				test.Bool(t, ok).IsFalse()
+				test.That(t, cfg).Equals(Config{})
+			},
+		},
+		{scenario: "configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = StreamResponse()(rq)
+
+				// ACT
+				cfg, ok := ConfigFromContext(rq.Context())
+
+				// ASSERT
+				test.IsTrue(t, ok, "config present")
+				test.That(t, cfg).Equals(Config{StreamResponse: true})
+			},
+		},
+		{scenario: "parent context is not modified",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				parent, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = AcceptStatus(http.StatusNotFound)(parent)
+				rq := parent.WithContext(parent.Context())
+
+				// ACT
+				_ = AcceptStatus(http.StatusConflict)(rq)
+
+				// ASSERT
+				cfg, _ := ConfigFromContext(parent.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotFound})
+
+				cfg, _ = ConfigFromContext(rq.Context())
+				test.That(t, cfg.AcceptStatus).Equals([]int{http.StatusNotFound, http.StatusConflict})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestWithoutConfig(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "no config",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				ctx := context.Background()
+
+				// ACT
+				result := WithoutConfig(ctx)
+
+				// ASSERT
+				test.IsTrue(t, result == ctx, "context is unchanged")
+			},
+		},
+		{scenario: "configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				type key struct{}
+				ctx := context.WithValue(context.Background(), key{}, "value")
+				rq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "", nil)
+				_ = AcceptStatus(http.StatusNotFound)(rq)
+
+				// ACT
+				result := WithoutConfig(rq.Context())
+
+				// ASSERT
+				cfg, ok := ConfigFromContext(result)
+				test.Bool(t, ok).IsFalse()
+				test.That(t, cfg).Equals(Config{})
+				test.That(t, result.Value(key{})).Equals(any("value"))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
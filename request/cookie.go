@@ -0,0 +1,30 @@
+package request
+
+import "net/http"
+
+// Cookie adds a cookie with a specified name and value to the request.
+//
+// Cookies added to a request are sent in addition to any cookies applied
+// from a cookie jar configured on the client (see: http.CookieJar).
+func Cookie(name, value string) func(*http.Request) error {
+	return Cookies(&http.Cookie{Name: name, Value: value})
+}
+
+// Cookies adds cookies to the request.  Only the Name and Value of each
+// cookie are sent; nil cookies are ignored.
+//
+// Cookies added to a request are sent in addition to any cookies applied
+// from a cookie jar configured on the client (see: http.CookieJar).
+func Cookies(cookies ...*http.Cookie) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		if rq.Header == nil {
+			rq.Header = http.Header{}
+		}
+		for _, cookie := range cookies {
+			if cookie != nil {
+				rq.AddCookie(cookie)
+			}
+		}
+		return nil
+	}
+}
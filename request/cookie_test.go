@@ -0,0 +1,36 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestCookie(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := Cookie("session", "abc")(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.That(t, rq.Header.Get("Cookie")).Equals("session=abc")
+}
+
+func TestCookies(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := Cookies(
+		&http.Cookie{Name: "a", Value: "1"},
+		nil,
+		&http.Cookie{Name: "b", Value: "2", Path: "/ignored"},
+	)(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.That(t, rq.Header.Get("Cookie")).Equals("a=1; b=2")
+}
@@ -8,4 +8,9 @@ var (
 	ErrSetBoundary      = errors.New("SetBoundary error")
 	ErrTooManyArguments = errors.New("too many arguments")
 	ErrInvalidQuery     = errors.New("invalid query")
+
+	ErrMissingCredentials   = errors.New("missing credentials")
+	ErrMissingPathParameter = errors.New("missing path parameter")
+
+	ErrInvalidCertificateFingerprint = errors.New("invalid certificate fingerprint")
 )
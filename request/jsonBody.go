@@ -1,10 +1,8 @@
 package request
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 )
 
@@ -19,8 +17,7 @@ func JSONBody(v any) func(*http.Request) error {
 			return fmt.Errorf("JSONBody: %w: %w", ErrMarshallingJSON, err)
 		}
 
-		rq.Body = io.NopCloser(bytes.NewReader(b))
-		rq.ContentLength = int64(len(b))
+		setBody(rq, b)
 		rq.Header.Set("Content-Type", "application/json")
 
 		return nil
@@ -0,0 +1,40 @@
+package request
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+// LogFields attaches structured fields (e.g. an order id or tenant) to a
+// request, to be included by any logging or metrics middleware in records
+// relating to the request.  The fields are carried in the request context and
+// are never sent to a server.
+//
+// The option may be specified more than once; fields are combined, with the
+// value specified by a later option replacing any earlier value of the same
+// field.
+func LogFields(fields map[string]any) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			if cfg.LogFields == nil {
+				cfg.LogFields = make(map[string]any, len(fields))
+			}
+			for k, v := range fields {
+				cfg.LogFields[k] = v
+			}
+		})
+		return nil
+	}
+}
+
+// LogFieldsFromContext returns any log fields attached to a request, given
+// the request context.  The returned map is a copy and may be modified by
+// the caller.  If no fields are attached, nil is returned.
+func LogFieldsFromContext(ctx context.Context) map[string]any {
+	cfg, _ := ConfigFromContext(ctx)
+	if len(cfg.LogFields) == 0 {
+		return nil
+	}
+	return maps.Clone(cfg.LogFields)
+}
@@ -0,0 +1,75 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestLogFields(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no fields",
+			exec: func(t *testing.T) {
+				// ACT
+				result := LogFieldsFromContext(context.Background())
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "fields combined",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err1 := LogFields(map[string]any{"order": 42, "tenant": "a"})(rq)
+				err2 := LogFields(map[string]any{"tenant": "b"})(rq)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, LogFieldsFromContext(rq.Context())).Equals(map[string]any{"order": 42, "tenant": "b"})
+			},
+		},
+		{scenario: "parent context is not modified",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				parent, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = LogFields(map[string]any{"tenant": "a"})(parent)
+				rq := parent.WithContext(parent.Context())
+
+				// ACT
+				_ = LogFields(map[string]any{"tenant": "b"})(rq)
+
+				// ASSERT
+				test.Map(t, LogFieldsFromContext(parent.Context())).Equals(map[string]any{"tenant": "a"})
+				test.Map(t, LogFieldsFromContext(rq.Context())).Equals(map[string]any{"tenant": "b"})
+			},
+		},
+		{scenario: "returned fields are a copy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = LogFields(map[string]any{"tenant": "a"})(rq)
+
+				// ACT
+				LogFieldsFromContext(rq.Context())["tenant"] = "modified"
+
+				// ASSERT
+				test.Map(t, LogFieldsFromContext(rq.Context())).Equals(map[string]any{"tenant": "a"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package request
+
+import "net/http"
+
+// MaxResponseBytes configures the maximum size (in bytes) of the response body
+// that will be read by the client performing the request, overriding any
+// MaxResponseBytes configured on the client.  If the response body exceeds the
+// limit, the client returns an error wrapping http.ErrResponseBodyTooLarge.
+//
+// MaxResponseBytes(0) explicitly removes any limit for the request, regardless
+// of any limit configured on the client.  The limit does not apply to a
+// streamed response (see: StreamResponse).
+func MaxResponseBytes(n int64) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.MaxResponseBytes = &n
+		})
+		return nil
+	}
+}
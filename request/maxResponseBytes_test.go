@@ -0,0 +1,22 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestMaxResponseBytes(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+	_ = MaxResponseBytes(1024)(rq)
+
+	// ACT
+	err := MaxResponseBytes(0)(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.That(t, *cfg.MaxResponseBytes).Equals(0)
+}
@@ -2,9 +2,14 @@ package request
 
 import (
 	"net/http"
-	"strconv"
+	"time"
 )
 
+// MaxRetriesHeader identifies a header that may be used to specify the
+// maximum retries for a request.  The header is supported for backwards
+// compatibility only; the MaxRetries() option configures the request context
+// and does not set this header.
+//
 // canonical casing avoids go-staticcheck flagging the constant with SA1008
 const MaxRetriesHeader = "X-Blugnu-Http-Max-Retries"
 
@@ -15,9 +20,34 @@ const MaxRetriesHeader = "X-Blugnu-Http-Max-Retries"
 // e.g. if the client is configured with MaxRetries == 5 and a request is
 // submitted with MaxRetries == 3, then at most 4 attempts will be made: the
 // initial request and at most 3 retry attempts
+//
+// MaxRetries(0) explicitly disables retries for the request, regardless of any
+// retries configured on the client.  MaxRetries replaces any
+// UnlimitedRetriesWithin option applied previously.
 func MaxRetries(n uint) func(*http.Request) error {
 	return func(rq *http.Request) error {
-		rq.Header[MaxRetriesHeader] = []string{strconv.Itoa(int(n))}
+		configure(rq, func(cfg *Config) {
+			cfg.MaxRetries = &n
+			cfg.UnlimitedRetriesWithin = nil
+		})
+		return nil
+	}
+}
+
+// UnlimitedRetriesWithin configures a request to be retried without limit on
+// the number of retries, but only within a specified duration from the initial
+// attempt; a retry is not attempted if it could not complete within that
+// duration.  If the duration is zero, retries are bounded only by the request
+// context.
+//
+// UnlimitedRetriesWithin overrides any retries configured on the client used to
+// make the request and replaces any MaxRetries option applied previously.
+func UnlimitedRetriesWithin(d time.Duration) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.MaxRetries = nil
+			cfg.UnlimitedRetriesWithin = &d
+		})
 		return nil
 	}
 }
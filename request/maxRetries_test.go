@@ -3,6 +3,7 @@ package request
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/blugnu/test"
 )
@@ -13,7 +14,7 @@ func TestMaxRetries(t *testing.T) {
 		scenario string
 		exec     func(*testing.T)
 	}{
-		{scenario: "no header",
+		{scenario: "no config",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
@@ -23,21 +24,40 @@ func TestMaxRetries(t *testing.T) {
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[MaxRetriesHeader][0]).Equals("3")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, *cfg.MaxRetries).Equals(3)
+				test.That(t, rq.Header[MaxRetriesHeader]).IsNil()
 			},
 		},
-		{scenario: "existing header",
+		{scenario: "existing config",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
-				rq.Header[MaxRetriesHeader] = []string{"10"}
+				_ = MaxRetries(10)(rq)
 
 				// ACT
 				err := MaxRetries(3)(rq)
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[MaxRetriesHeader][0]).Equals("3")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, *cfg.MaxRetries).Equals(3)
+			},
+		},
+		{scenario: "replaces unlimited retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+				_ = UnlimitedRetriesWithin(time.Minute)(rq)
+
+				// ACT
+				err := MaxRetries(0)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, *cfg.MaxRetries).Equals(0)
+				test.IsTrue(t, cfg.UnlimitedRetriesWithin == nil, "unlimited retries removed")
 			},
 		},
 	}
@@ -47,3 +67,18 @@ func TestMaxRetries(t *testing.T) {
 		})
 	}
 }
+
+func TestUnlimitedRetriesWithin(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+	_ = MaxRetries(3)(rq)
+
+	// ACT
+	err := UnlimitedRetriesWithin(time.Minute)(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.That(t, *cfg.UnlimitedRetriesWithin).Equals(time.Minute)
+	test.IsTrue(t, cfg.MaxRetries == nil, "max retries removed")
+}
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/blugnu/http/multipart"
+)
+
+// MultipartForm sets the body of a request to multipart form data comprising
+// the fields and files added to a multipart.Builder, in the order in which
+// they were added.  The Content-Type header is set accordingly.
+//
+// The body is streamed; the content of files is read as the body is sent and
+// the ContentLength of the request is unknown.  If the body may be replayed
+// (see: multipart.Builder.Replayable) GetBody is set, e.g. so that the request
+// may be retried; otherwise the request should not be retried.
+func MultipartForm(b *multipart.Builder) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		return multipartStream("MultipartForm", func() (func(*multipart.Writer) error, error) {
+			return b.WriteParts, nil
+		}, b.Replayable())(rq)
+	}
+}
@@ -1,9 +1,7 @@
 package request
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/blugnu/http/multipart"
@@ -33,8 +31,7 @@ func MultipartFormDataFromMap[K comparable, V any](
 		}
 
 		rq.Header.Set("Content-Type", ct)
-		rq.Body = io.NopCloser(bytes.NewReader(body))
-		rq.ContentLength = int64(len(body))
+		setBody(rq, body)
 
 		return nil
 	}
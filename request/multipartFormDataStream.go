@@ -0,0 +1,148 @@
+package request
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/blugnu/http/multipart"
+)
+
+// randomBoundary returns a random boundary string for a multipart body
+var randomBoundary = func() string {
+	var b [30]byte
+	_, _ = io.ReadFull(rand.Reader, b[:])
+	return fmt.Sprintf("%x", b[:])
+}
+
+// MultipartFormDataStream sets the body of a request to multipart form data,
+// with the parts written by a supplied function as the body is sent, rather
+// than the body being held in memory in its entirety (cf.
+// MultipartFormDataFromMap).  The Content-Type header is set accordingly.
+//
+// The ContentLength of the request is unknown and the body is sent using
+// chunked encoding.  GetBody is set so that the body may be replayed, e.g.
+// when a request is retried; the function is called again each time the body
+// is replayed and must write the same parts each time.
+//
+// Any error returned by the function causes the request to fail.
+func MultipartFormDataStream(write func(*multipart.Writer) error) func(*http.Request) error {
+	return multipartStream("MultipartFormDataStream", func() (func(*multipart.Writer) error, error) {
+		return write, nil
+	}, true)
+}
+
+// MultipartFile sets the body of a request to multipart form data comprising a
+// single part with a specified field name and filename, with content read from
+// a supplied reader (e.g. an *os.File) as the body is sent.  The Content-Type
+// header is set accordingly.
+//
+// If the reader is an io.Seeker, GetBody is set so that the body may be
+// replayed from the current position of the reader, e.g. when a request is
+// retried; any previous body is closed before the reader is repositioned.  Otherwise the body cannot be replayed, so the request should not
+// be retried.  The reader is not closed by the client.
+func MultipartFile(fieldName, filename string, r io.Reader) func(*http.Request) error {
+	s, seekable := r.(io.Seeker)
+
+	return func(rq *http.Request) error {
+		var start int64
+		if seekable {
+			var err error
+			if start, err = s.Seek(0, io.SeekCurrent); err != nil {
+				return fmt.Errorf("MultipartFile: %w", err)
+			}
+		}
+
+		first := true
+		return multipartStream("MultipartFile", func() (func(*multipart.Writer) error, error) {
+			if seekable && !first {
+				if _, err := s.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+			first = false
+			return func(w *multipart.Writer) error {
+				part, err := w.CreateFormFile(fieldName, filename)
+				if err != nil {
+					return err
+				}
+				_, err = io.Copy(part, r)
+				return err
+			}, nil
+		}, seekable)(rq)
+	}
+}
+
+// multipartStream returns a request option setting the body of a request to a
+// multipart body streamed using a write function obtained from a supplied
+// function for each body.  If the body is replayable, GetBody returns a new
+// body, using the same boundary, once any previous body has been closed and
+// the goroutine writing it has returned.
+//
+// All bodies provided for the request are closed when the request is complete
+// (see Cleanup), ensuring that no writing goroutine outlives the request.
+func multipartStream(
+	name string,
+	writer func() (func(*multipart.Writer) error, error),
+	replayable bool,
+) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		mu := sync.Mutex{}
+		bodies := []io.ReadCloser{}
+		boundary := randomBoundary()
+
+		newBody := func() (string, io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			// the previous body is closed and its writer allowed to return
+			// before a new writer is obtained, so that a source shared by
+			// the writers (e.g. the reader of a MultipartFile) is never used
+			// by more than one writer at a time
+			if n := len(bodies); n > 0 {
+				prev := bodies[n-1]
+				_ = prev.Close()
+				if d, ok := prev.(interface{ Done() <-chan struct{} }); ok {
+					<-d.Done()
+				}
+			}
+
+			write, err := writer()
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %w", name, err)
+			}
+			ct, body, err := multipart.Stream(write, multipart.Boundary(boundary))
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %w", name, err)
+			}
+			bodies = append(bodies, body)
+			return ct, body, nil
+		}
+
+		ct, body, err := newBody()
+		if err != nil {
+			return err
+		}
+
+		rq.Header.Set("Content-Type", ct)
+		rq.Body = body
+		rq.ContentLength = -1
+		rq.GetBody = nil
+		if replayable {
+			rq.GetBody = func() (io.ReadCloser, error) {
+				_, body, err := newBody()
+				return body, err
+			}
+		}
+
+		return Cleanup(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, body := range bodies {
+				_ = body.Close()
+			}
+		})(rq)
+	}
+}
@@ -0,0 +1,235 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	blugnu "github.com/blugnu/http/multipart"
+	"github.com/blugnu/test"
+)
+
+func TestMultipartFormDataStream(t *testing.T) {
+	// ARRANGE
+	og := randomBoundary
+	defer func() { randomBoundary = og }()
+	randomBoundary = func() string { return "boundary" }
+
+	// parts returns the name, filename and content of each part of the body
+	// of a request
+	parts := func(t *testing.T, rq *http.Request, body io.Reader) []string {
+		t.Helper()
+		_, params, err := mime.ParseMediaType(rq.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := []string{}
+		mpr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mpr.NextPart()
+			if err == io.EOF {
+				return result
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := io.ReadAll(part)
+			result = append(result, part.FormName()+":"+part.FileName()+":"+string(content))
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "MultipartFormDataStream",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				err := MultipartFormDataStream(func(w *blugnu.Writer) error {
+					if err := w.WriteField("name", "value"); err != nil {
+						return err
+					}
+					part, err := w.CreateFormFile("file", "file.txt")
+					if err != nil {
+						return err
+					}
+					_, err = part.Write([]byte("content"))
+					return err
+				})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("Content-Type")).Equals("multipart/form-data; boundary=boundary")
+				test.That(t, rq.ContentLength).Equals(int64(-1))
+				test.Strings(t, parts(t, rq, rq.Body)).Equals([]string{"name::value", "file:file.txt:content"})
+
+				body, err := rq.GetBody()
+				test.Error(t, err).IsNil()
+				test.Strings(t, parts(t, rq, body)).Equals([]string{"name::value", "file:file.txt:content"})
+
+				test.That(t, len(CleanupFuncs(rq))).Equals(1)
+			},
+		},
+		{scenario: "MultipartFormDataStream/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				writeerr := errors.New("write error")
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				err := MultipartFormDataStream(func(*blugnu.Writer) error { return writeerr })(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				_, err = io.ReadAll(rq.Body)
+				test.Error(t, err).Is(writeerr)
+			},
+		},
+		{scenario: "MultipartFormDataStream/cleanup closes bodies",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				_ = MultipartFormDataStream(func(w *blugnu.Writer) error { return w.WriteField("name", "value") })(rq)
+				replay, _ := rq.GetBody()
+
+				// ACT
+				CleanupFuncs(rq)[0]()
+
+				// ASSERT
+				_, err := rq.Body.Read(make([]byte, 1))
+				test.Error(t, err).Is(io.ErrClosedPipe)
+				_, err = replay.Read(make([]byte, 1))
+				test.Error(t, err).Is(io.ErrClosedPipe)
+			},
+		},
+		{scenario: "MultipartFile/seekable reader",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := strings.NewReader("xxfile content")
+				_, _ = r.Seek(2, io.SeekStart)
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				err := MultipartFile("file", "file.txt", r)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, parts(t, rq, rq.Body)).Equals([]string{"file:file.txt:file content"})
+
+				body, err := rq.GetBody()
+				test.Error(t, err).IsNil()
+				test.Strings(t, parts(t, rq, body)).Equals([]string{"file:file.txt:file content"})
+			},
+		},
+		{scenario: "MultipartFile/replay waits for previous writer",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				src := &blockingSeeker{
+					ReadSeeker: strings.NewReader("file content"),
+					reading:    make(chan struct{}),
+					release:    make(chan struct{}),
+				}
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				_ = MultipartFile("file", "file.txt", src)(rq)
+				go func() { _, _ = io.Copy(io.Discard, rq.Body) }()
+				<-src.reading
+
+				// ACT
+				replayed := make(chan error)
+				go func() {
+					_, err := rq.GetBody()
+					replayed <- err
+				}()
+				time.Sleep(10 * time.Millisecond)
+				close(src.release)
+
+				// ASSERT
+				test.Error(t, <-replayed).IsNil()
+				test.IsFalse(t, src.seekedWhileReading.Load(), "reader repositioned while being read")
+			},
+		},
+		{scenario: "MultipartFile/non-seekable reader",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				err := MultipartFile("file", "file.txt", io.MultiReader(strings.NewReader("file content")))(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, rq.GetBody == nil, "body is not replayable")
+				test.Strings(t, parts(t, rq, rq.Body)).Equals([]string{"file:file.txt:file content"})
+			},
+		},
+		{scenario: "MultipartFile/seek error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				seekerr := errors.New("seek error")
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+
+				// ACT
+				err := MultipartFile("file", "file.txt", failingSeeker{err: seekerr})(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(seekerr)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+// failingSeeker is an io.ReadSeeker that fails to seek
+type failingSeeker struct {
+	io.Reader
+	err error
+}
+
+// Seek implements io.Seeker, returning the configured error
+func (fs failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, fs.err
+}
+
+// blockingSeeker is an io.ReadSeeker whose first read blocks until released,
+// recording whether it is repositioned while a read is in progress
+type blockingSeeker struct {
+	io.ReadSeeker
+	once               sync.Once
+	reading            chan struct{}
+	release            chan struct{}
+	inRead             atomic.Bool
+	seekedWhileReading atomic.Bool
+}
+
+// Read implements io.Reader, blocking the first read until released
+func (bs *blockingSeeker) Read(p []byte) (int, error) {
+	bs.inRead.Store(true)
+	defer bs.inRead.Store(false)
+	bs.once.Do(func() {
+		close(bs.reading)
+		<-bs.release
+	})
+	return bs.ReadSeeker.Read(p)
+}
+
+// Seek implements io.Seeker, recording whether a read is in progress
+func (bs *blockingSeeker) Seek(offset int64, whence int) (int64, error) {
+	if bs.inRead.Load() {
+		bs.seekedWhileReading.Store(true)
+	}
+	return bs.ReadSeeker.Seek(offset, whence)
+}
@@ -0,0 +1,84 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/multipart"
+	"github.com/blugnu/test"
+)
+
+func TestMultipartForm(t *testing.T) {
+	// ARRANGE
+	og := randomBoundary
+	defer func() { randomBoundary = og }()
+	randomBoundary = func() string { return "boundary" }
+
+	wantBody := "--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"owner\"\r\n" +
+		"\r\n" +
+		"jane\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"document\"; filename=\"report.pdf\"\r\n" +
+		"Content-Type: application/pdf\r\n" +
+		"\r\n" +
+		"pdf content\r\n" +
+		"--boundary--\r\n"
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "replayable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				b := &multipart.Builder{}
+				b.AddField("owner", "jane").
+					AddFile("document", "report.pdf", "application/pdf", strings.NewReader("pdf content"))
+
+				// ACT
+				err := MultipartForm(b)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("Content-Type")).Equals("multipart/form-data; boundary=boundary")
+				test.That(t, rq.ContentLength).Equals(int64(-1))
+
+				body, _ := io.ReadAll(rq.Body)
+				test.That(t, string(body)).Equals(wantBody)
+
+				replay, err := rq.GetBody()
+				test.Error(t, err).IsNil()
+				body, _ = io.ReadAll(replay)
+				test.That(t, string(body)).Equals(wantBody)
+			},
+		},
+		{scenario: "not replayable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodPost, "http://hostname", nil)
+				b := &multipart.Builder{}
+				b.AddField("owner", "jane").
+					AddFile("document", "report.pdf", "application/pdf", io.MultiReader(strings.NewReader("pdf content")))
+
+				// ACT
+				err := MultipartForm(b)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, rq.GetBody == nil, "body is not replayable")
+
+				body, _ := io.ReadAll(rq.Body)
+				test.That(t, string(body)).Equals(wantBody)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
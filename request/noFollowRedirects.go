@@ -0,0 +1,26 @@
+package request
+
+import "net/http"
+
+// NoFollowRedirects configures the request such that the client performing
+// the request will not follow any redirect; the redirect response is returned,
+// resulting in an error unless the status code of the redirect is acceptable
+// (see: AcceptStatus).  This overrides any MaxRedirects configured on the
+// client.
+//
+// The Location of the redirect may be obtained from the response:
+//
+//	r, err := client.Get(ctx, "login",
+//		request.NoFollowRedirects(),
+//		request.AcceptStatus(http.StatusFound),
+//	)
+//	...
+//	loc := r.Header.Get("Location")
+func NoFollowRedirects() func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.NoFollowRedirects = true
+		})
+		return nil
+	}
+}
@@ -0,0 +1,130 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PatchOp is a single operation in a JSON Patch document (RFC 6902).
+type PatchOp struct {
+	// Op is the operation: "add", "remove", "replace", "move", "copy" or "test"
+	Op string
+
+	// Path is a JSON Pointer (RFC 6901) identifying the target of the operation
+	Path string
+
+	// From is a JSON Pointer identifying the source of a "move" or "copy"
+	// operation
+	From string
+
+	// Value is the value of an "add", "replace" or "test" operation
+	Value any
+}
+
+// MarshalJSON implements json.Marshaler for PatchOp, including only the members
+// relevant to the operation.  In particular a Value is always included for an
+// "add", "replace" or "test" operation, even if nil (marshalled as null).
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	m := map[string]any{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case "add", "replace", "test":
+		m["value"] = op.Value
+	case "move", "copy":
+		m["from"] = op.From
+	}
+	return json.Marshal(m)
+}
+
+// Patch is a JSON Patch document (RFC 6902): an ordered list of operations.
+// Methods are provided to build a Patch fluently:
+//
+//	patch := request.Patch{}.
+//		Replace("/name", "Jane").
+//		Remove("/tags/0").
+//		Add(request.PatchPath("meta", "a/b"), true)
+type Patch []PatchOp
+
+// Add returns the Patch with an "add" operation appended
+func (p Patch) Add(path string, v any) Patch {
+	return append(p, PatchOp{Op: "add", Path: path, Value: v})
+}
+
+// Copy returns the Patch with a "copy" operation appended
+func (p Patch) Copy(from string, path string) Patch {
+	return append(p, PatchOp{Op: "copy", Path: path, From: from})
+}
+
+// Move returns the Patch with a "move" operation appended
+func (p Patch) Move(from string, path string) Patch {
+	return append(p, PatchOp{Op: "move", Path: path, From: from})
+}
+
+// Remove returns the Patch with a "remove" operation appended
+func (p Patch) Remove(path string) Patch {
+	return append(p, PatchOp{Op: "remove", Path: path})
+}
+
+// Replace returns the Patch with a "replace" operation appended
+func (p Patch) Replace(path string, v any) Patch {
+	return append(p, PatchOp{Op: "replace", Path: path, Value: v})
+}
+
+// Test returns the Patch with a "test" operation appended
+func (p Patch) Test(path string, v any) Patch {
+	return append(p, PatchOp{Op: "test", Path: path, Value: v})
+}
+
+// PatchPath returns a JSON Pointer (RFC 6901) formed from a number of
+// reference tokens, escaping any "~" or "/" characters in each token:
+//
+//	request.PatchPath("meta", "a/b") -> "/meta/a~1b"
+func PatchPath(tokens ...string) string {
+	sb := &strings.Builder{}
+	for _, t := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// JSONPatch sets the body of a request to a JSON Patch document (RFC 6902).
+// A Content-Type header is added with the value application/json-patch+json.
+func JSONPatch(ops Patch) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		if ops == nil {
+			ops = Patch{}
+		}
+		b, err := json.Marshal(ops)
+		if err != nil {
+			return fmt.Errorf("JSONPatch: %w: %w", ErrMarshallingJSON, err)
+		}
+
+		setBody(rq, b)
+		rq.Header.Set("Content-Type", "application/json-patch+json")
+
+		return nil
+	}
+}
+
+// MergePatch sets the body of a request to a JSON Merge Patch document
+// (RFC 7396), marshalling a supplied value as JSON.  A Content-Type header is
+// added with the value application/merge-patch+json.
+//
+// In a merge patch, a null value removes the corresponding member of the
+// target; to remove members, use a map (or struct fields without omitempty)
+// holding nil values.
+func MergePatch(v any) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("MergePatch: %w: %w", ErrMarshallingJSON, err)
+		}
+
+		setBody(rq, b)
+		rq.Header.Set("Content-Type", "application/merge-patch+json")
+
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestPatch(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		act      func(*http.Request) error
+		assert   func(*testing.T, *http.Request, error)
+	}{
+		{scenario: "JSONPatch/no operations",
+			act: func(rq *http.Request) error {
+				return JSONPatch(nil)(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				body, _ := io.ReadAll(rq.Body)
+
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("Content-Type"), "content type").Equals("application/json-patch+json")
+				test.Bytes(t, body).Equals([]byte("[]"))
+			},
+		},
+		{scenario: "JSONPatch/operations",
+			act: func(rq *http.Request) error {
+				return JSONPatch(Patch{}.
+					Add(PatchPath("meta", "a/b~c"), nil).
+					Remove("/tags/0").
+					Replace("/name", "Jane").
+					Move("/from", "/to").
+					Copy("/src", "/dest").
+					Test("/version", 2),
+				)(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				body, _ := io.ReadAll(rq.Body)
+
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.ContentLength, "content length").Equals(int64(len(body)))
+				test.That(t, string(body)).Equals(`[` +
+					`{"op":"add","path":"/meta/a~1b~0c","value":null},` +
+					`{"op":"remove","path":"/tags/0"},` +
+					`{"op":"replace","path":"/name","value":"Jane"},` +
+					`{"from":"/from","op":"move","path":"/to"},` +
+					`{"from":"/src","op":"copy","path":"/dest"},` +
+					`{"op":"test","path":"/version","value":2}` +
+					`]`)
+			},
+		},
+		{scenario: "JSONPatch/marshalling error",
+			act: func(rq *http.Request) error {
+				return JSONPatch(Patch{}.Add("/x", unmarshallable{}))(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(ErrMarshallingJSON)
+				test.IsTrue(t, rq.Body == nil, "body is nil")
+			},
+		},
+		{scenario: "MergePatch",
+			act: func(rq *http.Request) error {
+				return MergePatch(map[string]any{"name": "Jane", "tags": nil})(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				body, _ := io.ReadAll(rq.Body)
+
+				test.Error(t, err).IsNil()
+				test.Value(t, rq.Header.Get("Content-Type"), "content type").Equals("application/merge-patch+json")
+				test.That(t, string(body)).Equals(`{"name":"Jane","tags":null}`)
+			},
+		},
+		{scenario: "MergePatch/marshalling error",
+			act: func(rq *http.Request) error {
+				return MergePatch(unmarshallable{})(rq)
+			},
+			assert: func(t *testing.T, rq *http.Request, err error) {
+				test.Error(t, err).Is(ErrMarshallingJSON)
+				test.IsTrue(t, rq.Body == nil, "body is nil")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			rq, _ := http.NewRequest(http.MethodPatch, "http://hostname", nil)
+
+			// ACT
+			err := tc.act(rq)
+
+			// ASSERT
+			tc.assert(t, rq, err)
+		})
+	}
+}
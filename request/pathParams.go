@@ -0,0 +1,65 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pathParam matches a parameter in a templated path, e.g. {id}
+var pathParam = regexp.MustCompile(`{([^{}/]+)}`)
+
+// PathParams substitutes the parameters in a templated request path with the
+// values in a supplied map.  Parameters are identified in the path by name,
+// enclosed in braces:
+//
+//	c.Get(ctx, "users/{id}/orders/{orderID}", request.PathParams(map[string]string{
+//		"id":      "42",
+//		"orderID": "9",
+//	}))
+//
+// Values of any type may be supplied; each value is formatted using fmt.Sprint
+// and path escaped, so that a value containing (for example) a '/' or '?' is
+// substituted as a single path segment:
+//
+//	request.PathParams(map[string]string{"id": "a/b"}) -> users/a%2Fb/orders
+//
+// If the path has a parameter with no corresponding value in the map, an
+// ErrMissingPathParameter error is returned, identifying the first such
+// parameter in the path.  Values for which there is no
+// corresponding parameter are ignored.
+func PathParams[V any](params map[string]V) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		src := rq.URL.Path
+		path := strings.Builder{}
+		raw := strings.Builder{}
+
+		last := 0
+		for _, m := range pathParam.FindAllStringSubmatchIndex(src, -1) {
+			k := src[m[2]:m[3]]
+			pv, ok := params[k]
+			if !ok {
+				return fmt.Errorf("PathParams: %w: %s", ErrMissingPathParameter, k)
+			}
+			v := fmt.Sprint(pv)
+			lit := src[last:m[0]]
+			path.WriteString(lit)
+			path.WriteString(v)
+			raw.WriteString((&url.URL{Path: lit}).EscapedPath())
+			raw.WriteString(url.PathEscape(v))
+			last = m[1]
+		}
+		if last == 0 {
+			return nil
+		}
+		lit := src[last:]
+		path.WriteString(lit)
+		raw.WriteString((&url.URL{Path: lit}).EscapedPath())
+
+		rq.URL.Path = path.String()
+		rq.URL.RawPath = raw.String()
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestPathParams(t *testing.T) {
+	// ARRANGE
+	newRequest := func(url string) *http.Request {
+		rq, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		return rq
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no parameters",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest("https://example.com/users")
+
+				// ACT
+				err := PathParams(map[string]string{"id": "42"})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/users")
+			},
+		},
+		{scenario: "parameters substituted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest("https://example.com/users/{id}/orders/{orderID}?page=2")
+
+				// ACT
+				err := PathParams(map[string]string{"id": "42", "orderID": "9", "unused": "x"})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/users/42/orders/9?page=2")
+			},
+		},
+		{scenario: "values are escaped",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest("https://example.com/files/{name}/content")
+
+				// ACT
+				err := PathParams(map[string]string{"name": "a/b c?.txt"})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.Path).Equals("/files/a/b c?.txt/content")
+				test.That(t, rq.URL.String()).Equals("https://example.com/files/a%2Fb%20c%3F.txt/content")
+			},
+		},
+		{scenario: "values of any type",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest("https://example.com/users/{id}/{active}")
+
+				// ACT
+				err := PathParams(map[string]any{"id": 42, "active": true})(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/users/42/true")
+			},
+		},
+		{scenario: "missing parameter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := newRequest("https://example.com/users/{id}/{version}")
+
+				// ACT
+				err := PathParams(map[string]string{"ID": "42"})(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMissingPathParameter)
+				test.That(t, err.Error()).Equals("PathParams: missing path parameter: id")
+				test.That(t, rq.URL.Path).Equals("/users/{id}/{version}")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package request
+
+import "net/http"
+
+// PollCursor configures a function to propagate a cursor from the response to
+// each poll of a long-polling request (see: http.HttpClient.LongPoll) to the
+// request for the next poll, e.g. from a response header to a query parameter
+// of the next request:
+//
+//	request.PollCursor(func(prev *http.Response, next *http.Request) error {
+//		return request.QueryP("after", prev.Header.Get("X-Cursor"))(next)
+//	})
+//
+// The function is called only for polls returning a response that is not
+// empty, i.e. a response other than 204 No Content or 304 Not Modified, once
+// the response has been delivered.  The body of a response is consumed by the
+// receiver of the response and is not available to the function; the response
+// passed to the function has a copy of the headers of the response and no
+// body.
+func PollCursor(fn func(prev *http.Response, next *http.Request) error) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.PollCursor = fn
+		})
+		return nil
+	}
+}
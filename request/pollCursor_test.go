@@ -0,0 +1,25 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestPollCursor(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+	called := false
+	fn := func(*http.Response, *http.Request) error { called = true; return nil }
+
+	// ACT
+	err := PollCursor(fn)(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.That(t, cfg.PollCursor).IsNotNil()
+	_ = cfg.PollCursor(nil, nil)
+	test.IsTrue(t, called, "cursor function called")
+}
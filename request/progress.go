@@ -0,0 +1,41 @@
+package request
+
+import "net/http"
+
+// ProgressFunc configures a function to be called to report progress in
+// sending the body of a request, so that (for example) a CLI or UI may show
+// an upload progress bar for a large body.
+//
+// The function is called with the number of bytes sent so far and the total
+// number of bytes to be sent.  The total is -1 if the length of the body is
+// not known.
+//
+// The option may be specified before or after the option setting the body of
+// the request; progress is reported by the client performing the request.
+func ProgressFunc(fn func(sent, total int64)) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.Progress = fn
+		})
+		return nil
+	}
+}
+
+// DownloadProgressFunc configures a function to be called to report progress
+// in receiving the body of the response to a request, e.g. to show a progress
+// bar when downloading a large file.
+//
+// The function is called with the number of bytes received so far and the
+// total number of bytes expected.  The total is -1 if the length of the body
+// is not known.  For a 206 Partial Content response, the number of bytes
+// received includes the start of the range received and the total is the
+// complete length of the resource (if known), as identified by the
+// Content-Range header of the response.
+func DownloadProgressFunc(fn func(received, total int64)) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.DownloadProgress = fn
+		})
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestProgressFunc(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodPost, "", nil)
+
+	// ACT
+	err := ProgressFunc(func(int64, int64) {})(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.IsTrue(t, cfg.Progress != nil, "progress func is set")
+}
+
+func TestDownloadProgressFunc(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := DownloadProgressFunc(func(int64, int64) {})(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.IsTrue(t, cfg.DownloadProgress != nil, "download progress func is set")
+}
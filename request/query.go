@@ -1,7 +1,6 @@
 package request
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 )
@@ -43,6 +42,10 @@ func Query(params map[string]any) func(*http.Request) error {
 //
 //	request.QueryP("foo", true) -> ?foo=true
 //	request.QueryP("'a map'", "key=value") -> ?%27a+map%27=key%3Dvalue
+//
+// time.Time values are formatted using DefaultTimeLayout (RFC3339) and
+// time.Duration values as for time.Duration.String(); to format a time using
+// a different layout, use QueryTime.
 func QueryP(k string, v any) func(*http.Request) error {
 	return func(rq *http.Request) error {
 		append := func(s string) {
@@ -59,13 +62,29 @@ func QueryP(k string, v any) func(*http.Request) error {
 		case v == nil:
 			append(k)
 		default:
-			s := k + "=" + url.QueryEscape(fmt.Sprintf("%v", v))
+			s := k + "=" + url.QueryEscape(formatQueryValue(v))
 			append(s)
 		}
 		return nil
 	}
 }
 
+// QuerySlice adds a key-value pair to the query of a request for each of a
+// number of values, for APIs that accept repeated parameters.  Keys and values
+// are url encoded and formatted as for QueryP:
+//
+//	request.QuerySlice("id", 1, 2, 3) -> ?id=1&id=2&id=3
+//
+// If no values are specified the query is not modified.
+func QuerySlice(k string, values ...any) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		for _, v := range values {
+			_ = QueryP(k, v)(rq)
+		}
+		return nil
+	}
+}
+
 // RawQuery sets the query string of a request.  Any existing
 // query string will be overwritten.
 //
@@ -0,0 +1,94 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// QueryStruct adds the exported fields of a struct (or pointer to a struct) to
+// the query of a request, in the order in which the fields are declared.
+//
+// The key for each field is the field name unless a name is specified by a
+// `query` tag.  Fields tagged `query:"-"` are ignored.  If the tag includes the
+// omitempty option, a field with a zero value is not added to the query:
+//
+//	type Search struct {
+//		Term  string    `query:"q"`
+//		Since time.Time `query:"since,omitempty"`
+//		Day   time.Time `query:"day,omitempty" layout:"2006-01-02"`
+//		Tags  []string  `query:"tag,omitempty"`
+//	}
+//
+// Values are formatted as for QueryP; time.Time values are formatted using the
+// layout specified by a `layout` tag, if present, or DefaultTimeLayout (RFC3339).
+// A slice or array field adds a key-value pair for each element.  A nil pointer
+// field is not added to the query; a non-nil pointer adds the value referenced.
+//
+// If the value is not a struct or pointer to a struct, an ErrInvalidQuery error
+// is returned.
+func QueryStruct(v any) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return fmt.Errorf("QueryStruct: %w: %T is not a struct", ErrInvalidQuery, v)
+		}
+
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			f := rt.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(f.Tag.Get("query"), ",")
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = f.Name
+			}
+
+			fv := rv.Field(i)
+			if slices.Contains(strings.Split(opts, ","), "omitempty") && fv.IsZero() {
+				continue
+			}
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Pointer {
+				continue
+			}
+
+			format := func(v reflect.Value) any {
+				if t, ok := v.Interface().(time.Time); ok {
+					if layout := f.Tag.Get("layout"); layout != "" {
+						return t.Format(layout)
+					}
+				}
+				return v.Interface()
+			}
+
+			switch fv.Kind() {
+			case reflect.Slice, reflect.Array:
+				values := make([]any, fv.Len())
+				for j := range values {
+					values[j] = format(fv.Index(j))
+				}
+				_ = QuerySlice(name, values...)(rq)
+			default:
+				_ = QueryP(name, format(fv))(rq)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestQueryStruct(t *testing.T) {
+	// ARRANGE
+	type search struct {
+		Term     string        `query:"q"`
+		Since    time.Time     `query:"since,omitempty"`
+		Day      time.Time     `query:"day,omitempty" layout:"2006-01-02"`
+		Timeout  time.Duration `query:"timeout,omitempty"`
+		Tags     []string      `query:"tag,omitempty"`
+		Limit    *int          `query:"limit"`
+		Ignored  string        `query:"-"`
+		Untagged bool
+		private  string
+	}
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	limit := 10
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "not a struct",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryStruct(42)(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidQuery)
+			},
+		},
+		{scenario: "zero values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryStruct(search{})(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("q=&Untagged=false")
+			},
+		},
+		{scenario: "all values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{RawQuery: "existing"}}
+
+				// ACT
+				err := QueryStruct(&search{
+					Term:     "a b",
+					Since:    tm,
+					Day:      tm,
+					Timeout:  time.Minute,
+					Tags:     []string{"x", "y"},
+					Limit:    &limit,
+					Ignored:  "ignored",
+					Untagged: true,
+					private:  "private",
+				})(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("existing&q=a+b&since=2010-09-08T07%3A06%3A05Z&day=2010-09-08&timeout=1m0s&tag=x&tag=y&limit=10&Untagged=true")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
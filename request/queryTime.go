@@ -0,0 +1,46 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeLayout is the layout used to format time.Time values in a query
+// when no other layout is specified
+const DefaultTimeLayout = time.RFC3339
+
+// QueryTime adds a time value to the query of a request, formatted using a
+// specified layout.  If the layout is empty, DefaultTimeLayout (RFC3339) is used:
+//
+//	request.QueryTime("since", t, "")           -> ?since=2010-09-08T07%3A06%3A05Z
+//	request.QueryTime("date", t, time.DateOnly) -> ?date=2010-09-08
+func QueryTime(k string, t time.Time, layout string) func(*http.Request) error {
+	if layout == "" {
+		layout = DefaultTimeLayout
+	}
+	return QueryP(k, t.Format(layout))
+}
+
+// QueryDuration adds a duration value to the query of a request, formatted
+// as for time.Duration.String():
+//
+//	request.QueryDuration("timeout", 90*time.Second) -> ?timeout=1m30s
+func QueryDuration(k string, d time.Duration) func(*http.Request) error {
+	return QueryP(k, d.String())
+}
+
+// formatQueryValue returns the string representation of a value to be added
+// to a query.  time.Time values are formatted using DefaultTimeLayout and
+// time.Duration values as for time.Duration.String(); any other value is
+// formatted using the %v verb.
+func formatQueryValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(DefaultTimeLayout)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,91 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestQueryTime(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "QueryTime/default layout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryTime("since", tm, "")(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("since=2010-09-08T07%3A06%3A05Z")
+			},
+		},
+		{scenario: "QueryTime/specified layout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryTime("date", tm, time.DateOnly)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("date=2010-09-08")
+			},
+		},
+		{scenario: "QueryDuration",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryDuration("timeout", 90*time.Second)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("timeout=1m30s")
+			},
+		},
+		{scenario: "QueryP/time",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryP("since", tm)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("since=2010-09-08T07%3A06%3A05Z")
+			},
+		},
+		{scenario: "QueryP/duration",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QueryP("timeout", 2*time.Millisecond)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("timeout=2ms")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
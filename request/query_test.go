@@ -166,6 +166,59 @@ func TestQueryP(t *testing.T) {
 	}
 }
 
+func TestQuerySlice(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{RawQuery: "existing"}}
+
+				// ACT
+				err := QuerySlice("id")(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("existing")
+			},
+		},
+		{scenario: "multiple values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{}}
+
+				// ACT
+				err := QuerySlice("id", 1, "a b", nil)(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("id=1&id=a+b&id")
+			},
+		},
+		{scenario: "append/url encoding",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq := &http.Request{URL: &url.URL{RawQuery: "existing"}}
+
+				// ACT
+				err := QuerySlice("k:k", "x=1", "y&2")(rq)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, rq.URL.RawQuery).Equals("existing&k%3Ak=x%3D1&k%3Ak=y%262")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
 func TestRawQuery(t *testing.T) {
 	// ARRANGE
 	rq := &http.Request{URL: &url.URL{RawQuery: "will be over-written"}}
@@ -0,0 +1,18 @@
+package request
+
+import "net/http"
+
+// RequestID specifies the correlation id to be sent with the request by a
+// client configured to send request ids (see: http.RequestID), overriding
+// any id that would otherwise be used.
+//
+// If the client is not configured to send request ids the option has no
+// effect; to send an id in a specific header regardless, use Header().
+func RequestID(id string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.RequestID = id
+		})
+		return nil
+	}
+}
@@ -2,16 +2,23 @@ package request
 
 import "net/http"
 
+// ResponseBodyRequiredHeader identifies a header that may be used to specify
+// that a response body is required.  The header is supported for backwards
+// compatibility only; the ResponseBodyRequired() option configures the
+// request context and does not set this header.
+//
 // canonical casing avoids go-staticcheck flagging the constant with SA1008
 const ResponseBodyRequiredHeader = "X-Blugnu-Http-Response-Body-Required"
 
 // ResponseBodyRequired establishes that a non-empty response body is expected
 // in response to this request.  If the response provides an empty body the
 // client will return an http.ErrNoResponseBody error, together with the
-// response
+// response.  A 304 Not Modified response (which has no body) is exempt.
 func ResponseBodyRequired() func(*http.Request) error {
 	return func(rq *http.Request) error {
-		rq.Header[ResponseBodyRequiredHeader] = []string{"true"}
+		configure(rq, func(cfg *Config) {
+			cfg.ResponseBodyRequired = true
+		})
 		return nil
 	}
 }
@@ -23,7 +23,9 @@ func TestResponseBody(t *testing.T) {
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[ResponseBodyRequiredHeader][0]).Equals("true")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.IsTrue(t, cfg.ResponseBodyRequired, "response body required")
+				test.That(t, rq.Header[ResponseBodyRequiredHeader]).IsNil()
 			},
 		},
 		{scenario: "existing header/false",
@@ -37,7 +39,9 @@ func TestResponseBody(t *testing.T) {
 
 				// ASSERT
 				test.Error(t, err).IsNil()
-				test.That(t, rq.Header[ResponseBodyRequiredHeader][0]).Equals("true")
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.IsTrue(t, cfg.ResponseBodyRequired, "response body required")
+				test.Strings(t, rq.Header[ResponseBodyRequiredHeader]).Equals([]string{"false"})
 			},
 		},
 	}
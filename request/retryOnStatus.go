@@ -0,0 +1,20 @@
+package request
+
+import (
+	"net/http"
+)
+
+// RetryOnStatus configures the status codes for which a response to the
+// request is retried (up to the maximum retries for the request), overriding
+// any status codes configured on the client used to make the request.
+//
+// If no status codes are specified, responses to the request are not retried,
+// regardless of status.
+func RetryOnStatus(statusCodes ...int) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.RetryOnStatus = append([]int{}, statusCodes...)
+		})
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestRetryOnStatus(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "status codes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := RetryOnStatus(http.StatusServiceUnavailable, http.StatusBadGateway)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.Slice(t, cfg.RetryOnStatus).Equals([]int{http.StatusServiceUnavailable, http.StatusBadGateway})
+			},
+		},
+		{scenario: "no status codes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := RetryOnStatus()(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.IsTrue(t, cfg.RetryOnStatus != nil, "retry status configured")
+				test.That(t, len(cfg.RetryOnStatus)).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -2,16 +2,26 @@ package request
 
 import "net/http"
 
+// StreamResponseHeader identifies a header that may be used to specify that
+// a response is to be streamed.  The header is supported for backwards
+// compatibility only; the StreamResponse() option configures the request
+// context and does not set this header.
+//
 // canonical casing avoids go-staticcheck flagging the constant with SA1008
 const StreamResponseHeader = "X-Blugnu-Http-Stream-Response"
 
-// StreamResponse adds a request header indicating that the client expects
-// to stream the response body.  The header is removed 
+// StreamResponse configures the request such that the client will not read
+// the response body before returning the response to the caller; the caller
+// is responsible for reading and closing the response body.
 //
-// If specified, the usual reading of the response body prior to returning
-// the response to the caller is skipped.
-func StreamResponse() func(*http.Request) {
-	return func(r *http.Request) {
-		r.Header[StreamResponseHeader] = []string{"true"}
+// If the request context is cancelled (or its deadline exceeded) while the
+// body is being read, the body is closed and any read fails with the error
+// of the context.
+func StreamResponse() func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.StreamResponse = true
+		})
+		return nil
 	}
 }
@@ -19,10 +19,13 @@ func TestStreamResponse(t *testing.T) {
 				rq, _ := http.NewRequest(http.MethodGet, "", nil)
 
 				// ACT
-				StreamResponse()(rq)
+				err := StreamResponse()(rq)
 
 				// ASSERT
-				test.That(t, rq.Header[StreamResponseHeader][0]).Equals("true")
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.IsTrue(t, cfg.StreamResponse, "stream response")
+				test.That(t, rq.Header[StreamResponseHeader]).IsNil()
 			},
 		},
 		{scenario: "existing header/false",
@@ -32,10 +35,13 @@ func TestStreamResponse(t *testing.T) {
 				rq.Header[StreamResponseHeader] = []string{"false"}
 
 				// ACT
-				StreamResponse()(rq)
+				err := StreamResponse()(rq)
 
 				// ASSERT
-				test.That(t, rq.Header[StreamResponseHeader][0]).Equals("true")
+				test.Error(t, err).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.IsTrue(t, cfg.StreamResponse, "stream response")
+				test.Strings(t, rq.Header[StreamResponseHeader]).Equals([]string{"false"})
 			},
 		},
 	}
@@ -0,0 +1,55 @@
+package request
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TLSServerName configures the server name to be sent in the TLS handshake
+// (SNI) and used to verify the server certificate, overriding the host of the
+// request url.  This enables (e.g.) a host to be called using an IP address.
+//
+// TLS options are applied by a client wrapping an *http.Client; if the
+// Transport of the *http.Client is not an *http.Transport the request fails
+// with http.ErrTLSOptionsNotSupported.  Any other wrapped client (e.g. a mock)
+// is responsible for applying the options (see: ConfigFromContext).
+func TLSServerName(name string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		configure(rq, func(cfg *Config) {
+			cfg.TLSServerName = name
+		})
+		return nil
+	}
+}
+
+// PinCertificate pins the certificate presented by the server for a request,
+// identified by the SHA-256 fingerprint of the DER encoded certificate as a
+// hex string (colon separators are permitted and case is ignored).  Any
+// certificate in the chain presented by the server may be pinned, e.g. the
+// leaf certificate or the certificate of an issuing CA.
+//
+// The option may be applied more than once (e.g. to allow for rotation of a
+// certificate); the request fails if the server does not present any of the
+// pinned certificates.  Pinning is in addition to, not instead of, normal
+// verification of the server certificate.
+//
+// TLS options are applied by a client wrapping an *http.Client; if the
+// Transport of the *http.Client is not an *http.Transport the request fails
+// with http.ErrTLSOptionsNotSupported.  Any other wrapped client (e.g. a mock)
+// is responsible for applying the options (see: ConfigFromContext).
+func PinCertificate(fingerprint string) func(*http.Request) error {
+	return func(rq *http.Request) error {
+		b, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("PinCertificate: %w: %q", ErrInvalidCertificateFingerprint, fingerprint)
+		}
+
+		configure(rq, func(cfg *Config) {
+			cfg.PinnedCertificates = append(cfg.PinnedCertificates, [sha256.Size]byte(b))
+		})
+		return nil
+	}
+}
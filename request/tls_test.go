@@ -0,0 +1,91 @@
+package request
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestTLSServerName(t *testing.T) {
+	// ARRANGE
+	rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+	// ACT
+	err := TLSServerName("example.com")(rq)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	cfg, _ := ConfigFromContext(rq.Context())
+	test.That(t, cfg.TLSServerName).Equals("example.com")
+}
+
+func TestPinCertificate(t *testing.T) {
+	// ARRANGE
+	fp1 := sha256.Sum256([]byte("certificate 1"))
+	fp2 := sha256.Sum256([]byte("certificate 2"))
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "hex fingerprints",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err1 := PinCertificate(strings.ToUpper(hexString(fp1[:], ":")))(rq)
+				err2 := PinCertificate(hexString(fp2[:], ""))(rq)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				cfg, _ := ConfigFromContext(rq.Context())
+				test.That(t, cfg.PinnedCertificates).Equals([][sha256.Size]byte{fp1, fp2})
+			},
+		},
+		{scenario: "invalid fingerprint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := PinCertificate("not a fingerprint")(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidCertificateFingerprint)
+			},
+		},
+		{scenario: "fingerprint of wrong length",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := PinCertificate(hexString(fp1[:16], ""))(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidCertificateFingerprint)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+// hexString returns the hex encoding of a []byte, with bytes separated by a
+// specified separator
+func hexString(b []byte, sep string) string {
+	s := make([]string, len(b))
+	for i, c := range b {
+		s[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(s, sep)
+}
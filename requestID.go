@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/blugnu/http/request"
+)
+
+// DefaultRequestIDHeader is the header in which a request id is sent if no
+// header is specified when configuring a client using the RequestID option
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// requestID holds the configuration of request ids for a client
+type requestID struct {
+	header   string
+	generate func() string
+}
+
+// requestIDKey is the key under which a request id is held in a context
+type requestIDKey struct{}
+
+// RequestID configures a client to send a correlation id with every request,
+// in a specified header (if empty, DefaultRequestIDHeader), so that failures
+// may be traced across services.  The id is included in any ClientError
+// returned for the request.
+//
+// The id of a request is, in order of precedence:
+//
+//   - any id specified for the request using the request.RequestID option;
+//   - any value of the header already set on the request;
+//   - any id carried by the request context (see: WithRequestID), e.g. the
+//     id of an inbound request being handled;
+//   - a new id, obtained using the specified generator function (if nil, a
+//     random UUID is generated).
+//
+// The same id is sent with every attempt to perform a request.
+func RequestID(generator func() string, header string) ClientOption {
+	return func(c *client) error {
+		if header == "" {
+			header = DefaultRequestIDHeader
+		}
+		if generator == nil {
+			generator = newRequestID
+		}
+		c.requestID = &requestID{header: header, generate: generator}
+		return nil
+	}
+}
+
+// WithRequestID returns a context carrying a specified request id, to be sent
+// with any request made using the context by a client configured with the
+// RequestID option.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns any request id carried by a context (see:
+// WithRequestID).  If the context carries no request id, an empty string is
+// returned.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// stamp establishes the id of a request, setting the request id header of
+// the request and returning the id
+func (cfg *requestID) stamp(rq *http.Request) string {
+	ctx := rq.Context()
+
+	id := ""
+	if rc, ok := request.ConfigFromContext(ctx); ok {
+		id = rc.RequestID
+	}
+	if id == "" {
+		id = rq.Header.Get(cfg.header)
+	}
+	if id == "" {
+		id = RequestIDFromContext(ctx)
+	}
+	if id == "" {
+		id = cfg.generate()
+	}
+
+	if rq.Header == nil {
+		rq.Header = http.Header{}
+	}
+	rq.Header.Set(cfg.header, id)
+	return id
+}
+
+// newRequestID returns a random (version 4) UUID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand.Read does not fail on supported platforms
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// requestIDOf returns a description of the id of a request, for inclusion in
+// mock expectation reports.  If the request is nil or has no id, an empty
+// string is returned.
+func requestIDOf(rq *http.Request) string {
+	if rq == nil {
+		return ""
+	}
+	if id := RequestIDFromContext(rq.Context()); id != "" {
+		return fmt.Sprintf(" (request id: %s)", id)
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRequestID(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server returns a client configured with a specified RequestID option,
+	// responding with a specified status and recording the request id
+	// header of each request
+	server := func(opt ClientOption, status int, sent *[]string) HttpClient {
+		c, _ := NewClient("name", URL("https://example.com"), opt,
+			Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+				*sent = append(*sent, rq.Header.Get("X-Correlation-Id")+rq.Header.Get(DefaultRequestIDHeader))
+				return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+			})),
+		)
+		return c
+	}
+	fixed := func() string { return "generated" }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option defaults",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := RequestID(nil, "")(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.requestID.header).Equals(DefaultRequestIDHeader)
+				test.That(t, c.requestID.generate).IsNotNil()
+			},
+		},
+		{scenario: "generated id",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(nil, ""), http.StatusOK, &sent)
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(sent)).Equals(1)
+				test.IsTrue(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(sent[0]), "is uuid")
+			},
+		},
+		{scenario: "custom generator and header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(fixed, "X-Correlation-Id"), http.StatusOK, &sent)
+
+				// ACT
+				_, _ = c.Get(ctx, "")
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"generated"})
+			},
+		},
+		{scenario: "id from context",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(fixed, ""), http.StatusOK, &sent)
+
+				// ACT
+				_, _ = c.Get(WithRequestID(ctx, "inbound"), "")
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"inbound"})
+			},
+		},
+		{scenario: "id from header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(fixed, ""), http.StatusOK, &sent)
+
+				// ACT
+				_, _ = c.Get(WithRequestID(ctx, "inbound"), "", request.Header(DefaultRequestIDHeader, "header"))
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"header"})
+			},
+		},
+		{scenario: "id from request option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(fixed, ""), http.StatusOK, &sent)
+
+				// ACT
+				_, _ = c.Get(WithRequestID(ctx, "inbound"), "",
+					request.Header(DefaultRequestIDHeader, "header"),
+					request.RequestID("option"),
+				)
+
+				// ASSERT
+				test.Strings(t, sent).Equals([]string{"option"})
+			},
+		},
+		{scenario: "id included in error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := server(RequestID(fixed, ""), http.StatusNotFound, &sent)
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				var clientErr ClientError
+				test.IsTrue(t, errors.As(err, &clientErr), "is a ClientError")
+				test.That(t, clientErr.RequestID).Equals("generated")
+			},
+		},
+		{scenario: "RequestIDFromContext/no id",
+			exec: func(t *testing.T) {
+				// ACT
+				result := RequestIDFromContext(ctx)
+
+				// ASSERT
+				test.That(t, result).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo holds the rate limit information reported by the headers of a
+// response, using either the RateLimit-* headers (IETF draft) or the
+// conventional X-RateLimit-* headers.
+type RateLimitInfo struct {
+	// Limit is the maximum number of requests permitted in the current
+	// window, or -1 if not reported
+	Limit int
+
+	// Remaining is the number of requests remaining in the current window,
+	// or -1 if not reported
+	Remaining int
+
+	// Reset is the time at which the current window resets; the zero time
+	// if not reported
+	Reset time.Time
+}
+
+// ResponseMeta holds metadata of a response, such as pagination and rate limit
+// headers, which is otherwise lost when a response is decoded into a typed
+// value (see: GetJSONWithMeta).
+type ResponseMeta struct {
+	// StatusCode and Status are the status code and status of the response
+	StatusCode int
+	Status     string
+
+	// Header holds all headers of the response
+	Header http.Header
+
+	// TotalCount is the value of any X-Total-Count header, or -1 if the
+	// response has no (valid) X-Total-Count header
+	TotalCount int
+
+	// Links holds the links of any Link header of the response, keyed by
+	// relation (e.g. Links.Next() for a paginated response)
+	Links Links
+
+	// RateLimit holds any rate limit information reported by the response
+	RateLimit RateLimitInfo
+
+	// ETag is the value of any ETag header of the response, e.g. to be used
+	// with request.IfNoneMatch or request.IfMatch
+	ETag string
+
+	// LastModified is the time of any (valid) Last-Modified header of the
+	// response, e.g. to be used with request.IfModifiedSince; the zero time
+	// if the response has no (valid) Last-Modified header
+	LastModified time.Time
+}
+
+// MetaOf returns the metadata of a response.  If the response is nil, the
+// zero value is returned (with TotalCount, RateLimit.Limit and
+// RateLimit.Remaining of -1).
+func MetaOf(r *http.Response) ResponseMeta {
+	meta := ResponseMeta{
+		TotalCount: -1,
+		RateLimit:  RateLimitInfo{Limit: -1, Remaining: -1},
+	}
+	if r == nil {
+		return meta
+	}
+
+	meta.StatusCode = r.StatusCode
+	meta.Status = r.Status
+	meta.Header = r.Header
+	if n, err := strconv.Atoi(r.Header.Get("X-Total-Count")); err == nil {
+		meta.TotalCount = n
+	}
+	meta.Links = ParseLinkHeader(r.Header.Values("Link")...)
+	meta.RateLimit = parseRateLimitInfo(r.Header)
+	meta.ETag = r.Header.Get("ETag")
+	if t, err := http.ParseTime(r.Header.Get("Last-Modified")); err == nil {
+		meta.LastModified = t
+	}
+
+	return meta
+}
+
+// GetJSONWithMeta is a generic function that performs a GET request using a
+// client as for GetJSON, returning the metadata of the response in addition to
+// the decoded value, so that typed callers retain access to pagination, rate
+// limit and other headers.
+//
+// If the request fails with a response (e.g. an unexpected status) the
+// metadata of that response is returned together with the error.
+func GetJSONWithMeta[T any](
+	ctx context.Context,
+	c HttpClient,
+	path string,
+	opts ...RequestOption,
+) (T, ResponseMeta, error) {
+	v, r, err := getJSON[T](ctx, c, path, opts)
+	return v, MetaOf(r), err
+}
+
+// parseRateLimitInfo parses the rate limit headers of a response.  The
+// RateLimit-* headers (IETF draft) take precedence over X-RateLimit-*
+// headers.  A reset value is interpreted as a number of seconds from now,
+// unless (as for some X-RateLimit-Reset headers) it is large enough to be a
+// unix time.
+func parseRateLimitInfo(h http.Header) RateLimitInfo {
+	info := RateLimitInfo{Limit: -1, Remaining: -1}
+
+	value := func(name string) (int64, bool) {
+		for _, k := range []string{"RateLimit-" + name, "X-RateLimit-" + name} {
+			if n, err := strconv.ParseInt(strings.TrimSpace(h.Get(k)), 10, 64); err == nil && n >= 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+
+	if n, ok := value("Limit"); ok {
+		info.Limit = int(n)
+	}
+	if n, ok := value("Remaining"); ok {
+		info.Remaining = int(n)
+	}
+	if n, ok := value("Reset"); ok {
+		const unixTimeThreshold = 1_000_000_000 // ~2001-09-09, ~31 years of seconds
+		if n >= unixTimeThreshold {
+			info.Reset = time.Unix(n, 0)
+		} else {
+			info.Reset = timeNow().Add(time.Duration(n) * time.Second)
+		}
+	}
+	return info
+}
+
+// ParseLinkHeader parses the values of any Link headers (RFC 8288), returning
+// the links keyed by relation.  A link with more than one relation is included
+// for each relation; the title and type parameters of a link are captured.
+// Invalid links are ignored.
+func ParseLinkHeader(values ...string) Links {
+	links := Links{}
+	for _, s := range values {
+		for s != "" {
+			s = strings.TrimLeft(s, " \t,")
+			if !strings.HasPrefix(s, "<") {
+				break
+			}
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				break
+			}
+			link := Link{Href: s[1:end]}
+			s = s[end+1:]
+
+			var rels []string
+			for {
+				s = strings.TrimLeft(s, " \t")
+				if !strings.HasPrefix(s, ";") {
+					break
+				}
+				var k, v string
+				k, v, s = parseLinkParam(s[1:])
+				switch strings.ToLower(k) {
+				case "rel":
+					rels = strings.Fields(v)
+				case "title":
+					link.Title = v
+				case "type":
+					link.Type = v
+				}
+			}
+			for _, rel := range rels {
+				rel = strings.ToLower(rel)
+				links[rel] = append(links[rel], link)
+			}
+		}
+	}
+	return links
+}
+
+// parseLinkParam parses a link parameter (key=value or key="value") from the
+// start of a string, returning the key, value and the remainder of the string
+func parseLinkParam(s string) (key, value, rest string) {
+	s = strings.TrimLeft(s, " \t")
+	end := strings.IndexAny(s, "=;,")
+	if end < 0 {
+		return strings.TrimSpace(s), "", ""
+	}
+	key = strings.TrimSpace(s[:end])
+	if s[end] != '=' {
+		return key, "", s[end:]
+	}
+
+	s = strings.TrimLeft(s[end+1:], " \t")
+	if strings.HasPrefix(s, `"`) {
+		b := strings.Builder{}
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+					b.WriteByte(s[i])
+				}
+			case '"':
+				return key, b.String(), s[i+1:]
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return key, b.String(), ""
+	}
+
+	end = strings.IndexAny(s, ";,")
+	if end < 0 {
+		return key, strings.TrimSpace(s), ""
+	}
+	return key, strings.TrimSpace(s[:end]), s[end:]
+}
@@ -0,0 +1,279 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestMetaOf(t *testing.T) {
+	// ARRANGE
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	og := timeNow
+	defer func() { timeNow = og }()
+	timeNow = func() time.Time { return now }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "nil response",
+			exec: func(t *testing.T) {
+				// ACT
+				result := MetaOf(nil)
+
+				// ASSERT
+				test.That(t, result.StatusCode).Equals(0)
+				test.That(t, result.TotalCount).Equals(-1)
+				test.That(t, result.RateLimit).Equals(RateLimitInfo{Limit: -1, Remaining: -1})
+			},
+		},
+		{scenario: "no metadata headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}}
+
+				// ACT
+				result := MetaOf(r)
+
+				// ASSERT
+				test.That(t, result.StatusCode).Equals(http.StatusOK)
+				test.That(t, result.Status).Equals("200 OK")
+				test.That(t, result.TotalCount).Equals(-1)
+				test.That(t, len(result.Links)).Equals(0)
+				test.That(t, result.RateLimit).Equals(RateLimitInfo{Limit: -1, Remaining: -1})
+			},
+		},
+		{scenario: "pagination and rate limit headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				h := http.Header{}
+				h.Set("X-Total-Count", "42")
+				h.Set("Link", `<https://example.com/items?page=2>; rel="next", <https://example.com/items?page=5>; rel=last`)
+				h.Set("RateLimit-Limit", "100")
+				h.Set("RateLimit-Remaining", "99")
+				h.Set("RateLimit-Reset", "30")
+				r := &http.Response{StatusCode: http.StatusOK, Header: h}
+
+				// ACT
+				result := MetaOf(r)
+
+				// ASSERT
+				test.That(t, result.TotalCount).Equals(42)
+				next, _ := result.Links.Next()
+				test.That(t, next).Equals("https://example.com/items?page=2")
+				last, _ := result.Links.Last()
+				test.That(t, last).Equals("https://example.com/items?page=5")
+				test.That(t, result.RateLimit).Equals(RateLimitInfo{Limit: 100, Remaining: 99, Reset: now.Add(30 * time.Second)})
+			},
+		},
+		{scenario: "X-RateLimit headers with unix reset",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				h := http.Header{}
+				h.Set("X-RateLimit-Limit", "60")
+				h.Set("X-RateLimit-Remaining", "0")
+				h.Set("X-RateLimit-Reset", "1577836860")
+				r := &http.Response{StatusCode: http.StatusOK, Header: h}
+
+				// ACT
+				result := MetaOf(r)
+
+				// ASSERT
+				test.That(t, result.RateLimit.Limit).Equals(60)
+				test.That(t, result.RateLimit.Remaining).Equals(0)
+				test.IsTrue(t, result.RateLimit.Reset.Equal(now.Add(time.Minute)), "reset time")
+			},
+		},
+		{scenario: "validators",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				h := http.Header{}
+				h.Set("ETag", `"v1"`)
+				h.Set("Last-Modified", "Wed, 01 Jan 2020 00:00:00 GMT")
+				r := &http.Response{StatusCode: http.StatusOK, Header: h}
+
+				// ACT
+				result := MetaOf(r)
+
+				// ASSERT
+				test.That(t, result.ETag).Equals(`"v1"`)
+				test.IsTrue(t, result.LastModified.Equal(now), "last modified")
+			},
+		},
+		{scenario: "invalid last modified",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				h := http.Header{}
+				h.Set("Last-Modified", "yesterday")
+				r := &http.Response{StatusCode: http.StatusOK, Header: h}
+
+				// ACT
+				result := MetaOf(r)
+
+				// ASSERT
+				test.IsTrue(t, result.LastModified.IsZero(), "last modified")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		values   []string
+		result   Links
+	}{
+		{scenario: "no values", result: Links{}},
+		{scenario: "single link",
+			values: []string{`<https://example.com/2>; rel="next"`},
+			result: Links{"next": {{Href: "https://example.com/2"}}},
+		},
+		{scenario: "multiple relations, title and type",
+			values: []string{`<https://example.com/1>; rel="self canonical"; title="the \"one\""; type=application/json`},
+			result: Links{
+				"self":      {{Href: "https://example.com/1", Title: `the "one"`, Type: "application/json"}},
+				"canonical": {{Href: "https://example.com/1", Title: `the "one"`, Type: "application/json"}},
+			},
+		},
+		{scenario: "multiple links and headers",
+			values: []string{
+				`<https://example.com/1>; rel=prev, <https://example.com/3>; rel=next`,
+				`<https://example.com/9>; rel=Last`,
+			},
+			result: Links{
+				"prev": {{Href: "https://example.com/1"}},
+				"next": {{Href: "https://example.com/3"}},
+				"last": {{Href: "https://example.com/9"}},
+			},
+		},
+		{scenario: "parameter without value",
+			values: []string{`<https://example.com/1>; crossorigin; rel=next`},
+			result: Links{"next": {{Href: "https://example.com/1"}}},
+		},
+		{scenario: "no relation",
+			values: []string{`<https://example.com/1>; title="untitled"`},
+			result: Links{},
+		},
+		{scenario: "invalid",
+			values: []string{`https://example.com/1; rel=next`, `<https://example.com/1; rel=next`},
+			result: Links{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			result := ParseLinkHeader(tc.values...)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
+
+func TestGetJSONWithMeta(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	server := func(status int, body string) HttpClient {
+		c, _ := NewClient("name", URL("https://example.com"),
+			Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+				h := http.Header{}
+				h.Set("X-Total-Count", "2")
+				return &http.Response{StatusCode: status, Header: h, Body: io.NopCloser(strings.NewReader(body))}, nil
+			})),
+		)
+		return c
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ok",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(http.StatusOK, `[1,2]`)
+
+				// ACT
+				result, meta, err := GetJSONWithMeta[[]int](ctx, c, "items")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result).Equals([]int{1, 2})
+				test.That(t, meta.StatusCode).Equals(http.StatusOK)
+				test.That(t, meta.TotalCount).Equals(2)
+			},
+		},
+		{scenario: "unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(http.StatusNotFound, `not found`)
+
+				// ACT
+				result, meta, err := GetJSONWithMeta[[]int](ctx, c, "items")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, result).IsNil()
+				test.That(t, meta.StatusCode).Equals(http.StatusNotFound)
+			},
+		},
+		{scenario: "not modified",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(http.StatusNotModified, ``)
+
+				// ACT
+				result, meta, err := GetJSONWithMeta[[]int](ctx, c, "items", request.IfNoneMatch(`"v1"`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).IsNil()
+				test.That(t, meta.StatusCode).Equals(http.StatusNotModified)
+			},
+		},
+		{scenario: "not modified/not accepted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(http.StatusNotModified, ``)
+
+				// ACT
+				_, meta, err := GetJSONWithMeta[[]int](ctx, c, "items")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, meta.StatusCode).Equals(http.StatusNotModified)
+			},
+		},
+		{scenario: "invalid json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := server(http.StatusOK, `not json`)
+
+				// ACT
+				_, meta, err := GetJSONWithMeta[[]int](ctx, c, "items")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+				test.That(t, meta.TotalCount).Equals(2)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// ResultOf returns a Result holding the response and error returned by a
+// client, enabling the response body to be consumed in a single call:
+//
+//	var customer Customer
+//	if err := http.ResultOf(client.Get(ctx, "customers/42")).JSON(&customer); err != nil {
+//		return err
+//	}
+func ResultOf(r *http.Response, err error) Result {
+	return Result{Response: r, Err: err}
+}
+
+// requestContext returns the context of the request for which the response was
+// received, if known
+func (res Result) requestContext() context.Context {
+	if res.Response != nil && res.Response.Request != nil {
+		return res.Response.Request.Context()
+	}
+	return context.Background()
+}
+
+// Bytes reads and returns the body of the response.  If the Result holds an
+// error, the error is returned.  The response body is always closed.
+func (res Result) Bytes() ([]byte, error) {
+	if res.Response == nil {
+		return nil, res.Err
+	}
+
+	ctx := res.requestContext()
+	defer func() { _ = closeBody(ctx, res.Response.Body) }()
+
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	b, err := ioReadAll(res.Response.Body)
+	if err != nil {
+		return nil, errorcontext.Errorf(ctx, "http.Result: %w: %w", ErrReadingResponseBody, err)
+	}
+	return b, nil
+}
+
+// Discard discards the body of the response, returning any error held by the
+// Result.  The response body is always closed.
+func (res Result) Discard() error {
+	_, err := res.Bytes()
+	return err
+}
+
+// JSON decodes the JSON body of the response into a value (which must be a
+// pointer).  If the Result holds an error, the error is returned without
+// decoding the body.  The response body is always closed.
+func (res Result) JSON(v any) error {
+	b, err := res.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errorcontext.Errorf(res.requestContext(), "http.Result: %w: %w", ErrInvalidJSON, err)
+	}
+	return nil
+}
+
+// StatusIs returns true if the Result holds a response with any of a
+// specified status codes, regardless of any error held by the Result.
+func (res Result) StatusIs(codes ...int) bool {
+	return res.Response != nil && slices.Contains(codes, res.Response.StatusCode)
+}
+
+// Text reads and returns the body of the response as a string.  If the Result
+// holds an error, the error is returned.  The response body is always closed.
+func (res Result) Text() (string, error) {
+	b, err := res.Bytes()
+	return string(b), err
+}
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// closer is a response body calling a function when closed
+type closer struct {
+	io.Reader
+	close func()
+}
+
+func (c closer) Close() error {
+	c.close()
+	return nil
+}
+
+func TestResult(t *testing.T) {
+	// ARRANGE
+	og := ioReadAll
+	defer func() { ioReadAll = og }()
+
+	response := func(status int, s string) (*http.Response, *bool) {
+		closed := false
+		rq, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com", nil)
+		return &http.Response{
+			StatusCode: status,
+			Request:    rq,
+			Body:       closer{Reader: strings.NewReader(s), close: func() { closed = true }},
+		}, &closed
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ResultOf",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := &http.Response{}
+				err := errors.New("error")
+
+				// ACT
+				result := ResultOf(r, err)
+
+				// ASSERT
+				test.That(t, result).Equals(Result{Response: r, Err: err})
+			},
+		},
+		{scenario: "Bytes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, closed := response(http.StatusOK, "body")
+
+				// ACT
+				result, err := ResultOf(r, nil).Bytes()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(result)).Equals("body")
+				test.IsTrue(t, *closed, "body closed")
+			},
+		},
+		{scenario: "Bytes/with error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, closed := response(http.StatusNotFound, "body")
+
+				// ACT
+				result, err := ResultOf(r, ErrUnexpectedStatusCode).Bytes()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, result).IsNil()
+				test.IsTrue(t, *closed, "body closed")
+			},
+		},
+		{scenario: "Bytes/no response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				clienterr := errors.New("client error")
+
+				// ACT
+				result, err := ResultOf(nil, clienterr).Bytes()
+
+				// ASSERT
+				test.Error(t, err).Is(clienterr)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "Bytes/read error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer func() { ioReadAll = og }()
+				readerr := errors.New("read error")
+				ioReadAll = func(io.Reader) ([]byte, error) { return nil, readerr }
+				r, _ := response(http.StatusOK, "body")
+
+				// ACT
+				_, err := ResultOf(r, nil).Bytes()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrReadingResponseBody)
+				test.Error(t, err).Is(readerr)
+			},
+		},
+		{scenario: "Discard",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, closed := response(http.StatusOK, "body")
+
+				// ACT
+				err := ResultOf(r, nil).Discard()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, *closed, "body closed")
+			},
+		},
+		{scenario: "JSON",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, _ := response(http.StatusOK, `{"id":42}`)
+				result := struct {
+					ID int `json:"id"`
+				}{}
+
+				// ACT
+				err := ResultOf(r, nil).JSON(&result)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.ID).Equals(42)
+			},
+		},
+		{scenario: "JSON/invalid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, _ := response(http.StatusOK, `not json`)
+				result := map[string]any{}
+
+				// ACT
+				err := ResultOf(r, nil).JSON(&result)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidJSON)
+			},
+		},
+		{scenario: "JSON/with error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, _ := response(http.StatusNotFound, `{"id":42}`)
+				result := map[string]any{}
+
+				// ACT
+				err := ResultOf(r, ErrUnexpectedStatusCode).JSON(&result)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, len(result)).Equals(0)
+			},
+		},
+		{scenario: "StatusIs",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, _ := response(http.StatusNotFound, "")
+
+				// ACT
+				result := ResultOf(r, ErrUnexpectedStatusCode)
+
+				// ASSERT
+				test.IsTrue(t, result.StatusIs(http.StatusNotFound, http.StatusGone), "404 or 410")
+				test.IsFalse(t, result.StatusIs(http.StatusOK), "200")
+				test.IsFalse(t, ResultOf(nil, nil).StatusIs(http.StatusOK), "no response")
+			},
+		},
+		{scenario: "Text",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r, _ := response(http.StatusOK, "body")
+
+				// ACT
+				result, err := ResultOf(r, nil).Text()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals("body")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
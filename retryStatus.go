@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultRetryStatus identifies the status codes for which a response is
+// retried by a client if no other status codes are configured using the
+// RetryOnStatus option.  These are the status codes for which IsRetryable
+// returns true, other than 408 Request Timeout and 425 Too Early (see:
+// IsRetryable).
+var DefaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryOnStatus configures the status codes for which a response is retried by
+// the client, replacing the DefaultRetryStatus.  The status codes for an
+// individual request may be overridden using the request.RetryOnStatus option.
+//
+// A response is retried only if retries are configured for the request (see:
+// MaxRetries) and the status code is not acceptable to the request (see:
+// request.AcceptStatus).  If the response has a Retry-After header, the retry
+// is delayed until the time indicated, otherwise the delay is determined by the
+// backoff policy for the request.
+//
+// If no status codes are specified, responses are not retried regardless of
+// status.
+//
+// If the client is configured to HandleTooManyRequests, 429 Too Many Requests
+// responses are handled as rate limited responses and are not retried by this
+// option.
+func RetryOnStatus(codes ...int) ClientOption {
+	return func(c *client) error {
+		c.retryStatus = append([]int{}, codes...)
+		return nil
+	}
+}
+
+// retryDelay returns the delay before a retry of a request that received a
+// specified response.  If the response has a Retry-After header indicating a
+// time in the future, the delay is the time until then, otherwise the
+// specified backoff delay is returned.
+func retryDelay(r *http.Response, backoff time.Duration) time.Duration {
+	reset := retryAfter(r.Header.Get("Retry-After"))
+	if reset.IsZero() {
+		return backoff
+	}
+	if d := reset.Sub(timeNow()); d > 0 {
+		return d
+	}
+	return 0
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRetryOnStatus(t *testing.T) {
+	// ARRANGE
+	c := client{}
+
+	// ACT
+	err := RetryOnStatus(http.StatusInternalServerError)(&c)
+
+	// ASSERT
+	test.Error(t, err).IsNil()
+	test.Slice(t, c.retryStatus).Equals([]int{http.StatusInternalServerError})
+}
+
+func TestDefaultRetryStatus(t *testing.T) {
+	// ASSERT
+	test.Slice(t, DefaultRetryStatus).Equals([]int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	})
+	for _, code := range DefaultRetryStatus {
+		test.IsTrue(t, IsRetryable(code), fmt.Sprintf("IsRetryable(%d)", code))
+	}
+	for _, code := range []int{http.StatusRequestTimeout, http.StatusTooEarly} {
+		test.IsTrue(t, IsRetryable(code), fmt.Sprintf("IsRetryable(%d)", code))
+		test.IsFalse(t, slices.Contains(DefaultRetryStatus, code), fmt.Sprintf("%d is not retried by default", code))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// ARRANGE
+	og := timeNow
+	defer func() { timeNow = og }()
+	now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	testcases := []struct {
+		scenario string
+		header   string
+		result   time.Duration
+	}{
+		{scenario: "no Retry-After", result: time.Second},
+		{scenario: "invalid Retry-After", header: "soon", result: time.Second},
+		{scenario: "Retry-After seconds", header: "20", result: 20 * time.Second},
+		{scenario: "Retry-After date", header: now.Add(time.Minute).Format(http.TimeFormat), result: time.Minute},
+		{scenario: "Retry-After in the past", header: now.Add(-time.Minute).Format(http.TimeFormat), result: 0},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			r := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				r.Header.Set("Retry-After", tc.header)
+			}
+
+			// ACT
+			result := retryDelay(r, time.Second)
+
+			// ASSERT
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
+
+func TestClientRetriesStatus(t *testing.T) {
+	// ARRANGE
+	og := timeAfter
+	defer func() { timeAfter = og }()
+	timeAfter = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	// responding returns a Doer responding with each of the specified status
+	// codes in turn, recording the number of requests in n
+	responding := func(n *int, codes ...int) Doer {
+		return DoerFunc(func(*http.Request) (*http.Response, error) {
+			sc := codes[*n]
+			*n++
+			r := &http.Response{StatusCode: sc, Header: http.Header{}, Body: http.NoBody}
+			if sc == http.StatusTooManyRequests {
+				r.Header.Set("Retry-After", "5")
+			}
+			return r, nil
+		})
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "retryable status is retried",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 503, 502, 200)), MaxRetries(2))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusOK)
+				test.That(t, n).Equals(3)
+			},
+		},
+		{scenario: "no retries configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 503, 200)))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "retries exhausted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 503, 503, 200)), MaxRetries(1))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrMaxRetriesExceeded)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, r.StatusCode).Equals(http.StatusServiceUnavailable)
+				test.That(t, n).Equals(2)
+			},
+		},
+		{scenario: "non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 500, 200)), MaxRetries(1))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "acceptable status is not retried",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 503, 200)), MaxRetries(1))
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.AcceptStatus(http.StatusServiceUnavailable))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusServiceUnavailable)
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "client status codes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name",
+					Using(responding(&n, 500, 503, 200)),
+					MaxRetries(2),
+					RetryOnStatus(http.StatusInternalServerError),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(2)
+			},
+		},
+		{scenario: "request status codes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				c, _ := NewClient("name", Using(responding(&n, 503, 200)), MaxRetries(1))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path", request.RetryOnStatus())
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "Retry-After is respected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer func(og func() time.Time) { timeNow = og }(timeNow)
+				now := time.Now()
+				timeNow = func() time.Time { return now }
+
+				n := 0
+				waits := map[string]time.Duration{}
+				c, _ := NewClient("name",
+					Using(responding(&n, 429, 503, 200)),
+					MaxRetries(2),
+					Backoff(ConstantBackoff(time.Second)),
+					OnWait(func(reason string, d time.Duration) { waits[reason] = d }),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, waits).Equals(map[string]time.Duration{
+					WaitRetryAfter: 5 * time.Second,
+					WaitBackoff:    time.Second,
+				})
+			},
+		},
+		{scenario: "context cancelled while waiting",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ctx, cancel := context.WithCancel(context.Background())
+				c, _ := NewClient("name",
+					Using(responding(&n, 503, 200)),
+					MaxRetries(1),
+					OnWait(func(string, time.Duration) { cancel() }),
+				)
+				defer func(og func(time.Duration) <-chan time.Time) { timeAfter = og }(timeAfter)
+				timeAfter = func(time.Duration) <-chan time.Time { return nil }
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.That(t, n).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
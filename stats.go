@@ -0,0 +1,166 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// TransferStats records the number of requests performed and the number of
+// bytes sent and received in request and response bodies.
+type TransferStats struct {
+	// Requests is the number of requests performed, including any retries
+	Requests int64
+
+	// BytesSent is the number of bytes sent in request bodies
+	BytesSent int64
+
+	// BytesReceived is the number of bytes received in response bodies; for
+	// a streamed response, bytes are counted as the body is read
+	BytesReceived int64
+}
+
+// ClientStats holds the TransferStats of a client, in total and for each
+// named endpoint invoked using the client (see: Endpoint).
+type ClientStats struct {
+	TransferStats
+
+	// Endpoints holds the TransferStats for each named endpoint invoked
+	Endpoints map[string]TransferStats
+}
+
+// StatsProvider is implemented by clients that record TransferStats.  The
+// HttpClient returned by NewClient (or NewMockClient) implements StatsProvider:
+//
+//	if sp, ok := c.(http.StatsProvider); ok {
+//		stats := sp.Stats()
+//		...
+//	}
+type StatsProvider interface {
+	Stats() ClientStats
+}
+
+// clientStats records the TransferStats of a client.  It is held by pointer
+// so that stats are shared by all copies of a client.
+type clientStats struct {
+	mu        sync.Mutex
+	total     TransferStats
+	endpoints map[string]TransferStats
+}
+
+// add adds to the stats for the client and (if not empty) a named endpoint
+func (s *clientStats) add(endpoint string, delta TransferStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	add := func(ts *TransferStats) {
+		ts.Requests += delta.Requests
+		ts.BytesSent += delta.BytesSent
+		ts.BytesReceived += delta.BytesReceived
+	}
+
+	add(&s.total)
+	if endpoint == "" {
+		return
+	}
+	if s.endpoints == nil {
+		s.endpoints = map[string]TransferStats{}
+	}
+	ts := s.endpoints[endpoint]
+	add(&ts)
+	s.endpoints[endpoint] = ts
+}
+
+// Stats returns the TransferStats of the client, implementing StatsProvider
+func (c client) Stats() ClientStats {
+	if c.stats == nil {
+		return ClientStats{}
+	}
+
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	result := ClientStats{TransferStats: c.stats.total}
+	if len(c.stats.endpoints) > 0 {
+		result.Endpoints = make(map[string]TransferStats, len(c.stats.endpoints))
+		for k, v := range c.stats.endpoints {
+			result.Endpoints[k] = v
+		}
+	}
+	return result
+}
+
+// countingReader wraps a body, calling a function with the number of bytes
+// read by each read
+type countingReader struct {
+	io.ReadCloser
+	count func(int64)
+}
+
+// Read implements io.Reader, counting the bytes read from the wrapped body
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.ReadCloser.Read(p)
+	if n > 0 {
+		cr.count(int64(n))
+	}
+	return n, err
+}
+
+// transferCount counts the bytes sent and received in the bodies of the
+// requests and responses of all attempts to perform a request, for recording
+// in the RequestMetrics of the request
+type transferCount struct {
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// countSent wraps the body of a request (and any GetBody function) such that
+// bytes sent are recorded in the stats of the client and in any transferCount
+// of the request.  If the request has no body, or bytes sent are not recorded,
+// the request is not modified.
+func (c client) countSent(rq *http.Request, tc *transferCount) {
+	if (c.stats == nil && tc == nil) || rq.Body == nil || rq.Body == http.NoBody {
+		return
+	}
+
+	endpoint := EndpointName(rq.Context())
+	count := func(n int64) {
+		if c.stats != nil {
+			c.stats.add(endpoint, TransferStats{BytesSent: n})
+		}
+		if tc != nil {
+			tc.sent.Add(n)
+		}
+	}
+
+	rq.Body = countingReader{ReadCloser: rq.Body, count: count}
+	if getBody := rq.GetBody; getBody != nil {
+		rq.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return countingReader{ReadCloser: body, count: count}, nil
+		}
+	}
+}
+
+// countReceived wraps the body of a response such that bytes received are
+// recorded in the stats of the client and in any transferCount of the request
+// as the body is read
+func (c client) countReceived(rq *http.Request, r *http.Response, tc *transferCount) {
+	if (c.stats == nil && tc == nil) || r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	endpoint := EndpointName(rq.Context())
+	r.Body = countingReader{ReadCloser: r.Body, count: func(n int64) {
+		if c.stats != nil {
+			c.stats.add(endpoint, TransferStats{BytesReceived: n})
+		}
+		if tc != nil {
+			tc.received.Add(n)
+		}
+	}}
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestStats(t *testing.T) {
+	// ARRANGE
+	// echo responds with the body of each request, after reading it
+	echo := DoerFunc(func(rq *http.Request) (*http.Response, error) {
+		b := []byte{}
+		if rq.Body != nil {
+			b, _ = io.ReadAll(rq.Body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(b)),
+		}, nil
+	})
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no stats",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{wrapped: echo}
+				rq, _ := http.NewRequest(http.MethodPost, "", strings.NewReader("body"))
+
+				// ACT
+				_, err := c.Do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.Stats()).Equals(ClientStats{})
+			},
+		},
+		{scenario: "requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", Using(echo))
+				ctx := context.Background()
+
+				// ACT
+				_, _ = c.Post(ctx, "path", request.Body([]byte("12345")))
+				_, _ = c.Get(ctx, "path")
+
+				// ASSERT
+				sp, ok := c.(StatsProvider)
+				test.IsTrue(t, ok, "is a StatsProvider")
+				test.That(t, sp.Stats()).Equals(ClientStats{
+					TransferStats: TransferStats{Requests: 2, BytesSent: 5, BytesReceived: 5},
+				})
+			},
+		},
+		{scenario: "endpoints",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", Using(echo), Endpoint("create", http.MethodPost, "items"))
+				ctx := context.Background()
+
+				// ACT
+				_, _ = c.Invoke(ctx, "create", nil, request.Body([]byte("123")))
+				_, _ = c.Post(ctx, "path", request.Body([]byte("12345")))
+
+				// ASSERT
+				test.That(t, c.(StatsProvider).Stats()).Equals(ClientStats{
+					TransferStats: TransferStats{Requests: 2, BytesSent: 8, BytesReceived: 8},
+					Endpoints: map[string]TransferStats{
+						"create": {Requests: 1, BytesSent: 3, BytesReceived: 3},
+					},
+				})
+			},
+		},
+		{scenario: "retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				attempts := 0
+				fail := DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					attempts++
+					if attempts == 1 {
+						_, _ = io.ReadAll(rq.Body)
+						return nil, io.ErrUnexpectedEOF
+					}
+					return echo.Do(rq)
+				})
+				c, _ := NewClient("name", Using(fail), MaxRetries(1))
+
+				// ACT
+				_, err := c.Post(context.Background(), "path", request.Body([]byte("12345")))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.(StatsProvider).Stats()).Equals(ClientStats{
+					TransferStats: TransferStats{Requests: 2, BytesSent: 10, BytesReceived: 5},
+				})
+			},
+		},
+		{scenario: "streamed response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", Using(echo))
+				r, _ := c.Post(context.Background(), "path", request.Body([]byte("12345")), request.StreamResponse())
+
+				// ACT
+				before := c.(StatsProvider).Stats().BytesReceived
+				_, _ = io.ReadAll(r.Body)
+				after := c.(StatsProvider).Stats().BytesReceived
+
+				// ASSERT
+				test.That(t, before).Equals(0)
+				test.That(t, after).Equals(5)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
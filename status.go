@@ -0,0 +1,64 @@
+package http
+
+// IsInformational returns true if the specified status code is in the
+// 1xx (Informational) range.
+func IsInformational(code int) bool {
+	return code >= 100 && code <= 199
+}
+
+// IsSuccess returns true if the specified status code is in the 2xx
+// (Successful) range.
+func IsSuccess(code int) bool {
+	return code >= 200 && code <= 299
+}
+
+// IsRedirect returns true if the specified status code is in the 3xx
+// (Redirection) range.
+func IsRedirect(code int) bool {
+	return code >= 300 && code <= 399
+}
+
+// IsClientError returns true if the specified status code is in the 4xx
+// (Client Error) range.
+func IsClientError(code int) bool {
+	return code >= 400 && code <= 499
+}
+
+// IsServerError returns true if the specified status code is in the 5xx
+// (Server Error) range.
+func IsServerError(code int) bool {
+	return code >= 500 && code <= 599
+}
+
+// IsRetryable returns true if the specified status code indicates a
+// transient condition for which repeating the same request may succeed:
+//
+//	408 Request Timeout
+//	425 Too Early
+//	429 Too Many Requests
+//	502 Bad Gateway
+//	503 Service Unavailable
+//	504 Gateway Timeout
+//
+// Other 5xx status codes are not considered retryable as they typically
+// indicate a fault that will not be resolved by repeating the request.
+//
+// The status codes retried by a client by default (see: DefaultRetryStatus)
+// are those retryable status codes other than 408 and 425.  A 408 is usually
+// sent by a server closing an idle connection, which net/http handles without
+// returning a response, and a 425 is sent only for a request in TLS early
+// data, which net/http does not send; a client receiving either response is
+// unlikely to succeed by repeating the request unchanged.
+func IsRetryable(code int) bool {
+	switch code {
+	case StatusRequestTimeout,
+		StatusTooEarly,
+		StatusTooManyRequests,
+		StatusBadGateway,
+		StatusServiceUnavailable,
+		StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// maxStatusErrorBodySize is the maximum number of bytes of the body of a
+// response that are copied into an UnexpectedStatusCodeError
+const maxStatusErrorBodySize = 64 << 10
+
+// statusError returns an UnexpectedStatusCodeError describing a response with
+// an unacceptable status code, including the headers and a copy of (up to
+// 64 KiB of) the body of the response, together with any problem details.
+//
+// The body of the response is replaced so that it may still be read in its
+// entirety.
+func statusError(r *http.Response) UnexpectedStatusCodeError {
+	err := UnexpectedStatusCodeError{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+		Header:     r.Header.Clone(),
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return err
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxStatusErrorBodySize+1))
+	r.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	if len(body) > maxStatusErrorBodySize {
+		err.Body = body[:maxStatusErrorBodySize]
+		err.Truncated = true
+		return err
+	}
+	if len(body) > 0 {
+		err.Body = body
+		err.Problem = problemDetails(r.Header, body)
+	}
+	return err
+}
+
+// replayBody is a response body comprising content read from the original
+// body followed by any remainder of that body, closing the original body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestStatusError(t *testing.T) {
+	// ARRANGE
+	response := func(contentType, body string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Status:     "400 Bad Request",
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("text/plain", "invalid order")
+
+				// ACT
+				result := statusError(r)
+
+				// ASSERT
+				test.That(t, result.StatusCode).Equals(http.StatusBadRequest)
+				test.That(t, result.Status).Equals("400 Bad Request")
+				test.That(t, result.Header.Get("Content-Type")).Equals("text/plain")
+				test.Bytes(t, result.Body).Equals([]byte("invalid order"))
+				test.IsFalse(t, result.Truncated)
+				test.That(t, result.Problem).IsNil()
+
+				content, _ := io.ReadAll(r.Body)
+				test.That(t, string(content)).Equals("invalid order")
+			},
+		},
+		{scenario: "headers are copied",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response("text/plain", "")
+
+				// ACT
+				result := statusError(r)
+				r.Header.Set("Content-Type", "application/json")
+
+				// ASSERT
+				test.That(t, result.Header.Get("Content-Type")).Equals("text/plain")
+			},
+		},
+		{scenario: "no body",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: http.NoBody}
+
+				// ACT
+				result := statusError(r)
+
+				// ASSERT
+				test.That(t, result.Body).IsNil()
+				test.That(t, r.Body).Equals(http.NoBody)
+			},
+		},
+		{scenario: "problem details",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				r := response(MediaTypeProblemJSON, `{"title":"Invalid order"}`)
+
+				// ACT
+				result := statusError(r)
+
+				// ASSERT
+				test.That(t, result.Problem).IsNotNil()
+				test.That(t, result.Problem.Title).Equals("Invalid order")
+
+				content, _ := io.ReadAll(r.Body)
+				test.That(t, string(content)).Equals(`{"title":"Invalid order"}`)
+			},
+		},
+		{scenario: "body too large",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				body := `{"title":"` + strings.Repeat("x", maxStatusErrorBodySize) + `"}`
+				r := response(MediaTypeProblemJSON, body)
+
+				// ACT
+				result := statusError(r)
+
+				// ASSERT
+				test.That(t, len(result.Body)).Equals(maxStatusErrorBodySize)
+				test.IsTrue(t, result.Truncated)
+				test.That(t, result.Problem).IsNil()
+
+				content, _ := io.ReadAll(r.Body)
+				test.That(t, string(content)).Equals(body)
+			},
+		},
+		{scenario: "errors.As from client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("http://hostname"), Using(DoerFunc(func(*http.Request) (*http.Response, error) {
+					r := response("application/json", `{"error":"invalid order"}`)
+					r.Header.Set("X-Request-Id", "abc")
+					return r, nil
+				})))
+
+				// ACT
+				r, err := c.Post(context.Background(), "orders")
+
+				// ASSERT
+				var statusErr UnexpectedStatusCodeError
+				test.IsTrue(t, errors.As(err, &statusErr), "is an UnexpectedStatusCodeError")
+				test.That(t, statusErr.StatusCode).Equals(http.StatusBadRequest)
+				test.That(t, statusErr.Header.Get("X-Request-Id")).Equals("abc")
+				test.Bytes(t, statusErr.Body).Equals([]byte(`{"error":"invalid order"}`))
+
+				content, _ := io.ReadAll(r.Body)
+				test.That(t, string(content)).Equals(`{"error":"invalid order"}`)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestStatusClassification(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		code          int
+		informational bool
+		success       bool
+		redirect      bool
+		clientError   bool
+		serverError   bool
+		retryable     bool
+	}{
+		{code: 99},
+		{code: StatusContinue, informational: true},
+		{code: StatusOK, success: true},
+		{code: StatusNoContent, success: true},
+		{code: StatusFound, redirect: true},
+		{code: StatusBadRequest, clientError: true},
+		{code: StatusRequestTimeout, clientError: true, retryable: true},
+		{code: StatusTooEarly, clientError: true, retryable: true},
+		{code: StatusTooManyRequests, clientError: true, retryable: true},
+		{code: StatusInternalServerError, serverError: true},
+		{code: StatusBadGateway, serverError: true, retryable: true},
+		{code: StatusServiceUnavailable, serverError: true, retryable: true},
+		{code: StatusGatewayTimeout, serverError: true, retryable: true},
+		{code: 600},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("%d", tc.code), func(t *testing.T) {
+			// ACT & ASSERT
+			test.That(t, IsInformational(tc.code), "IsInformational").Equals(tc.informational)
+			test.That(t, IsSuccess(tc.code), "IsSuccess").Equals(tc.success)
+			test.That(t, IsRedirect(tc.code), "IsRedirect").Equals(tc.redirect)
+			test.That(t, IsClientError(tc.code), "IsClientError").Equals(tc.clientError)
+			test.That(t, IsServerError(tc.code), "IsServerError").Equals(tc.serverError)
+			test.That(t, IsRetryable(tc.code), "IsRetryable").Equals(tc.retryable)
+		})
+	}
+}
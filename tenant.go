@@ -0,0 +1,95 @@
+package http
+
+import "context"
+
+// TenantConfig holds the configuration of a tenant, resolved by a
+// TenantProvider for a request made for that tenant.
+type TenantConfig struct {
+	// URL, if not empty, replaces the url of the client as the base url of
+	// requests for the tenant
+	URL string
+
+	// Options are applied to every request for the tenant (e.g. to supply
+	// credentials), before any options supplied for the request itself
+	Options []RequestOption
+}
+
+// TenantProvider resolves the configuration of a tenant, identified by id.
+// The provider is called for every request made for a tenant so should
+// cache configuration as appropriate.
+type TenantProvider interface {
+	Tenant(ctx context.Context, id string) (TenantConfig, error)
+}
+
+// TenantProviderFunc adapts an ordinary function to the TenantProvider
+// interface.
+type TenantProviderFunc func(ctx context.Context, id string) (TenantConfig, error)
+
+// Tenant calls the function with the context and id.
+func (fn TenantProviderFunc) Tenant(ctx context.Context, id string) (TenantConfig, error) {
+	return fn(ctx, id)
+}
+
+// tenantKey is the key under which the id of a tenant is held in a context
+type tenantKey struct{}
+
+// Tenants configures a client to resolve the configuration of a tenant when a
+// request is made for that tenant, i.e. with a context established using
+// Tenant().  This enables a single client to be used for multiple tenants,
+// each with their own base url and/or credentials, rather than configuring
+// a nearly identical client for each tenant.
+//
+// Requests made with a context not identifying a tenant use the url and
+// options of the client.
+//
+// Tenant configuration is resolved by NewRequest (and so by the convenience
+// methods such as Get and Invoke); it is not applied to requests initialised
+// separately and performed using Do.
+//
+// # Example
+//
+//	c, err := http.NewClient("billing",
+//		http.URL("https://billing.example.com"),
+//		http.Tenants(provider),
+//	)
+//
+//	r, err := c.Get(http.Tenant(ctx, "acme"), "invoices")
+func Tenants(p TenantProvider) ClientOption {
+	return func(c *client) error {
+		c.tenants = p
+		return nil
+	}
+}
+
+// Tenant returns a context identifying the tenant for which requests made
+// with the context are performed (see: Tenants).
+func Tenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantID returns the id of the tenant identified by a context.  If the
+// context does not identify a tenant, an empty string is returned.
+func TenantID(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
+
+// resolveTenant returns the base url and request options to be used for a
+// request made with a specified context
+func (c client) resolveTenant(ctx context.Context, opts []RequestOption) (string, []RequestOption, error) {
+	id := TenantID(ctx)
+	if c.tenants == nil || id == "" {
+		return c.url, opts, nil
+	}
+
+	cfg, err := c.tenants.Tenant(ctx, id)
+	if err != nil {
+		return "", nil, TenantError{Tenant: id, Err: err}
+	}
+
+	url := c.url
+	if cfg.URL != "" {
+		url = cfg.URL
+	}
+	return url, append(append([]RequestOption{}, cfg.Options...), opts...), nil
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestTenant(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// ACT
+	result := Tenant(ctx, "acme")
+
+	// ASSERT
+	test.That(t, TenantID(result)).Equals("acme")
+	test.That(t, TenantID(ctx)).Equals("")
+}
+
+func TestTenants(t *testing.T) {
+	// ARRANGE
+	// token returns a function returning a specified token
+	token := func(s string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { return s, nil }
+	}
+	provider := TenantProviderFunc(func(_ context.Context, id string) (TenantConfig, error) {
+		switch id {
+		case "acme":
+			return TenantConfig{URL: "https://acme.example.com", Options: []RequestOption{request.BearerToken(token("acme-token"))}}, nil
+		case "other":
+			return TenantConfig{Options: []RequestOption{request.BearerToken(token("other-token"))}}, nil
+		default:
+			return TenantConfig{}, errors.New("unknown tenant")
+		}
+	})
+	c, _ := NewClient("name", URL("https://example.com"), Tenants(provider))
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := Tenants(provider)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.tenants).IsNotNil()
+			},
+		},
+		{scenario: "no tenant",
+			exec: func(t *testing.T) {
+				// ACT
+				rq, err := c.NewRequest(context.Background(), http.MethodGet, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/path")
+				test.That(t, rq.Header.Get("Authorization")).Equals("")
+			},
+		},
+		{scenario: "tenant url and options",
+			exec: func(t *testing.T) {
+				// ACT
+				rq, err := c.NewRequest(Tenant(context.Background(), "acme"), http.MethodGet, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://acme.example.com/path")
+				test.That(t, rq.Header.Get("Authorization")).Equals("Bearer acme-token")
+			},
+		},
+		{scenario: "tenant options applied before request options",
+			exec: func(t *testing.T) {
+				// ACT
+				rq, err := c.NewRequest(Tenant(context.Background(), "other"), http.MethodGet, "path", request.BearerToken(token("request-token")))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/path")
+				test.Strings(t, rq.Header.Values("Authorization")).Equals([]string{"Bearer other-token", "Bearer request-token"})
+			},
+		},
+		{scenario: "provider error",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := c.Get(Tenant(context.Background(), "unknown"), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTenant)
+				var tenantErr TenantError
+				test.IsTrue(t, errors.As(err, &tenantErr), "is TenantError")
+				test.That(t, tenantErr.Tenant).Equals("unknown")
+			},
+		},
+		{scenario: "client not configured for tenants",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://example.com"))
+
+				// ACT
+				rq, err := c.NewRequest(Tenant(context.Background(), "acme"), http.MethodGet, "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.URL.String()).Equals("https://example.com/path")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blugnu/errorcontext"
+)
+
+// throttle is a token bucket limiting the rate at which requests are sent by
+// a client.  The bucket holds up to burst tokens and is refilled at rate tokens
+// per second; each request takes a token, waiting if none is available.
+//
+// A client is a value type; the throttle is held by pointer so that the state
+// is shared by all copies of the client.
+type throttle struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit configures a client to limit the rate at which requests are sent
+// to rps requests per second, permitting bursts of up to burst requests.
+//
+// Every attempt to perform a request (including any retry) waits for the rate
+// limit, unless the request is configured with request.BypassRateLimit().  If
+// the request context is done while waiting, the request fails with the
+// context error without being sent.
+//
+// Rate limiting is independent of any handling of 429 Too Many Requests
+// responses (see: HandleTooManyRequests).
+func RateLimit(rps float64, burst int) ClientOption {
+	return func(c *client) error {
+		if rps <= 0 {
+			return fmt.Errorf("http: RateLimit option: rps must be greater than zero: %v", rps)
+		}
+		if burst < 1 {
+			return fmt.Errorf("http: RateLimit option: burst must be at least 1: %d", burst)
+		}
+		c.throttle = &throttle{
+			rate:   rps,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket, returning the time to wait before
+// the token is available (zero if available immediately)
+func (th *throttle) reserve() time.Duration {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
+	now := timeNow()
+	if !th.last.IsZero() {
+		th.tokens = min(th.burst, th.tokens+now.Sub(th.last).Seconds()*th.rate)
+	}
+	th.last = now
+
+	th.tokens--
+	if th.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-th.tokens / th.rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a request that was not sent
+func (th *throttle) cancel() {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
+	th.tokens = min(th.burst, th.tokens+1)
+}
+
+// awaitThrottle is called before each attempt to perform a request, waiting
+// for a token to be available.  If the context is done while waiting the
+// token is returned and the context error is returned.
+func (c client) awaitThrottle(ctx context.Context) error {
+	d := c.throttle.reserve()
+	if d == 0 {
+		return nil
+	}
+	if err := c.wait(ctx, WaitThrottled, d); err != nil {
+		c.throttle.cancel()
+		return errorcontext.Errorf(ctx, "%w", err)
+	}
+	return nil
+}
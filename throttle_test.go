@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRateLimit(t *testing.T) {
+	// ARRANGE
+	ogNow := timeNow
+	ogAfter := timeAfter
+	defer func() {
+		timeNow = ogNow
+		timeAfter = ogAfter
+	}()
+	now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	ok := DoerFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/invalid rps",
+			exec: func(t *testing.T) {
+				// ACT
+				err := RateLimit(0, 1)(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/invalid burst",
+			exec: func(t *testing.T) {
+				// ACT
+				err := RateLimit(1, 0)(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/valid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := RateLimit(10, 2)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.throttle.rate).Equals(10.0)
+				test.That(t, c.throttle.burst).Equals(2.0)
+				test.That(t, c.throttle.tokens).Equals(2.0)
+			},
+		},
+		{scenario: "reserve",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				th := &throttle{rate: 2, burst: 2, tokens: 2}
+
+				// ACT
+				result := []time.Duration{th.reserve(), th.reserve(), th.reserve()}
+				now = now.Add(2 * time.Second)
+				result = append(result, th.reserve(), th.reserve(), th.reserve())
+
+				// ASSERT
+				test.Slice(t, result).Equals([]time.Duration{
+					0, 0, 500 * time.Millisecond, // burst, then waits for the next token
+					0, 0, 500 * time.Millisecond, // bucket refilled (to burst) after 2s
+				})
+			},
+		},
+		{scenario: "requests wait for tokens",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				waits := []time.Duration{}
+				timeAfter = func(d time.Duration) <-chan time.Time {
+					ch := make(chan time.Time, 1)
+					ch <- now
+					return ch
+				}
+				c, _ := NewClient("name", URL("https://example.com"), Using(ok),
+					RateLimit(1, 1),
+					OnWait(func(reason string, d time.Duration) {
+						test.That(t, reason).Equals(WaitThrottled)
+						waits = append(waits, d)
+					}),
+				)
+
+				// ACT
+				_, err1 := c.Get(context.Background(), "path")
+				_, err2 := c.Get(context.Background(), "path")
+				_, err3 := c.Get(context.Background(), "path", request.BypassRateLimit())
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Error(t, err3).IsNil()
+				test.Slice(t, waits).Equals([]time.Duration{time.Second})
+			},
+		},
+		{scenario: "context done while waiting",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeAfter = func(time.Duration) <-chan time.Time { return nil }
+				sent := 0
+				c, _ := NewClient("name", URL("https://example.com"),
+					Using(DoerFunc(func(rq *http.Request) (*http.Response, error) {
+						sent++
+						return ok(rq)
+					})),
+					RateLimit(1, 1),
+				)
+				_, _ = c.Get(context.Background(), "path")
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				// ACT
+				_, err := c.Get(ctx, "path")
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+				test.That(t, sent).Equals(1)
+				test.That(t, c.(client).throttle.tokens).Equals(0.0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutConfig configures the timeouts applied to the stages of a request
+// (see: Timeouts).  A zero duration applies no timeout to the stage.
+//
+// Unlike an overall timeout (e.g. the Timeout of an *http.Client or the
+// deadline of a request context), these timeouts are suitable for long
+// streaming responses that still require liveness detection.
+type TimeoutConfig struct {
+	// Connect is the maximum time to establish a connection, including any
+	// TLS handshake
+	Connect time.Duration
+
+	// ResponseHeader is the maximum time to wait for the headers of a
+	// response after the request has been written
+	ResponseHeader time.Duration
+
+	// BodyIdle is the maximum time that any read of a response body may
+	// wait for data; a body that is idle for longer is closed and the read
+	// fails with ErrBodyIdleTimeout
+	BodyIdle time.Duration
+}
+
+// Timeouts configures a client with timeouts for each stage of a request.
+//
+// Connect and ResponseHeader timeouts are applied by deriving a transport
+// from that of the *http.Client used by the client (see: Using), which must
+// be an *http.Transport; if it is not, requests fail with
+// ErrTimeoutsNotSupported.  These timeouts are not applied if the client uses
+// some other Doer.  The derived transport is created once, when the client is
+// initialised (see: NewClient), and is used for every request performed by the
+// client; its idle connections are closed by the CloseIdleConnections method
+// of the client.
+//
+// The BodyIdle timeout is applied to the body of every response, including
+// streamed responses (see: request.StreamResponse).
+func Timeouts(cfg TimeoutConfig) ClientOption {
+	return func(c *client) error {
+		if cfg.Connect < 0 || cfg.ResponseHeader < 0 || cfg.BodyIdle < 0 {
+			return fmt.Errorf("http: Timeouts option: timeouts must not be negative: %+v", cfg)
+		}
+		c.timeouts = &cfg
+		return nil
+	}
+}
+
+// withTimeouts returns a Doer to be used to perform a request with the
+// Connect and ResponseHeader timeouts of a specified config.
+//
+// If the Doer is an *http.Client, a copy is returned with a Transport derived
+// from the Transport of the client and configured with the timeouts; if the
+// Transport of the client is not an *http.Transport, ErrTimeoutsNotSupported
+// is returned.  A new transport is derived on each call.  Any other Doer (or any Doer, if no Connect or ResponseHeader
+// timeout is configured) is returned unmodified.
+func withTimeouts(d Doer, cfg TimeoutConfig) (Doer, error) {
+	hc, ok := d.(*http.Client)
+	if !ok || (cfg.Connect == 0 && cfg.ResponseHeader == 0) {
+		return d, nil
+	}
+
+	rt := hc.Transport
+	if s, ok := rt.(stripOptionHeaders); ok {
+		rt = s.next
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: transport is %T", ErrTimeoutsNotSupported, rt)
+	}
+
+	cpy := *hc
+	cpy.Transport = StripOptionHeaders(timeoutTransport(base, cfg))
+	return &cpy, nil
+}
+
+// timeoutTransport returns a transport derived from a base transport,
+// configured with the Connect and ResponseHeader timeouts of a config
+func timeoutTransport(base *http.Transport, cfg TimeoutConfig) *http.Transport {
+	t := base.Clone()
+	if cfg.Connect > 0 {
+		t.DialContext = dialTimeout(base.DialContext, cfg.Connect)
+		t.TLSHandshakeTimeout = cfg.Connect
+	}
+	if cfg.ResponseHeader > 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseHeader
+	}
+	return t
+}
+
+// dialTimeout returns a dial function applying a timeout to a specified dial
+// function, so that any custom dialer (e.g. dialing a unix socket) is retained.
+// If the dial function is nil, a net.Dialer with the timeout is used.
+func dialTimeout(
+	dial func(context.Context, string, string) (net.Conn, error),
+	timeout time.Duration,
+) func(context.Context, string, string) (net.Conn, error) {
+	if dial == nil {
+		return (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, network, addr)
+	}
+}
+
+// idleTimeoutBody wraps the body of a response such that a read that waits
+// for data for longer than a specified duration fails.  When the timeout
+// expires the wrapped body is closed, unblocking the read in progress.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	d       time.Duration
+	expired atomic.Bool
+}
+
+// newIdleTimeoutBody returns a body wrapping a specified body, applying an
+// idle timeout of a specified duration to each read
+func newIdleTimeoutBody(body io.ReadCloser, d time.Duration) io.ReadCloser {
+	b := &idleTimeoutBody{ReadCloser: body, d: d}
+	b.timer = time.AfterFunc(d, func() {
+		b.expired.Store(true)
+		_ = body.Close()
+	})
+	b.timer.Stop()
+	return b
+}
+
+// Read implements io.Reader, failing with ErrBodyIdleTimeout if the read
+// waits for data for longer than the idle timeout
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	if b.expired.Load() {
+		return 0, b.err()
+	}
+
+	b.timer.Reset(b.d)
+	n, err := b.ReadCloser.Read(p)
+	if !b.timer.Stop() && b.expired.Load() && err != nil && err != io.EOF {
+		return n, b.err()
+	}
+	return n, err
+}
+
+// Close implements io.Closer, stopping the idle timer and closing the wrapped
+// body
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
+
+// err returns the error of a read that has timed out; the error wraps
+// os.ErrDeadlineExceeded, so is identified as a timeout by IsTimeout
+func (b *idleTimeoutBody) err() error {
+	return fmt.Errorf("%w (%v): %w", ErrBodyIdleTimeout, b.d, os.ErrDeadlineExceeded)
+}
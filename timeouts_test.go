@@ -0,0 +1,269 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestTimeouts(t *testing.T) {
+	// ARRANGE
+	// server returns a test server writing the headers of a response after a
+	// specified delay, followed by part of a body; the response is then
+	// stalled until the request is done
+	server := func(t *testing.T, delay time.Duration) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, rq *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-rq.Context().Done():
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte("partial"))
+			rw.(http.Flusher).Flush()
+			<-rq.Context().Done()
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option/negative timeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := Timeouts(TimeoutConfig{BodyIdle: -1})(&client{})
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "option/valid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+				cfg := TimeoutConfig{Connect: time.Second, ResponseHeader: 2 * time.Second, BodyIdle: 3 * time.Second}
+
+				// ACT
+				err := Timeouts(cfg)(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, *c.timeouts).Equals(cfg)
+			},
+		},
+		{scenario: "withTimeouts/not an http client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				d := DoerFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+
+				// ACT
+				result, err := withTimeouts(d, TimeoutConfig{Connect: time.Second})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).IsNotNil()
+				_, ok := result.(DoerFunc)
+				test.IsTrue(t, ok, "doer unmodified")
+			},
+		},
+		{scenario: "withTimeouts/no transport timeouts",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := &http.Client{}
+
+				// ACT
+				result, err := withTimeouts(hc, TimeoutConfig{BodyIdle: time.Second})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, result == Doer(hc), "client unmodified")
+			},
+		},
+		{scenario: "withTimeouts/unsupported transport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := &http.Client{Transport: &fakeTransport{}}
+
+				// ACT
+				_, err := withTimeouts(hc, TimeoutConfig{Connect: time.Second})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTimeoutsNotSupported)
+			},
+		},
+		{scenario: "withTimeouts/derived transport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				base := &http.Transport{}
+				hc := withStrippedOptionHeaders(&http.Client{Transport: base})
+				cfg := TimeoutConfig{Connect: time.Second, ResponseHeader: 2 * time.Second}
+
+				// ACT
+				d1, err1 := withTimeouts(hc, cfg)
+				d2, err2 := withTimeouts(hc, cfg)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				t1 := d1.(*http.Client).Transport.(stripOptionHeaders).next.(*http.Transport)
+				t2 := d2.(*http.Client).Transport.(stripOptionHeaders).next.(*http.Transport)
+				test.IsTrue(t, t1 != base, "base transport not modified")
+				test.IsTrue(t, t1 != t2, "derived transport not shared")
+				test.That(t, t1.TLSHandshakeTimeout).Equals(time.Second)
+				test.That(t, t1.ResponseHeaderTimeout).Equals(2 * time.Second)
+				test.That(t, t1.DialContext).IsNotNil()
+				test.That(t, base.ResponseHeaderTimeout).Equals(time.Duration(0))
+			},
+		},
+		{scenario: "derived transport is created once per client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				base := &http.Transport{}
+				cfg := TimeoutConfig{Connect: time.Second}
+				transport := func(c HttpClient) *http.Transport {
+					return c.(client).chain.requests.base.(*http.Client).Transport.(stripOptionHeaders).next.(*http.Transport)
+				}
+
+				// ACT
+				c1, _ := NewClient("one", Using(&http.Client{Transport: base}), Timeouts(cfg))
+				c2, _ := NewClient("two", Using(&http.Client{Transport: base}), Timeouts(cfg))
+
+				// ASSERT
+				test.IsTrue(t, transport(c1) != base, "base transport not used")
+				test.IsTrue(t, transport(c1) != transport(c2), "transport not shared by clients")
+			},
+		},
+		{scenario: "idle connections of derived transport are closed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				closed := make(chan struct{}, 1)
+				srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					rw.WriteHeader(http.StatusOK)
+				}))
+				srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+					if state == http.StateClosed {
+						select {
+						case closed <- struct{}{}:
+						default:
+						}
+					}
+				}
+				srv.Start()
+				defer srv.Close()
+				c, _ := NewClient("name", URL(srv.URL), Using(&http.Client{Transport: &http.Transport{}}),
+					Timeouts(TimeoutConfig{Connect: time.Second}),
+				)
+				_, err := c.Get(context.Background(), "path")
+				test.Error(t, err).IsNil()
+
+				// ACT
+				c.(interface{ CloseIdleConnections() }).CloseIdleConnections()
+
+				// ASSERT
+				select {
+				case <-closed:
+				case <-time.After(time.Second):
+					t.Error("idle connection not closed")
+				}
+			},
+		},
+		{scenario: "response header timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(t, time.Second)
+				c, _ := NewClient("name", URL(srv.URL), Using(&http.Client{Transport: &http.Transport{}}),
+					Timeouts(TimeoutConfig{ResponseHeader: 20 * time.Millisecond}),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.IsTrue(t, IsTimeout(err), "is timeout")
+			},
+		},
+		{scenario: "body idle timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(t, 0)
+				c, _ := NewClient("name", URL(srv.URL), Using(&http.Client{Transport: &http.Transport{}}),
+					Timeouts(TimeoutConfig{BodyIdle: 20 * time.Millisecond}),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				defer r.Body.Close()
+				body, err := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("partial")
+				test.Error(t, err).Is(ErrBodyIdleTimeout)
+				test.IsTrue(t, IsTimeout(err), "is timeout")
+			},
+		},
+		{scenario: "idle body/reads within timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				pr, pw := io.Pipe()
+				body := newIdleTimeoutBody(pr, 50*time.Millisecond)
+				go func() {
+					for i := 0; i < 3; i++ {
+						time.Sleep(10 * time.Millisecond)
+						_, _ = pw.Write([]byte("x"))
+					}
+					pw.Close()
+				}()
+
+				// ACT
+				result, err := io.ReadAll(body)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(result)).Equals("xxx")
+				test.Error(t, body.Close()).IsNil()
+			},
+		},
+		{scenario: "dialTimeout/no dial func",
+			exec: func(t *testing.T) {
+				// ACT
+				dial := dialTimeout(nil, time.Second)
+
+				// ASSERT
+				test.That(t, dial).IsNotNil()
+			},
+		},
+		{scenario: "dialTimeout/wraps dial func",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var deadline time.Time
+				dial := dialTimeout(func(ctx context.Context, network, addr string) (net.Conn, error) {
+					deadline, _ = ctx.Deadline()
+					return nil, nil
+				}, time.Second)
+
+				// ACT
+				_, err := dial(context.Background(), "tcp", "host:80")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, time.Until(deadline) > 0 && time.Until(deadline) <= time.Second)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// requestTLS holds the TLS options configured for a request (see:
+// request.TLSServerName and request.PinCertificate)
+type requestTLS struct {
+	serverName string
+	pins       [][sha256.Size]byte
+}
+
+// key returns a string identifying the TLS options
+func (opts requestTLS) key() string {
+	s := make([]string, 0, len(opts.pins)+1)
+	s = append(s, opts.serverName)
+	for _, pin := range opts.pins {
+		s = append(s, hex.EncodeToString(pin[:]))
+	}
+	return strings.Join(s, ",")
+}
+
+// tlsTransportKey identifies a transport configured with specific TLS options
+// derived from a specific base transport
+type tlsTransportKey struct {
+	base *http.Transport
+	opts string
+}
+
+// maxTLSTransports is the maximum number of transports configured with TLS
+// options held by a client; once exceeded, the least recently used transport
+// is forgotten and its idle connections closed
+const maxTLSTransports = 100
+
+// tlsTransports holds the transports configured for TLS options applied to the
+// requests of a client.  Transports are re-used for requests with the same
+// options so that connections are pooled, but never shared with requests
+// having different options (or none) or with other clients.
+type tlsTransports struct {
+	mu         sync.Mutex
+	transports map[tlsTransportKey]*list.Element
+	lru        *list.List
+}
+
+// tlsTransportEntry is an item in the lru list of the transports held by a
+// tlsTransports
+type tlsTransportEntry struct {
+	key       tlsTransportKey
+	transport *http.Transport
+}
+
+// newTLSTransports returns a new, empty tlsTransports
+func newTLSTransports() *tlsTransports {
+	return &tlsTransports{
+		transports: map[tlsTransportKey]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// get returns the transport derived from a base transport, configured with
+// specified TLS options, deriving a new transport if required.  If the maximum
+// number of transports is exceeded, the least recently used transport is
+// forgotten and its idle connections closed.
+func (tt *tlsTransports) get(base *http.Transport, opts requestTLS) *http.Transport {
+	key := tlsTransportKey{base: base, opts: opts.key()}
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if el, ok := tt.transports[key]; ok {
+		tt.lru.MoveToFront(el)
+		return el.Value.(*tlsTransportEntry).transport
+	}
+
+	t := tlsTransport(base, opts)
+	tt.transports[key] = tt.lru.PushFront(&tlsTransportEntry{key: key, transport: t})
+	if tt.lru.Len() > maxTLSTransports {
+		el := tt.lru.Back()
+		tt.lru.Remove(el)
+		evicted := el.Value.(*tlsTransportEntry)
+		delete(tt.transports, evicted.key)
+		evicted.transport.CloseIdleConnections()
+	}
+	return t
+}
+
+// closeIdleConnections closes any idle connections of the transports held
+func (tt *tlsTransports) closeIdleConnections() {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	for el := tt.lru.Front(); el != nil; el = el.Next() {
+		el.Value.(*tlsTransportEntry).transport.CloseIdleConnections()
+	}
+}
+
+// withTLS returns a Doer to be used to perform a request with specified TLS
+// options.
+//
+// If the Doer is an *http.Client, a copy is returned with a Transport derived
+// from the Transport of the client and configured with the TLS options; if the
+// Transport of the client is not an *http.Transport, ErrTLSOptionsNotSupported
+// is returned.  Any other Doer is returned unmodified.
+//
+// The derived Transport is obtained from a specified tlsTransports, so that it
+// is re-used for other requests with the same options.
+func withTLS(d Doer, opts requestTLS, transports *tlsTransports) (Doer, error) {
+	hc, ok := d.(*http.Client)
+	if !ok {
+		return d, nil
+	}
+
+	rt := hc.Transport
+	if s, ok := rt.(stripOptionHeaders); ok {
+		rt = s.next
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: transport is %T", ErrTLSOptionsNotSupported, rt)
+	}
+
+	cpy := *hc
+	cpy.Transport = StripOptionHeaders(transports.get(base, opts))
+	return &cpy, nil
+}
+
+// tlsTransport returns a new transport derived from a base transport,
+// configured with specified TLS options.
+func tlsTransport(base *http.Transport, opts requestTLS) *http.Transport {
+	t := base.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	if opts.serverName != "" {
+		t.TLSClientConfig.ServerName = opts.serverName
+	}
+	if len(opts.pins) > 0 {
+		verify := t.TLSClientConfig.VerifyConnection
+		t.TLSClientConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+			return verifyPins(cs, opts.pins)
+		}
+	}
+
+	return t
+}
+
+// verifyPins returns nil if any certificate presented by a server (or in any
+// verified chain) has a pinned fingerprint, otherwise ErrCertificateNotPinned.
+func verifyPins(cs tls.ConnectionState, pins [][sha256.Size]byte) error {
+	pinned := func(raw []byte) bool {
+		fp := sha256.Sum256(raw)
+		for _, pin := range pins {
+			if fp == pin {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, cert := range cs.PeerCertificates {
+		if pinned(cert.Raw) {
+			return nil
+		}
+	}
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			if pinned(cert.Raw) {
+				return nil
+			}
+		}
+	}
+	return ErrCertificateNotPinned
+}
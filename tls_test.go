@@ -0,0 +1,217 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestRequestTLSKey(t *testing.T) {
+	// ARRANGE
+	pin := sha256.Sum256([]byte("certificate"))
+	sut := requestTLS{serverName: "example.com", pins: [][sha256.Size]byte{pin}}
+
+	// ACT
+	result := sut.key()
+
+	// ASSERT
+	test.That(t, result).Equals("example.com," + hex.EncodeToString(pin[:]))
+}
+
+func TestVerifyPins(t *testing.T) {
+	// ARRANGE
+	leaf := &x509.Certificate{Raw: []byte("leaf")}
+	ca := &x509.Certificate{Raw: []byte("ca")}
+	cs := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf},
+		VerifiedChains:   [][]*x509.Certificate{{leaf, ca}},
+	}
+
+	testcases := []struct {
+		scenario string
+		pins     [][sha256.Size]byte
+		result   error
+	}{
+		{scenario: "leaf pinned", pins: [][sha256.Size]byte{sha256.Sum256([]byte("leaf"))}},
+		{scenario: "ca pinned", pins: [][sha256.Size]byte{sha256.Sum256([]byte("other")), sha256.Sum256([]byte("ca"))}},
+		{scenario: "not pinned", pins: [][sha256.Size]byte{sha256.Sum256([]byte("other"))}, result: ErrCertificateNotPinned},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			err := verifyPins(cs, tc.pins)
+
+			// ASSERT
+			test.Error(t, err).Is(tc.result)
+		})
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "not an *http.Client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				d := &fakeClient{}
+
+				// ACT
+				result, err := withTLS(d, requestTLS{serverName: "example.com"}, newTLSTransports())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(Doer(d))
+			},
+		},
+		{scenario: "unsupported transport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := withStrippedOptionHeaders(&http.Client{Transport: &fakeTransport{}})
+
+				// ACT
+				_, err := withTLS(hc, requestTLS{serverName: "example.com"}, newTLSTransports())
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTLSOptionsNotSupported)
+			},
+		},
+		{scenario: "default transport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := withStrippedOptionHeaders(http.DefaultClient)
+				opts := requestTLS{serverName: "example.com"}
+				transports := newTLSTransports()
+
+				// ACT
+				result, err := withTLS(hc, opts, transports)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				rt := result.(*http.Client).Transport.(stripOptionHeaders).next.(*http.Transport)
+				test.That(t, rt.TLSClientConfig.ServerName).Equals("example.com")
+				test.IsTrue(t, rt == transports.get(http.DefaultTransport.(*http.Transport), opts), "transport is re-used")
+				test.IsTrue(t, hc.Transport.(stripOptionHeaders).next == nil, "supplied client is not modified")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestTLSTransports(t *testing.T) {
+	// ARRANGE
+	base := &http.Transport{}
+	opts := func(i int) requestTLS {
+		return requestTLS{serverName: fmt.Sprintf("%d.example.com", i)}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "transports are not shared",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tt1 := newTLSTransports()
+				tt2 := newTLSTransports()
+
+				// ACT
+				t1 := tt1.get(base, opts(1))
+				t2 := tt2.get(base, opts(1))
+
+				// ASSERT
+				test.IsTrue(t, t1 != t2, "transports are not shared")
+				test.IsTrue(t, t1 != tt1.get(base, opts(2)), "transport not re-used for other options")
+			},
+		},
+		{scenario: "least recently used transport is evicted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := newTLSTransports()
+				first := sut.get(base, opts(0))
+				second := sut.get(base, opts(1))
+				for i := 2; i < maxTLSTransports; i++ {
+					_ = sut.get(base, opts(i))
+				}
+				_ = sut.get(base, opts(0)) // most recently used
+
+				// ACT
+				_ = sut.get(base, opts(maxTLSTransports))
+
+				// ASSERT
+				test.That(t, sut.lru.Len()).Equals(maxTLSTransports)
+				test.IsTrue(t, sut.get(base, opts(0)) == first, "recently used transport retained")
+				test.IsTrue(t, sut.get(base, opts(1)) != second, "least recently used transport evicted")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestClientTLSOptions(t *testing.T) {
+	// ARRANGE
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // handshake failures are expected
+	srv.StartTLS()
+	defer srv.Close()
+
+	fp := sha256.Sum256(srv.Certificate().Raw)
+	other := sha256.Sum256([]byte("other"))
+	c, _ := NewClient("name", URL(srv.URL), Using(srv.Client()))
+
+	testcases := []struct {
+		scenario string
+		opts     []RequestOption
+		result   error
+	}{
+		{scenario: "no options"},
+		{scenario: "server name", opts: []RequestOption{request.TLSServerName("example.com")}},
+		{scenario: "invalid server name", opts: []RequestOption{request.TLSServerName("invalid.example.org")}, result: x509.HostnameError{}},
+		{scenario: "pinned certificate", opts: []RequestOption{
+			request.PinCertificate(hex.EncodeToString(other[:])),
+			request.PinCertificate(hex.EncodeToString(fp[:])),
+		}},
+		{scenario: "certificate not pinned", opts: []RequestOption{request.PinCertificate(hex.EncodeToString(other[:]))}, result: ErrCertificateNotPinned},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			_, err := c.Get(context.Background(), "path", tc.opts...)
+
+			// ASSERT
+			switch tc.result.(type) {
+			case nil:
+				test.Error(t, err).IsNil()
+			case x509.HostnameError:
+				var target x509.HostnameError
+				test.IsTrue(t, errors.As(err, &target), "hostname error")
+			default:
+				test.Error(t, err).Is(tc.result)
+			}
+		})
+	}
+}
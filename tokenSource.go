@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/blugnu/errorcontext"
+	"github.com/blugnu/http/request"
+)
+
+// Token is an access token obtained by a TokenSource
+type Token struct {
+	// AccessToken is the token presented in the Authorization header of a
+	// request
+	AccessToken string
+
+	// Type is the type of the token, presented in the Authorization header
+	// of a request; if empty, "Bearer" is assumed
+	Type string
+
+	// Expiry is the time at which the token expires; if zero, the token
+	// does not expire (but may still be refreshed following a 401
+	// Unauthorized response)
+	Expiry time.Time
+}
+
+// authorization returns the value of the Authorization header for the token
+func (t Token) authorization() string {
+	typ := t.Type
+	if typ == "" {
+		typ = "Bearer"
+	}
+	return typ + " " + t.AccessToken
+}
+
+// TokenSource caches a token obtained using a function (e.g. performing an
+// OAuth2 client-credentials grant), refreshing the token before it expires.
+//
+// A TokenSource is safe for concurrent use; when a token must be obtained,
+// concurrent callers wait for a single call of the function.
+type TokenSource struct {
+	mu            sync.Mutex
+	fetch         func(context.Context) (Token, error)
+	refreshBefore time.Duration
+	token         *Token
+}
+
+// NewTokenSource returns a TokenSource obtaining tokens using a specified
+// function.  A cached token is refreshed when it is within a specified
+// duration of its expiry, so that a token does not expire while a request
+// is in flight.
+func NewTokenSource(fn func(context.Context) (Token, error), refreshBefore time.Duration) *TokenSource {
+	return &TokenSource{fetch: fn, refreshBefore: refreshBefore}
+}
+
+// Token returns the cached token, obtaining a new token if no token is cached,
+// the cached token has been invalidated or is due to expire.
+func (ts *TokenSource) Token(ctx context.Context) (Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if t := ts.token; t != nil && (t.Expiry.IsZero() || timeNow().Add(ts.refreshBefore).Before(t.Expiry)) {
+		return *t, nil
+	}
+
+	t, err := ts.fetch(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	ts.token = &t
+	return t, nil
+}
+
+// AccessToken returns the access token of the cached token, obtaining a new
+// token as required.  The method has the signature required by the
+// request.BearerToken request option:
+//
+//	r, err := c.Get(ctx, "orders", request.BearerToken(ts.AccessToken))
+func (ts *TokenSource) AccessToken(ctx context.Context) (string, error) {
+	t, err := ts.Token(ctx)
+	return t.AccessToken, err
+}
+
+// Invalidate discards any cached token, so that a new token is obtained when
+// next required.
+func (ts *TokenSource) Invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.token = nil
+}
+
+// invalidate discards the cached token if it is a specified token; a token
+// refreshed since the specified token was obtained is retained
+func (ts *TokenSource) invalidate(t Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != nil && *ts.token == t {
+		ts.token = nil
+	}
+}
+
+// BearerAuth configures a client to authorize every request using a token
+// obtained from a TokenSource, setting the Authorization header of each
+// attempt to perform a request.
+//
+// If a request is rejected with a 401 Unauthorized response, the token is
+// invalidated and the request is retried once with a new token (if the body
+// of the request can be replayed), in addition to any retries configured for
+// the request.  If a 401 response is acceptable to the request (see:
+// request.AcceptStatus) the request is not retried.
+//
+// A nil TokenSource removes any TokenSource configured previously.
+func BearerAuth(ts *TokenSource) ClientOption {
+	return func(c *client) error {
+		c.tokens = ts
+		return nil
+	}
+}
+
+// authorize sets the Authorization header of a request using a token obtained
+// from the TokenSource of the client, returning the token.  The configuration
+// of the request is not carried into the context in which any token is
+// obtained, so that it does not apply to any request made to obtain a token.
+func (c client) authorize(ctx context.Context, rq *http.Request) (Token, error) {
+	t, err := c.tokens.Token(request.WithoutConfig(ctx))
+	if err != nil {
+		return t, errorcontext.Errorf(ctx, "%w: %w", ErrObtainingToken, err)
+	}
+	rq.Header.Set("Authorization", t.authorization())
+	return t, nil
+}
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestTokenSource(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	og := timeNow
+	defer func() { timeNow = og }()
+	timeNow = func() time.Time { return now }
+
+	// counter returns a function obtaining tokens numbered in sequence,
+	// each expiring in one minute
+	counter := func(n *int) func(context.Context) (Token, error) {
+		return func(context.Context) (Token, error) {
+			*n++
+			return Token{AccessToken: "token-" + strconv.Itoa(*n), Expiry: timeNow().Add(time.Minute)}, nil
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "token is cached",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ts := NewTokenSource(counter(&n), 10*time.Second)
+
+				// ACT
+				t1, err1 := ts.Token(ctx)
+				t2, err2 := ts.Token(ctx)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, t1.AccessToken).Equals("token-1")
+				test.That(t, t2.AccessToken).Equals("token-1")
+			},
+		},
+		{scenario: "token without expiry is cached",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ts := NewTokenSource(func(context.Context) (Token, error) {
+					n++
+					return Token{AccessToken: "token"}, nil
+				}, time.Minute)
+
+				// ACT
+				_, _ = ts.Token(ctx)
+				_, _ = ts.Token(ctx)
+
+				// ASSERT
+				test.That(t, n).Equals(1)
+			},
+		},
+		{scenario: "token is refreshed before expiry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer func(t time.Time) { now = t }(now)
+				n := 0
+				ts := NewTokenSource(counter(&n), 10*time.Second)
+				_, _ = ts.Token(ctx)
+
+				// ACT
+				now = now.Add(50 * time.Second)
+				result, err := ts.Token(ctx)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.AccessToken).Equals("token-2")
+			},
+		},
+		{scenario: "error obtaining token",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tokenerr := errors.New("token error")
+				ts := NewTokenSource(func(context.Context) (Token, error) { return Token{}, tokenerr }, 0)
+
+				// ACT
+				_, err := ts.AccessToken(ctx)
+
+				// ASSERT
+				test.Error(t, err).Is(tokenerr)
+			},
+		},
+		{scenario: "AccessToken",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ts := NewTokenSource(counter(&n), 0)
+				rq, _ := http.NewRequest(http.MethodGet, "", nil)
+
+				// ACT
+				err := request.BearerToken(ts.AccessToken)(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Header.Get("Authorization")).Equals("Bearer token-1")
+			},
+		},
+		{scenario: "Invalidate",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ts := NewTokenSource(counter(&n), 0)
+				_, _ = ts.Token(ctx)
+
+				// ACT
+				ts.Invalidate()
+				result, _ := ts.Token(ctx)
+
+				// ASSERT
+				test.That(t, result.AccessToken).Equals("token-2")
+			},
+		},
+		{scenario: "invalidate/refreshed token is retained",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				n := 0
+				ts := NewTokenSource(counter(&n), 0)
+				stale, _ := ts.Token(ctx)
+				ts.Invalidate()
+				_, _ = ts.Token(ctx)
+
+				// ACT
+				ts.invalidate(stale)
+				result, _ := ts.Token(ctx)
+
+				// ASSERT
+				test.That(t, result.AccessToken).Equals("token-2")
+			},
+		},
+		{scenario: "authorization",
+			exec: func(t *testing.T) {
+				// ACT
+				bearer := Token{AccessToken: "abc"}.authorization()
+				mac := Token{AccessToken: "abc", Type: "MAC"}.authorization()
+
+				// ASSERT
+				test.That(t, bearer).Equals("Bearer abc")
+				test.That(t, mac).Equals("MAC abc")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// respond returns a Doer recording the Authorization header of each
+	// request and responding with each of a sequence of status codes
+	respond := func(sent *[]string, status ...int) Doer {
+		return DoerFunc(func(rq *http.Request) (*http.Response, error) {
+			*sent = append(*sent, rq.Header.Get("Authorization"))
+			sc := status[min(len(*sent), len(status))-1]
+			return &http.Response{StatusCode: sc, Body: http.NoBody}, nil
+		})
+	}
+	counter := func() *TokenSource {
+		n := 0
+		return NewTokenSource(func(context.Context) (Token, error) {
+			n++
+			return Token{AccessToken: "token-" + strconv.Itoa(n)}, nil
+		}, 0)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := BearerAuth(counter())(&c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.tokens).IsNotNil()
+			},
+		},
+		{scenario: "request is authorized",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := client{wrapped: respond(&sent, http.StatusOK), tokens: counter()}
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, sent).Equals([]string{"Bearer token-1"})
+			},
+		},
+		{scenario: "unauthorized/retried with new token",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := client{wrapped: respond(&sent, http.StatusUnauthorized, http.StatusOK), tokens: counter()}
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, sent).Equals([]string{"Bearer token-1", "Bearer token-2"})
+			},
+		},
+		{scenario: "unauthorized/retried only once",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := client{wrapped: respond(&sent, http.StatusUnauthorized), tokens: counter()}
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.Strings(t, sent).Equals([]string{"Bearer token-1", "Bearer token-2"})
+			},
+		},
+		{scenario: "unauthorized/accepted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				c := client{wrapped: respond(&sent, http.StatusUnauthorized), tokens: counter()}
+
+				// ACT
+				r, err := c.Get(ctx, "", request.AcceptStatus(http.StatusUnauthorized))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusUnauthorized)
+				test.That(t, len(sent)).Equals(1)
+			},
+		},
+		{scenario: "token is obtained without the config of the request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sent := []string{}
+				issuer := client{wrapped: respond(&sent, http.StatusNotFound)}
+				c := client{
+					wrapped: respond(&sent, http.StatusNotFound),
+					tokens: NewTokenSource(func(ctx context.Context) (Token, error) {
+						rq, _ := http.NewRequestWithContext(ctx, http.MethodPost, "token", nil)
+						_, err := issuer.Do(rq)
+						return Token{AccessToken: "token"}, err
+					}, 0),
+				}
+
+				// ACT
+				_, err := c.Get(ctx, "", request.AcceptStatus(http.StatusNotFound))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrObtainingToken)
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+			},
+		},
+		{scenario: "error obtaining token",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tokenerr := errors.New("token error")
+				sent := []string{}
+				c := client{
+					wrapped: respond(&sent, http.StatusOK),
+					tokens:  NewTokenSource(func(context.Context) (Token, error) { return Token{}, tokenerr }, 0),
+				}
+
+				// ACT
+				_, err := c.Get(ctx, "")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrObtainingToken)
+				test.Error(t, err).Is(tokenerr)
+				test.That(t, len(sent)).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ResponseTransformer is a function that transforms a response, e.g. to strip
+// an envelope from a response body or to rename fields during an api migration.
+//
+// ResponseTransformer is an alias for an anonymous function type, so
+// transformers declared in other modules using an equivalent signature may be
+// used without any coupling to this package.
+type ResponseTransformer = func(*http.Response) (*http.Response, error)
+
+// TransformResponse registers transformers applied to every successful
+// response returned by the client, after the status code of the response has
+// been checked and before the response is returned.  The option may be applied
+// more than once; transformers are accumulated.
+//
+// Transformers are chained in the order registered, each receiving the
+// response returned by the previous transformer.  Unless the request is
+// streamed (see: request.StreamResponse) the body of the response has been
+// read and is provided as an in-memory reader; a transformer replacing the
+// body should also set the ContentLength of the response.
+//
+// If a transformer returns an error, no further transformers are applied and
+// the client returns an error wrapping ErrTransformingResponse.
+//
+// Any nil transformer is ignored.
+func TransformResponse(transformers ...ResponseTransformer) ClientOption {
+	return func(c *client) error {
+		for _, fn := range transformers {
+			if fn != nil {
+				c.transformers = append(c.transformers, fn)
+			}
+		}
+		return nil
+	}
+}
+
+// transform applies the transformers of the client to a response
+func (c client) transform(r *http.Response) (*http.Response, error) {
+	for _, fn := range c.transformers {
+		var err error
+		if r, err = fn(r); err != nil {
+			return r, fmt.Errorf("%w: %w", ErrTransformingResponse, err)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestTransformResponse(t *testing.T) {
+	// ARRANGE
+	respond := func(status int, body string) Doer {
+		return DoerFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		})
+	}
+
+	// unwrap returns a transformer replacing the body of a response with the
+	// body with a specified prefix and suffix removed
+	unwrap := func(prefix, suffix string) ResponseTransformer {
+		return func(r *http.Response) (*http.Response, error) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return r, err
+			}
+			body = bytes.TrimSuffix(bytes.TrimPrefix(body, []byte(prefix)), []byte(suffix))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			return r, nil
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "option accumulates transformers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err1 := TransformResponse(unwrap("", ""), nil)(&c)
+				err2 := TransformResponse(unwrap("", ""))(&c)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, len(c.transformers)).Equals(2)
+			},
+		},
+		{scenario: "transformers are chained in order",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name",
+					Using(respond(http.StatusOK, `{"data":{"id":1}}`)),
+					TransformResponse(unwrap(`{"data":`, "}"), unwrap("{", "}")),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals(`"id":1`)
+				test.That(t, r.ContentLength).Equals(int64(6))
+			},
+		},
+		{scenario: "streamed response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name",
+					Using(respond(http.StatusOK, "<body>")),
+					TransformResponse(unwrap("<", ">")),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path", request.StreamResponse())
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				body, _ := io.ReadAll(r.Body)
+				test.That(t, string(body)).Equals("body")
+			},
+		},
+		{scenario: "transformer error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				trerr := errors.New("transformer error")
+				called := false
+				c, _ := NewClient("name",
+					Using(respond(http.StatusOK, "body")),
+					TransformResponse(
+						func(r *http.Response) (*http.Response, error) { return r, trerr },
+						func(r *http.Response) (*http.Response, error) { called = true; return r, nil },
+					),
+				)
+
+				// ACT
+				r, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTransformingResponse)
+				test.Error(t, err).Is(trerr)
+				test.That(t, r).IsNotNil()
+				test.IsFalse(t, called, "subsequent transformer called")
+			},
+		},
+		{scenario: "unexpected status code",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				called := false
+				c, _ := NewClient("name",
+					Using(respond(http.StatusNotFound, "body")),
+					TransformResponse(func(r *http.Response) (*http.Response, error) { called = true; return r, nil }),
+				)
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedStatusCode)
+				test.IsFalse(t, called, "transformer called")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// optionHeaderPrefix is the prefix of the (legacy) headers used to convey
+// request options to the client
+const optionHeaderPrefix = "X-Blugnu-Http-"
+
+// stripOptionHeaders is a RoundTripper that removes any request option
+// headers from a request before passing it to a wrapped RoundTripper
+type stripOptionHeaders struct {
+	next http.RoundTripper
+}
+
+// StripOptionHeaders returns a RoundTripper that removes any request option
+// headers (X-Blugnu-Http-*) from a request before passing it to a specified
+// RoundTripper.  If the specified RoundTripper is nil, http.DefaultTransport
+// is used.
+//
+// Request option headers are removed by the client when a request is performed,
+// but may be re-introduced if a request is cloned by some middleware; this
+// RoundTripper provides a guarantee that such headers are never sent to a
+// server.
+//
+// An *http.Client supplied to NewClient using the Using() option is
+// automatically configured with this RoundTripper; it only needs to be used
+// explicitly where some other client implementation is used.
+func StripOptionHeaders(rt http.RoundTripper) http.RoundTripper {
+	if s, ok := rt.(stripOptionHeaders); ok {
+		return s
+	}
+	return stripOptionHeaders{next: rt}
+}
+
+// RoundTrip implements the RoundTripper interface, removing any request
+// option headers before passing the request to the wrapped RoundTripper.
+//
+// A RoundTripper must not modify the request; if any option headers are
+// present the request is cloned and the headers removed from the clone.
+func (rt stripOptionHeaders) RoundTrip(rq *http.Request) (*http.Response, error) {
+	next := rt.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	for k := range rq.Header {
+		if !strings.HasPrefix(k, optionHeaderPrefix) {
+			continue
+		}
+		rq = rq.Clone(rq.Context())
+		for k := range rq.Header {
+			if strings.HasPrefix(k, optionHeaderPrefix) {
+				delete(rq.Header, k)
+			}
+		}
+		break
+	}
+
+	return next.RoundTrip(rq)
+}
+
+// CloseIdleConnections closes any idle connections of the wrapped RoundTripper,
+// if it supports doing so (as *http.Transport does).  This enables the idle
+// connections of an *http.Client with a wrapped Transport to be closed using
+// the CloseIdleConnections method of the client.
+func (rt stripOptionHeaders) CloseIdleConnections() {
+	next := rt.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if ci, ok := next.(closeIdler); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// withStrippedOptionHeaders returns a copy of a supplied *http.Client with
+// its Transport wrapped by a StripOptionHeaders RoundTripper.  The supplied
+// client is not modified.
+func withStrippedOptionHeaders(c *http.Client) *http.Client {
+	cpy := *c
+	cpy.Transport = StripOptionHeaders(c.Transport)
+	return &cpy
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ConfigureTransport configures the *http.Transport used by the client using
+// a supplied function.  It enables any Transport setting not provided for by
+// other options (e.g. MaxIdleConns, Proxy or TLSConfig) to be configured without
+// having to construct an *http.Client and *http.Transport explicitly.
+//
+// Transport options are applied once all other options have been applied,
+// in the order in which they were specified, to a clone of the Transport of
+// the wrapped *http.Client (or of http.DefaultTransport, if the client has no
+// Transport); neither the client nor the Transport supplied using the Using()
+// option is modified.
+//
+// If the wrapped client is not an *http.Client, or its Transport is not an
+// *http.Transport, NewClient returns an error wrapping
+// ErrTransportOptionsNotSupported.
+func ConfigureTransport(fn func(*http.Transport)) ClientOption {
+	return func(c *client) error {
+		if fn == nil {
+			return errors.New("http: ConfigureTransport option: func must not be nil")
+		}
+		c.transport = append(c.transport, fn)
+		return nil
+	}
+}
+
+// IdleConnTimeout configures the maximum time that an idle connection will
+// remain idle before closing itself.  Zero means no limit.
+//
+// This is a transport option; see ConfigureTransport for details.
+func IdleConnTimeout(d time.Duration) ClientOption {
+	return func(c *client) error {
+		if d < 0 {
+			return fmt.Errorf("http: IdleConnTimeout option: timeout must not be negative: %v", d)
+		}
+		return ConfigureTransport(func(t *http.Transport) { t.IdleConnTimeout = d })(c)
+	}
+}
+
+// MaxConnsPerHost configures the maximum number of connections to each host,
+// including connections in the dialing, active and idle states.  Zero means
+// no limit.
+//
+// This is a transport option; see ConfigureTransport for details.
+func MaxConnsPerHost(n int) ClientOption {
+	return func(c *client) error {
+		if n < 0 {
+			return fmt.Errorf("http: MaxConnsPerHost option: value must not be negative: %d", n)
+		}
+		return ConfigureTransport(func(t *http.Transport) { t.MaxConnsPerHost = n })(c)
+	}
+}
+
+// MaxIdleConns configures the maximum number of idle (keep-alive) connections
+// across all hosts.  Zero means no limit.
+//
+// This is a transport option; see ConfigureTransport for details.
+func MaxIdleConns(n int) ClientOption {
+	return func(c *client) error {
+		if n < 0 {
+			return fmt.Errorf("http: MaxIdleConns option: value must not be negative: %d", n)
+		}
+		return ConfigureTransport(func(t *http.Transport) { t.MaxIdleConns = n })(c)
+	}
+}
+
+// MaxIdleConnsPerHost configures the maximum number of idle (keep-alive)
+// connections to keep for each host.  If zero, http.DefaultMaxIdleConnsPerHost
+// is used.
+//
+// This is a transport option; see ConfigureTransport for details.
+func MaxIdleConnsPerHost(n int) ClientOption {
+	return func(c *client) error {
+		if n < 0 {
+			return fmt.Errorf("http: MaxIdleConnsPerHost option: value must not be negative: %d", n)
+		}
+		return ConfigureTransport(func(t *http.Transport) { t.MaxIdleConnsPerHost = n })(c)
+	}
+}
+
+// Proxy configures a function returning the proxy to be used for a given
+// request (e.g. http.ProxyFromEnvironment or http.ProxyURL).  If the function
+// returns a nil URL, or the function is nil, no proxy is used.
+//
+// This is a transport option; see ConfigureTransport for details.
+func Proxy(fn func(*http.Request) (*url.URL, error)) ClientOption {
+	return ConfigureTransport(func(t *http.Transport) { t.Proxy = fn })
+}
+
+// TLSConfig configures the TLS configuration used by the client.  A clone of
+// the supplied configuration is used, replacing any TLS configuration of the
+// Transport; a nil configuration restores the default TLS configuration.
+//
+// This is a transport option; see ConfigureTransport for details.
+func TLSConfig(cfg *tls.Config) ClientOption {
+	return ConfigureTransport(func(t *http.Transport) { t.TLSClientConfig = cfg.Clone() })
+}
+
+// configureTransport applies any transport options configured on a client to
+// a clone of the Transport of the wrapped *http.Client, replacing the wrapped
+// client with a copy using the configured Transport.
+func (c *client) configureTransport() error {
+	if len(c.transport) == 0 {
+		return nil
+	}
+
+	hc, ok := c.wrapped.(*http.Client)
+	if !ok {
+		return fmt.Errorf("%w: client is %T", ErrTransportOptionsNotSupported, c.wrapped)
+	}
+
+	rt := hc.Transport
+	if s, ok := rt.(stripOptionHeaders); ok {
+		rt = s.next
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("%w: transport is %T", ErrTransportOptionsNotSupported, rt)
+	}
+
+	t := base.Clone()
+	for _, fn := range c.transport {
+		fn(t)
+	}
+
+	cpy := *hc
+	cpy.Transport = StripOptionHeaders(t)
+	c.wrapped = &cpy
+	return nil
+}
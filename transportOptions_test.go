@@ -0,0 +1,173 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestTransportOptions(t *testing.T) {
+	// ARRANGE
+	// transportOf returns the *http.Transport of the wrapped *http.Client
+	// of a client
+	transportOf := func(t *testing.T, c HttpClient) *http.Transport {
+		t.Helper()
+		hc := c.(client).wrapped.(*http.Client)
+		return hc.Transport.(stripOptionHeaders).next.(*http.Transport)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no transport options",
+			exec: func(t *testing.T) {
+				// ACT
+				c, err := NewClient("name")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, c.(client).wrapped.(*http.Client).Transport).Equals(http.RoundTripper(stripOptionHeaders{}))
+			},
+		},
+		{scenario: "pooling options",
+			exec: func(t *testing.T) {
+				// ACT
+				c, err := NewClient("name",
+					IdleConnTimeout(time.Minute),
+					MaxConnsPerHost(10),
+					MaxIdleConns(20),
+					MaxIdleConnsPerHost(5),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				tr := transportOf(t, c)
+				test.That(t, tr.IdleConnTimeout).Equals(time.Minute)
+				test.That(t, tr.MaxConnsPerHost).Equals(10)
+				test.That(t, tr.MaxIdleConns).Equals(20)
+				test.That(t, tr.MaxIdleConnsPerHost).Equals(5)
+				test.That(t, http.DefaultTransport.(*http.Transport).MaxConnsPerHost).Equals(0)
+			},
+		},
+		{scenario: "negative values",
+			exec: func(t *testing.T) {
+				for _, opt := range []ClientOption{
+					IdleConnTimeout(-time.Second),
+					MaxConnsPerHost(-1),
+					MaxIdleConns(-1),
+					MaxIdleConnsPerHost(-1),
+				} {
+					// ACT
+					_, err := NewClient("name", opt)
+
+					// ASSERT
+					test.Error(t, err).Is(ErrInitialisingClient)
+				}
+			},
+		},
+		{scenario: "proxy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				proxy, _ := url.Parse("http://proxy:3128")
+
+				// ACT
+				c, err := NewClient("name", Proxy(http.ProxyURL(proxy)))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				got, _ := transportOf(t, c).Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: "host"}})
+				test.That(t, got.String()).Equals("http://proxy:3128")
+			},
+		},
+		{scenario: "tls config",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cfg := &tls.Config{MinVersion: tls.VersionTLS13}
+
+				// ACT
+				c, err := NewClient("name", TLSConfig(cfg))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				tr := transportOf(t, c)
+				test.That(t, tr.TLSClientConfig.MinVersion).Equals(uint16(tls.VersionTLS13))
+				test.IsFalse(t, tr.TLSClientConfig == cfg, "config is cloned")
+			},
+		},
+		{scenario: "options are applied in order",
+			exec: func(t *testing.T) {
+				// ACT
+				c, err := NewClient("name",
+					MaxIdleConns(20),
+					ConfigureTransport(func(t *http.Transport) { t.MaxIdleConns = 30 }),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, transportOf(t, c).MaxIdleConns).Equals(30)
+			},
+		},
+		{scenario: "options are applied after Using",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				own := &http.Transport{MaxIdleConns: 1, MaxConnsPerHost: 2}
+				hc := &http.Client{Transport: own, Timeout: time.Second}
+
+				// ACT
+				c, err := NewClient("name", MaxIdleConns(20), Using(hc))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				tr := transportOf(t, c)
+				test.That(t, tr.MaxIdleConns).Equals(20)
+				test.That(t, tr.MaxConnsPerHost).Equals(2)
+				test.That(t, c.(client).wrapped.(*http.Client).Timeout).Equals(time.Second)
+				test.That(t, own.MaxIdleConns).Equals(1)
+				test.That(t, hc.Transport).Equals(http.RoundTripper(own))
+			},
+		},
+		{scenario: "nil func",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("name", ConfigureTransport(nil))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+			},
+		},
+		{scenario: "client is not an *http.Client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				d := DoerFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+
+				// ACT
+				_, err := NewClient("name", Using(d), MaxIdleConns(1))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTransportOptionsNotSupported)
+			},
+		},
+		{scenario: "transport is not an *http.Transport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := &http.Client{Transport: &fakeTransport{}}
+
+				// ACT
+				_, err := NewClient("name", Using(hc), MaxIdleConns(1))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrTransportOptionsNotSupported)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+// fakeTransport is a RoundTripper that records the request sent
+type fakeTransport struct {
+	sent *http.Request
+}
+
+func (fake *fakeTransport) RoundTrip(rq *http.Request) (*http.Response, error) {
+	fake.sent = rq
+	return httptest.NewRecorder().Result(), nil
+}
+
+// idleTransport is a RoundTripper that records whether its idle connections
+// were closed
+type idleTransport struct {
+	fakeTransport
+	closed bool
+}
+
+func (fake *idleTransport) CloseIdleConnections() {
+	fake.closed = true
+}
+
+func TestStripOptionHeaders(t *testing.T) {
+	// ARRANGE
+	next := &fakeTransport{}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no option headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname", nil)
+				rq.Header.Set("Accept", "application/json")
+
+				// ACT
+				_, err := StripOptionHeaders(next).RoundTrip(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, next.sent == rq, "request is not cloned")
+			},
+		},
+		{scenario: "option headers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := http.NewRequest(http.MethodGet, "http://hostname", nil)
+				rq.Header.Set("Accept", "application/json")
+				rq.Header[request.MaxRetriesHeader] = []string{"1"}
+				rq.Header[request.StreamResponseHeader] = []string{"true"}
+
+				// ACT
+				_, err := StripOptionHeaders(next).RoundTrip(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, next.sent.Header).Equals(http.Header{"Accept": {"application/json"}})
+				test.That(t, len(rq.Header), "original request headers").Equals(3)
+			},
+		},
+		{scenario: "already wrapped",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rt := StripOptionHeaders(next)
+
+				// ACT
+				result := StripOptionHeaders(rt)
+
+				// ASSERT
+				test.That(t, result).Equals(rt)
+			},
+		},
+		{scenario: "CloseIdleConnections",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				idle := &idleTransport{}
+				hc := &http.Client{Transport: StripOptionHeaders(idle)}
+
+				// ACT
+				hc.CloseIdleConnections()
+
+				// ASSERT
+				test.IsTrue(t, idle.closed, "idle connections closed")
+			},
+		},
+		{scenario: "Using/*http.Client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hc := &http.Client{Transport: next}
+				c := &client{}
+
+				// ACT
+				err := Using(hc)(c)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, hc.Transport).Equals(http.RoundTripper(next), "supplied client is not modified")
+				if wrapped, ok := test.IsType[*http.Client](t, c.wrapped); ok {
+					test.That(t, wrapped.Transport).Equals(StripOptionHeaders(next))
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
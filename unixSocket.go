@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// unixSocketURL is the base url of a client configured with a unix:// url
+// (see: URL)
+const unixSocketURL = "http://localhost"
+
+// UnixSocket configures the client to connect to a server listening on a unix
+// domain socket (e.g. /var/run/docker.sock) instead of connecting to the host
+// of each request url.  Requests are otherwise unaffected; paths, queries and
+// headers are specified as usual.
+//
+// A client may also be configured to use a unix socket by specifying a url
+// with a unix scheme, identifying the path to the socket, using the URL option
+// (e.g. unix:///var/run/docker.sock).
+//
+// This is a transport option; see ConfigureTransport for details.
+func UnixSocket(path string) ClientOption {
+	return func(c *client) error {
+		if path == "" {
+			return errors.New("http: UnixSocket option: path must not be empty")
+		}
+		return ConfigureTransport(func(t *http.Transport) {
+			dialer := &net.Dialer{}
+			t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", path)
+			}
+			t.Proxy = nil
+		})(c)
+	}
+}
+
+// unixSocket configures a client with a base url of http://localhost and a
+// unix socket identified by the path of a specified unix:// url
+func unixSocket(c *client, u *url.URL) error {
+	if u.Host != "" || u.Path == "" {
+		return InvalidURLError{URL: u.String(), Err: errors.New("unix socket url must be of the form unix:///path/to/socket")}
+	}
+	c.url = unixSocketURL
+	return UnixSocket(u.Path)(c)
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestUnixSocket(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server starts a server listening on a unix socket, responding with the
+	// path and query of each request, returning the path to the socket
+	server := func(t *testing.T) string {
+		t.Helper()
+		dir, err := os.MkdirTemp("", "sock")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+		path := filepath.Join(dir, "test.sock")
+		l, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Host + r.URL.RequestURI()))
+		})}
+		go func() { _ = srv.Serve(l) }()
+		t.Cleanup(func() { _ = srv.Close() })
+		return path
+	}
+
+	body := func(t *testing.T, r *http.Response) string {
+		t.Helper()
+		b, _ := io.ReadAll(r.Body)
+		return string(b)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "UnixSocket option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sock := server(t)
+				c, err := NewClient("docker", URL("http://docker"), UnixSocket(sock))
+				test.Error(t, err).IsNil()
+
+				// ACT
+				r, err := c.Get(ctx, "v1.43/containers/json", request.Query(map[string]any{"all": 1}))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("docker/v1.43/containers/json?all=1")
+			},
+		},
+		{scenario: "unix url",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sock := server(t)
+				c, err := NewClient("docker", URL("unix://"+sock))
+				test.Error(t, err).IsNil()
+
+				// ACT
+				r, err := c.Get(ctx, "_ping")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("localhost/_ping")
+			},
+		},
+		{scenario: "with connect timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sock := server(t)
+				c, _ := NewClient("docker",
+					URL("unix://"+sock),
+					Timeouts(TimeoutConfig{Connect: time.Second}),
+				)
+
+				// ACT
+				r, err := c.Get(ctx, "_ping")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, body(t, r)).Equals("localhost/_ping")
+			},
+		},
+		{scenario: "no socket",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("docker", URL("unix:///no/such/socket.sock"))
+
+				// ACT
+				_, err := c.Get(ctx, "_ping")
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "empty path",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := NewClient("docker", UnixSocket(""))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInitialisingClient)
+			},
+		},
+		{scenario: "invalid unix url",
+			exec: func(t *testing.T) {
+				for _, u := range []string{"unix://docker.sock", "unix:"} {
+					// ACT
+					_, err := NewClient("docker", URL(u))
+
+					// ASSERT
+					test.Error(t, err).Is(ErrInvalidURL)
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/blugnu/http/request"
+)
+
+// UploadFile performs a Post request, appending the specified path to the
+// client url and applying any RequestOptions, with a multipart form data body
+// comprising a single part with a specified field name and filename.  The
+// content of the part is read from a supplied reader (e.g. an *os.File) as
+// the body is sent; it is not held in memory.
+//
+// If the reader is an io.Seeker the body may be replayed, so that the request
+// may be retried; otherwise the request should not be retried (see:
+// request.MultipartFile).  The reader is not closed by the client.
+//
+// To upload multiple files or form fields in a single request, use the
+// request.MultipartFormDataStream option.
+func (c client) UploadFile(
+	ctx context.Context,
+	path string,
+	fieldName string,
+	filename string,
+	r io.Reader,
+	opts ...RequestOption,
+) (*http.Response, error) {
+	opts = append(append([]RequestOption{}, opts...), request.MultipartFile(fieldName, filename, r))
+	return c.execute(ctx, http.MethodPost, path, opts...)
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/http/request"
+	"github.com/blugnu/test"
+)
+
+func TestUploadFile(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	// server returns a test server responding to each request with the next of
+	// a specified sequence of status codes, recording the method and the
+	// field name, filename and content of the uploaded file
+	server := func(t *testing.T, uploads *[]string, statusCodes ...int) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f, h, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer f.Close()
+			content, _ := io.ReadAll(f)
+			*uploads = append(*uploads, r.Method+" "+h.Filename+": "+string(content))
+
+			statusCode := statusCodes[0]
+			if len(statusCodes) > 1 {
+				statusCodes = statusCodes[1:]
+			}
+			w.WriteHeader(statusCode)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "uploaded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				uploads := []string{}
+				srv := server(t, &uploads, http.StatusCreated)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				r, err := c.UploadFile(ctx, "upload", "file", "file.txt", io.MultiReader(strings.NewReader("file content")),
+					request.AcceptStatus(http.StatusCreated),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusCreated)
+				test.Strings(t, uploads).Equals([]string{"POST file.txt: file content"})
+			},
+		},
+		{scenario: "streamed/authorized using a token source making requests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				uploads := []string{}
+				srv := server(t, &uploads, http.StatusCreated)
+				issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					_, _ = w.Write([]byte("token"))
+				}))
+				t.Cleanup(issuer.Close)
+				tc, _ := NewClient("issuer", URL(issuer.URL))
+				ts := NewTokenSource(func(ctx context.Context) (Token, error) {
+					rq, _ := http.NewRequestWithContext(ctx, http.MethodPost, issuer.URL, nil)
+					r, err := tc.Do(rq)
+					if err != nil {
+						return Token{}, err
+					}
+					token, _ := io.ReadAll(r.Body)
+					return Token{AccessToken: string(token)}, nil
+				}, 0)
+				c, _ := NewClient("name", URL(srv.URL), BearerAuth(ts))
+
+				// ACT
+				r, err := c.UploadFile(ctx, "upload", "file", "file.txt", io.MultiReader(strings.NewReader("file content")),
+					request.AcceptStatus(http.StatusCreated),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, r.StatusCode).Equals(http.StatusCreated)
+				test.Strings(t, uploads).Equals([]string{"POST file.txt: file content"})
+			},
+		},
+		{scenario: "retried with seekable reader",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				uploads := []string{}
+				srv := server(t, &uploads, http.StatusServiceUnavailable, http.StatusOK)
+				c, _ := NewClient("name", URL(srv.URL))
+
+				// ACT
+				_, err := c.UploadFile(ctx, "upload", "file", "file.txt", strings.NewReader("file content"),
+					request.MaxRetries(1),
+					request.RetryOnStatus(http.StatusServiceUnavailable),
+					request.Backoff(ConstantBackoff(time.Millisecond)),
+				)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, uploads).Equals([]string{
+					"POST file.txt: file content",
+					"POST file.txt: file content",
+				})
+			},
+		},
+		{scenario: "invalid request",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				uploads := []string{}
+				srv := server(t, &uploads, http.StatusOK)
+				c, _ := NewClient("name", URL(srv.URL))
+				opterr := errors.New("option error")
+
+				// ACT
+				_, err := c.UploadFile(ctx, "upload", "file", "file.txt", strings.NewReader("file content"),
+					func(*http.Request) error { return opterr },
+				)
+
+				// ASSERT
+				test.Error(t, err).Is(opterr)
+				test.That(t, len(uploads)).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/blugnu/http/request"
+)
+
+// baseURLer is implemented by clients (and BaseClient) to provide the base
+// url of the client to a URLBuilder
+type baseURLer interface {
+	baseURL() string
+}
+
+// baseURL returns the base url of the client
+func (c client) baseURL() string {
+	return c.url
+}
+
+// baseURL returns the base url of the embedded client, if known
+func (c BaseClient) baseURL() string {
+	if b, ok := c.HttpClient.(baseURLer); ok {
+		return b.baseURL()
+	}
+	return ""
+}
+
+// URLBuilder builds a url from a base url, joining and escaping path segments
+// and query parameters, for cases where the url itself is required rather
+// than a request (e.g. links in responses or request signing).
+//
+// A URLBuilder is obtained using URLFor (for the base url of a client) or
+// NewURLBuilder (for a specified base url).  Methods may be chained:
+//
+//	u := http.URLFor(c).Path("users", id).Query("expand", "roles").String()
+type URLBuilder struct {
+	rq  *http.Request
+	err error
+}
+
+// URLFor returns a URLBuilder for the base url of a client.  If the base url
+// of the client cannot be determined the url is built relative to an empty
+// base url.
+func URLFor(c HttpClient) *URLBuilder {
+	base := ""
+	if b, ok := c.(baseURLer); ok {
+		base = b.baseURL()
+	}
+	return NewURLBuilder(base)
+}
+
+// NewURLBuilder returns a URLBuilder for a specified base url.  If the base
+// url is invalid the error is returned by the URL method of the builder.
+func NewURLBuilder(base string) *URLBuilder {
+	u, err := url.Parse(base)
+	if err != nil {
+		return &URLBuilder{err: InvalidURLError{URL: base, Err: err}, rq: &http.Request{URL: &url.URL{}}}
+	}
+	return &URLBuilder{rq: &http.Request{URL: u}}
+}
+
+// Path appends segments to the path of the url.  Each segment is formatted
+// using fmt.Sprint and path escaped, so that a segment containing a "/" (for
+// example) remains a single segment.
+func (b *URLBuilder) Path(segments ...any) *URLBuilder {
+	for _, s := range segments {
+		b.rq.URL = b.rq.URL.JoinPath(url.PathEscape(fmt.Sprint(s)))
+	}
+	return b
+}
+
+// Query adds a key-value pair to the query of the url, formatted and escaped
+// as for request.QueryP.
+func (b *URLBuilder) Query(k string, v any) *URLBuilder {
+	_ = request.QueryP(k, v)(b.rq)
+	return b
+}
+
+// QuerySlice adds a key-value pair to the query of the url for each of a
+// number of values, as for request.QuerySlice.
+func (b *URLBuilder) QuerySlice(k string, values ...any) *URLBuilder {
+	_ = request.QuerySlice(k, values...)(b.rq)
+	return b
+}
+
+// URL returns the url built, or an error if the base url was invalid.  The
+// url returned is a copy; subsequent changes to the builder do not affect it.
+func (b *URLBuilder) URL() (*url.URL, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	u := *b.rq.URL
+	return &u, nil
+}
+
+// String returns the url built as a string.  If the base url was invalid an
+// empty string is returned.
+func (b *URLBuilder) String() string {
+	if b.err != nil {
+		return ""
+	}
+	return b.rq.URL.String()
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestURLBuilder(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "URLFor/client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewClient("name", URL("https://api.example.com/v1"))
+
+				// ACT
+				result := URLFor(c).Path("users", 42).Query("expand", "roles").String()
+
+				// ASSERT
+				test.That(t, result).Equals("https://api.example.com/v1/users/42?expand=roles")
+			},
+		},
+		{scenario: "URLFor/base client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c, _ := NewBaseClient("name", URL("https://api.example.com"))
+
+				// ACT
+				result := URLFor(c).Path("users").String()
+
+				// ASSERT
+				test.That(t, result).Equals("https://api.example.com/users")
+			},
+		},
+		{scenario: "URLFor/base client with other client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := BaseClient{HttpClient: BaseClient{}}
+
+				// ACT
+				result := URLFor(c).Path("users").String()
+
+				// ASSERT
+				test.That(t, result).Equals("users")
+			},
+		},
+		{scenario: "escaping",
+			exec: func(t *testing.T) {
+				// ACT
+				result := NewURLBuilder("http://hostname/").
+					Path("a/b", "c d").
+					Query("q", "x&y").
+					QuerySlice("id", 1, 2).
+					String()
+
+				// ASSERT
+				test.That(t, result).Equals("http://hostname/a%2Fb/c%20d?q=x%26y&id=1&id=2")
+			},
+		},
+		{scenario: "URL",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := NewURLBuilder("http://hostname").Path("a")
+
+				// ACT
+				result, err := b.URL()
+				b.Path("b")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.String()).Equals("http://hostname/a")
+			},
+		},
+		{scenario: "invalid base url",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := NewURLBuilder("http://host name:port").Path("a").Query("k", "v")
+
+				// ACT
+				result, err := b.URL()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidURL)
+				test.That(t, result).IsNil()
+				test.That(t, b.String()).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
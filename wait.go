@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"time"
+)
+
+// timeAfter is used by a client to wait; it may be replaced in tests to avoid
+// waiting
+var timeAfter = time.After
+
+// Reasons reported to an OnWait function when a client deliberately waits
+const (
+	WaitBackoff       = "backoff"
+	WaitInjectedDelay = "injected delay"
+	WaitPollInterval  = "poll interval"
+	WaitRateLimited   = "rate limited"
+	WaitRetryAfter    = "retry after"
+	WaitThrottled     = "throttled"
+)
+
+// OnWait configures a function to be called whenever the client deliberately
+// waits before performing a request, e.g. for a rate limit to reset.  The
+// function is called with the reason for the wait and the duration of the
+// wait, before the wait begins.
+//
+// This enables interactive tools to inform a user of the delay (e.g. "rate
+// limited, retrying in 20s…") rather than appearing to hang.
+//
+// The function is called on the goroutine performing the request and should
+// return promptly.
+func OnWait(fn func(reason string, d time.Duration)) ClientOption {
+	return func(c *client) error {
+		c.onWait = fn
+		return nil
+	}
+}
+
+// wait waits for a specified duration, returning the context error if the
+// context is done before the duration has elapsed.  Any OnWait function
+// configured on the client is called before waiting.
+func (c client) wait(ctx context.Context, reason string, d time.Duration) error {
+	if c.onWait != nil {
+		c.onWait(reason, d)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeAfter(d):
+		return nil
+	}
+}
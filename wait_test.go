@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestOnWait(t *testing.T) {
+	// ARRANGE
+	og := timeAfter
+	defer func() { timeAfter = og }()
+	immediate := func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	timeAfter = immediate
+
+	type waited struct {
+		reason string
+		d      time.Duration
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "wait/no OnWait function",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+
+				// ACT
+				err := c.wait(context.Background(), "reason", time.Second)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "wait/OnWait function",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				c := client{}
+				waits := []waited{}
+				_ = OnWait(func(reason string, d time.Duration) {
+					waits = append(waits, waited{reason, d})
+				})(&c)
+
+				// ACT
+				err := c.wait(context.Background(), "reason", time.Second)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, waits).Equals([]waited{{"reason", time.Second}})
+			},
+		},
+		{scenario: "wait/context cancelled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				timeAfter = func(time.Duration) <-chan time.Time { return nil }
+				defer func() { timeAfter = immediate }()
+				c := client{}
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				// ACT
+				err := c.wait(ctx, "reason", time.Second)
+
+				// ASSERT
+				test.Error(t, err).Is(context.Canceled)
+			},
+		},
+		{scenario: "rate limited",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				now := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+				clock := now
+				og := timeNow
+				defer func() { timeNow = og }()
+				timeNow = func() time.Time { return clock }
+
+				count := 0
+				doer := DoerFunc(func(rq *http.Request) (*http.Response, error) {
+					count++
+					r := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+					if count == 1 {
+						r.StatusCode = http.StatusTooManyRequests
+						r.Header.Set("Retry-After", "20")
+					}
+					return r, nil
+				})
+				waits := []waited{}
+				c, _ := NewClient("name", Using(doer), HandleTooManyRequests(1), OnWait(func(reason string, d time.Duration) {
+					waits = append(waits, waited{reason, d})
+					clock = clock.Add(d)
+				}))
+
+				// ACT
+				_, err := c.Get(context.Background(), "path")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, waits).Equals([]waited{{WaitRateLimited, 20 * time.Second}})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}